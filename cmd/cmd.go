@@ -8,10 +8,13 @@ import (
 	"github.com/urfave/cli/v2"
 
 	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/yavirt/cmd/flavor"
 	"github.com/projecteru2/yavirt/cmd/guest"
+	"github.com/projecteru2/yavirt/cmd/host"
 	"github.com/projecteru2/yavirt/cmd/image"
 	"github.com/projecteru2/yavirt/cmd/network"
 	"github.com/projecteru2/yavirt/cmd/run"
+	"github.com/projecteru2/yavirt/cmd/throttle"
 	"github.com/projecteru2/yavirt/configs"
 	"github.com/projecteru2/yavirt/internal/service/boar"
 	"github.com/projecteru2/yavirt/internal/ver"
@@ -72,9 +75,12 @@ func main() {
 				Name:   "info",
 				Action: run.Run(info),
 			},
+			flavor.Command(),
 			guest.Command(),
+			host.Command(),
 			image.Command(),
 			network.Command(),
+			throttle.Command(),
 		},
 
 		Version: "v",
@@ -0,0 +1,167 @@
+package flavor
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/yavirt/cmd/run"
+	"github.com/projecteru2/yavirt/internal/types"
+)
+
+// Command .
+func Command() *cli.Command {
+	return &cli.Command{
+		Name:  "flavor",
+		Usage: "manage the named instance types",
+		Subcommands: []*cli.Command{
+			{
+				Name:      "create",
+				ArgsUsage: "<name>",
+				Flags:     createFlags(),
+				Action:    run.Run(create),
+			},
+			{
+				Name:      "get",
+				ArgsUsage: "<name>",
+				Action:    run.Run(get),
+			},
+			{
+				Name:   "list",
+				Action: run.Run(list),
+			},
+			{
+				Name:      "rm",
+				ArgsUsage: "<name>",
+				Action:    run.Run(rm),
+			},
+		},
+	}
+}
+
+func createFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.IntFlag{
+			Name:     "cpu",
+			Required: true,
+		},
+		&cli.Int64Flag{
+			Name:     "memory",
+			Usage:    "in bytes",
+			Required: true,
+		},
+		&cli.Int64Flag{
+			Name:  "sys-disk",
+			Usage: "the size of sys disk in bytes, use the virtual size of image by default",
+		},
+		&cli.Int64Flag{
+			Name: "read-iops",
+		},
+		&cli.Int64Flag{
+			Name: "write-iops",
+		},
+		&cli.Int64Flag{
+			Name: "read-bps",
+		},
+		&cli.Int64Flag{
+			Name: "write-bps",
+		},
+		&cli.Int64Flag{
+			Name:  "bandwidth",
+			Usage: "the average bandwidth of NIC in bits per second",
+		},
+		&cli.Int64Flag{
+			Name:  "peak-bandwidth",
+			Usage: "the peak bandwidth of NIC in bits per second",
+		},
+		&cli.BoolFlag{
+			Name:  "cpu-pinning",
+			Usage: "pin vCPUs to dedicated pCPUs",
+		},
+		&cli.StringFlag{
+			Name:  "hugepages",
+			Usage: "back guest memory with hugepages, 2M or 1G",
+		},
+	}
+}
+
+func create(c *cli.Context, runtime run.Runtime) error {
+	name := c.Args().First()
+	if len(name) < 1 {
+		return errors.New("flavor name is required")
+	}
+	flavor := &types.Flavor{
+		Name:        name,
+		CPU:         c.Int("cpu"),
+		Memory:      c.Int64("memory"),
+		SysDiskSize: c.Int64("sys-disk"),
+	}
+	if c.IsSet("read-iops") || c.IsSet("write-iops") || c.IsSet("read-bps") || c.IsSet("write-bps") {
+		flavor.VolumeQoS = &types.VolumeQoS{
+			ReadIOPS:  c.Int64("read-iops"),
+			WriteIOPS: c.Int64("write-iops"),
+			ReadBPS:   c.Int64("read-bps"),
+			WriteBPS:  c.Int64("write-bps"),
+		}
+	}
+	if c.IsSet("bandwidth") || c.IsSet("peak-bandwidth") {
+		flavor.Bandwidth = &types.NICBandwidth{
+			Average: c.Int64("bandwidth"),
+			Peak:    c.Int64("peak-bandwidth"),
+		}
+	}
+	if c.Bool("cpu-pinning") {
+		flavor.CPUPinning = &types.CPUPinningRequest{}
+	}
+	if size := c.String("hugepages"); size != "" {
+		flavor.Hugepages = &types.HugepagesRequest{Size: size}
+	}
+
+	if err := runtime.Svc.CreateFlavor(runtime.Ctx, flavor); err != nil {
+		return errors.Wrap(err, "")
+	}
+	fmt.Printf("flavor %s created\n", name)
+	return nil
+}
+
+func get(c *cli.Context, runtime run.Runtime) error {
+	name := c.Args().First()
+	if len(name) < 1 {
+		return errors.New("flavor name is required")
+	}
+	flavor, err := runtime.Svc.GetFlavor(runtime.Ctx, name)
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+	bs, err := json.MarshalIndent(flavor, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+	fmt.Println(string(bs))
+	return nil
+}
+
+func list(_ *cli.Context, runtime run.Runtime) error {
+	flavors, err := runtime.Svc.ListFlavors(runtime.Ctx)
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+	for _, flavor := range flavors {
+		fmt.Printf("%s\tcpu: %d\tmemory: %d\tsys disk: %d\n", flavor.Name, flavor.CPU, flavor.Memory, flavor.SysDiskSize)
+	}
+	return nil
+}
+
+func rm(c *cli.Context, runtime run.Runtime) error {
+	name := c.Args().First()
+	if len(name) < 1 {
+		return errors.New("flavor name is required")
+	}
+	if err := runtime.Svc.DeleteFlavor(runtime.Ctx, name); err != nil {
+		return errors.Wrap(err, "")
+	}
+	fmt.Printf("flavor %s deleted\n", name)
+	return nil
+}
@@ -0,0 +1,66 @@
+package guest
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
+
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/yavirt/cmd/run"
+	"github.com/projecteru2/yavirt/internal/types"
+)
+
+func applyFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:     "file",
+			Aliases:  []string{"f"},
+			Usage:    "the guest spec in YAML",
+			Required: true,
+		},
+		&cli.BoolFlag{
+			Name:  "dry-run",
+			Usage: "print the plan without changing anything",
+		},
+	}
+}
+
+func apply(c *cli.Context, runtime run.Runtime) error {
+	bs, err := os.ReadFile(c.String("file"))
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+	spec := &types.GuestSpec{}
+	if err := yaml.Unmarshal(bs, spec); err != nil {
+		return errors.Wrapf(err, "invalid spec %s", c.String("file"))
+	}
+	if id := c.Args().First(); id != "" {
+		spec.ID = id
+	}
+
+	plan, err := runtime.Svc.ApplyGuest(runtime.Ctx, spec, c.Bool("dry-run"))
+	if plan != nil {
+		printPlan(plan)
+	}
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+	if !c.Bool("dry-run") && plan.ID != "" {
+		fmt.Printf("%s applied\n", plan.ID)
+	}
+	return nil
+}
+
+func printPlan(plan *types.ApplyPlan) {
+	if len(plan.Actions) == 0 {
+		fmt.Println("no changes")
+	}
+	for _, action := range plan.Actions {
+		fmt.Printf("  %s\n", action)
+	}
+	for _, warning := range plan.Warnings {
+		fmt.Printf("warning: %s\n", warning)
+	}
+}
@@ -65,6 +65,8 @@ func attachGuest(c *cli.Context, runtime run.Runtime) error { //nolint
 
 	flags := intertypes.NewOpenConsoleFlags(force, safe, cmds)
 	flags.Devname = devname
+	flags.User = c.String("user")
+	flags.ReadOnly = c.Bool("read-only")
 	stream := &buffer{
 		fromQ: utils.NewBytesQueue(),
 		to:    make(chan []byte, 10),
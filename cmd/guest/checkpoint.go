@@ -0,0 +1,91 @@
+package guest
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/yavirt/cmd/run"
+)
+
+func checkpointFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:     "name",
+			Required: true,
+		},
+	}
+}
+
+func createCheckpointFlags() []cli.Flag {
+	return append(checkpointFlags(), &cli.StringFlag{
+		Name: "description",
+	})
+}
+
+func createCheckpoint(c *cli.Context, runtime run.Runtime) error {
+	defer runtime.CancelFn()
+
+	id := c.Args().First()
+	if len(id) < 1 {
+		return errors.New("Guest ID is required")
+	}
+	cp, err := runtime.Svc.CreateCheckpoint(runtime.Ctx, id, c.String("name"), c.String("description"))
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+	bs, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+	fmt.Println(string(bs))
+	return nil
+}
+
+func listCheckpoints(c *cli.Context, runtime run.Runtime) error {
+	defer runtime.CancelFn()
+
+	id := c.Args().First()
+	if len(id) < 1 {
+		return errors.New("Guest ID is required")
+	}
+	cps, err := runtime.Svc.ListCheckpoints(runtime.Ctx, id)
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+	fmt.Printf("Total: %d checkpoint(s)\n", len(cps))
+	for _, cp := range cps {
+		fmt.Printf("%s\t%s\trunning=%t\t%s\n", cp.Name, cp.CreatedAt.Format("2006-01-02T15:04:05Z"), cp.Running, cp.Description)
+	}
+	return nil
+}
+
+func revertCheckpoint(c *cli.Context, runtime run.Runtime) error {
+	defer runtime.CancelFn()
+
+	id := c.Args().First()
+	if len(id) < 1 {
+		return errors.New("Guest ID is required")
+	}
+	if err := runtime.Svc.RevertCheckpoint(runtime.Ctx, id, c.String("name")); err != nil {
+		return errors.Wrap(err, "")
+	}
+	fmt.Printf("%s reverted to %s\n", id, c.String("name"))
+	return nil
+}
+
+func deleteCheckpoint(c *cli.Context, runtime run.Runtime) error {
+	defer runtime.CancelFn()
+
+	id := c.Args().First()
+	if len(id) < 1 {
+		return errors.New("Guest ID is required")
+	}
+	if err := runtime.Svc.DeleteCheckpoint(runtime.Ctx, id, c.String("name")); err != nil {
+		return errors.Wrap(err, "")
+	}
+	fmt.Printf("checkpoint %s of %s deleted\n", c.String("name"), id)
+	return nil
+}
@@ -0,0 +1,94 @@
+package guest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/yavirt/cmd/run"
+	"github.com/projecteru2/yavirt/internal/types"
+)
+
+func consoleTokenFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:  "kind",
+			Value: types.ConsoleKindVNC,
+			Usage: "vnc or serial",
+		},
+		&cli.StringFlag{
+			Name:  "user",
+			Usage: "who opens the console, it's recorded for the audit",
+		},
+		&cli.BoolFlag{
+			Name:  "read-only",
+			Usage: "open the serial console as a viewer",
+		},
+	}
+}
+
+func consoleToken(c *cli.Context, runtime run.Runtime) error {
+	defer runtime.CancelFn()
+
+	opts := types.ConsoleTokenOptions{
+		Kind:     c.String("kind"),
+		User:     c.String("user"),
+		ReadOnly: c.Bool("read-only"),
+	}
+	tok, err := runtime.Svc.CreateConsoleToken(runtime.Ctx, c.Args().First(), opts)
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+	bs, err := json.MarshalIndent(tok, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+	fmt.Println(string(bs))
+
+	return nil
+}
+
+func consoleLogFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.IntFlag{
+			Name:  "kb",
+			Value: 64,
+			Usage: "the size to print in KiB",
+		},
+	}
+}
+
+func consoleLog(c *cli.Context, runtime run.Runtime) error {
+	defer runtime.CancelFn()
+
+	bs, err := runtime.Svc.ConsoleLog(runtime.Ctx, c.Args().First(), c.Int("kb"))
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+	_, err = os.Stdout.Write(bs)
+	return err
+}
+
+func screenshotFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:     "output",
+			Aliases:  []string{"o"},
+			Required: true,
+			Usage:    "the PNG file to write",
+		},
+	}
+}
+
+func screenshot(c *cli.Context, runtime run.Runtime) error {
+	defer runtime.CancelFn()
+
+	png, err := runtime.Svc.Screenshot(runtime.Ctx, c.Args().First())
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+	return os.WriteFile(c.String("output"), png, 0600)
+}
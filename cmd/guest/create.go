@@ -3,6 +3,7 @@ package guest
 import (
 	"encoding/json"
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/urfave/cli/v2"
@@ -31,6 +32,18 @@ func createFlags() []cli.Flag {
 			Name:  "memory",
 			Value: utils.GB,
 		},
+		&cli.StringFlag{
+			Name:  "iso",
+			Usage: "install from the ISO, an image name or a host path under iso_dir, the image name is optional if it's in the image hub",
+		},
+		&cli.Int64Flag{
+			Name:  "sys-disk",
+			Usage: "the size of the blank sys disk in bytes for installing from the ISO",
+		},
+		&cli.StringFlag{
+			Name:  "boot-order",
+			Usage: "the boot devices separated by comma for installing from the ISO, like cdrom,hd",
+		},
 		&cli.StringFlag{
 			Name:  "storage",
 			Usage: "mount info. like, --storage /data0:53687091200",
@@ -44,6 +57,55 @@ func createFlags() []cli.Flag {
 		&cli.StringFlag{
 			Name: "image-user",
 		},
+		&cli.StringFlag{
+			Name:  "user-data",
+			Usage: "user-data file, a cloud-config, multipart MIME archive or shell script",
+		},
+		&cli.StringFlag{
+			Name:  "user-data-mode",
+			Value: types.UserDataModeMerge,
+			Usage: "merge or replace the generated user-data",
+		},
+		&cli.StringFlag{
+			Name:  "vendor-data",
+			Usage: "vendor-data file",
+		},
+		&cli.StringFlag{
+			Name:  "firmware",
+			Usage: "bios or uefi, use the firmware of image by default",
+		},
+		&cli.BoolFlag{
+			Name:  "secure-boot",
+			Usage: "enable secure boot, requires uefi firmware",
+		},
+		&cli.BoolFlag{
+			Name:  "tpm",
+			Usage: "attach an emulated TPM 2.0",
+		},
+		&cli.BoolFlag{
+			Name:  "cpu-pinning",
+			Usage: "pin vCPUs to dedicated pCPUs",
+		},
+		&cli.StringFlag{
+			Name:  "flavor",
+			Usage: "the named instance type, it decides the cpu and memory",
+		},
+		&cli.StringFlag{
+			Name:  "hugepages",
+			Usage: "back guest memory with hugepages, 2M or 1G",
+		},
+		&cli.StringFlag{
+			Name:  "cpu-mode",
+			Usage: "host-passthrough, host-model or custom, use the host's config by default",
+		},
+		&cli.StringFlag{
+			Name:  "cpu-model",
+			Usage: "the named CPU model of custom mode, e.g. Skylake-Server",
+		},
+		&cli.StringSliceFlag{
+			Name:  "cpu-feature",
+			Usage: "CPU feature, +name requires it and -name disables it",
+		},
 	}
 }
 
@@ -70,9 +132,40 @@ func create(c *cli.Context, runtime run.Runtime) error {
 		},
 		Resources: res,
 	}
+	if name := c.String("flavor"); name != "" {
+		opts.Labels[types.FlavorLabelKey] = name
+		if !c.IsSet("cpu") {
+			opts.CPU = 0
+		}
+		if !c.IsSet("memory") {
+			opts.Mem = 0
+		}
+	}
+	if err := setCloudInitLabel(c, opts.Labels); err != nil {
+		return errors.Wrap(err, "")
+	}
+	if err := setFirmwareLabel(c, opts.Labels); err != nil {
+		return errors.Wrap(err, "")
+	}
+	if err := setISOLabel(c, opts.Labels); err != nil {
+		return errors.Wrap(err, "")
+	}
+	if c.Bool("cpu-pinning") {
+		opts.Labels[types.CPUPinningLabelKey] = "{}"
+	}
+	if err := setCPUModelLabel(c, opts.Labels); err != nil {
+		return errors.Wrap(err, "")
+	}
+	if size := c.String("hugepages"); size != "" {
+		bs, err := json.Marshal(types.HugepagesRequest{Size: size})
+		if err != nil {
+			return errors.Wrap(err, "")
+		}
+		opts.Labels[types.HugepagesLabelKey] = string(bs)
+	}
 
 	switch {
-	case len(opts.ImageName) < 1:
+	case len(opts.ImageName) < 1 && c.String("iso") == "":
 		return fmt.Errorf("image name is required")
 	case opts.CPU < 1:
 		return fmt.Errorf("--cpu is required")
@@ -96,6 +189,57 @@ func create(c *cli.Context, runtime run.Runtime) error {
 	return nil
 }
 
+func setCloudInitLabel(c *cli.Context, labels map[string]string) error {
+	if c.String("user-data") == "" && c.String("vendor-data") == "" {
+		return nil
+	}
+	ciCfg := types.CloudInitConfig{
+		UserDataMode: c.String("user-data-mode"),
+	}
+	if fname := c.String("user-data"); fname != "" {
+		bs, err := os.ReadFile(fname)
+		if err != nil {
+			return err
+		}
+		ciCfg.UserData = string(bs)
+	}
+	if fname := c.String("vendor-data"); fname != "" {
+		bs, err := os.ReadFile(fname)
+		if err != nil {
+			return err
+		}
+		ciCfg.VendorData = string(bs)
+	}
+	bs, err := json.Marshal(ciCfg)
+	if err != nil {
+		return err
+	}
+	labels["instance/cloud-init"] = string(bs)
+	return nil
+}
+
+func setFirmwareLabel(c *cli.Context, labels map[string]string) error {
+	if !c.IsSet("firmware") && !c.IsSet("secure-boot") && !c.IsSet("tpm") {
+		return nil
+	}
+	fw := map[string]any{}
+	if c.IsSet("firmware") {
+		fw["type"] = c.String("firmware")
+	}
+	if c.IsSet("secure-boot") {
+		fw["secure_boot"] = c.Bool("secure-boot")
+	}
+	if c.IsSet("tpm") {
+		fw["tpm"] = c.Bool("tpm")
+	}
+	bs, err := json.Marshal(fw)
+	if err != nil {
+		return err
+	}
+	labels["instance/firmware"] = string(bs)
+	return nil
+}
+
 func generateResources(c *cli.Context) (ans map[string][]byte, err error) {
 	ans = map[string][]byte{}
 	// for storage resources
@@ -131,3 +275,26 @@ func generateResources(c *cli.Context) (ans map[string][]byte, err error) {
 	}
 	return
 }
+
+func setCPUModelLabel(c *cli.Context, labels map[string]string) error {
+	if !c.IsSet("cpu-mode") && !c.IsSet("cpu-model") && !c.IsSet("cpu-feature") {
+		return nil
+	}
+	model := types.CPUModel{
+		Mode:     c.String("cpu-mode"),
+		Model:    c.String("cpu-model"),
+		Features: c.StringSlice("cpu-feature"),
+	}
+	if model.Mode == "" && model.Model != "" {
+		model.Mode = types.CPUModeCustom
+	}
+	if err := model.Check(); err != nil {
+		return err
+	}
+	bs, err := json.Marshal(model)
+	if err != nil {
+		return err
+	}
+	labels[types.CPUModelLabelKey] = string(bs)
+	return nil
+}
@@ -0,0 +1,73 @@
+package guest
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/yavirt/cmd/run"
+	intertypes "github.com/projecteru2/yavirt/internal/types"
+)
+
+func dumpFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:  "format",
+			Usage: "elf, kdump-zlib, kdump-lzo or kdump-snappy, the default is the one of config",
+		},
+	}
+}
+
+func dump(c *cli.Context, runtime run.Runtime) error {
+	defer runtime.CancelFn()
+
+	id := c.Args().First()
+	if len(id) < 1 {
+		return errors.New("Guest ID is required")
+	}
+
+	progress := make(chan intertypes.DumpProgress)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case p := <-progress:
+				fmt.Printf("\rdumping %d/%d MiB", p.Processed>>20, p.Total>>20)
+			case <-done:
+				return
+			}
+		}
+	}()
+	d, err := runtime.Svc.DumpGuest(runtime.Ctx, id, &intertypes.DumpOptions{Format: c.String("format")}, progress)
+	close(done)
+	fmt.Println()
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+	bs, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+	fmt.Println(string(bs))
+	return nil
+}
+
+func listDumps(c *cli.Context, runtime run.Runtime) error {
+	defer runtime.CancelFn()
+
+	id := c.Args().First()
+	if len(id) < 1 {
+		return errors.New("Guest ID is required")
+	}
+	dumps, err := runtime.Svc.ListGuestDumps(runtime.Ctx, id)
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+	fmt.Printf("Total: %d dump(s)\n", len(dumps))
+	for _, d := range dumps {
+		fmt.Printf("%s\t%s\t%d\t%s\n", d.CreatedAt.Format("2006-01-02T15:04:05Z"), d.Format, d.Size, d.Path)
+	}
+	return nil
+}
@@ -26,6 +26,15 @@ func execFlags() []cli.Flag {
 			Name:  "safe",
 			Value: false,
 		},
+		&cli.BoolFlag{
+			Name:  "read-only",
+			Usage: "attach as a viewer, the console is shared with the writer",
+		},
+		&cli.StringFlag{
+			Name:  "user",
+			Value: os.Getenv("USER"),
+			Usage: "who attaches, it's recorded for the audit",
+		},
 	}
 }
 
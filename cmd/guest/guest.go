@@ -38,6 +38,11 @@ func Command() *cli.Command {
 				Name:   "resume",
 				Action: run.Run(resume),
 			},
+			{
+				Name:   "hibernate",
+				Usage:  "save the memory to disk and stop, the next start restores it",
+				Action: run.Run(hibernate),
+			},
 			{
 				Name:   "stop",
 				Flags:  controlFlags(),
@@ -58,6 +63,60 @@ func Command() *cli.Command {
 				Flags:  resizeFlags(),
 				Action: run.Run(resize),
 			},
+			{
+				Name:   "update-qos",
+				Usage:  "change the CPU, volume and NIC QoS without restart",
+				Flags:  updateQoSFlags(),
+				Action: run.Run(updateQoS),
+			},
+			{
+				Name:   "apply",
+				Usage:  "converge a guest to the spec file, or create it if it doesn't exist",
+				Flags:  applyFlags(),
+				Action: run.Run(apply),
+			},
+			{
+				Name:   "console-token",
+				Usage:  "issue a token to open the browser console on the HTTP server",
+				Flags:  consoleTokenFlags(),
+				Action: run.Run(consoleToken),
+			},
+			{
+				Name:   "console-log",
+				Usage:  "print the tail of the serial console log",
+				Flags:  consoleLogFlags(),
+				Action: run.Run(consoleLog),
+			},
+			{
+				Name:   "screenshot",
+				Usage:  "capture the display in PNG",
+				Flags:  screenshotFlags(),
+				Action: run.Run(screenshot),
+			},
+			{
+				Name:   "rescue",
+				Usage:  "boot from the rescue image with the system disk attached as a secondary disk",
+				Flags:  rescueFlags(),
+				Action: run.Run(rescue),
+			},
+			{
+				Name:   "unrescue",
+				Action: run.Run(unrescue),
+			},
+			{
+				Name:   "attach-iso",
+				Usage:  "insert an ISO, which is an image name or a host path under iso_dir",
+				Action: run.Run(attachISO),
+			},
+			{
+				Name:   "eject-iso",
+				Action: run.Run(ejectISO),
+			},
+			{
+				Name:   "boot-order",
+				Usage:  "set the boot devices separated by comma like cdrom,hd, boot from the sys disk if it's empty",
+				Action: run.Run(setBootOrder),
+			},
 			{
 				Name:   "capture",
 				Flags:  captureFlags(),
@@ -93,6 +152,36 @@ func Command() *cli.Command {
 				Flags:  restoreSnapshotFlags(),
 				Action: run.Run(restoreSnapshot),
 			},
+			{
+				Name:   "create-checkpoint",
+				Usage:  "capture the disks, and the memory if it's running",
+				Flags:  createCheckpointFlags(),
+				Action: run.Run(createCheckpoint),
+			},
+			{
+				Name:   "list-checkpoint",
+				Action: run.Run(listCheckpoints),
+			},
+			{
+				Name:   "revert-checkpoint",
+				Flags:  checkpointFlags(),
+				Action: run.Run(revertCheckpoint),
+			},
+			{
+				Name:   "delete-checkpoint",
+				Flags:  checkpointFlags(),
+				Action: run.Run(deleteCheckpoint),
+			},
+			{
+				Name:   "dump",
+				Usage:  "write the memory to the dump dir of host, the guest is paused while dumping",
+				Flags:  dumpFlags(),
+				Action: run.Run(dump),
+			},
+			{
+				Name:   "list-dump",
+				Action: run.Run(listDumps),
+			},
 		},
 	}
 }
@@ -0,0 +1,86 @@
+package guest
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/yavirt/cmd/run"
+	"github.com/projecteru2/yavirt/internal/types"
+)
+
+func attachISO(c *cli.Context, runtime run.Runtime) error {
+	defer runtime.CancelFn()
+
+	id, src := c.Args().Get(0), c.Args().Get(1)
+	if id == "" || src == "" {
+		return errors.New("usage: attach-iso <guest id> <image name or host path>")
+	}
+	if err := runtime.Svc.AttachISO(runtime.Ctx, id, src); err != nil {
+		return errors.Wrap(err, "")
+	}
+
+	fmt.Printf("%s is inserted into %s\n", src, id)
+
+	return nil
+}
+
+func ejectISO(c *cli.Context, runtime run.Runtime) error {
+	defer runtime.CancelFn()
+
+	id := c.Args().First()
+	if err := runtime.Svc.EjectISO(runtime.Ctx, id); err != nil {
+		return errors.Wrap(err, "")
+	}
+
+	fmt.Printf("%s ejected\n", id)
+
+	return nil
+}
+
+func setBootOrder(c *cli.Context, runtime run.Runtime) error {
+	defer runtime.CancelFn()
+
+	id := c.Args().First()
+	if id == "" {
+		return errors.New("usage: boot-order <guest id> [devices]")
+	}
+	var order []string
+	if devs := c.Args().Get(1); devs != "" {
+		order = strings.Split(devs, ",")
+	}
+	if err := runtime.Svc.SetBootOrder(runtime.Ctx, id, order); err != nil {
+		return errors.Wrap(err, "")
+	}
+
+	fmt.Printf("the boot order of %s is set to %v, it takes effect by the next boot\n", id, order)
+
+	return nil
+}
+
+// setISOLabel asks to install the guest from the ISO.
+func setISOLabel(c *cli.Context, labels map[string]string) error {
+	src := c.String("iso")
+	if src == "" {
+		return nil
+	}
+	iso := types.ISOInstall{
+		Source:      src,
+		SysDiskSize: c.Int64("sys-disk"),
+	}
+	if order := c.String("boot-order"); order != "" {
+		iso.BootOrder = strings.Split(order, ",")
+	}
+	if err := iso.Check(); err != nil {
+		return err
+	}
+	bs, err := json.Marshal(iso)
+	if err != nil {
+		return err
+	}
+	labels[types.ISOLabelKey] = string(bs)
+	return nil
+}
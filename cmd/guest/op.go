@@ -9,6 +9,7 @@ import (
 	"github.com/projecteru2/core/log"
 	"github.com/projecteru2/libyavirt/types"
 	"github.com/projecteru2/yavirt/cmd/run"
+	intertypes "github.com/projecteru2/yavirt/internal/types"
 )
 
 func controlFlags() []cli.Flag {
@@ -63,6 +64,20 @@ func resume(c *cli.Context, runtime run.Runtime) error {
 	return nil
 }
 
+func hibernate(c *cli.Context, runtime run.Runtime) error {
+	defer runtime.CancelFn()
+
+	id := c.Args().First()
+	log.Debugf(c.Context, "Hibernating guest %s", id)
+	if err := runtime.Svc.ControlGuest(runtime.Ctx, id, intertypes.HibernateOp.String(), false); err != nil {
+		return errors.Wrap(err, "")
+	}
+
+	fmt.Printf("%s hibernated\n", id)
+
+	return nil
+}
+
 func stop(c *cli.Context, runtime run.Runtime) error {
 	defer runtime.CancelFn()
 
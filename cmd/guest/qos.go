@@ -0,0 +1,103 @@
+package guest
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/yavirt/cmd/run"
+	"github.com/projecteru2/yavirt/internal/types"
+)
+
+func updateQoSFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.Uint64Flag{
+			Name:  "cpu-shares",
+			Usage: "the relative CPU weight, 0 means the default",
+		},
+		&cli.Uint64Flag{
+			Name:  "cpu-period",
+			Usage: "in microseconds, 0 means the default",
+		},
+		&cli.Int64Flag{
+			Name:  "cpu-quota",
+			Usage: "in microseconds of every period, 0 means unlimited",
+		},
+		&cli.StringSliceFlag{
+			Name:  "volume",
+			Usage: "<volume ID or device>:<read IOPS>:<write IOPS>:<read bytes/s>:<write bytes/s>, 0 means unlimited",
+		},
+		&cli.Int64Flag{
+			Name:  "bandwidth-average",
+			Usage: "the average bandwidth of NIC in bits/s, 0 means the default",
+		},
+		&cli.Int64Flag{
+			Name:  "bandwidth-peak",
+			Usage: "the peak bandwidth of NIC in bits/s, 0 means the default",
+		},
+	}
+}
+
+func updateQoS(c *cli.Context, runtime run.Runtime) error {
+	defer runtime.CancelFn()
+
+	id := c.Args().First()
+	if len(id) < 1 {
+		return errors.New("Guest ID is required")
+	}
+
+	qos := &types.GuestQoS{}
+	if c.IsSet("cpu-shares") || c.IsSet("cpu-period") || c.IsSet("cpu-quota") {
+		qos.CPU = &types.CPUQoS{
+			Shares: c.Uint64("cpu-shares"),
+			Period: c.Uint64("cpu-period"),
+			Quota:  c.Int64("cpu-quota"),
+		}
+	}
+	if c.IsSet("bandwidth-average") || c.IsSet("bandwidth-peak") {
+		qos.Bandwidth = &types.NICBandwidth{
+			Average: c.Int64("bandwidth-average"),
+			Peak:    c.Int64("bandwidth-peak"),
+		}
+	}
+	for _, raw := range c.StringSlice("volume") {
+		key, vq, err := parseVolumeQoS(raw)
+		if err != nil {
+			return err
+		}
+		if qos.Volumes == nil {
+			qos.Volumes = map[string]types.VolumeQoS{}
+		}
+		qos.Volumes[key] = vq
+	}
+
+	if err := runtime.Svc.UpdateGuestQoS(runtime.Ctx, id, qos); err != nil {
+		return errors.Wrap(err, "")
+	}
+	fmt.Printf("%s QoS updated\n", id)
+	return nil
+}
+
+func parseVolumeQoS(raw string) (string, types.VolumeQoS, error) {
+	parts := strings.Split(raw, ":")
+	if len(parts) != 5 {
+		return "", types.VolumeQoS{}, errors.Newf("invalid volume QoS %s", raw)
+	}
+	var vals [4]int64
+	for i, s := range parts[1:] {
+		v, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return "", types.VolumeQoS{}, errors.Wrapf(err, "invalid volume QoS %s", raw)
+		}
+		vals[i] = v
+	}
+	return parts[0], types.VolumeQoS{
+		ReadIOPS:  vals[0],
+		WriteIOPS: vals[1],
+		ReadBPS:   vals[2],
+		WriteBPS:  vals[3],
+	}, nil
+}
@@ -0,0 +1,45 @@
+package guest
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/yavirt/cmd/run"
+)
+
+func rescueFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:  "image",
+			Usage: "the rescue image, the default one of config is used if it's empty",
+		},
+	}
+}
+
+func rescue(c *cli.Context, runtime run.Runtime) error {
+	defer runtime.CancelFn()
+
+	id := c.Args().First()
+	if err := runtime.Svc.RescueGuest(runtime.Ctx, id, c.String("image")); err != nil {
+		return errors.Wrap(err, "")
+	}
+
+	fmt.Printf("%s is in rescue mode\n", id)
+
+	return nil
+}
+
+func unrescue(c *cli.Context, runtime run.Runtime) error {
+	defer runtime.CancelFn()
+
+	id := c.Args().First()
+	if err := runtime.Svc.UnrescueGuest(runtime.Ctx, id); err != nil {
+		return errors.Wrap(err, "")
+	}
+
+	fmt.Printf("%s is unrescued\n", id)
+
+	return nil
+}
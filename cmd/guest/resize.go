@@ -23,6 +23,10 @@ func resizeFlags() []cli.Flag {
 		&cli.Int64Flag{
 			Name: "memory",
 		},
+		&cli.StringFlag{
+			Name:  "flavor",
+			Usage: "resize to the cpu and memory of the named instance type",
+		},
 	}
 }
 
@@ -44,9 +48,10 @@ func resize(c *cli.Context, runtime run.Runtime) (err error) {
 	cpu := c.Int("cpu")
 	mem := c.Int64("memory")
 	req := &types.GuestResizeOption{
-		ID:  id,
-		CPU: cpu,
-		Mem: mem,
+		ID:     id,
+		CPU:    cpu,
+		Mem:    mem,
+		Flavor: c.String("flavor"),
 		//TODO: add resources
 	}
 	if err = runtime.Svc.ResizeGuest(runtime.Ctx, id, req); err != nil {
@@ -0,0 +1,189 @@
+package host
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/yavirt/cmd/run"
+	"github.com/projecteru2/yavirt/configs"
+	"github.com/projecteru2/yavirt/internal/types"
+)
+
+// Command .
+func Command() *cli.Command {
+	return &cli.Command{
+		Name: "host",
+		Subcommands: []*cli.Command{
+			{
+				Name:   "cpu",
+				Usage:  "show the CPU definition of this host",
+				Action: run.Run(cpu),
+			},
+			{
+				Name:   "baseline-cpu",
+				Usage:  "compute the CPU model which all registered hosts support",
+				Action: run.Run(baselineCPU),
+			},
+			{
+				Name:   "deregister",
+				Usage:  "remove the CPU of this host from the baseline, it mustn't have guests",
+				Action: run.Run(deregister),
+			},
+			{
+				Name:  "maintenance",
+				Usage: "put this host into maintenance, or bring it back",
+				Subcommands: []*cli.Command{
+					{
+						Name:  "enter",
+						Usage: "stop accepting guests, mark the eru node unavailable and optionally evacuate the guests",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:  "reason",
+								Usage: "why the host is in maintenance",
+							},
+							&cli.StringFlag{
+								Name:  "evacuate",
+								Usage: "stop or hibernate the running guests, they're left running if it's empty",
+							},
+						},
+						Action: run.Run(enterMaintenance),
+					},
+					{
+						Name:   "exit",
+						Usage:  "bring this host back, the evacuated guests aren't started",
+						Action: run.Run(exitMaintenance),
+					},
+					{
+						Name:   "status",
+						Usage:  "show the maintenance and the progress of evacuation",
+						Action: run.Run(maintenanceStatus),
+					},
+				},
+			},
+			{
+				Name:  "events",
+				Usage: "list the events of guests in the journal of this host",
+				Flags: []cli.Flag{
+					&cli.Uint64Flag{
+						Name:  "since",
+						Usage: "only the events after the seq",
+					},
+					&cli.IntFlag{
+						Name:  "limit",
+						Usage: "the max number of events, 0 means all",
+					},
+				},
+				Action: run.Run(events),
+			},
+		},
+	}
+}
+
+func cpu(_ *cli.Context, runtime run.Runtime) error {
+	xml, err := runtime.Svc.HostCPU(runtime.Ctx)
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+	fmt.Println(xml)
+	return nil
+}
+
+func baselineCPU(_ *cli.Context, runtime run.Runtime) error {
+	model, err := runtime.Svc.BaselineCPU(runtime.Ctx)
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+	bs, err := json.MarshalIndent(model, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+	fmt.Println(string(bs))
+	return nil
+}
+
+func deregister(_ *cli.Context, runtime run.Runtime) error {
+	defer runtime.CancelFn()
+	if err := runtime.Svc.DeregisterHost(runtime.Ctx); err != nil {
+		return errors.Wrap(err, "")
+	}
+	fmt.Printf("%s is deregistered\n", configs.Hostname())
+	return nil
+}
+
+func enterMaintenance(c *cli.Context, runtime run.Runtime) error {
+	defer runtime.CancelFn()
+	m, err := runtime.Svc.EnterMaintenance(runtime.Ctx, types.MaintenanceOptions{
+		Reason:   c.String("reason"),
+		Evacuate: c.String("evacuate"),
+	})
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+	fmt.Printf("%s is in maintenance\n", configs.Hostname())
+
+	// the evacuation runs in this process, so wait for it
+	for m.Evacuation != nil && !m.Evacuation.Finished {
+		fmt.Printf("evacuated %d/%d\n", len(m.Evacuation.Done)+len(m.Evacuation.Failed), m.Evacuation.Total)
+		select {
+		case <-runtime.Ctx.Done():
+			return errors.Wrap(runtime.Ctx.Err(), "the evacuation is interrupted")
+		case <-time.After(2 * time.Second):
+		}
+		if m, err = runtime.Svc.GetMaintenance(runtime.Ctx); err != nil {
+			return errors.Wrap(err, "")
+		}
+		if m == nil {
+			return errors.New("the maintenance is exited during evacuation")
+		}
+	}
+	if m.Evacuation != nil {
+		fmt.Printf("evacuated %d guests, %d failed\n", len(m.Evacuation.Done), len(m.Evacuation.Failed))
+		for id, msg := range m.Evacuation.Failed {
+			fmt.Printf("  %s: %s\n", id, msg)
+		}
+	}
+	return nil
+}
+
+func exitMaintenance(_ *cli.Context, runtime run.Runtime) error {
+	defer runtime.CancelFn()
+	return errors.Wrap(runtime.Svc.ExitMaintenance(runtime.Ctx), "")
+}
+
+func maintenanceStatus(_ *cli.Context, runtime run.Runtime) error {
+	defer runtime.CancelFn()
+	m, err := runtime.Svc.GetMaintenance(runtime.Ctx)
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+	if m == nil {
+		fmt.Println("not in maintenance")
+		return nil
+	}
+	bs, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+	fmt.Println(string(bs))
+	return nil
+}
+
+func events(c *cli.Context, runtime run.Runtime) error {
+	defer runtime.CancelFn()
+	evts, err := runtime.Svc.ListEvents(runtime.Ctx, c.Uint64("since"), c.Int("limit"))
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+	for _, evt := range evts {
+		result := evt.Status
+		if evt.Failed() {
+			result = "error: " + evt.Error
+		}
+		fmt.Printf("%d\t%s\t%s\t%s\t%s\t%s\n", evt.Seq, evt.Time.Format(time.RFC3339), evt.ID, evt.Op, result, evt.Detail)
+	}
+	return nil
+}
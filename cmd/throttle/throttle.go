@@ -0,0 +1,147 @@
+package throttle
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/yavirt/cmd/run"
+	"github.com/projecteru2/yavirt/internal/types"
+)
+
+// Command .
+func Command() *cli.Command {
+	return &cli.Command{
+		Name:  "throttle-group",
+		Usage: "manage the IO budgets shared by the volumes of a guest, every guest has its own budget",
+		Subcommands: []*cli.Command{
+			{
+				Name:      "create",
+				ArgsUsage: "<name>",
+				Flags:     limitFlags(),
+				Action:    run.Run(create),
+			},
+			{
+				Name:      "get",
+				ArgsUsage: "<name>",
+				Action:    run.Run(get),
+			},
+			{
+				Name:   "list",
+				Action: run.Run(list),
+			},
+			{
+				Name:      "update",
+				Usage:     "change the limits, and apply them to the guests of this host",
+				ArgsUsage: "<name>",
+				Flags:     limitFlags(),
+				Action:    run.Run(update),
+			},
+			{
+				Name:      "rm",
+				ArgsUsage: "<name>",
+				Action:    run.Run(rm),
+			},
+		},
+	}
+}
+
+func limitFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.Int64Flag{
+			Name: "read-iops",
+		},
+		&cli.Int64Flag{
+			Name: "write-iops",
+		},
+		&cli.Int64Flag{
+			Name: "read-bps",
+		},
+		&cli.Int64Flag{
+			Name: "write-bps",
+		},
+	}
+}
+
+func parseGroup(c *cli.Context) (*types.ThrottleGroup, error) {
+	name := c.Args().First()
+	if len(name) < 1 {
+		return nil, errors.New("throttle group name is required")
+	}
+	return &types.ThrottleGroup{
+		Name: name,
+		VolumeQoS: types.VolumeQoS{
+			ReadIOPS:  c.Int64("read-iops"),
+			WriteIOPS: c.Int64("write-iops"),
+			ReadBPS:   c.Int64("read-bps"),
+			WriteBPS:  c.Int64("write-bps"),
+		},
+	}, nil
+}
+
+func create(c *cli.Context, runtime run.Runtime) error {
+	group, err := parseGroup(c)
+	if err != nil {
+		return err
+	}
+	if err := runtime.Svc.CreateThrottleGroup(runtime.Ctx, group); err != nil {
+		return errors.Wrap(err, "")
+	}
+	fmt.Printf("throttle group %s created, the budget is per guest and isn't shared across guests\n", group.Name)
+	return nil
+}
+
+func get(c *cli.Context, runtime run.Runtime) error {
+	name := c.Args().First()
+	if len(name) < 1 {
+		return errors.New("throttle group name is required")
+	}
+	group, err := runtime.Svc.GetThrottleGroup(runtime.Ctx, name)
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+	bs, err := json.MarshalIndent(group, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+	fmt.Println(string(bs))
+	return nil
+}
+
+func list(_ *cli.Context, runtime run.Runtime) error {
+	groups, err := runtime.Svc.ListThrottleGroups(runtime.Ctx)
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+	for _, group := range groups {
+		fmt.Printf("%s\tscope: %s\tread iops: %d\twrite iops: %d\tread bps: %d\twrite bps: %d\n",
+			group.Name, group.Scope, group.ReadIOPS, group.WriteIOPS, group.ReadBPS, group.WriteBPS)
+	}
+	return nil
+}
+
+func update(c *cli.Context, runtime run.Runtime) error {
+	group, err := parseGroup(c)
+	if err != nil {
+		return err
+	}
+	if err := runtime.Svc.UpdateThrottleGroup(runtime.Ctx, group); err != nil {
+		return errors.Wrap(err, "")
+	}
+	fmt.Printf("throttle group %s updated\n", group.Name)
+	return nil
+}
+
+func rm(c *cli.Context, runtime run.Runtime) error {
+	name := c.Args().First()
+	if len(name) < 1 {
+		return errors.New("throttle group name is required")
+	}
+	if err := runtime.Svc.DeleteThrottleGroup(runtime.Ctx, name); err != nil {
+		return errors.Wrap(err, "")
+	}
+	fmt.Printf("throttle group %s deleted\n", name)
+	return nil
+}
@@ -194,6 +194,222 @@ type ResourceConfig struct {
 	ExcludePCIs     []string `toml:"exclude_pcis"`
 
 	GPUProductMap map[string]string `toml:"gpu_product_map"`
+
+	CPUPinning    CPUPinningConfig    `toml:"cpu_pinning"`
+	CPUHotplug    CPUHotplugConfig    `toml:"cpu_hotplug"`
+	MemoryHotplug MemoryHotplugConfig `toml:"memory_hotplug"`
+	Balloon       BalloonConfig       `toml:"balloon"`
+}
+
+// CPUHotplugConfig .
+type CPUHotplugConfig struct {
+	Enable bool `toml:"enable"`
+	// the max vCPUs of guest is MaxCPURatio * vCPUs, capped by max_cpu
+	MaxCPURatio float64 `toml:"max_cpu_ratio" default:"2"`
+}
+
+// MemoryHotplugConfig .
+type MemoryHotplugConfig struct {
+	// virtio-mem or dimm, empty means memory can't grow beyond the boot-time one
+	Mode string `toml:"mode"`
+	// the max memory of guest is MaxMemoryRatio * boot memory, capped by max_memory
+	MaxMemoryRatio float64 `toml:"max_memory_ratio" default:"4"`
+	Slots          int     `toml:"slots" default:"16"`
+	// the granularity of hotplug, in bytes
+	BlockSize int64 `toml:"block_size" default:"2097152"`
+}
+
+// BalloonConfig is the policy of reclaiming idle guest memory,
+// it depends on the memory stats which are collected every mem_stats_period.
+type BalloonConfig struct {
+	Enable   bool          `toml:"enable"`
+	Interval time.Duration `toml:"interval" default:"1m"`
+	// reclaim when the usable memory is more than HighWatermark of current memory
+	HighWatermark float64 `toml:"high_watermark" default:"0.5"`
+	// give back when the usable memory is less than LowWatermark of current memory
+	LowWatermark float64 `toml:"low_watermark" default:"0.1"`
+	// the ratio of usable memory after adjusting
+	TargetFree float64 `toml:"target_free" default:"0.3"`
+	// never shrink below MinRatio of the maximum memory
+	MinRatio float64 `toml:"min_ratio" default:"0.5"`
+}
+
+// CPUPinningConfig .
+type CPUPinningConfig struct {
+	// the pCPUs reserved for host, they are excluded from the capacity
+	// and are used by emulator threads of pinned guests if not dedicated.
+	ReservedCPUs string `toml:"reserved_cpus"`
+	// allocate an extra dedicated pCPU for the emulator threads of each pinned guest
+	DedicatedEmulator bool `toml:"dedicated_emulator"`
+}
+
+// CloudInitConfig contains the vendor-data used by cloud-init,
+// the image-specific one takes precedence over the host-wide one.
+type CloudInitConfig struct {
+	VendorDataFile       string            `toml:"vendor_data_file"`
+	ImageVendorDataFiles map[string]string `toml:"image_vendor_data_files"` // image name -> vendor-data file
+}
+
+// GetVendorDataFile returns the vendor-data file for the image.
+func (c *CloudInitConfig) GetVendorDataFile(imgName string) string {
+	if fname, ok := c.ImageVendorDataFiles[imgName]; ok {
+		return fname
+	}
+	return c.VendorDataFile
+}
+
+// FirmwareConfig contains the OVMF firmwares and the default firmware of guests,
+// the firmware of image or guest takes precedence over the default one.
+type FirmwareConfig struct {
+	Default        string `toml:"default" default:"bios"` // bios or uefi
+	OVMFCode       string `toml:"ovmf_code" default:"/usr/share/OVMF/OVMF_CODE_4M.fd"`
+	OVMFVars       string `toml:"ovmf_vars" default:"/usr/share/OVMF/OVMF_VARS_4M.fd"`
+	OVMFSecureCode string `toml:"ovmf_secure_code" default:"/usr/share/OVMF/OVMF_CODE_4M.secboot.fd"`
+	OVMFSecureVars string `toml:"ovmf_secure_vars" default:"/usr/share/OVMF/OVMF_VARS_4M.ms.fd"`
+	TPMModel       string `toml:"tpm_model" default:"tpm-crb"`
+	SwtpmStateDir  string `toml:"swtpm_state_dir" default:"/var/lib/libvirt/swtpm"`
+}
+
+// CPUModelConfig is the default CPU model of guests on this host,
+// the hosts in the same pool should share the same config so guests can move between them.
+type CPUModelConfig struct {
+	Mode     string   `toml:"mode" default:"host-passthrough"` // host-passthrough, host-model or custom
+	Model    string   `toml:"model"`                           // required by custom mode, e.g. Skylake-Server
+	Features []string `toml:"features"`                        // e.g. ["+avx2", "-vmx"]
+}
+
+// ConsoleConfig is for the browser consoles which are proxied by the WebSocket
+// endpoints of the HTTP server, they are accessed by the signed tokens.
+type ConsoleConfig struct {
+	TokenSecret    string        `toml:"token_secret"` // a random one is generated if it's empty
+	TokenTTL       time.Duration `toml:"token_ttl" default:"1m"`
+	AllowedOrigins []string      `toml:"allowed_origins"` // all origins are allowed if it's empty
+
+	// the sessions are recorded in the asciicast v2 format if RecordDir isn't empty,
+	// the recordings older than RecordRetention are removed, 0 keeps them forever.
+	RecordDir       string        `toml:"record_dir"`
+	RecordRetention time.Duration `toml:"record_retention" default:"720h"`
+
+	// the serial console of every guest is logged into LogDir by virtlogd, which
+	// rotates the logs by max_size and max_backups in virtlogd.conf,
+	// LogBackups must be the same as max_backups.
+	LogDir     string `toml:"log_dir"` // default is <virt_dir>/console
+	LogBackups int    `toml:"log_backups" default:"3"`
+}
+
+const (
+	// ShutdownActionNone leaves the guests running.
+	ShutdownActionNone = "none"
+	// ShutdownActionStop shuts the guests down.
+	ShutdownActionStop = "stop"
+	// ShutdownActionHibernate saves the guests to disk, they're restored by starting.
+	ShutdownActionHibernate = "hibernate"
+)
+
+// LifecycleConfig is how the guests are handled when yavirtd is shut down and
+// started, e.g. by the reboot of host.
+type LifecycleConfig struct {
+	// the running guests are handled by ShutdownAction in parallel by the shutdown
+	// of host, the ones which aren't done before ShutdownTimeout are left as they are.
+	// ShutdownConcurrency also limits the evacuation of maintenance.
+	ShutdownAction      string        `toml:"shutdown_action" default:"none"`
+	ShutdownTimeout     time.Duration `toml:"shutdown_timeout" default:"3m"`
+	ShutdownConcurrency int           `toml:"shutdown_concurrency" default:"8"`
+
+	// the guests which were running at shutdown are started again in groups,
+	// see the instance/boot-* labels, StartupDelay is waited between the groups.
+	// The guests aren't autostarted by libvirt then.
+	StartupRestore bool          `toml:"startup_restore"`
+	StartupDelay   time.Duration `toml:"startup_delay" default:"10s"`
+}
+
+// Check .
+func (c *LifecycleConfig) Check() error {
+	switch c.ShutdownAction {
+	case ShutdownActionNone, ShutdownActionStop, ShutdownActionHibernate:
+	default:
+		return errors.Newf("invalid lifecycle shutdown_action %s", c.ShutdownAction)
+	}
+	if c.ShutdownConcurrency < 1 {
+		return errors.New("lifecycle shutdown_concurrency must be positive")
+	}
+	return nil
+}
+
+const (
+	// CrashActionReset restarts the crashed guest.
+	CrashActionReset = "reset"
+	// CrashActionPoweroff stops the crashed guest.
+	CrashActionPoweroff = "poweroff"
+	// CrashActionPause leaves the crashed guest paused for debugging.
+	CrashActionPause = "pause"
+	// CrashActionDump collects the crash dump, then restarts the guest.
+	CrashActionDump = "dump"
+)
+
+// CrashConfig is how the guests are watched and handled when they crash,
+// the instance/crash-policy label of guest overrides it.
+type CrashConfig struct {
+	// the i6300esb watchdog and the pvpanic device of guests
+	Watchdog bool `toml:"watchdog"`
+	PVPanic  bool `toml:"pvpanic"`
+
+	// Action is taken when the watchdog expires or the guest panics,
+	// the crash dump is collected into the core dump dir before it if AutoDump is set.
+	Action   string `toml:"action" default:"reset"`
+	AutoDump bool   `toml:"auto_dump"`
+}
+
+// Check .
+func (c *CrashConfig) Check() error {
+	return CheckCrashAction(c.Action)
+}
+
+// CheckCrashAction checks if action is one of the CrashAction*.
+func CheckCrashAction(action string) error {
+	switch action {
+	case CrashActionReset, CrashActionPoweroff, CrashActionPause, CrashActionDump:
+		return nil
+	default:
+		return errors.Newf("invalid crash action %s", action)
+	}
+}
+
+const (
+	// DumpFormatELF is the ELF core which gdb and crash read.
+	DumpFormatELF = "elf"
+	// DumpFormatKdumpZlib is the kdump-compressed format by zlib, crash reads it.
+	DumpFormatKdumpZlib = "kdump-zlib"
+	// DumpFormatKdumpLzo is the kdump-compressed format by lzo.
+	DumpFormatKdumpLzo = "kdump-lzo"
+	// DumpFormatKdumpSnappy is the kdump-compressed format by snappy.
+	DumpFormatKdumpSnappy = "kdump-snappy"
+)
+
+// CoreDumpConfig is where and how the memory dumps of guests are written.
+type CoreDumpConfig struct {
+	Dir    string `toml:"dir"` // default is <virt_dir>/dumps
+	Format string `toml:"format" default:"kdump-zlib"`
+
+	// a dump is refused if the dumps would exceed Quota bytes with it, 0 means unlimited,
+	// the dumps older than Retention are removed, 0 keeps them forever.
+	Quota     int64         `toml:"quota" default:"107374182400"`
+	Retention time.Duration `toml:"retention" default:"168h"`
+}
+
+// Check .
+func (c *CoreDumpConfig) Check() error {
+	return CheckDumpFormat(c.Format)
+}
+
+// CheckDumpFormat checks if format is one of the DumpFormat*.
+func CheckDumpFormat(format string) error {
+	switch format {
+	case DumpFormatELF, DumpFormatKdumpZlib, DumpFormatKdumpLzo, DumpFormatKdumpSnappy:
+		return nil
+	default:
+		return errors.Newf("invalid dump format %s", format)
+	}
 }
 
 type VMAuthConfig struct {
@@ -238,6 +454,13 @@ type Config struct {
 
 	MaxSnapshotsCount     int `toml:"max_snapshots_count" default:"30"`
 	SnapshotRestorableDay int `toml:"snapshot_restorable_days" default:"7"`
+	MaxCheckpointsCount   int `toml:"max_checkpoints_count" default:"10"`
+
+	// the latest EventJournalSize events of guests are kept, 0 disables the journal
+	EventJournalSize int `toml:"event_journal_size" default:"1000"`
+	// the throttle groups updated on the other hosts are applied to the guests
+	// of this host every ThrottleGroupSyncInterval, 0 disables the sync
+	ThrottleGroupSyncInterval time.Duration `toml:"throttle_group_sync_interval" default:"1m"`
 
 	MetaTimeout time.Duration `toml:"meta_timeout" default:"1m"`
 	MetaType    string        `toml:"meta_type" default:"etcd"`
@@ -249,6 +472,11 @@ type Config struct {
 	VirtBridge              string `toml:"virt_bridge" default:"yavirbr0"`
 	VirtCPUCachePassthrough bool   `toml:"virt_cpu_cache_passthrough" default:"true"`
 
+	// the default image to boot the guests in rescue mode
+	RescueImage string `toml:"rescue_image"`
+	// the host path ISOs must be under ISODir, default is <virt_dir>/iso
+	ISODir string `toml:"iso_dir"`
+
 	Batches []*Batch `toml:"batches"`
 
 	// system recovery
@@ -258,17 +486,24 @@ type Config struct {
 	RecoveryInterval      time.Duration `toml:"recovery_interval" default:"10m"`
 
 	// host-related config
-	Host     HostConfig           `toml:"host"`
-	Eru      EruConfig            `toml:"eru"`
-	Etcd     ETCDConfig           `toml:"etcd"`
-	Network  NetworkConfig        `toml:"network"`
-	Storage  StorageConfig        `toml:"storage"`
-	Resource ResourceConfig       `toml:"resource"`
-	ImageHub vmitypes.Config      `toml:"image_hub"`
-	Auth     coretypes.AuthConfig `toml:"auth"` // grpc auth
-	VMAuth   VMAuthConfig         `toml:"vm_auth"`
-	Log      LogConfig            `toml:"log"`
-	Notify   bison.Config         `toml:"notify"`
+	Host      HostConfig           `toml:"host"`
+	Eru       EruConfig            `toml:"eru"`
+	Etcd      ETCDConfig           `toml:"etcd"`
+	Network   NetworkConfig        `toml:"network"`
+	Storage   StorageConfig        `toml:"storage"`
+	Resource  ResourceConfig       `toml:"resource"`
+	ImageHub  vmitypes.Config      `toml:"image_hub"`
+	Auth      coretypes.AuthConfig `toml:"auth"` // grpc auth
+	VMAuth    VMAuthConfig         `toml:"vm_auth"`
+	CloudInit CloudInitConfig      `toml:"cloud_init"`
+	Firmware  FirmwareConfig       `toml:"firmware"`
+	CPUModel  CPUModelConfig       `toml:"cpu_model"`
+	Console   ConsoleConfig        `toml:"console"`
+	Lifecycle LifecycleConfig      `toml:"lifecycle"`
+	Crash     CrashConfig          `toml:"crash"`
+	CoreDump  CoreDumpConfig       `toml:"core_dump"`
+	Log       LogConfig            `toml:"log"`
+	Notify    bison.Config         `toml:"notify"`
 }
 
 func Hostname() string {
@@ -370,6 +605,15 @@ func (cfg *Config) Prepare(c *cli.Context) (err error) {
 	if err := cfg.ImageHub.CheckAndRefine(); err != nil {
 		return err
 	}
+	if err := cfg.Lifecycle.Check(); err != nil {
+		return err
+	}
+	if err := cfg.Crash.Check(); err != nil {
+		return err
+	}
+	if err := cfg.CoreDump.Check(); err != nil {
+		return err
+	}
 	return cfg.loadVirtDirs()
 }
 
@@ -417,9 +661,18 @@ func (cfg *Config) loadVirtDirs() error {
 	cfg.VirtFlockDir = filepath.Join(cfg.VirtDir, "flock")
 	cfg.VirtTmplDir = filepath.Join(cfg.VirtDir, "template")
 	cfg.VirtCloudInitDir = filepath.Join(cfg.VirtDir, "cloud-init")
+	if cfg.Console.LogDir == "" {
+		cfg.Console.LogDir = filepath.Join(cfg.VirtDir, "console")
+	}
+	if cfg.CoreDump.Dir == "" {
+		cfg.CoreDump.Dir = filepath.Join(cfg.VirtDir, "dumps")
+	}
+	if cfg.ISODir == "" {
+		cfg.ISODir = filepath.Join(cfg.VirtDir, "iso")
+	}
 
 	// ensure directories
-	for _, d := range []string{cfg.VirtFlockDir, cfg.VirtTmplDir, cfg.VirtCloudInitDir} {
+	for _, d := range []string{cfg.VirtFlockDir, cfg.VirtTmplDir, cfg.VirtCloudInitDir, cfg.Console.LogDir, cfg.CoreDump.Dir, cfg.ISODir} {
 		if err := os.MkdirAll(d, 0755); err != nil && !os.IsExist(err) {
 			return err
 		}
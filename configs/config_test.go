@@ -101,10 +101,43 @@ func TestDefault(t *testing.T) {
 
 	assert.Equal(t, cfg.MaxConcurrency, 100000)
 	assert.Equal(t, cfg.MaxSnapshotsCount, 30)
+	assert.Equal(t, cfg.EventJournalSize, 1000)
 	assert.Equal(t, cfg.SnapshotRestorableDay, 7)
 
 	assert.False(t, cfg.RecoveryOn)
 	assert.Equal(t, cfg.RecoveryMaxRetries, 2)
 	assert.Equal(t, cfg.RecoveryRetryInterval, 3*time.Minute)
 	assert.Equal(t, cfg.Network.OVN.NBAddrs, []string{"tcp:127.0.0.1:6641"})
+
+	assert.Equal(t, cfg.Lifecycle.ShutdownAction, ShutdownActionNone)
+	assert.Equal(t, cfg.Lifecycle.ShutdownTimeout, 3*time.Minute)
+	assert.Nil(t, cfg.Lifecycle.Check())
+	assert.Equal(t, cfg.Crash.Action, CrashActionReset)
+	assert.Nil(t, cfg.Crash.Check())
+	assert.Equal(t, cfg.CoreDump.Format, DumpFormatKdumpZlib)
+	assert.Equal(t, cfg.CoreDump.Retention, 168*time.Hour)
+	assert.Nil(t, cfg.CoreDump.Check())
+}
+
+func TestLifecycleConfig(t *testing.T) {
+	cfg := LifecycleConfig{ShutdownAction: ShutdownActionHibernate, ShutdownConcurrency: 1}
+	assert.Nil(t, cfg.Check())
+	cfg.ShutdownConcurrency = 0
+	assert.NotNil(t, cfg.Check())
+	cfg = LifecycleConfig{ShutdownAction: "reboot", ShutdownConcurrency: 1}
+	assert.NotNil(t, cfg.Check())
+}
+
+func TestCrashConfig(t *testing.T) {
+	cfg := CrashConfig{Action: CrashActionDump}
+	assert.Nil(t, cfg.Check())
+	cfg.Action = "reboot"
+	assert.NotNil(t, cfg.Check())
+}
+
+func TestCoreDumpConfig(t *testing.T) {
+	cfg := CoreDumpConfig{Format: DumpFormatELF}
+	assert.Nil(t, cfg.Check())
+	cfg.Format = "vmcore"
+	assert.NotNil(t, cfg.Check())
 }
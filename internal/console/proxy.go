@@ -0,0 +1,206 @@
+package console
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/cockroachdb/errors"
+	"github.com/gorilla/websocket"
+	"github.com/projecteru2/core/log"
+	"github.com/projecteru2/yavirt/configs"
+	"github.com/projecteru2/yavirt/internal/types"
+	"github.com/projecteru2/yavirt/internal/vmcache"
+	"github.com/projecteru2/yavirt/pkg/terrors"
+)
+
+// pathPrefix is followed by the console kind, e.g. /console/vnc?token=xxx
+const pathPrefix = "/console/"
+
+// Attacher attaches a stream to the console of guest.
+type Attacher interface {
+	AttachGuest(ctx context.Context, id string, stream io.ReadWriteCloser, flags types.OpenConsoleFlags) error
+}
+
+// Handler proxies the VNC display (for noVNC) and the serial console (for xterm.js)
+// of guests over WebSocket.
+type Handler struct {
+	signer   *Signer
+	attacher Attacher
+	vncAddr  func(id string) (string, error)
+	upgrader websocket.Upgrader
+}
+
+// NewHandler .
+func NewHandler(attacher Attacher) *Handler {
+	return newHandler(DefaultSigner(), attacher, vncAddr, configs.Conf.Console.AllowedOrigins)
+}
+
+func newHandler(signer *Signer, attacher Attacher, vncAddr func(string) (string, error), origins []string) *Handler {
+	return &Handler{
+		signer:   signer,
+		attacher: attacher,
+		vncAddr:  vncAddr,
+		upgrader: websocket.Upgrader{
+			// noVNC asks for the binary subprotocol
+			Subprotocols: []string{"binary"},
+			CheckOrigin:  checkOrigin(origins),
+		},
+	}
+}
+
+// Pattern is the path to register the handler.
+func Pattern() string {
+	return pathPrefix
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFunc("console.ServeHTTP")
+	kind := strings.TrimPrefix(r.URL.Path, pathPrefix)
+	if err := checkKind(kind); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	claims, err := h.signer.Verify(r.URL.Query().Get("token"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	if claims.Kind != kind {
+		http.Error(w, "the token isn't for "+kind, http.StatusForbidden)
+		return
+	}
+
+	var addr string
+	if kind == types.ConsoleKindVNC {
+		if addr, err = h.vncAddr(claims.ID); err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+	}
+
+	// the upgrader replies the error itself
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		logger.Warnf(r.Context(), "failed to upgrade: %s", err)
+		return
+	}
+	defer conn.Close()
+
+	ctx := r.Context()
+	logger.Infof(ctx, "%s console of %s is opened from %s", kind, claims.ID, r.RemoteAddr)
+	switch kind {
+	case types.ConsoleKindVNC:
+		err = proxyVNC(conn, addr)
+	case types.ConsoleKindSerial:
+		flags := types.OpenConsoleFlags{
+			Devname:  types.SerialConsoleDevname,
+			User:     claims.User,
+			ReadOnly: claims.ReadOnly,
+		}
+		if flags.User == "" {
+			flags.User = r.RemoteAddr
+		}
+		err = h.attacher.AttachGuest(ctx, claims.ID, newStream(conn), flags)
+	}
+	if err != nil {
+		logger.Warnf(ctx, "%s console of %s is closed: %s", kind, claims.ID, err)
+		_ = conn.WriteMessage(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseInternalServerErr, err.Error()))
+	}
+}
+
+// proxyVNC relays between the WebSocket and the VNC server of guest.
+func proxyVNC(conn *websocket.Conn, addr string) error {
+	vnc, err := net.Dial("tcp", addr)
+	if err != nil {
+		return errors.Wrapf(err, "failed to connect VNC %s", addr)
+	}
+	defer vnc.Close()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = io.Copy(vnc, newStream(conn))
+		// stop the reading of VNC
+		_ = vnc.Close()
+	}()
+	_, _ = io.Copy(newStream(conn), vnc)
+	// stop the reading of WebSocket
+	_ = conn.Close()
+	<-done
+	return nil
+}
+
+func vncAddr(id string) (string, error) {
+	entry := vmcache.FetchDomainEntry(id)
+	if entry == nil || entry.VNCPort <= 0 {
+		return "", errors.Wrapf(terrors.ErrInvalidValue, "guest %s has no VNC display", id)
+	}
+	return net.JoinHostPort("127.0.0.1", strconv.Itoa(entry.VNCPort)), nil
+}
+
+func checkOrigin(origins []string) func(r *http.Request) bool {
+	if len(origins) == 0 {
+		// the token is the credential
+		return func(*http.Request) bool { return true }
+	}
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		for _, o := range origins {
+			if strings.EqualFold(o, origin) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// stream adapts the WebSocket to io.ReadWriteCloser, the messages are binary.
+type stream struct {
+	conn   *websocket.Conn
+	reader io.Reader
+	mu     sync.Mutex
+}
+
+func newStream(conn *websocket.Conn) *stream {
+	return &stream{conn: conn}
+}
+
+func (s *stream) Read(p []byte) (int, error) {
+	for {
+		if s.reader == nil {
+			_, r, err := s.conn.NextReader()
+			if err != nil {
+				return 0, io.EOF
+			}
+			s.reader = r
+		}
+		n, err := s.reader.Read(p)
+		if errors.Is(err, io.EOF) {
+			s.reader = nil
+			if n == 0 {
+				continue
+			}
+			err = nil
+		}
+		return n, err
+	}
+}
+
+func (s *stream) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.conn.WriteMessage(websocket.BinaryMessage, p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (s *stream) Close() error {
+	return s.conn.Close()
+}
@@ -0,0 +1,77 @@
+package console
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/projecteru2/yavirt/internal/types"
+	"github.com/projecteru2/yavirt/pkg/test/assert"
+)
+
+type echoAttacher struct {
+	flags types.OpenConsoleFlags
+}
+
+func (a *echoAttacher) AttachGuest(_ context.Context, _ string, stream io.ReadWriteCloser, flags types.OpenConsoleFlags) error {
+	a.flags = flags
+	_, err := io.Copy(stream, stream)
+	return err
+}
+
+func TestProxy(t *testing.T) {
+	// a VNC server echoes
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NilErr(t, err)
+	defer lis.Close()
+	go func() {
+		conn, err := lis.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, _ = io.Copy(conn, conn)
+	}()
+
+	signer := NewSigner([]byte("secret"), time.Minute)
+	attacher := &echoAttacher{}
+	vncAddr := func(string) (string, error) { return lis.Addr().String(), nil }
+	srv := httptest.NewServer(newHandler(signer, attacher, vncAddr, nil))
+	defer srv.Close()
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http")
+
+	for _, kind := range []string{types.ConsoleKindVNC, types.ConsoleKindSerial} {
+		tok, err := signer.Issue("guest0", types.ConsoleTokenOptions{Kind: kind, User: "alice"})
+		assert.NilErr(t, err)
+		conn, resp, err := websocket.DefaultDialer.Dial(wsURL+tok.Path, nil)
+		assert.NilErr(t, err)
+		resp.Body.Close()
+
+		assert.NilErr(t, conn.WriteMessage(websocket.BinaryMessage, []byte("hello")))
+		_, msg, err := conn.ReadMessage()
+		assert.NilErr(t, err)
+		assert.Equal(t, "hello", string(msg))
+		conn.Close()
+	}
+	assert.Equal(t, types.SerialConsoleDevname, attacher.flags.Devname)
+	assert.Equal(t, "alice", attacher.flags.User)
+
+	// the token of VNC can't open the serial console
+	tok, err := signer.Issue("guest0", types.ConsoleTokenOptions{Kind: types.ConsoleKindVNC})
+	assert.NilErr(t, err)
+	_, resp, err := websocket.DefaultDialer.Dial(wsURL+"/console/serial?token="+tok.Token, nil)
+	assert.Err(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	resp.Body.Close()
+
+	_, resp, err = websocket.DefaultDialer.Dial(wsURL+"/console/vnc?token=bad", nil)
+	assert.Err(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	resp.Body.Close()
+}
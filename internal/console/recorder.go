@@ -0,0 +1,155 @@
+package console
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+const (
+	castExt = ".cast"
+
+	// the size of serial console is unknown, it's the default of terminals.
+	castWidth  = 80
+	castHeight = 24
+)
+
+// Recorder writes a console session in the asciicast v2 format,
+// the input is attributed to the writer of the last "m" marker.
+// See https://docs.asciinema.org/manual/asciicast/v2/
+type Recorder struct {
+	mu    sync.Mutex
+	file  *os.File
+	enc   *json.Encoder
+	start time.Time
+	now   func() time.Time
+	err   error
+}
+
+type castHeader struct {
+	Version   int    `json:"version"`
+	Width     int    `json:"width"`
+	Height    int    `json:"height"`
+	Timestamp int64  `json:"timestamp"`
+	Title     string `json:"title"`
+}
+
+// NewRecorder creates the recording of the console devname of guest id in dir,
+// and removes the recordings which are older than retention.
+func NewRecorder(dir, id, devname string, retention time.Duration) (*Recorder, error) {
+	return newRecorder(dir, id, devname, retention, time.Now)
+}
+
+func newRecorder(dir, id, devname string, retention time.Duration, now func() time.Time) (*Recorder, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, errors.Wrapf(err, "failed to create record dir %s", dir)
+	}
+	if err := prune(dir, retention, now()); err != nil {
+		return nil, err
+	}
+
+	start := now()
+	fname := fmt.Sprintf("%s-%s-%s%s", id, devname, start.UTC().Format("20060102T150405.000000000Z"), castExt)
+	file, err := os.OpenFile(filepath.Join(dir, fname), os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0600)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create recording")
+	}
+	r := &Recorder{
+		file:  file,
+		enc:   json.NewEncoder(file),
+		start: start,
+		now:   now,
+	}
+	hdr := castHeader{
+		Version:   2,
+		Width:     castWidth,
+		Height:    castHeight,
+		Timestamp: start.Unix(),
+		Title:     fmt.Sprintf("%s %s", id, devname),
+	}
+	if err := r.enc.Encode(hdr); err != nil {
+		file.Close()
+		return nil, errors.Wrapf(err, "failed to write recording header")
+	}
+	return r, nil
+}
+
+// Filepath .
+func (r *Recorder) Filepath() string {
+	return r.file.Name()
+}
+
+// Output records what the guest printed.
+func (r *Recorder) Output(p []byte) {
+	r.event("o", string(p))
+}
+
+// Input records what the writer typed.
+func (r *Recorder) Input(p []byte) {
+	r.event("i", string(p))
+}
+
+// Marker records who attached and detached.
+func (r *Recorder) Marker(label string) {
+	r.event("m", label)
+}
+
+// Err returns the first failure of writing, the events after that are dropped.
+func (r *Recorder) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+// Close .
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.Close()
+}
+
+func (r *Recorder) event(code, data string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return
+	}
+	elapsed := r.now().Sub(r.start).Seconds()
+	if err := r.enc.Encode([]any{elapsed, code, data}); err != nil {
+		r.err = errors.Wrapf(err, "failed to write recording")
+	}
+}
+
+// prune removes the recordings which were modified before retention,
+// they are kept forever if retention isn't positive.
+func prune(dir string, retention time.Duration, now time.Time) error {
+	if retention <= 0 {
+		return nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read record dir %s", dir)
+	}
+	deadline := now.Add(-retention)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), castExt) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if info.ModTime().Before(deadline) {
+			if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil && !os.IsNotExist(err) {
+				return errors.Wrapf(err, "failed to remove recording %s", entry.Name())
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package console
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/projecteru2/yavirt/pkg/test/assert"
+)
+
+func TestRecorder(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+
+	stale := filepath.Join(dir, "guest0-serial0-old.cast")
+	assert.NilErr(t, os.WriteFile(stale, nil, 0600))
+	assert.NilErr(t, os.Chtimes(stale, now.Add(-2*time.Hour), now.Add(-2*time.Hour)))
+	other := filepath.Join(dir, "notes.txt")
+	assert.NilErr(t, os.WriteFile(other, nil, 0600))
+	assert.NilErr(t, os.Chtimes(other, now.Add(-2*time.Hour), now.Add(-2*time.Hour)))
+
+	clock := now
+	rec, err := newRecorder(dir, "guest0", "serial0", time.Hour, func() time.Time { return clock })
+	assert.NilErr(t, err)
+	clock = clock.Add(1500 * time.Millisecond)
+	rec.Marker("writer alice attached")
+	rec.Input([]byte("ls\r"))
+	rec.Output([]byte("\x1b[0m"))
+	assert.NilErr(t, rec.Err())
+	assert.NilErr(t, rec.Close())
+
+	_, err = os.Stat(stale)
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(other)
+	assert.NilErr(t, err)
+
+	bs, err := os.ReadFile(rec.Filepath())
+	assert.NilErr(t, err)
+	lines := strings.Split(strings.TrimSpace(string(bs)), "\n")
+	assert.Equal(t, 4, len(lines))
+	assert.True(t, strings.HasPrefix(lines[0], `{"version":2,"width":80,"height":24,`))
+	assert.Equal(t, `[1.5,"m","writer alice attached"]`, lines[1])
+	assert.Equal(t, `[1.5,"i","ls\r"]`, lines[2])
+	assert.Equal(t, `[1.5,"o","\u001b[0m"]`, lines[3])
+}
@@ -0,0 +1,93 @@
+package console
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/yavirt/configs"
+)
+
+const serialLogExt = ".log"
+
+// SerialLogFilepath returns the log of the serial console of guest,
+// it's written by virtlogd, which rotates it by its max_size and max_backups,
+// and the rotated ones are suffixed by .0, .1 ...
+func SerialLogFilepath(id string) string {
+	return filepath.Join(configs.Conf.Console.LogDir, id+serialLogExt)
+}
+
+// RemoveSerialLog removes the log of guest including the rotated ones.
+func RemoveSerialLog(id string) {
+	path := SerialLogFilepath(id)
+	_ = os.Remove(path)
+	for i := 0; i < configs.Conf.Console.LogBackups; i++ {
+		_ = os.Remove(backupPath(path, i))
+	}
+}
+
+// ReadSerialLog returns the last size bytes of the serial console log of guest.
+func ReadSerialLog(id string, size int64) ([]byte, error) {
+	return readLog(SerialLogFilepath(id), configs.Conf.Console.LogBackups, size)
+}
+
+// readLog returns the last size bytes of the log, the rotated ones are read
+// if the current one isn't enough.
+func readLog(path string, backups int, size int64) ([]byte, error) {
+	var chunks [][]byte
+	for i := -1; i < backups && size > 0; i++ {
+		p := path
+		if i >= 0 {
+			p = backupPath(path, i)
+		}
+		bs, err := tail(p, size)
+		if os.IsNotExist(err) {
+			if i < 0 {
+				// the current one is created on starting
+				continue
+			}
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, bs)
+		size -= int64(len(bs))
+	}
+
+	var buf []byte
+	for i := len(chunks) - 1; i >= 0; i-- {
+		buf = append(buf, chunks[i]...)
+	}
+	return buf, nil
+}
+
+func tail(path string, size int64) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		// not wrapped for os.IsNotExist
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, errors.Wrap(err, "")
+	}
+	offset := info.Size() - size
+	if offset < 0 {
+		offset = 0
+	}
+	bs := make([]byte, info.Size()-offset)
+	n, err := f.ReadAt(bs, offset)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, errors.Wrap(err, "")
+	}
+	return bs[:n], nil
+}
+
+// backupPath returns the ith rotated log, the newest one is suffixed by .0.
+func backupPath(path string, i int) string {
+	return fmt.Sprintf("%s.%d", path, i)
+}
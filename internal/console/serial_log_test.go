@@ -0,0 +1,50 @@
+package console
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/projecteru2/yavirt/pkg/test/assert"
+)
+
+func TestSerialLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "guest0.log")
+
+	// nothing logged yet
+	bs, err := readLog(path, 2, 10)
+	assert.NilErr(t, err)
+	assert.Equal(t, 0, len(bs))
+
+	write := func(p, s string) {
+		assert.NilErr(t, os.WriteFile(p, []byte(s), 0600))
+	}
+
+	// not rotated yet
+	write(path, "abcd")
+	bs, err = readLog(path, 2, 3)
+	assert.NilErr(t, err)
+	assert.Equal(t, "bcd", string(bs))
+
+	// rotated by virtlogd, the newest backup is .0
+	write(backupPath(path, 1), "ghijkl")
+	write(backupPath(path, 0), "mnopqrst")
+	write(path, "uv")
+	bs, err = readLog(path, 2, 3)
+	assert.NilErr(t, err)
+	assert.Equal(t, "tuv", string(bs))
+	bs, err = readLog(path, 2, 100)
+	assert.NilErr(t, err)
+	assert.Equal(t, "ghijklmnopqrstuv", string(bs))
+
+	// the backups beyond max_backups are ignored
+	bs, err = readLog(path, 1, 100)
+	assert.NilErr(t, err)
+	assert.Equal(t, "mnopqrstuv", string(bs))
+
+	// the current one is created on starting
+	assert.NilErr(t, os.Remove(path))
+	bs, err = readLog(path, 2, 9)
+	assert.NilErr(t, err)
+	assert.Equal(t, "lmnopqrst", string(bs))
+}
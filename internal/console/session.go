@@ -0,0 +1,335 @@
+package console
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/core/log"
+	"github.com/projecteru2/yavirt/internal/types"
+)
+
+// the output is buffered for every client, the slow ones are detached
+// rather than blocking the others.
+const clientBufferSize = 256
+
+var (
+	// ErrConsoleBusy .
+	ErrConsoleBusy = errors.New("the console is being written by another client")
+	// ErrSessionClosed .
+	ErrSessionClosed = errors.New("the console session is closed")
+)
+
+// OpenFunc relays between the console of guest and upstream until ctx is done.
+type OpenFunc func(ctx context.Context, upstream io.ReadWriteCloser) error
+
+// Hub shares the consoles of guests, a console is opened for the first client
+// and closed after the last one detached. Every console has at most one writer,
+// and any number of read-only viewers.
+type Hub struct {
+	mu        sync.Mutex
+	sessions  map[string]*Session
+	execSeq   int
+	recordDir string
+	retention time.Duration
+}
+
+// NewHub creates a hub, the sessions are recorded into recordDir if it isn't empty.
+func NewHub(recordDir string, retention time.Duration) *Hub {
+	return &Hub{
+		sessions:  map[string]*Session{},
+		recordDir: recordDir,
+		retention: retention,
+	}
+}
+
+// Attach attaches stream to the console flags.Devname of guest id, the console
+// is opened by open if there's no session yet, and always if flags.Commands are
+// given. It blocks until the stream is detached, or the session is closed.
+func (h *Hub) Attach(ctx context.Context, id string, stream io.ReadWriteCloser, flags types.OpenConsoleFlags, open OpenFunc) error {
+	h.mu.Lock()
+	key := fmt.Sprintf("%s/%s", id, flags.Devname)
+	if len(flags.Commands) > 0 {
+		// the commands are run by the console which is opened for them,
+		// so it isn't shared with the other clients.
+		h.execSeq++
+		key = fmt.Sprintf("%s#exec-%d", key, h.execSeq)
+	}
+	sess, ok := h.sessions[key]
+	if !ok {
+		sess = h.newSession(ctx, key, id, flags.Devname)
+		h.sessions[key] = sess
+	}
+	// the first client always joins
+	c, err := sess.join(stream, flags)
+	h.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		go h.run(sess, open)
+	}
+
+	defer h.detach(sess, c)
+	return sess.serve(ctx, c)
+}
+
+// Session is a shared console.
+type Session struct {
+	key string
+	rec *Recorder
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	inR    *io.PipeReader
+	inW    *io.PipeWriter
+	done   chan struct{}
+	err    error
+
+	mu      sync.Mutex
+	clients map[*client]struct{}
+	writer  *client
+}
+
+func (h *Hub) newSession(ctx context.Context, key, id, devname string) *Session {
+	var rec *Recorder
+	if h.recordDir != "" {
+		var err error
+		if rec, err = NewRecorder(h.recordDir, id, devname, h.retention); err != nil {
+			log.WithFunc("console.Hub").Warnf(ctx, "failed to record console %s: %s", key, err)
+			rec = nil
+		}
+	}
+	// the session outlives the client which opened it
+	sctx, cancel := context.WithCancel(context.Background())
+	inR, inW := io.Pipe()
+	return &Session{
+		key:     key,
+		rec:     rec,
+		ctx:     sctx,
+		cancel:  cancel,
+		inR:     inR,
+		inW:     inW,
+		done:    make(chan struct{}),
+		clients: map[*client]struct{}{},
+	}
+}
+
+func (h *Hub) run(sess *Session, open OpenFunc) {
+	err := open(sess.ctx, &upstream{sess: sess})
+
+	h.mu.Lock()
+	if h.sessions[sess.key] == sess {
+		delete(h.sessions, sess.key)
+	}
+	h.mu.Unlock()
+
+	sess.close(err)
+}
+
+// detach removes c, the session is stopped if it's the last client.
+func (h *Hub) detach(sess *Session, c *client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if sess.leave(c) == 0 && h.sessions[sess.key] == sess {
+		delete(h.sessions, sess.key)
+		sess.stop()
+	}
+}
+
+func (s *Session) join(stream io.ReadWriteCloser, flags types.OpenConsoleFlags) (*client, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := &client{
+		user:   flags.User,
+		claim:  flags.ClaimedUser,
+		stream: stream,
+		out:    make(chan []byte, clientBufferSize),
+		done:   make(chan struct{}),
+	}
+	if !flags.ReadOnly {
+		if s.writer != nil {
+			if !flags.Force {
+				return nil, errors.Wrapf(ErrConsoleBusy, "%s is writing", s.writer)
+			}
+			// the previous writer becomes a viewer
+			s.mark("writer %s is taken over by %s", s.writer, c)
+		}
+		s.writer = c
+		s.mark("writer %s attached", c)
+	} else {
+		s.mark("viewer %s attached", c)
+	}
+	s.clients[c] = struct{}{}
+	return c, nil
+}
+
+// leave returns the number of the remaining clients.
+func (s *Session) leave(c *client) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.clients[c]; !ok {
+		return len(s.clients)
+	}
+	delete(s.clients, c)
+	if s.writer == c {
+		s.writer = nil
+		s.mark("writer %s detached", c)
+	} else {
+		s.mark("viewer %s detached", c)
+	}
+	return len(s.clients)
+}
+
+func (s *Session) serve(ctx context.Context, c *client) error {
+	// session -> client
+	go func() {
+		for {
+			select {
+			case bs := <-c.out:
+				if _, err := c.stream.Write(bs); err != nil {
+					c.close()
+					return
+				}
+			case <-c.done:
+				return
+			}
+		}
+	}()
+
+	// client -> session, the input of viewers is discarded
+	go func() {
+		buf := make([]byte, 32*1024)
+		for {
+			n, err := c.stream.Read(buf)
+			if n > 0 {
+				s.input(c, buf[:n])
+			}
+			if err != nil {
+				c.close()
+				return
+			}
+		}
+	}()
+
+	select {
+	case <-c.done:
+		return nil
+	case <-ctx.Done():
+		c.close()
+		return nil
+	case <-s.done:
+		c.close()
+		return s.err
+	}
+}
+
+func (s *Session) input(c *client, p []byte) {
+	s.mu.Lock()
+	isWriter := s.writer == c
+	s.mu.Unlock()
+	if !isWriter {
+		return
+	}
+	if s.rec != nil {
+		s.rec.Input(p)
+	}
+	// fails only if the session is closed
+	_, _ = s.inW.Write(p)
+}
+
+func (s *Session) broadcast(p []byte) {
+	bs := bytes.Clone(p)
+	if s.rec != nil {
+		s.rec.Output(bs)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for c := range s.clients {
+		select {
+		case c.out <- bs:
+		default:
+			log.WithFunc("console.Session").Warnf(s.ctx, "client %s of %s is too slow, detached", c, s.key)
+			c.close()
+		}
+	}
+}
+
+// stop asks the console to be closed.
+func (s *Session) stop() {
+	s.cancel()
+	// unblocks the reading of console
+	_ = s.inW.Close()
+}
+
+// close is called after the console was closed.
+func (s *Session) close(err error) {
+	s.stop()
+	if err == nil {
+		err = ErrSessionClosed
+	}
+	s.err = err
+	close(s.done)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.rec != nil {
+		s.mark("session closed")
+		if err := s.rec.Close(); err != nil {
+			log.WithFunc("console.Session").Warnf(context.TODO(), "failed to close recording of %s: %s", s.key, err)
+		}
+	}
+}
+
+// mark must be called with s.mu held.
+func (s *Session) mark(format string, args ...any) {
+	if s.rec != nil {
+		s.rec.Marker(fmt.Sprintf(format, args...))
+	}
+}
+
+// upstream is the side of console.
+type upstream struct {
+	sess *Session
+}
+
+func (u *upstream) Read(p []byte) (int, error) {
+	return u.sess.inR.Read(p)
+}
+
+func (u *upstream) Write(p []byte) (int, error) {
+	u.sess.broadcast(p)
+	return len(p), nil
+}
+
+func (u *upstream) Close() error {
+	return nil
+}
+
+type client struct {
+	user      string
+	claim     string
+	stream    io.ReadWriteCloser
+	out       chan []byte
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// String records the claimed user along with the user, e.g. "10.0.0.1:4242 (claimed alice)".
+func (c *client) String() string {
+	if c.claim == "" {
+		return c.user
+	}
+	return fmt.Sprintf("%s (claimed %s)", c.user, c.claim)
+}
+
+func (c *client) close() {
+	c.closeOnce.Do(func() { close(c.done) })
+}
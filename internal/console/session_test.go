@@ -0,0 +1,174 @@
+package console
+
+import (
+	"context"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/yavirt/internal/types"
+	"github.com/projecteru2/yavirt/pkg/test/assert"
+)
+
+func TestSession(t *testing.T) {
+	dir := t.TempDir()
+	hub := NewHub(dir, time.Hour)
+
+	opens := 0
+	closed := make(chan struct{})
+	// the console echoes
+	open := func(_ context.Context, up io.ReadWriteCloser) error {
+		opens++
+		defer close(closed)
+		_, err := io.Copy(up, up)
+		return err
+	}
+
+	attach := func(user string, readOnly, force bool) (net.Conn, chan error) {
+		srv, cli := net.Pipe()
+		flags := types.OpenConsoleFlags{Devname: types.SerialConsoleDevname, User: user, ReadOnly: readOnly}
+		flags.Force = force
+		errCh := make(chan error, 1)
+		go func() { errCh <- hub.Attach(context.Background(), "guest0", srv, flags, open) }()
+		return cli, errCh
+	}
+	expect := func(conn net.Conn, want string) {
+		buf := make([]byte, len(want))
+		_, err := io.ReadFull(conn, buf)
+		assert.NilErr(t, err)
+		assert.Equal(t, want, string(buf))
+	}
+
+	alice, aliceErr := attach("alice", false, false)
+	waitClients(t, hub, 1)
+	viewer, viewerErr := attach("carol", true, false)
+	waitClients(t, hub, 2)
+
+	_, err := alice.Write([]byte("hi"))
+	assert.NilErr(t, err)
+	expect(alice, "hi")
+	expect(viewer, "hi")
+
+	// the input of viewer is discarded
+	_, err = viewer.Write([]byte("x"))
+	assert.NilErr(t, err)
+	_, err = alice.Write([]byte("yo"))
+	assert.NilErr(t, err)
+	expect(viewer, "yo")
+	expect(alice, "yo")
+
+	// only one writer
+	_, busyErr := attach("bob", false, false)
+	assert.True(t, errors.Is(<-busyErr, ErrConsoleBusy))
+
+	bob, bobErr := attach("bob", false, true)
+	waitClients(t, hub, 3)
+	_, err = alice.Write([]byte("no"))
+	assert.NilErr(t, err)
+	_, err = bob.Write([]byte("ok"))
+	assert.NilErr(t, err)
+	expect(viewer, "ok")
+	expect(alice, "ok")
+	expect(bob, "ok")
+
+	// the console is closed after the last client detached
+	for _, conn := range []net.Conn{alice, viewer, bob} {
+		conn.Close()
+	}
+	for _, errCh := range []chan error{aliceErr, viewerErr, bobErr} {
+		assert.NilErr(t, <-errCh)
+	}
+	<-closed
+	assert.Equal(t, 1, opens)
+
+	entries, err := os.ReadDir(dir)
+	assert.NilErr(t, err)
+	assert.Equal(t, 1, len(entries))
+	bs, err := os.ReadFile(dir + "/" + entries[0].Name())
+	assert.NilErr(t, err)
+	cast := string(bs)
+	for _, want := range []string{
+		`"version":2`,
+		`"m","writer alice attached"`,
+		`"m","viewer carol attached"`,
+		`"m","writer alice is taken over by bob"`,
+		`"i","hi"`,
+		`"o","hi"`,
+		`"i","ok"`,
+	} {
+		assert.True(t, strings.Contains(cast, want))
+	}
+	for _, unwanted := range []string{`"i","x"`, `"i","no"`} {
+		assert.False(t, strings.Contains(cast, unwanted))
+	}
+}
+
+func waitClients(t *testing.T, hub *Hub, n int) {
+	for i := 0; i < 100; i++ {
+		hub.mu.Lock()
+		sess := hub.sessions["guest0/"+types.SerialConsoleDevname]
+		hub.mu.Unlock()
+		if sess != nil {
+			sess.mu.Lock()
+			count := len(sess.clients)
+			sess.mu.Unlock()
+			if count == n {
+				return
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("expect %d clients", n)
+}
+
+func TestClientUser(t *testing.T) {
+	c := &client{user: "10.0.0.1:4242"}
+	assert.Equal(t, "10.0.0.1:4242", c.String())
+	// the claimed user is only recorded along with the peer
+	c.claim = "alice"
+	assert.Equal(t, "10.0.0.1:4242 (claimed alice)", c.String())
+}
+
+func TestExecSession(t *testing.T) {
+	hub := NewHub("", time.Hour)
+	// the console writes the commands which it's opened with
+	open := func(cmds []string) OpenFunc {
+		return func(ctx context.Context, up io.ReadWriteCloser) error {
+			if _, err := up.Write([]byte(strings.Join(cmds, ";"))); err != nil {
+				return err
+			}
+			<-ctx.Done()
+			return nil
+		}
+	}
+	exec := func(cmds ...string) (net.Conn, chan error) {
+		srv, cli := net.Pipe()
+		flags := types.OpenConsoleFlags{Devname: types.SerialConsoleDevname, User: "alice", Commands: cmds}
+		errCh := make(chan error, 1)
+		go func() { errCh <- hub.Attach(context.Background(), "guest0", srv, flags, open(cmds)) }()
+		return cli, errCh
+	}
+	expect := func(conn net.Conn, want string) {
+		buf := make([]byte, len(want))
+		_, err := io.ReadFull(conn, buf)
+		assert.NilErr(t, err)
+		assert.Equal(t, want, string(buf))
+	}
+
+	// the consoles running commands aren't shared
+	foo, fooErr := exec("foo")
+	expect(foo, "foo")
+	bar, barErr := exec("bar", "baz")
+	expect(bar, "bar;baz")
+
+	for _, conn := range []net.Conn{foo, bar} {
+		conn.Close()
+	}
+	for _, errCh := range []chan error{fooErr, barErr} {
+		assert.NilErr(t, <-errCh)
+	}
+}
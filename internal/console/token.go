@@ -0,0 +1,140 @@
+package console
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/yavirt/configs"
+	"github.com/projecteru2/yavirt/internal/types"
+	"github.com/projecteru2/yavirt/pkg/terrors"
+)
+
+var (
+	// ErrInvalidToken .
+	ErrInvalidToken = errors.New("invalid console token")
+
+	defaultSigner     *Signer
+	defaultSignerOnce sync.Once
+)
+
+// Claims is the payload of token.
+type Claims struct {
+	ID        string `json:"id"`
+	Kind      string `json:"kind"`
+	User      string `json:"user,omitempty"`
+	ReadOnly  bool   `json:"ro,omitempty"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// Signer issues and verifies the HMAC-SHA256 signed tokens,
+// a token is <base64 payload>.<base64 signature>.
+type Signer struct {
+	secret []byte
+	ttl    time.Duration
+	now    func() time.Time
+}
+
+// NewSigner .
+func NewSigner(secret []byte, ttl time.Duration) *Signer {
+	return &Signer{
+		secret: secret,
+		ttl:    ttl,
+		now:    time.Now,
+	}
+}
+
+// DefaultSigner returns the signer of config, the secret is generated
+// if it's not configured, so the tokens are invalid after restarting.
+func DefaultSigner() *Signer {
+	defaultSignerOnce.Do(func() {
+		cfg := &configs.Conf.Console
+		secret := []byte(cfg.TokenSecret)
+		if len(secret) == 0 {
+			secret = make([]byte, 32)
+			if _, err := rand.Read(secret); err != nil {
+				panic(err)
+			}
+		}
+		defaultSigner = NewSigner(secret, cfg.TokenTTL)
+	})
+	return defaultSigner
+}
+
+// Issue creates a token to access the console of guest.
+func (s *Signer) Issue(id string, opts types.ConsoleTokenOptions) (*types.ConsoleToken, error) {
+	kind := opts.Kind
+	if err := checkKind(kind); err != nil {
+		return nil, err
+	}
+	if opts.ReadOnly && kind != types.ConsoleKindSerial {
+		// the VNC display is relayed as is
+		return nil, errors.Wrapf(terrors.ErrInvalidValue, "read-only isn't supported by the %s console", kind)
+	}
+	expiresAt := s.now().Add(s.ttl)
+	payload, err := json.Marshal(Claims{
+		ID:        id,
+		Kind:      kind,
+		User:      opts.User,
+		ReadOnly:  opts.ReadOnly,
+		ExpiresAt: expiresAt.Unix(),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "")
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	token := encoded + "." + s.sign(encoded)
+	return &types.ConsoleToken{
+		Token:     token,
+		Kind:      kind,
+		User:      opts.User,
+		ReadOnly:  opts.ReadOnly,
+		Path:      fmt.Sprintf("%s%s?token=%s", pathPrefix, kind, token),
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// Verify checks the signature and the expiration of token.
+func (s *Signer) Verify(token string) (*Claims, error) {
+	encoded, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+	if !hmac.Equal([]byte(sig), []byte(s.sign(encoded))) {
+		return nil, errors.Wrapf(ErrInvalidToken, "bad signature")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errors.Wrapf(ErrInvalidToken, "bad payload")
+	}
+	claims := &Claims{}
+	if err := json.Unmarshal(payload, claims); err != nil {
+		return nil, errors.Wrapf(ErrInvalidToken, "bad payload")
+	}
+	if s.now().Unix() > claims.ExpiresAt {
+		return nil, errors.Wrapf(ErrInvalidToken, "expired")
+	}
+	return claims, nil
+}
+
+func (s *Signer) sign(encoded string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func checkKind(kind string) error {
+	switch kind {
+	case types.ConsoleKindVNC, types.ConsoleKindSerial:
+		return nil
+	default:
+		return errors.Wrapf(terrors.ErrInvalidValue, "invalid console kind %s", kind)
+	}
+}
@@ -0,0 +1,55 @@
+package console
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/projecteru2/yavirt/internal/types"
+	"github.com/projecteru2/yavirt/pkg/test/assert"
+)
+
+func TestToken(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	signer := NewSigner([]byte("secret"), time.Minute)
+	signer.now = func() time.Time { return now }
+
+	tok, err := signer.Issue("guest0", types.ConsoleTokenOptions{Kind: types.ConsoleKindVNC})
+	assert.NilErr(t, err)
+	assert.Equal(t, now.Add(time.Minute), tok.ExpiresAt)
+	assert.Equal(t, "/console/vnc?token="+tok.Token, tok.Path)
+
+	claims, err := signer.Verify(tok.Token)
+	assert.NilErr(t, err)
+	assert.Equal(t, "guest0", claims.ID)
+	assert.Equal(t, types.ConsoleKindVNC, claims.Kind)
+
+	// tampered
+	payload, sig, _ := strings.Cut(tok.Token, ".")
+	other, err := signer.Issue("guest1", types.ConsoleTokenOptions{Kind: types.ConsoleKindVNC})
+	assert.NilErr(t, err)
+	otherPayload, _, _ := strings.Cut(other.Token, ".")
+	for _, invalid := range []string{"", payload, otherPayload + "." + sig, payload + ".x"} {
+		_, err = signer.Verify(invalid)
+		assert.Err(t, err)
+	}
+	_, err = NewSigner([]byte("other"), time.Minute).Verify(tok.Token)
+	assert.Err(t, err)
+
+	// expired
+	now = now.Add(2 * time.Minute)
+	_, err = signer.Verify(tok.Token)
+	assert.Err(t, err)
+
+	_, err = signer.Issue("guest0", types.ConsoleTokenOptions{Kind: "spice"})
+	assert.Err(t, err)
+	_, err = signer.Issue("guest0", types.ConsoleTokenOptions{Kind: types.ConsoleKindVNC, ReadOnly: true})
+	assert.Err(t, err)
+
+	tok, err = signer.Issue("guest0", types.ConsoleTokenOptions{Kind: types.ConsoleKindSerial, User: "alice", ReadOnly: true})
+	assert.NilErr(t, err)
+	claims, err = signer.Verify(tok.Token)
+	assert.NilErr(t, err)
+	assert.Equal(t, "alice", claims.User)
+	assert.True(t, claims.ReadOnly)
+}
@@ -39,6 +39,7 @@ func TestRunNodeManager(t *testing.T) {
 	store := manager.store.(*storemocks.MockStore)
 	svc := manager.svc.(*mocks.Service)
 	svc.On("IsHealthy", mock.Anything).Return(true)
+	svc.On("GetMaintenance", mock.Anything).Return(nil, nil)
 
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(manager.config.HeartbeatInterval*3)*time.Second)
 	defer cancel()
@@ -26,6 +26,9 @@ func (m *Manager) initMonitor(ctx context.Context) (err error) {
 	for {
 		select {
 		case event := <-watcher.Events():
+			if event.Failed() {
+				continue
+			}
 			// don't block here
 			_ = utils.Pool.Submit(func() {
 				switch event.Op {
@@ -29,10 +29,19 @@ func (m *Manager) heartbeat(ctx context.Context) {
 	}
 }
 
+// ReportNodeStatus reports the status of node right now rather than waiting
+// for the next heartbeat, e.g. after entering or exiting maintenance.
+func (m *Manager) ReportNodeStatus(ctx context.Context) {
+	// the request may be done before reporting
+	ctx = context.WithoutCancel(ctx)
+	_ = utils.Pool.Submit(func() { m.nodeStatusReport(ctx) })
+}
+
 // nodeStatusReport does heartbeat, tells core this node is alive.
 // The TTL is set to double of HeartbeatInterval, by default it will be 360s,
 // which means if a node is not available, subcriber will notice this after at least 360s.
 // HealthCheck.Timeout is used as timeout of requesting core Profile
+// The node status is removed if the host is in maintenance, so nothing is scheduled onto it.
 func (m *Manager) nodeStatusReport(ctx context.Context) {
 	logger := log.WithFunc("nodeStatusReport").WithField("hostname", m.config.Hostname)
 	logger.Debug(ctx, "report begins")
@@ -41,6 +50,20 @@ func (m *Manager) nodeStatusReport(ctx context.Context) {
 		logger.Warn(ctx, "service is not healthy")
 		return
 	}
+	switch maint, err := m.svc.GetMaintenance(ctx); {
+	case err != nil:
+		logger.Warnf(ctx, "failed to get maintenance: %s", err)
+		return
+	case maint != nil:
+		logger.Infof(ctx, "host is in maintenance: %s", maint.Reason)
+		utils.WithTimeout(ctx, m.config.GlobalConnectionTimeout, func(ctx context.Context) {
+			err = m.store.SetNodeStatus(ctx, -1)
+		})
+		if err != nil {
+			logger.Error(ctx, err, "failed to remove node status")
+		}
+		return
+	}
 	if err := m.store.CheckHealth(ctx); err != nil {
 		logger.Error(ctx, err, "failed to check health of core")
 		m.mCol.coreHealthy.Store(false)
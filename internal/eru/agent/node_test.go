@@ -7,6 +7,7 @@ import (
 
 	storemocks "github.com/projecteru2/yavirt/internal/eru/store/mocks"
 	"github.com/projecteru2/yavirt/internal/service/mocks"
+	"github.com/projecteru2/yavirt/internal/types"
 	"github.com/projecteru2/yavirt/pkg/test/mock"
 
 	"github.com/stretchr/testify/assert"
@@ -19,6 +20,7 @@ func TestNodeStatusReport(t *testing.T) {
 	store := manager.store.(*storemocks.MockStore)
 	svc := manager.svc.(*mocks.Service)
 	svc.On("IsHealthy", mock.Anything).Return(true)
+	svc.On("GetMaintenance", mock.Anything).Return(nil, nil)
 
 	status, err := store.GetNodeStatus(ctx, "fake")
 	assert.Nil(t, err)
@@ -30,6 +32,29 @@ func TestNodeStatusReport(t *testing.T) {
 	assert.Equal(t, status.Alive, true)
 }
 
+func TestNodeStatusReportInMaintenance(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	manager := newMockManager(t)
+	store := manager.store.(*storemocks.MockStore)
+	svc := manager.svc.(*mocks.Service)
+	svc.On("IsHealthy", mock.Anything).Return(true)
+	svc.On("GetMaintenance", mock.Anything).Return(&types.Maintenance{}, nil).Once()
+	svc.On("GetMaintenance", mock.Anything).Return(nil, nil)
+
+	assert.Nil(t, store.SetNodeStatus(ctx, 6))
+	manager.nodeStatusReport(ctx)
+	status, err := store.GetNodeStatus(ctx, "fake")
+	assert.Nil(t, err)
+	assert.Equal(t, status.Alive, false)
+
+	// exited
+	manager.nodeStatusReport(ctx)
+	status, err = store.GetNodeStatus(ctx, "fake")
+	assert.Nil(t, err)
+	assert.Equal(t, status.Alive, true)
+}
+
 func TestHeartbeat(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -37,6 +62,7 @@ func TestHeartbeat(t *testing.T) {
 	store := manager.store.(*storemocks.MockStore)
 	svc := manager.svc.(*mocks.Service)
 	svc.On("IsHealthy", mock.Anything).Return(true)
+	svc.On("GetMaintenance", mock.Anything).Return(nil, nil)
 
 	status, err := store.GetNodeStatus(ctx, "fake")
 	assert.Nil(t, err)
@@ -10,6 +10,7 @@ import (
 	"sync"
 	"time"
 
+	mapset "github.com/deckarep/golang-set/v2"
 	"github.com/mitchellh/mapstructure"
 	"github.com/projecteru2/core/log"
 	cpumemtypes "github.com/projecteru2/core/resource/plugins/cpumem/types"
@@ -18,6 +19,7 @@ import (
 	"github.com/projecteru2/yavirt/configs"
 	"github.com/projecteru2/yavirt/internal/eru/types"
 	intertypes "github.com/projecteru2/yavirt/internal/types"
+	"github.com/projecteru2/yavirt/internal/vmcache"
 	"github.com/projecteru2/yavirt/pkg/notify/bison"
 	gputypes "github.com/yuyang0/resource-gpu/gpu/types"
 )
@@ -164,7 +166,9 @@ func (cm *CoreResourcesManager) UpdateCPUMem(nr *cpumemtypes.NodeResource) (err
 	if remoteNR == nil {
 		return err
 	}
-	if remoteNR.CPU == localNR.CPU && remoteNR.Memory <= localNR.Memory && remoteNR.Memory >= (localNR.Memory*75/100) {
+	// the pCPUs pinned by label are withdrawn from core, and are synced by Manager.SyncPinnedCPUs
+	pinned := mapset.NewSet[int](vmcache.FetchPinnedCPUs()...)
+	if equalCPUMap(remoteNR.CPUMap, localNR.CPUMap, pinned) && remoteNR.Memory <= localNR.Memory && remoteNR.Memory >= (localNR.Memory*75/100) {
 		logger.Info(ctx, "remote cpumem config is consistent")
 		return err
 	}
@@ -202,11 +206,96 @@ func (cm *CoreResourcesManager) UpdateCPUMem(nr *cpumemtypes.NodeResource) (err
 	return nil
 }
 
+// FetchCPUMap fetches the capacity and usage of pCPUs on core.
+func (cm *CoreResourcesManager) FetchCPUMap(ctx context.Context) (capacity, usage cpumemtypes.CPUMap, err error) {
+	resp, err := cli.GetNodeResource(ctx, configs.Hostname())
+	if err != nil {
+		return nil, nil, err
+	}
+	capacityNR, usageNR := cpumemtypes.NodeResource{}, cpumemtypes.NodeResource{}
+	if err = mapstructure.Decode(resp.Capacity[intertypes.PluginNameCPUMem], &capacityNR); err != nil {
+		return nil, nil, err
+	}
+	if err = mapstructure.Decode(resp.Usage[intertypes.PluginNameCPUMem], &usageNR); err != nil {
+		return nil, nil, err
+	}
+	return capacityNR.CPUMap, usageNR.CPUMap, nil
+}
+
+// UpdateCPUMap changes the pieces of pCPUs on core by deltas.
+func (cm *CoreResourcesManager) UpdateCPUMap(ctx context.Context, deltas cpumemtypes.CPUMap) error {
+	cb, _ := json.Marshal(resourcetypes.RawParams{
+		"cpu": formatCPUMap(deltas),
+	})
+	opts := &types.SetNodeOpts{
+		Nodename: configs.Hostname(),
+		Delta:    true,
+		Resources: map[string][]byte{
+			"cpumem": cb,
+		},
+	}
+	if _, err := cli.SetNode(ctx, opts); err != nil {
+		return err
+	}
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	// the cached capacity is stale, fetch it again when it's needed
+	cm.cpumem = nil
+	return nil
+}
+
+// equalCPUMap tells whether the pieces of pCPUs are the same
+// except the excluded ones.
+func equalCPUMap(a, b cpumemtypes.CPUMap, excluded mapset.Set[int]) bool {
+	trim := func(cpuMap cpumemtypes.CPUMap) cpumemtypes.CPUMap {
+		ans := cpumemtypes.CPUMap{}
+		for cpu, pieces := range cpuMap {
+			if id, err := strconv.Atoi(cpu); err == nil && excluded.Contains(id) {
+				continue
+			}
+			ans[cpu] = pieces
+		}
+		return ans
+	}
+	a, b = trim(a), trim(b)
+	if len(a) == 0 || len(a) != len(b) {
+		return false
+	}
+	for cpu, pieces := range a {
+		if other, ok := b[cpu]; !ok || other != pieces {
+			return false
+		}
+	}
+	return true
+}
+
+// formatCPUMap formats cpu map as core's cpu list, e.g. "2:100,3:100".
+func formatCPUMap(cpuMap cpumemtypes.CPUMap) string {
+	cpus := make([]string, 0, len(cpuMap))
+	for cpu := range cpuMap {
+		cpus = append(cpus, cpu)
+	}
+	sort.Slice(cpus, func(i, j int) bool {
+		a, _ := strconv.Atoi(cpus[i])
+		b, _ := strconv.Atoi(cpus[j])
+		return a < b
+	})
+	for idx, cpu := range cpus {
+		cpus[idx] = fmt.Sprintf("%s:%d", cpu, cpuMap[cpu])
+	}
+	return strings.Join(cpus, ",")
+}
+
 func convCpumemBytes(localNR *cpumemtypes.NodeResource) ([]byte, error) {
 	cpumem := resourcetypes.RawParams{
 		"cpu":    localNR.CPU,
 		"memory": localNR.Memory * 80 / 100, // use 80% of memory
 	}
+	// the IDs of pCPUs must be the same as the local ones, so cpus are bound correctly
+	if len(localNR.CPUMap) > 0 {
+		cpumem["cpu"] = formatCPUMap(localNR.CPUMap)
+	}
 	// nodeID => cpuID list
 	numaCPUMap := map[string][]string{}
 	for cpuID, numID := range localNR.NUMA {
@@ -3,6 +3,7 @@ package resources
 import (
 	"testing"
 
+	mapset "github.com/deckarep/golang-set/v2"
 	cpumemtypes "github.com/projecteru2/core/resource/plugins/cpumem/types"
 	"github.com/stretchr/testify/assert"
 )
@@ -25,6 +26,18 @@ func TestConvCpumemBytes(t *testing.T) {
 			expected:   []byte(`{"cpu":4,"memory":6553,"numa-cpu":["0,1","2,3"],"numa-memory":["800","8000"]}`),
 			expectFail: false,
 		},
+		{
+			name: "With cpu map",
+			localNR: &cpumemtypes.NodeResource{
+				CPU:        2,
+				CPUMap:     cpumemtypes.CPUMap{"10": 100, "2": 100},
+				Memory:     8192,
+				NUMA:       map[string]string{"2": "0", "10": "0"},
+				NUMAMemory: map[string]int64{"0": 1000},
+			},
+			expected:   []byte(`{"cpu":"2:100,10:100","memory":6553,"numa-cpu":["10,2"],"numa-memory":["800"]}`),
+			expectFail: false,
+		},
 		// Add more test cases as needed
 	}
 
@@ -40,3 +53,18 @@ func TestConvCpumemBytes(t *testing.T) {
 		})
 	}
 }
+
+func TestEqualCPUMap(t *testing.T) {
+	local := cpumemtypes.CPUMap{"0": 100, "1": 100, "2": 100, "3": 100}
+	pinned := mapset.NewSet[int](2)
+
+	// the pinned pCPUs are withdrawn from core
+	assert.True(t, equalCPUMap(cpumemtypes.CPUMap{"0": 100, "1": 100, "2": 0, "3": 100}, local, pinned))
+	assert.True(t, equalCPUMap(cpumemtypes.CPUMap{"0": 100, "1": 100, "3": 100}, local, pinned))
+	// the host gains pCPUs or releases the reserved ones
+	assert.False(t, equalCPUMap(cpumemtypes.CPUMap{"0": 100, "1": 100}, local, pinned))
+	// the unpinned pCPUs must be the same
+	assert.False(t, equalCPUMap(cpumemtypes.CPUMap{"0": 100, "1": 100, "2": 100, "3": 0}, local, pinned))
+	assert.False(t, equalCPUMap(cpumemtypes.CPUMap{"0": 100, "1": 100, "3": 100, "4": 100}, local, pinned))
+	assert.False(t, equalCPUMap(nil, nil, pinned))
+}
@@ -6,12 +6,17 @@ import (
 	"strconv"
 	"time"
 
+	mapset "github.com/deckarep/golang-set/v2"
 	"github.com/jaypipes/ghw"
 	"github.com/projecteru2/core/log"
 	cpumemtypes "github.com/projecteru2/core/resource/plugins/cpumem/types"
 	"github.com/projecteru2/yavirt/configs"
+	"github.com/projecteru2/yavirt/internal/types"
 )
 
+// cpuSharePieces is the pieces of a full pCPU, it's the same as core's default.
+const cpuSharePieces = 100
+
 type CPUMemManager struct {
 	cpumem  *cpumemtypes.NodeResource
 	coreMgr *CoreResourcesManager
@@ -40,6 +45,14 @@ func NewCPUMemManager(coreMgr *CoreResourcesManager, cfg *configs.Config) (*CPUM
 func fetchCPUMemFromHardware(cfg *configs.Config) (*cpumemtypes.NodeResource, error) {
 	numa := cpumemtypes.NUMA{}
 	numaMem := cpumemtypes.NUMAMemory{}
+	// the capacity of pinnable pCPUs, the reserved ones are excluded
+	cpuMap := cpumemtypes.CPUMap{}
+
+	reserved, err := types.ParseCPUSet(cfg.Resource.CPUPinning.ReservedCPUs)
+	if err != nil {
+		return nil, err
+	}
+	reservedSet := mapset.NewSet[int](reserved...)
 
 	cpu, err := ghw.CPU()
 	if err != nil {
@@ -66,12 +79,17 @@ func fetchCPUMemFromHardware(cfg *configs.Config) (*cpumemtypes.NodeResource, er
 		numaMem[strconv.Itoa(node.ID)] = (node.Memory.TotalUsableBytes - numaReservedMem) * 100 / 80
 		for _, core := range node.Cores {
 			for _, id := range core.LogicalProcessors {
+				if reservedSet.Contains(id) {
+					continue
+				}
 				numa[strconv.Itoa(id)] = fmt.Sprintf("%d", node.ID)
+				cpuMap[strconv.Itoa(id)] = cpuSharePieces
 			}
 		}
 	}
 	return &cpumemtypes.NodeResource{
-		CPU:        float64(cpu.TotalThreads),
+		CPU:        float64(int(cpu.TotalThreads) - reservedSet.Cardinality()),
+		CPUMap:     cpuMap,
 		Memory:     infoMem,
 		NUMAMemory: numaMem,
 		NUMA:       numa,
@@ -9,6 +9,7 @@ import (
 	"github.com/jaypipes/ghw/pkg/memory"
 	"github.com/jaypipes/ghw/pkg/topology"
 	"github.com/mcuadros/go-defaults"
+	cpumemtypes "github.com/projecteru2/core/resource/plugins/cpumem/types"
 	"github.com/projecteru2/yavirt/configs"
 	"github.com/stretchr/testify/assert"
 )
@@ -79,4 +80,12 @@ func TestFetchCPUMem(t *testing.T) {
 	for _, node := range res.NUMAMemory {
 		assert.Equal(t, node, int64(1025))
 	}
+	assert.Len(t, res.CPUMap, 4)
+
+	cfg.Resource.CPUPinning.ReservedCPUs = "0-1"
+	res, err = fetchCPUMemFromHardware(cfg)
+	assert.Nil(t, err)
+	assert.Equal(t, res.CPU, float64(2))
+	assert.Equal(t, cpumemtypes.CPUMap{"2": 100, "3": 100}, res.CPUMap)
+	assert.Len(t, res.NUMA, 2)
 }
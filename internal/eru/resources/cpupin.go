@@ -0,0 +1,205 @@
+package resources
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/cockroachdb/errors"
+	mapset "github.com/deckarep/golang-set/v2"
+	"github.com/jaypipes/ghw"
+	"github.com/projecteru2/core/log"
+	cpumemtypes "github.com/projecteru2/core/resource/plugins/cpumem/types"
+	"github.com/projecteru2/yavirt/configs"
+	"github.com/projecteru2/yavirt/internal/types"
+	"github.com/projecteru2/yavirt/internal/vmcache"
+)
+
+// CPUPinManager allocates dedicated pCPUs for guests,
+// the pCPUs which are in use are fetched from the cputune of domains.
+type CPUPinManager struct {
+	mu       sync.Mutex
+	cfg      *configs.CPUPinningConfig
+	nodeIDs  []int
+	nodes    map[int][]int // NUMA node -> pCPUs, siblings are adjacent
+	cpuNode  map[int]int   // pCPU -> NUMA node
+	reserved []int
+}
+
+func NewCPUPinManager(cfg *configs.Config) (*CPUPinManager, error) {
+	reserved, err := types.ParseCPUSet(cfg.Resource.CPUPinning.ReservedCPUs)
+	if err != nil {
+		return nil, err
+	}
+	topology, err := ghw.Topology()
+	if err != nil {
+		return nil, err
+	}
+	reservedSet := mapset.NewSet[int](reserved...)
+	mgr := &CPUPinManager{
+		cfg:      &cfg.Resource.CPUPinning,
+		nodes:    map[int][]int{},
+		cpuNode:  map[int]int{},
+		reserved: reserved,
+	}
+	for _, node := range topology.Nodes {
+		var cpus []int
+		for _, core := range node.Cores {
+			for _, id := range core.LogicalProcessors {
+				if reservedSet.Contains(id) {
+					continue
+				}
+				cpus = append(cpus, id)
+				mgr.cpuNode[id] = node.ID
+			}
+		}
+		mgr.nodeIDs = append(mgr.nodeIDs, node.ID)
+		mgr.nodes[node.ID] = cpus
+	}
+	sort.Ints(mgr.nodeIDs)
+	log.WithFunc("NewCPUPinManager").Infof(context.TODO(), "pinnable pCPUs: %v, reserved: %v", mgr.nodes, reserved)
+	return mgr, nil
+}
+
+// Alloc allocates count dedicated pCPUs for vCPUs, a single NUMA node is preferred.
+func (m *CPUPinManager) Alloc(count int, req *types.CPUPinningRequest) (*types.CPUPinning, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	used := mapset.NewSet[int](vmcache.FetchPinnedCPUs()...)
+	return m.alloc(count, m.dedicatedEmulator(req), used)
+}
+
+func (m *CPUPinManager) dedicatedEmulator(req *types.CPUPinningRequest) bool {
+	if req != nil && req.DedicatedEmulator != nil {
+		return *req.DedicatedEmulator
+	}
+	return m.cfg.DedicatedEmulator
+}
+
+func (m *CPUPinManager) alloc(count int, dedicatedEmulator bool, used mapset.Set[int]) (*types.CPUPinning, error) {
+	if count < 1 {
+		return nil, errors.Errorf("invalid vCPU count %d", count)
+	}
+	need := count
+	if dedicatedEmulator {
+		need++
+	}
+
+	free := map[int][]int{}
+	total := 0
+	for _, nodeID := range m.nodeIDs {
+		for _, id := range m.nodes[nodeID] {
+			if !used.Contains(id) {
+				free[nodeID] = append(free[nodeID], id)
+			}
+		}
+		total += len(free[nodeID])
+	}
+	if total < need {
+		return nil, errors.Errorf("no enough pCPUs, need %d but only %d free", need, total)
+	}
+
+	// best fit: the node which has the least free pCPUs but enough
+	candidates := append([]int(nil), m.nodeIDs...)
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return len(free[candidates[i]]) < len(free[candidates[j]])
+	})
+	var order []int
+	for _, nodeID := range candidates {
+		if len(free[nodeID]) >= need {
+			order = []int{nodeID}
+			break
+		}
+	}
+	// span nodes, the node which has the most free pCPUs goes first
+	if order == nil {
+		for i := len(candidates) - 1; i >= 0; i-- {
+			order = append(order, candidates[i])
+		}
+	}
+
+	pinning := &types.CPUPinning{}
+	for _, nodeID := range order {
+		if need == 0 {
+			break
+		}
+		n := min(need, len(free[nodeID]))
+		if n == 0 {
+			continue
+		}
+		pinning.Cells = append(pinning.Cells, types.NUMACell{
+			Node: nodeID,
+			CPUs: free[nodeID][:n],
+		})
+		need -= n
+	}
+
+	if dedicatedEmulator {
+		last := &pinning.Cells[len(pinning.Cells)-1]
+		pinning.EmulatorCPUs = []int{last.CPUs[len(last.CPUs)-1]}
+		last.CPUs = last.CPUs[:len(last.CPUs)-1]
+		if len(last.CPUs) == 0 {
+			pinning.Cells = pinning.Cells[:len(pinning.Cells)-1]
+		}
+	} else {
+		pinning.EmulatorCPUs = append([]int(nil), m.reserved...)
+	}
+	return pinning, nil
+}
+
+// FromCPUMap converts the pCPUs allocated by eru core to pinning,
+// the vCPUs are pinned to dedicated pCPUs, so only the whole pCPUs are accepted.
+func (m *CPUPinManager) FromCPUMap(cpuMap cpumemtypes.CPUMap) (*types.CPUPinning, error) {
+	cells := map[int][]int{}
+	for cpu, pieces := range cpuMap {
+		if pieces == 0 {
+			continue
+		}
+		id, err := strconv.Atoi(cpu)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid cpu %s", cpu)
+		}
+		if pieces != cpuSharePieces {
+			return nil, errors.Errorf("cpu %d is allocated %d/%d pieces, only the whole pCPUs can be pinned", id, pieces, cpuSharePieces)
+		}
+		nodeID, ok := m.cpuNode[id]
+		if !ok {
+			return nil, errors.Errorf("cpu %d isn't pinnable", id)
+		}
+		cells[nodeID] = append(cells[nodeID], id)
+	}
+	if len(cells) == 0 {
+		return nil, errors.New("no pCPUs in cpu map")
+	}
+	pinning := &types.CPUPinning{
+		EmulatorCPUs: append([]int(nil), m.reserved...),
+	}
+	for _, nodeID := range m.nodeIDs {
+		if cpus, ok := cells[nodeID]; ok {
+			sort.Ints(cpus)
+			pinning.Cells = append(pinning.Cells, types.NUMACell{Node: nodeID, CPUs: cpus})
+		}
+	}
+	return pinning, nil
+}
+
+// pinnedCPUsDeltas returns the pieces to change of the pCPUs on core,
+// the pCPUs pinned by label are withdrawn from core so that they won't be
+// bound to other workloads, and are given back after being released.
+// The pCPUs in use of core are bound by core, which aren't touched.
+func (m *CPUPinManager) pinnedCPUsDeltas(pinned mapset.Set[int], capacity, usage cpumemtypes.CPUMap) cpumemtypes.CPUMap {
+	deltas := cpumemtypes.CPUMap{}
+	for id := range m.cpuNode {
+		cpu := strconv.Itoa(id)
+		switch {
+		case usage[cpu] > 0:
+			continue
+		case pinned.Contains(id) && capacity[cpu] > 0:
+			deltas[cpu] = -capacity[cpu]
+		case !pinned.Contains(id) && capacity[cpu] == 0:
+			deltas[cpu] = cpuSharePieces
+		}
+	}
+	return deltas
+}
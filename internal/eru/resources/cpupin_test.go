@@ -0,0 +1,81 @@
+package resources
+
+import (
+	"testing"
+
+	mapset "github.com/deckarep/golang-set/v2"
+	cpumemtypes "github.com/projecteru2/core/resource/plugins/cpumem/types"
+	"github.com/projecteru2/yavirt/configs"
+	"github.com/projecteru2/yavirt/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestCPUPinManager() *CPUPinManager {
+	return &CPUPinManager{
+		cfg:     &configs.CPUPinningConfig{},
+		nodeIDs: []int{0, 1},
+		nodes: map[int][]int{
+			0: {2, 6, 3, 7},
+			1: {4, 8, 5, 9},
+		},
+		cpuNode:  map[int]int{2: 0, 6: 0, 3: 0, 7: 0, 4: 1, 8: 1, 5: 1, 9: 1},
+		reserved: []int{0, 1},
+	}
+}
+
+func TestCPUPinAlloc(t *testing.T) {
+	mgr := newTestCPUPinManager()
+
+	// best fit
+	pinning, err := mgr.alloc(2, false, mapset.NewSet[int](4))
+	assert.Nil(t, err)
+	assert.Equal(t, []types.NUMACell{{Node: 1, CPUs: []int{8, 5}}}, pinning.Cells)
+	assert.Equal(t, []int{0, 1}, pinning.EmulatorCPUs)
+
+	// dedicated emulator
+	pinning, err = mgr.alloc(2, true, mapset.NewSet[int]())
+	assert.Nil(t, err)
+	assert.Equal(t, []types.NUMACell{{Node: 0, CPUs: []int{2, 6}}}, pinning.Cells)
+	assert.Equal(t, []int{3}, pinning.EmulatorCPUs)
+
+	// span nodes
+	pinning, err = mgr.alloc(5, false, mapset.NewSet[int](2))
+	assert.Nil(t, err)
+	assert.Equal(t, []int{1, 0}, pinning.Nodes())
+	assert.Equal(t, []int{4, 8, 5, 9, 6}, pinning.VCPUs())
+
+	_, err = mgr.alloc(8, true, mapset.NewSet[int]())
+	assert.NotNil(t, err)
+}
+
+func TestCPUPinFromCPUMap(t *testing.T) {
+	mgr := newTestCPUPinManager()
+	pinning, err := mgr.FromCPUMap(cpumemtypes.CPUMap{"9": 100, "3": 100, "2": 100})
+	assert.Nil(t, err)
+	assert.Equal(t, []types.NUMACell{{Node: 0, CPUs: []int{2, 3}}, {Node: 1, CPUs: []int{9}}}, pinning.Cells)
+
+	// the released pCPUs are ignored
+	pinning, err = mgr.FromCPUMap(cpumemtypes.CPUMap{"4": 100, "5": 0})
+	assert.Nil(t, err)
+	assert.Equal(t, []types.NUMACell{{Node: 1, CPUs: []int{4}}}, pinning.Cells)
+
+	_, err = mgr.FromCPUMap(cpumemtypes.CPUMap{"0": 100})
+	assert.NotNil(t, err)
+
+	// the shared pCPUs can't be pinned
+	_, err = mgr.FromCPUMap(cpumemtypes.CPUMap{"2": 100, "3": 50})
+	assert.NotNil(t, err)
+}
+
+func TestCPUPinPinnedCPUsDeltas(t *testing.T) {
+	mgr := newTestCPUPinManager()
+	capacity := cpumemtypes.CPUMap{"2": 100, "3": 100, "4": 100, "5": 100, "6": 100, "7": 100, "9": 100}
+	usage := cpumemtypes.CPUMap{"2": 100, "3": 0}
+	// 2 is bound by core, 3 and 4 are pinned by label, 8 is released
+	deltas := mgr.pinnedCPUsDeltas(mapset.NewSet[int](2, 3, 4, 0), capacity, usage)
+	assert.Equal(t, cpumemtypes.CPUMap{"3": -100, "4": -100, "8": 100}, deltas)
+
+	capacity = cpumemtypes.CPUMap{"2": 100, "5": 100, "6": 100, "7": 100, "8": 100, "9": 100}
+	deltas = mgr.pinnedCPUsDeltas(mapset.NewSet[int](2, 3, 4, 0), capacity, usage)
+	assert.Empty(t, deltas)
+}
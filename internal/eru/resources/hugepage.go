@@ -0,0 +1,150 @@
+package resources
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/yavirt/internal/types"
+)
+
+var (
+	hugepagesSysDir = "/sys/kernel/mm/hugepages"
+	nodeSysDir      = "/sys/devices/system/node"
+)
+
+// FetchHugepages returns the host's hugepage pools, the key is the page size in KiB.
+func (mgr *Manager) FetchHugepages() (map[int64]*types.HugepageInfo, error) {
+	return readHugepages(hugepagesSysDir)
+}
+
+// FetchNodeHugepages returns the hugepage pools of the NUMA node.
+func (mgr *Manager) FetchNodeHugepages(node int) (map[int64]*types.HugepageInfo, error) {
+	return readHugepages(filepath.Join(nodeSysDir, fmt.Sprintf("node%d", node), "hugepages"))
+}
+
+// HostNode is the key of the pages which are taken from the whole host.
+const HostNode = -1
+
+type hugepageReservation struct {
+	sizeKiB int64
+	pages   map[int]int64
+}
+
+// CheckHugepages checks whether there are enough hugepages for the guest,
+// pages are the count needed on each NUMA node, or on HostNode if it isn't pinned.
+// The pages are reserved for the guest until ReleaseHugepages, so the guests
+// created concurrently can't take the same free pages.
+func (mgr *Manager) CheckHugepages(id string, sizeKiB int64, pages map[int]int64) error {
+	mgr.hugepageLock.Lock()
+	defer mgr.hugepageLock.Unlock()
+
+	for node, need := range pages {
+		var (
+			infos map[int64]*types.HugepageInfo
+			where string
+			err   error
+		)
+		if node == HostNode {
+			infos, err = mgr.FetchHugepages()
+			where = "host"
+		} else {
+			infos, err = mgr.FetchNodeHugepages(node)
+			where = fmt.Sprintf("node%d", node)
+		}
+		if err != nil {
+			return err
+		}
+		reserved := mgr.reservedHugepages(id, sizeKiB, node)
+		if err := checkAvailablePages(infos, sizeKiB, need+reserved, where); err != nil {
+			return err
+		}
+	}
+
+	if mgr.hugepages == nil {
+		mgr.hugepages = map[string]hugepageReservation{}
+	}
+	mgr.hugepages[id] = hugepageReservation{sizeKiB: sizeKiB, pages: pages}
+	return nil
+}
+
+// ReleaseHugepages drops the pages reserved for the guest,
+// they're taken by the guest after it's started, or aren't needed any more.
+func (mgr *Manager) ReleaseHugepages(id string) {
+	mgr.hugepageLock.Lock()
+	defer mgr.hugepageLock.Unlock()
+	delete(mgr.hugepages, id)
+}
+
+// reservedHugepages counts the pages reserved for the other guests on node,
+// all reserved pages are counted for the whole host.
+func (mgr *Manager) reservedHugepages(id string, sizeKiB int64, node int) (ans int64) {
+	for owner, resv := range mgr.hugepages {
+		if owner == id || resv.sizeKiB != sizeKiB {
+			continue
+		}
+		for n, pages := range resv.pages {
+			if node == HostNode || n == node {
+				ans += pages
+			}
+		}
+	}
+	return ans
+}
+
+func checkAvailablePages(infos map[int64]*types.HugepageInfo, sizeKiB, need int64, where string) error {
+	info, ok := infos[sizeKiB]
+	if !ok {
+		return errors.Errorf("hugepage size %dKiB isn't supported by %s", sizeKiB, where)
+	}
+	if avail := info.Available(); avail < need {
+		return errors.Errorf("no enough %dKiB hugepages on %s, need %d but only %d available", sizeKiB, where, need, avail)
+	}
+	return nil
+}
+
+// readHugepages reads the hugepages-<size>kB directories under dir.
+func readHugepages(dir string) (map[int64]*types.HugepageInfo, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[int64]*types.HugepageInfo{}, nil
+		}
+		return nil, errors.Wrap(err, "")
+	}
+	ans := map[int64]*types.HugepageInfo{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, "hugepages-") || !strings.HasSuffix(name, "kB") {
+			continue
+		}
+		sizeKiB, err := strconv.ParseInt(strings.TrimSuffix(strings.TrimPrefix(name, "hugepages-"), "kB"), 10, 64)
+		if err != nil {
+			continue
+		}
+		info := &types.HugepageInfo{SizeKiB: sizeKiB}
+		for fname, ptr := range map[string]*int64{
+			"nr_hugepages":      &info.Total,
+			"free_hugepages":    &info.Free,
+			"resv_hugepages":    &info.Reserved,
+			"surplus_hugepages": &info.Surplus,
+		} {
+			bs, err := os.ReadFile(filepath.Join(dir, name, fname))
+			if err != nil {
+				// per-node pools don't have resv_hugepages
+				if os.IsNotExist(err) {
+					continue
+				}
+				return nil, errors.Wrap(err, "")
+			}
+			if *ptr, err = strconv.ParseInt(strings.TrimSpace(string(bs)), 10, 64); err != nil {
+				return nil, errors.Wrapf(err, "invalid %s", fname)
+			}
+		}
+		ans[sizeKiB] = info
+	}
+	return ans, nil
+}
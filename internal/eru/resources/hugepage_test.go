@@ -0,0 +1,72 @@
+package resources
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHugepages(t *testing.T) {
+	dir := t.TempDir()
+	for fname, val := range map[string]string{
+		"hugepages-2048kB/nr_hugepages":      "512",
+		"hugepages-2048kB/free_hugepages":    "300",
+		"hugepages-2048kB/resv_hugepages":    "44",
+		"hugepages-2048kB/surplus_hugepages": "0",
+		"hugepages-1048576kB/nr_hugepages":   "0",
+		"hugepages-1048576kB/free_hugepages": "0",
+	} {
+		fpth := filepath.Join(dir, fname)
+		assert.Nil(t, os.MkdirAll(filepath.Dir(fpth), 0755))
+		assert.Nil(t, os.WriteFile(fpth, []byte(val+"\n"), 0644))
+	}
+	old := hugepagesSysDir
+	hugepagesSysDir = dir
+	defer func() { hugepagesSysDir = old }()
+
+	mgr := &Manager{}
+	infos, err := mgr.FetchHugepages()
+	assert.Nil(t, err)
+	assert.Len(t, infos, 2)
+	assert.Equal(t, int64(512), infos[2048].Total)
+	assert.Equal(t, int64(256), infos[2048].Available())
+	assert.Equal(t, int64(212), infos[2048].Used())
+
+	// 256 pages of 2M
+	assert.Nil(t, mgr.CheckHugepages("g0", 2048, map[int]int64{HostNode: 256}))
+	assert.NotNil(t, mgr.CheckHugepages("g0", 2048, map[int]int64{HostNode: 257}))
+	assert.NotNil(t, mgr.CheckHugepages("g0", 1<<20, map[int]int64{HostNode: 1}))
+
+	// the pages are reserved for g0
+	assert.Nil(t, mgr.CheckHugepages("g0", 2048, map[int]int64{HostNode: 200}))
+	assert.NotNil(t, mgr.CheckHugepages("g1", 2048, map[int]int64{HostNode: 100}))
+	assert.Nil(t, mgr.CheckHugepages("g1", 2048, map[int]int64{HostNode: 56}))
+	mgr.ReleaseHugepages("g0")
+	assert.Nil(t, mgr.CheckHugepages("g2", 2048, map[int]int64{HostNode: 200}))
+}
+
+func TestNodeHugepages(t *testing.T) {
+	dir := t.TempDir()
+	for fname, val := range map[string]string{
+		"node0/hugepages/hugepages-2048kB/nr_hugepages":   "100",
+		"node0/hugepages/hugepages-2048kB/free_hugepages": "100",
+		"node1/hugepages/hugepages-2048kB/nr_hugepages":   "100",
+		"node1/hugepages/hugepages-2048kB/free_hugepages": "30",
+	} {
+		fpth := filepath.Join(dir, fname)
+		assert.Nil(t, os.MkdirAll(filepath.Dir(fpth), 0755))
+		assert.Nil(t, os.WriteFile(fpth, []byte(val+"\n"), 0644))
+	}
+	old := nodeSysDir
+	nodeSysDir = dir
+	defer func() { nodeSysDir = old }()
+
+	// each node is checked with its own pages
+	mgr := &Manager{}
+	assert.Nil(t, mgr.CheckHugepages("g0", 2048, map[int]int64{0: 90, 1: 30}))
+	assert.NotNil(t, mgr.CheckHugepages("g1", 2048, map[int]int64{1: 1}))
+	assert.NotNil(t, mgr.CheckHugepages("g1", 2048, map[int]int64{0: 11}))
+	assert.Nil(t, mgr.CheckHugepages("g1", 2048, map[int]int64{0: 10}))
+}
@@ -5,7 +5,10 @@ import (
 	"encoding/json"
 	"sync"
 	"testing"
+	"time"
 
+	mapset "github.com/deckarep/golang-set/v2"
+	"github.com/projecteru2/core/log"
 	cpumemtypes "github.com/projecteru2/core/resource/plugins/cpumem/types"
 	stotypes "github.com/projecteru2/resource-storage/storage/types"
 	"github.com/projecteru2/yavirt/configs"
@@ -14,10 +17,15 @@ import (
 	corestore "github.com/projecteru2/yavirt/internal/eru/store/core"
 	storemocks "github.com/projecteru2/yavirt/internal/eru/store/mocks"
 	intertypes "github.com/projecteru2/yavirt/internal/types"
+	"github.com/projecteru2/yavirt/internal/vmcache"
 	bdtypes "github.com/yuyang0/resource-bandwidth/bandwidth/types"
 	gputypes "github.com/yuyang0/resource-gpu/gpu/types"
 )
 
+// pinnedCPUsSyncInterval is the interval to give back the released pCPUs
+// which were pinned by label, the new pinned ones are reported at once.
+const pinnedCPUsSyncInterval = time.Minute
+
 var (
 	mgr *Manager
 	cli store.Store
@@ -29,8 +37,15 @@ type Manager struct {
 
 	gpu     *GPUManager
 	cpumem  *CPUMemManager
+	cpupin  *CPUPinManager
 	sto     *StorageManager
 	gpuLock sync.Mutex
+	cpuLock sync.Mutex
+
+	pinSyncLock sync.Mutex
+
+	hugepageLock sync.Mutex
+	hugepages    map[string]hugepageReservation
 }
 
 func (mgr *Manager) AllocGPU(req *gputypes.EngineParams) (ans []intertypes.GPUInfo, err error) {
@@ -45,6 +60,72 @@ func (mgr *Manager) UnlockGPU() {
 	mgr.gpuLock.Unlock()
 }
 
+// AllocCPUs allocates dedicated pCPUs for a guest.
+func (mgr *Manager) AllocCPUs(count int, req *intertypes.CPUPinningRequest) (*intertypes.CPUPinning, error) {
+	return mgr.cpupin.Alloc(count, req)
+}
+
+// CPUPinningFromCPUMap converts the pCPUs allocated by eru core to pinning.
+func (mgr *Manager) CPUPinningFromCPUMap(cpuMap cpumemtypes.CPUMap) (*intertypes.CPUPinning, error) {
+	return mgr.cpupin.FromCPUMap(cpuMap)
+}
+
+// SyncPinnedCPUs reports the pCPUs pinned by label to core,
+// see CPUPinManager.pinnedCPUsDeltas.
+func (mgr *Manager) SyncPinnedCPUs(ctx context.Context) error {
+	mgr.pinSyncLock.Lock()
+	defer mgr.pinSyncLock.Unlock()
+
+	capacity, usage, err := mgr.coreMgr.FetchCPUMap(ctx)
+	if err != nil {
+		return err
+	}
+	pinned := mapset.NewSet[int](vmcache.FetchPinnedCPUs()...)
+	deltas := mgr.cpupin.pinnedCPUsDeltas(pinned, capacity, usage)
+	if len(deltas) == 0 {
+		return nil
+	}
+	log.WithFunc("SyncPinnedCPUs").Infof(ctx, "update the pinned pCPUs on core: %v", deltas)
+	return mgr.coreMgr.UpdateCPUMap(ctx, deltas)
+}
+
+func (mgr *Manager) runPinnedCPUsSync(ctx context.Context) {
+	ticker := time.NewTicker(pinnedCPUsSyncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := mgr.SyncPinnedCPUs(ctx); err != nil {
+				log.WithFunc("runPinnedCPUsSync").Error(ctx, err, "failed to sync pinned pCPUs")
+			}
+		}
+	}
+}
+
+func (mgr *Manager) LockCPU() {
+	mgr.cpuLock.Lock()
+}
+
+func (mgr *Manager) UnlockCPU() {
+	mgr.cpuLock.Unlock()
+}
+
+// ReallocWorkload asks eru core to change the resources of guest by the deltas,
+// which are keyed by the plugin names.
+func (mgr *Manager) ReallocWorkload(ctx context.Context, id string, deltas map[string]map[string]any) error {
+	req := map[string][]byte{}
+	for name, delta := range deltas {
+		bs, err := json.Marshal(delta)
+		if err != nil {
+			return err
+		}
+		req[name] = bs
+	}
+	return cli.ReallocWorkload(ctx, id, req)
+}
+
 func (mgr *Manager) FetchResources() (map[string][]byte, error) {
 	cpumemBytes, err := json.Marshal(mgr.cpumem.cpumem)
 	if err != nil {
@@ -106,6 +187,10 @@ func Setup(ctx context.Context, cfg *configs.Config, t *testing.T) (*Manager, er
 	if err != nil {
 		return nil, err
 	}
+	cpupinMgr, err := NewCPUPinManager(cfg)
+	if err != nil {
+		return nil, err
+	}
 	stoMgr, err := newStorageManager()
 	if err != nil {
 		return nil, err
@@ -114,8 +199,12 @@ func Setup(ctx context.Context, cfg *configs.Config, t *testing.T) (*Manager, er
 		cfg:     cfg,
 		gpu:     gpuMgr,
 		cpumem:  cpumemMgr,
+		cpupin:  cpupinMgr,
 		sto:     stoMgr,
 		coreMgr: coreMgr,
 	}
+	if t == nil {
+		go mgr.runPinnedCPUsSync(ctx)
+	}
 	return mgr, nil
 }
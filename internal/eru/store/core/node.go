@@ -194,8 +194,15 @@ func (c *Store) GetNodeResource(ctx context.Context, nodename string) (*types.No
 	if err = json.Unmarshal([]byte(resp.ResourceCapacity), &capacity); err != nil {
 		return nil, err
 	}
+	usage := resourcetypes.Resources{}
+	if resp.ResourceUsage != "" {
+		if err = json.Unmarshal([]byte(resp.ResourceUsage), &usage); err != nil {
+			return nil, err
+		}
+	}
 	return &types.NodeResource{
 		Capacity: capacity,
+		Usage:    usage,
 	}, nil
 }
 
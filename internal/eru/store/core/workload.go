@@ -79,3 +79,15 @@ func (c *Store) GetWorkload(ctx context.Context, id string) (*types.Workload, er
 	}
 	return ans, nil
 }
+
+// ReallocWorkload changes the resources of workload by the deltas,
+// which are keyed by the plugin names, eru core resizes the guest
+// by calling back after the resources are reallocated.
+func (c *Store) ReallocWorkload(ctx context.Context, id string, resources map[string][]byte) error {
+	opts := &pb.ReallocOptions{
+		Id:        virttypes.EruID(id),
+		Resources: resources,
+	}
+	_, err := c.GetClient().ReallocResource(ctx, opts)
+	return err
+}
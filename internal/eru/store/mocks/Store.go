@@ -293,6 +293,24 @@ func (_m *Store) NodeStatusStream(ctx context.Context) (<-chan *types.NodeStatus
 	return r0, r1
 }
 
+// ReallocWorkload provides a mock function with given fields: ctx, id, resources
+func (_m *Store) ReallocWorkload(ctx context.Context, id string, resources map[string][]byte) error {
+	ret := _m.Called(ctx, id, resources)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReallocWorkload")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, map[string][]byte) error); ok {
+		r0 = rf(ctx, id, resources)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // SetNode provides a mock function with given fields: ctx, opts
 func (_m *Store) SetNode(ctx context.Context, opts *types.SetNodeOpts) (*types.Node, error) {
 	ret := _m.Called(ctx, opts)
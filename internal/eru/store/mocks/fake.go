@@ -68,12 +68,14 @@ func NewFakeStore() store.Store {
 		nodename := "fake"
 		m.Lock()
 		defer m.Unlock()
+		// a negative ttl removes the status
+		alive := ttl >= 0
 		if status, ok := m.nodeStatus.Get(nodename); ok {
-			status.Alive = true
+			status.Alive = alive
 		} else {
 			m.nodeStatus.Set(nodename, &types.NodeStatus{
 				Nodename: nodename,
-				Alive:    true,
+				Alive:    alive,
 			})
 		}
 		return nil
@@ -146,6 +148,7 @@ func NewFakeStore() store.Store {
 			ID: virttypes.EruID("00033017009174384208170000000002"),
 		},
 	}, nil)
+	m.On("ReallocWorkload", mock.Anything, mock.Anything, mock.Anything).Return(nil)
 	// TODO
 	m.On("GetNodeResource", mock.Anything, mock.Anything).Return(&types.NodeResource{
 		Capacity: resourcetypes.Resources{
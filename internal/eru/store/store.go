@@ -21,4 +21,5 @@ type Store interface { //nolint
 	ListNodeWorkloads(ctx context.Context, nodename string) ([]*types.Workload, error)
 	GetNodeResource(ctx context.Context, nodename string) (*types.NodeResource, error)
 	GetWorkload(ctx context.Context, id string) (*types.Workload, error)
+	ReallocWorkload(ctx context.Context, id string, resources map[string][]byte) error
 }
@@ -23,6 +23,7 @@ type NodeStatus struct {
 
 type NodeResource struct {
 	Capacity resourcetypes.Resources
+	Usage    resourcetypes.Resources
 }
 
 type Workload struct {
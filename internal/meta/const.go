@@ -35,6 +35,10 @@ const (
 	StatusFreeze = "frozen"
 	// StatusThaw .
 	StatusThaw = "thawed"
+	// StatusHibernating .
+	StatusHibernating = "hibernating"
+	// StatusHibernated means the memory is saved to disk, and it's restored on starting.
+	StatusHibernated = "hibernated"
 )
 
 // AllStatuses .
@@ -51,4 +55,6 @@ var AllStatuses = []string{
 	StatusResizing,
 	StatusDestroying,
 	StatusDestroyed,
+	StatusHibernating,
+	StatusHibernated,
 }
@@ -82,12 +82,13 @@ func (g *Generic) checkStatus(next string) bool {
 	case StatusDestroyed:
 		return now == StatusDestroying
 	case StatusDestroying:
-		return now == StatusStopped || now == StatusDestroyed
+		return now == StatusStopped || now == StatusDestroyed || now == StatusHibernated
 
 	case StatusStopped:
 		return now == StatusStopping || now == StatusMigrating || now == StatusCaptured
 	case StatusStopping:
-		return now == StatusRunning || now == StatusStopped
+		// stopping a hibernated guest discards the saved memory
+		return now == StatusRunning || now == StatusStopped || now == StatusHibernated
 
 	case StatusCapturing:
 		return now == StatusCapturing || now == StatusStopped
@@ -112,7 +113,12 @@ func (g *Generic) checkStatus(next string) bool {
 		return now == StatusPaused
 
 	case StatusStarting:
-		return now == StatusStopped || now == StatusCreating
+		return now == StatusStopped || now == StatusCreating || now == StatusHibernated
+
+	case StatusHibernating:
+		return now == StatusRunning
+	case StatusHibernated:
+		return now == StatusHibernating
 
 	case StatusCreating:
 		return now == StatusPending
@@ -9,15 +9,23 @@ import (
 )
 
 const (
-	hostPrefix     = "/hosts"
-	guestPrefix    = "/guests"
-	volPrefix      = "/vols"
-	ipPrefix       = "/ips"
-	imgPrefix      = "/imgs"
-	uimgPrefix     = "/uimgs"
-	snapshotPrefix = "/snapshots"
-	ippPrefix      = "/ippools"
-	ipblockPrefix  = "/blocks"
+	hostPrefix        = "/hosts"
+	guestPrefix       = "/guests"
+	volPrefix         = "/vols"
+	ipPrefix          = "/ips"
+	imgPrefix         = "/imgs"
+	uimgPrefix        = "/uimgs"
+	snapshotPrefix    = "/snapshots"
+	ippPrefix         = "/ippools"
+	ipblockPrefix     = "/blocks"
+	hostCPUPrefix     = "/hostcpus"
+	flavorPrefix      = "/flavors"
+	checkpointPrefix  = "/checkpoints"
+	lifecyclePrefix   = "/lifecycle"
+	maintenancePrefix = "/maintenance"
+	throttlePrefix    = "/throttle-groups"
+	eventPrefix       = "/events"
+	eventSeqPrefix    = "/event-seqs"
 )
 
 // HostCounterKey /<prefix>/hosts:counter
@@ -40,6 +48,71 @@ func HostKey(name string) string {
 	return filepath.Join(configs.Conf.Etcd.Prefix, hostPrefix, name)
 }
 
+// HostCPUKey /<prefix>/hostcpus/<name>
+func HostCPUKey(name string) string {
+	return filepath.Join(HostCPUsPrefix(), name)
+}
+
+// HostCPUsPrefix /<prefix>/hostcpus/
+func HostCPUsPrefix() string {
+	return fmt.Sprintf("%s/", filepath.Join(configs.Conf.Etcd.Prefix, hostCPUPrefix))
+}
+
+// FlavorKey /<prefix>/flavors/<name>
+func FlavorKey(name string) string {
+	return filepath.Join(FlavorsPrefix(), name)
+}
+
+// FlavorsPrefix /<prefix>/flavors/
+func FlavorsPrefix() string {
+	return fmt.Sprintf("%s/", filepath.Join(configs.Conf.Etcd.Prefix, flavorPrefix))
+}
+
+// CheckpointKey /<prefix>/checkpoints/<guest ID>/<name>
+func CheckpointKey(guestID, name string) string {
+	return filepath.Join(CheckpointsPrefix(guestID), name)
+}
+
+// CheckpointsPrefix /<prefix>/checkpoints/<guest ID>/
+func CheckpointsPrefix(guestID string) string {
+	return fmt.Sprintf("%s/", filepath.Join(configs.Conf.Etcd.Prefix, checkpointPrefix, guestID))
+}
+
+// LifecycleKey /<prefix>/lifecycle/<host name>
+func LifecycleKey(hostName string) string {
+	return filepath.Join(configs.Conf.Etcd.Prefix, lifecyclePrefix, hostName)
+}
+
+// MaintenanceKey /<prefix>/maintenance/<host name>
+func MaintenanceKey(hostName string) string {
+	return filepath.Join(configs.Conf.Etcd.Prefix, maintenancePrefix, hostName)
+}
+
+// ThrottleGroupKey /<prefix>/throttle-groups/<name>
+func ThrottleGroupKey(name string) string {
+	return filepath.Join(ThrottleGroupsPrefix(), name)
+}
+
+// ThrottleGroupsPrefix /<prefix>/throttle-groups/
+func ThrottleGroupsPrefix() string {
+	return fmt.Sprintf("%s/", filepath.Join(configs.Conf.Etcd.Prefix, throttlePrefix))
+}
+
+// EventKey /<prefix>/events/<host name>/<seq>, seq is zero padded so the keys are in order.
+func EventKey(hostName string, seq uint64) string {
+	return filepath.Join(EventsPrefix(hostName), fmt.Sprintf("%020d", seq))
+}
+
+// EventSeqKey /<prefix>/event-seqs/<host name>, it's out of the events prefix.
+func EventSeqKey(hostName string) string {
+	return filepath.Join(configs.Conf.Etcd.Prefix, eventSeqPrefix, hostName)
+}
+
+// EventsPrefix /<prefix>/events/<host name>/
+func EventsPrefix(hostName string) string {
+	return fmt.Sprintf("%s/", filepath.Join(configs.Conf.Etcd.Prefix, eventPrefix, hostName))
+}
+
 // GuestKey /<prefix>/guests/<id>
 func GuestKey(id string) string {
 	return filepath.Join(GuestsPrefix(), id)
@@ -55,6 +128,11 @@ func VolumeKey(id string) string {
 	return filepath.Join(configs.Conf.Etcd.Prefix, volPrefix, id)
 }
 
+// VolumesPrefix /<prefix>/vols/
+func VolumesPrefix() string {
+	return fmt.Sprintf("%s/", filepath.Join(configs.Conf.Etcd.Prefix, volPrefix))
+}
+
 func SnapshotKey(id string) string {
 	return filepath.Join(configs.Conf.Etcd.Prefix, snapshotPrefix, id)
 }
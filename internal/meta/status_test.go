@@ -21,7 +21,7 @@ func TestStatusForward(t *testing.T) {
 		},
 		{
 			StatusStarting,
-			allow([]string{StatusStarting, StatusCreating, StatusStopped}),
+			allow([]string{StatusStarting, StatusCreating, StatusStopped, StatusHibernated}),
 		},
 		{
 			StatusRunning,
@@ -29,7 +29,7 @@ func TestStatusForward(t *testing.T) {
 		},
 		{
 			StatusStopping,
-			allow([]string{StatusStopping, StatusRunning, StatusStopped}),
+			allow([]string{StatusStopping, StatusRunning, StatusStopped, StatusHibernated}),
 		},
 		{
 			StatusStopped,
@@ -45,7 +45,7 @@ func TestStatusForward(t *testing.T) {
 		},
 		{
 			StatusDestroying,
-			allow([]string{StatusDestroying, StatusStopped, StatusDestroyed}),
+			allow([]string{StatusDestroying, StatusStopped, StatusDestroyed, StatusHibernated}),
 		},
 		{
 			StatusDestroyed,
@@ -59,6 +59,14 @@ func TestStatusForward(t *testing.T) {
 			StatusCaptured,
 			allow([]string{StatusCaptured, StatusCapturing}),
 		},
+		{
+			StatusHibernating,
+			allow([]string{StatusHibernating, StatusRunning}),
+		},
+		{
+			StatusHibernated,
+			allow([]string{StatusHibernated, StatusHibernating}),
+		},
 	}
 
 	var g = NewGeneric()
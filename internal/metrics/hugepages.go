@@ -0,0 +1,75 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/projecteru2/core/log"
+	"github.com/projecteru2/yavirt/internal/eru/resources"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	hugepagesTotalDesc = prometheus.NewDesc(
+		prometheus.BuildFQName("node", "hugepages", "total"),
+		"Number of hugepages in the pool.",
+		[]string{"host", "size"},
+		nil)
+	hugepagesFreeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName("node", "hugepages", "free"),
+		"Number of hugepages which are not allocated yet.",
+		[]string{"host", "size"},
+		nil)
+	hugepagesReservedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName("node", "hugepages", "reserved"),
+		"Number of hugepages which are reserved but not allocated yet.",
+		[]string{"host", "size"},
+		nil)
+	hugepagesUsedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName("node", "hugepages", "used"),
+		"Number of hugepages which are in use.",
+		[]string{"host", "size"},
+		nil)
+)
+
+// HugepagesExporter exports the usage of the host's hugepage pools.
+type HugepagesExporter struct {
+	host string
+}
+
+// NewHugepagesExporter .
+func NewHugepagesExporter(hn string) *HugepagesExporter {
+	return &HugepagesExporter{hn}
+}
+
+// Describe .
+func (e *HugepagesExporter) Describe(ch chan<- *prometheus.Desc) {
+	ch <- hugepagesTotalDesc
+	ch <- hugepagesFreeDesc
+	ch <- hugepagesReservedDesc
+	ch <- hugepagesUsedDesc
+}
+
+// Collect .
+func (e *HugepagesExporter) Collect(ch chan<- prometheus.Metric) {
+	mgr := resources.GetManager()
+	if mgr == nil {
+		return
+	}
+	infos, err := mgr.FetchHugepages()
+	if err != nil {
+		log.WithFunc("HugepagesExporter.Collect").Error(context.TODO(), err, "failed to fetch hugepages")
+		return
+	}
+	for _, info := range infos {
+		size := fmt.Sprintf("%dkB", info.SizeKiB)
+		for desc, val := range map[*prometheus.Desc]int64{
+			hugepagesTotalDesc:    info.Total,
+			hugepagesFreeDesc:     info.Free,
+			hugepagesReservedDesc: info.Reserved,
+			hugepagesUsedDesc:     info.Used(),
+		} {
+			ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, float64(val), e.host, size)
+		}
+	}
+}
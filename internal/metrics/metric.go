@@ -25,6 +25,7 @@ func Setup(hn string, cols ...prometheus.Collector) {
 	metr.RegisterCounter(MetricErrorCount, "yavirt errors", nil) //nolint
 	e := NewLibvirtExporter(hn)
 	prometheus.MustRegister(e)
+	prometheus.MustRegister(NewHugepagesExporter(hn))
 	if len(cols) > 0 {
 		prometheus.MustRegister(cols...)
 	}
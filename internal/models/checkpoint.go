@@ -0,0 +1,100 @@
+package models
+
+import (
+	"context"
+	"sort"
+
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/yavirt/internal/meta"
+	"github.com/projecteru2/yavirt/internal/types"
+	"github.com/projecteru2/yavirt/pkg/store"
+	"github.com/projecteru2/yavirt/pkg/terrors"
+	"github.com/projecteru2/yavirt/pkg/utils"
+)
+
+// CreateCheckpoint saves the metadata of a new checkpoint, it fails if the name exists.
+// etcd keys:
+//
+//	/checkpoints/<guest ID>/<name>
+func CreateCheckpoint(cp *types.Checkpoint) error {
+	bs, err := utils.JSONEncode(cp, "\t")
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+
+	ctx, cancel := meta.Context(context.Background())
+	defer cancel()
+
+	data := map[string]string{meta.CheckpointKey(cp.GuestID, cp.Name): string(bs)}
+	if err := store.Create(ctx, data); err != nil {
+		return errors.Wrapf(err, "failed to create checkpoint %s", cp.Name)
+	}
+	return nil
+}
+
+// LoadCheckpoint .
+func LoadCheckpoint(guestID, name string) (*types.Checkpoint, error) {
+	ctx, cancel := meta.Context(context.Background())
+	defer cancel()
+
+	cp := &types.Checkpoint{}
+	if _, err := store.Get(ctx, meta.CheckpointKey(guestID, name), cp); err != nil {
+		return nil, errors.Wrapf(err, "failed to load checkpoint %s", name)
+	}
+	return cp, nil
+}
+
+// ListCheckpoints returns the checkpoints of guest sorted by the creation time.
+func ListCheckpoints(guestID string) ([]*types.Checkpoint, error) {
+	ctx, cancel := meta.Context(context.Background())
+	defer cancel()
+
+	data, _, err := store.GetPrefix(ctx, meta.CheckpointsPrefix(guestID), 0)
+	switch {
+	case terrors.IsKeyNotExistsErr(err):
+		return nil, nil
+	case err != nil:
+		return nil, errors.Wrap(err, "failed to get prefix")
+	}
+
+	cps := make([]*types.Checkpoint, 0, len(data))
+	for key, val := range data {
+		cp := &types.Checkpoint{}
+		if err := utils.JSONDecode(val, cp); err != nil {
+			return nil, errors.Wrapf(err, "invalid checkpoint %s", key)
+		}
+		cps = append(cps, cp)
+	}
+	sort.Slice(cps, func(i, j int) bool {
+		return cps[i].CreatedAt.Before(cps[j].CreatedAt)
+	})
+	return cps, nil
+}
+
+// DeleteCheckpoint .
+func DeleteCheckpoint(guestID, name string) error {
+	ctx, cancel := meta.Context(context.Background())
+	defer cancel()
+
+	return errors.Wrapf(store.Delete(ctx, []string{meta.CheckpointKey(guestID, name)}, nil), "failed to delete checkpoint %s", name)
+}
+
+// DeleteCheckpoints removes the metadata of all checkpoints of guest.
+func DeleteCheckpoints(guestID string) error {
+	cps, err := ListCheckpoints(guestID)
+	if err != nil {
+		return err
+	}
+	keys := make([]string, len(cps))
+	for i, cp := range cps {
+		keys[i] = meta.CheckpointKey(guestID, cp.Name)
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+
+	ctx, cancel := meta.Context(context.Background())
+	defer cancel()
+
+	return errors.Wrapf(store.Delete(ctx, keys, nil), "failed to delete checkpoints of guest %s", guestID)
+}
@@ -0,0 +1,250 @@
+package models
+
+import (
+	"context"
+	"path"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/core/log"
+	"github.com/projecteru2/yavirt/internal/meta"
+	"github.com/projecteru2/yavirt/internal/types"
+	"github.com/projecteru2/yavirt/pkg/store"
+	"github.com/projecteru2/yavirt/pkg/terrors"
+	"github.com/projecteru2/yavirt/pkg/utils"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// the max number of events written in one transaction,
+// etcd limits the operations of a transaction to 128 by default.
+const maxEventsPerTxn = 64
+
+// EventJournal keeps the latest events of a host in etcd, the sequence
+// numbers continue from the high-water mark, which is kept ahead of the
+// assigned ones by the size, so the ones of the unwritten events aren't reused
+// after restarting unless the journal has fallen behind.
+// etcd keys:
+//
+//	/events/<host name>/<seq>
+//	/event-seqs/<host name>
+type EventJournal struct {
+	mu       sync.Mutex
+	hostName string
+	size     uint64
+	seq      uint64
+	// the events which haven't been written into etcd
+	pending []types.Event
+	kick    chan struct{}
+}
+
+// NewEventJournal loads the last sequence number of host, and writes the
+// appended events into etcd in the background until ctx is done.
+// Only yavirtd writes the journal, so the sequence numbers are assigned by one writer.
+func NewEventJournal(ctx context.Context, hostName string, size int) (*EventJournal, error) {
+	if size < 1 {
+		return nil, errors.Wrapf(terrors.ErrInvalidValue, "invalid event journal size %d", size)
+	}
+	mctx, cancel := meta.Context(context.Background())
+	defer cancel()
+
+	data, _, err := store.GetPrefix(mctx, meta.EventsPrefix(hostName), 0)
+	if err != nil && !terrors.IsKeyNotExistsErr(err) {
+		return nil, errors.Wrap(err, "failed to get prefix")
+	}
+	j := &EventJournal{
+		hostName: hostName,
+		size:     uint64(size),
+		kick:     make(chan struct{}, 1),
+	}
+	for key := range data {
+		if seq, err := strconv.ParseUint(path.Base(key), 10, 64); err == nil && seq > j.seq {
+			j.seq = seq
+		}
+	}
+	var mark uint64
+	if _, err := store.Get(mctx, meta.EventSeqKey(hostName), &mark); err != nil && !terrors.IsKeyNotExistsErr(err) {
+		return nil, errors.Wrap(err, "failed to get event seq")
+	}
+	j.seq = max(j.seq, mark)
+	switch succ, err := store.BatchOperate(mctx, []clientv3.Op{j.markOp(j.seq)}); {
+	case err != nil:
+		return nil, errors.Wrap(err, "failed to save event seq")
+	case !succ:
+		return nil, errors.Newf("failed to save event seq of %s", hostName)
+	}
+	go j.run(ctx)
+	return j, nil
+}
+
+// markOp saves the high-water mark of the sequence numbers,
+// the ones up to seq plus the size may have been assigned.
+func (j *EventJournal) markOp(seq uint64) clientv3.Op {
+	return clientv3.OpPut(meta.EventSeqKey(j.hostName), strconv.FormatUint(seq+j.size, 10))
+}
+
+// Append assigns the next sequence number to event, it doesn't wait for etcd,
+// as it's on the path of notifying the watchers. The oldest pending events
+// are dropped if etcd falls behind more than the size.
+func (j *EventJournal) Append(event *types.Event) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.seq++
+	event.Seq = j.seq
+	if uint64(len(j.pending)) >= j.size {
+		log.WithFunc("EventJournal.Append").Warnf(context.TODO(), "event journal falls behind, drop event %d", j.pending[0].Seq)
+		j.pending = j.pending[1:]
+	}
+	j.pending = append(j.pending, *event)
+
+	select {
+	case j.kick <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// List returns the events whose sequence numbers are greater than since in order,
+// including the ones which haven't been written, at most limit ones if it's positive.
+func (j *EventJournal) List(since uint64, limit int) ([]types.Event, error) {
+	j.mu.Lock()
+	pending := append([]types.Event(nil), j.pending...)
+	j.mu.Unlock()
+
+	events, err := ListEvents(j.hostName, since, 0)
+	if err != nil {
+		return nil, err
+	}
+	var last uint64
+	if len(events) > 0 {
+		last = events[len(events)-1].Seq
+	}
+	for _, event := range pending {
+		if event.Seq > since && event.Seq > last {
+			events = append(events, event)
+		}
+	}
+	if limit > 0 && len(events) > limit {
+		events = events[:limit]
+	}
+	return events, nil
+}
+
+func (j *EventJournal) run(ctx context.Context) {
+	logger := log.WithFunc("EventJournal.run")
+	for {
+		select {
+		case <-j.kick:
+		case <-ctx.Done():
+			// flush the pending events once before exiting
+			if err := j.flush(); err != nil {
+				logger.Warnf(context.TODO(), "failed to flush events: %s", err)
+			}
+			return
+		}
+		for {
+			err := j.flush()
+			if err == nil {
+				break
+			}
+			logger.Warnf(ctx, "failed to write events: %s", err)
+			select {
+			case <-time.After(time.Second):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// flush writes the pending events in batches until there are no more.
+func (j *EventJournal) flush() error {
+	for {
+		j.mu.Lock()
+		batch := append([]types.Event(nil), j.pending[:min(len(j.pending), maxEventsPerTxn)]...)
+		seq := j.seq
+		j.mu.Unlock()
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := j.write(batch, seq); err != nil {
+			return err
+		}
+		j.done(batch[len(batch)-1].Seq)
+	}
+}
+
+// done removes the written events from the pending ones.
+func (j *EventJournal) done(seq uint64) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	idx := sort.Search(len(j.pending), func(i int) bool {
+		return j.pending[i].Seq > seq
+	})
+	j.pending = j.pending[idx:]
+}
+
+// write saves the events in one transaction, the events out of the size
+// are removed, and the high-water mark is raised above seq in the same transaction.
+func (j *EventJournal) write(events []types.Event, seq uint64) error {
+	ctx, cancel := meta.Context(context.Background())
+	defer cancel()
+
+	ops := make([]clientv3.Op, 0, len(events)+2)
+	ops = append(ops, j.markOp(seq))
+	for i := range events {
+		bs, err := utils.JSONEncode(&events[i])
+		if err != nil {
+			return errors.Wrap(err, "")
+		}
+		ops = append(ops, clientv3.OpPut(meta.EventKey(j.hostName, events[i].Seq), string(bs)))
+	}
+	if last := events[len(events)-1].Seq; last > j.size {
+		// the keys are zero padded, so the range covers the oldest ones
+		end := meta.EventKey(j.hostName, last-j.size+1)
+		ops = append(ops, clientv3.OpDelete(meta.EventsPrefix(j.hostName), clientv3.WithRange(end)))
+	}
+	switch succ, err := store.BatchOperate(ctx, ops); {
+	case err != nil:
+		return errors.Wrapf(err, "failed to write events %d-%d", events[0].Seq, events[len(events)-1].Seq)
+	case !succ:
+		return errors.Newf("failed to write events %d-%d", events[0].Seq, events[len(events)-1].Seq)
+	}
+	return nil
+}
+
+// ListEvents returns the kept events of host whose sequence numbers
+// are greater than since in order, at most limit ones if it's positive.
+func ListEvents(hostName string, since uint64, limit int) ([]types.Event, error) {
+	ctx, cancel := meta.Context(context.Background())
+	defer cancel()
+
+	data, _, err := store.GetPrefix(ctx, meta.EventsPrefix(hostName), 0)
+	switch {
+	case terrors.IsKeyNotExistsErr(err):
+		return nil, nil
+	case err != nil:
+		return nil, errors.Wrap(err, "failed to get prefix")
+	}
+
+	events := make([]types.Event, 0, len(data))
+	for key, val := range data {
+		var event types.Event
+		if err := utils.JSONDecode(val, &event); err != nil {
+			return nil, errors.Wrapf(err, "invalid event %s", key)
+		}
+		if event.Seq > since {
+			events = append(events, event)
+		}
+	}
+	sort.Slice(events, func(i, k int) bool {
+		return events[i].Seq < events[k].Seq
+	})
+	if limit > 0 && len(events) > limit {
+		events = events[:limit]
+	}
+	return events, nil
+}
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"context"
+	"sort"
+
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/yavirt/configs"
+	"github.com/projecteru2/yavirt/internal/meta"
+	"github.com/projecteru2/yavirt/internal/types"
+	"github.com/projecteru2/yavirt/pkg/store"
+	"github.com/projecteru2/yavirt/pkg/terrors"
+	"github.com/projecteru2/yavirt/pkg/utils"
+)
+
+// CreateFlavor saves a new flavor, it fails if the name exists.
+// etcd keys:
+//
+//	/flavors/<name>
+func CreateFlavor(flavor *types.Flavor) error {
+	if err := flavor.Check(&configs.Conf.Resource); err != nil {
+		return err
+	}
+	bs, err := utils.JSONEncode(flavor, "\t")
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+
+	ctx, cancel := meta.Context(context.Background())
+	defer cancel()
+
+	data := map[string]string{meta.FlavorKey(flavor.Name): string(bs)}
+	if err := store.Create(ctx, data); err != nil {
+		return errors.Wrapf(err, "failed to create flavor %s", flavor.Name)
+	}
+	return nil
+}
+
+// LoadFlavor .
+func LoadFlavor(name string) (*types.Flavor, error) {
+	ctx, cancel := meta.Context(context.Background())
+	defer cancel()
+
+	flavor := &types.Flavor{}
+	if _, err := store.Get(ctx, meta.FlavorKey(name), flavor); err != nil {
+		return nil, errors.Wrapf(err, "failed to load flavor %s", name)
+	}
+	return flavor, nil
+}
+
+// ListFlavors returns all flavors sorted by name.
+func ListFlavors() ([]*types.Flavor, error) {
+	ctx, cancel := meta.Context(context.Background())
+	defer cancel()
+
+	data, _, err := store.GetPrefix(ctx, meta.FlavorsPrefix(), 0)
+	switch {
+	case terrors.IsKeyNotExistsErr(err):
+		return nil, nil
+	case err != nil:
+		return nil, errors.Wrap(err, "failed to get prefix")
+	}
+
+	flavors := make([]*types.Flavor, 0, len(data))
+	for key, val := range data {
+		flavor := &types.Flavor{}
+		if err := utils.JSONDecode(val, flavor); err != nil {
+			return nil, errors.Wrapf(err, "invalid flavor %s", key)
+		}
+		flavors = append(flavors, flavor)
+	}
+	sort.Slice(flavors, func(i, j int) bool {
+		return flavors[i].Name < flavors[j].Name
+	})
+	return flavors, nil
+}
+
+// DeleteFlavor .
+func DeleteFlavor(name string) error {
+	if _, err := LoadFlavor(name); err != nil {
+		return err
+	}
+
+	ctx, cancel := meta.Context(context.Background())
+	defer cancel()
+
+	return errors.Wrapf(store.Delete(ctx, []string{meta.FlavorKey(name)}, nil), "failed to delete flavor %s", name)
+}
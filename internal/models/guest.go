@@ -3,13 +3,16 @@ package models
 import (
 	"context"
 	"encoding/json"
+	"os"
 	"strings"
 
 	erucluster "github.com/projecteru2/core/cluster"
+	cpumemtypes "github.com/projecteru2/core/resource/plugins/cpumem/types"
 
 	"github.com/cockroachdb/errors"
 	"github.com/projecteru2/core/log"
 	"github.com/projecteru2/yavirt/configs"
+	"github.com/projecteru2/yavirt/internal/eru/resources"
 	"github.com/projecteru2/yavirt/internal/meta"
 	"github.com/projecteru2/yavirt/internal/network"
 	"github.com/projecteru2/yavirt/internal/types"
@@ -39,14 +42,23 @@ type Guest struct {
 	VolIDs          []string               `json:"vols"`
 	GPUEngineParams *gputypes.EngineParams `json:"gpu_engine_params"`
 	BDEngineParams  *bdtypes.EngineParams  `json:"bandwidth_engine_params"`
-	IPNets          meta.IPNets            `json:"ips"`
-	ExtraNetworks   Networks               `json:"extra_networks,omitempty"`
-	NetworkMode     string                 `json:"network,omitempty"`
-	NetworkPair     string                 `json:"network_pair,omitempty"`
-	EndpointID      string                 `json:"endpoint,omitempty"`
-	MAC             string                 `json:"mac"`
-	MTU             int                    `json:"mtu"`
-	JSONLabels      map[string]string      `json:"labels"`
+	CPUPinning      *types.CPUPinning      `json:"cpu_pinning,omitempty"`
+	CPUQoS          *types.CPUQoS          `json:"cpu_qos,omitempty"`
+	// the CPU and memory of the hibernated guest are released to eru.
+	CPUMemReleased bool              `json:"cpumem_released,omitempty"`
+	IPNets         meta.IPNets       `json:"ips"`
+	ExtraNetworks  Networks          `json:"extra_networks,omitempty"`
+	NetworkMode    string            `json:"network,omitempty"`
+	NetworkPair    string            `json:"network_pair,omitempty"`
+	EndpointID     string            `json:"endpoint,omitempty"`
+	MAC            string            `json:"mac"`
+	MTU            int               `json:"mtu"`
+	JSONLabels     map[string]string `json:"labels"`
+	RescueImage    string            `json:"rescue_image,omitempty"`
+	ISOPath        string            `json:"iso_path,omitempty"`
+	BootOrder      []string          `json:"boot_order,omitempty"`
+	// the distro detected from the sys volume by libguestfs
+	OSDistro string `json:"os_distro,omitempty"`
 
 	LambdaOption *LambdaOptions  `json:"lambda_option,omitempty"`
 	LambdaStdin  bool            `json:"lambda_stdin,omitempty"`
@@ -79,6 +91,10 @@ func (g *Guest) Check() error {
 		}
 	}
 
+	if _, err := g.CrashPolicy(); err != nil {
+		return err
+	}
+
 	return g.Vols.Check()
 }
 
@@ -176,6 +192,10 @@ func (g *Guest) Load(host *Host, networkHandler network.Driver, opts ...Option)
 		return errors.WithMessage(err, "failed to load IPs")
 	}
 
+	// the guests installed from the host path ISO have no image
+	if g.ImageName == "" {
+		return nil
+	}
 	if g.Img, err = vmiFact.LoadImage(context.TODO(), g.ImageName); err != nil {
 		if op.IgnoreLoadImageErr {
 			logger.Warnf(context.TODO(), "failed to load image %s: %s", g.ImageName, err)
@@ -269,6 +289,77 @@ func (g *Guest) CIDRs() []string {
 	return cidrs
 }
 
+// CPUPinningRequest returns the pinning request in label, and whether the guest needs pinning.
+func (g *Guest) CPUPinningRequest() (*types.CPUPinningRequest, bool, error) {
+	bs, ok := g.JSONLabels[types.CPUPinningLabelKey]
+	if !ok {
+		return nil, false, nil
+	}
+	req := &types.CPUPinningRequest{}
+	if err := json.Unmarshal([]byte(bs), req); err != nil {
+		return nil, false, errors.Wrapf(terrors.ErrInvalidValue, "invalid label %s: %s", types.CPUPinningLabelKey, bs)
+	}
+	return req, true, nil
+}
+
+// HugepagesRequest returns the hugepages request in label, and whether the guest is backed by hugepages.
+func (g *Guest) HugepagesRequest() (*types.HugepagesRequest, bool, error) {
+	bs, ok := g.JSONLabels[types.HugepagesLabelKey]
+	if !ok {
+		return nil, false, nil
+	}
+	req := &types.HugepagesRequest{}
+	if err := json.Unmarshal([]byte(bs), req); err != nil {
+		return nil, false, errors.Wrapf(terrors.ErrInvalidValue, "invalid label %s: %s", types.HugepagesLabelKey, bs)
+	}
+	return req, true, nil
+}
+
+// NICBandwidth returns the NIC bandwidth in label, the zero values mean the defaults.
+func (g *Guest) NICBandwidth() (*types.NICBandwidth, error) {
+	bw := &types.NICBandwidth{}
+	bs, ok := g.JSONLabels[types.NICBandwidthLabelKey]
+	if !ok {
+		return bw, nil
+	}
+	if err := json.Unmarshal([]byte(bs), bw); err != nil {
+		return nil, errors.Wrapf(terrors.ErrInvalidValue, "invalid label %s: %s", types.NICBandwidthLabelKey, bs)
+	}
+	return bw, nil
+}
+
+// CrashPolicy returns the crash config overridden by the label of guest.
+func (g *Guest) CrashPolicy() (*types.CrashPolicy, error) {
+	return types.NewCrashPolicy(&configs.Conf.Crash, g.JSONLabels)
+}
+
+// CheckHugepages refuses the guest if there are no enough hugepages,
+// they're checked on the NUMA node of each cell if the guest is pinned.
+// The pages are reserved for the guest until it's started or removed.
+func (g *Guest) CheckHugepages() error {
+	req, ok, err := g.HugepagesRequest()
+	if err != nil || !ok {
+		return err
+	}
+	sizeKiB, err := req.SizeInKiB()
+	if err != nil {
+		return err
+	}
+	pageBytes := sizeKiB << 10
+	if g.Memory%pageBytes != 0 {
+		return errors.Wrapf(terrors.ErrInvalidValue, "memory %d isn't a multiple of hugepage size %dKiB", g.Memory, sizeKiB)
+	}
+	pages := map[int]int64{resources.HostNode: g.Memory / pageBytes}
+	if g.CPUPinning != nil {
+		// the cells are sized in MiB like the guest NUMA topology
+		pages = map[int]int64{}
+		for idx, mem := range g.CPUPinning.CellMemory(g.MemoryInMiB()) {
+			pages[g.CPUPinning.Cells[idx].Node] += ((mem << 20) + pageBytes - 1) / pageBytes
+		}
+	}
+	return resources.GetManager().CheckHugepages(g.ID, sizeKiB, pages)
+}
+
 // MemoryInMiB .
 func (g *Guest) MemoryInMiB() int64 {
 	return utils.ConvToMB(g.Memory)
@@ -301,7 +392,10 @@ func (g *Guest) GenCloudInit(img *vmitypes.Image) (*types.CloudInitConfig, error
 			IP:     gwAddr,
 			OnLink: !inSubnet,
 		},
-		OS: &img.OS,
+		OS: &vmitypes.OSInfo{},
+	}
+	if img != nil {
+		obj.OS = &img.OS
 	}
 	if bs, ok := g.JSONLabels["instance/cloud-init"]; ok {
 		if err := json.Unmarshal([]byte(bs), &obj); err != nil {
@@ -315,6 +409,21 @@ func (g *Guest) GenCloudInit(img *vmitypes.Image) (*types.CloudInitConfig, error
 		obj.Username = configs.Conf.VMAuth.Username
 		obj.Password = configs.Conf.VMAuth.Password
 	}
+	// the generated user-data still needs an account when merging with user supplied user-data
+	if obj.UserData != "" && obj.UserDataMode != types.UserDataModeReplace && obj.Username == "" && obj.Password == "" {
+		obj.Username = configs.Conf.VMAuth.Username
+		obj.Password = configs.Conf.VMAuth.Password
+	}
+	// the guests installed from host path ISO have no image
+	if obj.VendorData == "" && img != nil {
+		if fname := configs.Conf.CloudInit.GetVendorDataFile(img.Fullname()); fname != "" {
+			bs, err := os.ReadFile(fname)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to read vendor-data %s", fname)
+			}
+			obj.VendorData = string(bs)
+		}
+	}
 	if obj.Hostname == "" {
 		obj.Hostname = interutils.RandomString(10)
 	}
@@ -346,9 +455,20 @@ func LoadGuest(id string) (*Guest, error) {
 
 // CreateGuest .
 func CreateGuest(opts types.GuestCreateOption, host *Host, vols []volume.Volume) (*Guest, error) {
-	img, err := vmiFact.LoadImage(context.TODO(), opts.ImageName)
+	iso, err := parseISOInstall(opts.Labels)
 	if err != nil {
-		return nil, errors.Wrapf(err, "failed to load image %s", opts.ImageName)
+		return nil, err
+	}
+	// the image of hub describes the OS to install
+	if iso != nil && opts.ImageName == "" && !types.IsHostPathISO(iso.Source) {
+		opts.ImageName = iso.Source
+	}
+	// the OS is installed from the host path ISO without image
+	var img *vmitypes.Image
+	if iso == nil || opts.ImageName != "" {
+		if img, err = vmiFact.LoadImage(context.TODO(), opts.ImageName); err != nil {
+			return nil, errors.Wrapf(err, "failed to load image %s", opts.ImageName)
+		}
 	}
 
 	var guest = newGuest()
@@ -361,11 +481,28 @@ func CreateGuest(opts types.GuestCreateOption, host *Host, vols []volume.Volume)
 	}
 	guest.MTU = 1500
 
-	guest.Img = img
-	guest.ImageName = img.Fullname()
+	var virtualSize int64
+	if img != nil {
+		guest.Img = img
+		guest.ImageName = img.Fullname()
+		virtualSize = img.VirtualSize
+	}
 	// Create sys volume when user doesn't specify one
 	if len(vols) == 0 || (!vols[0].IsSys()) {
-		sysVol := local.NewSysVolume(img.VirtualSize, img.Fullname())
+		sysVol := local.NewSysVolume(virtualSize, guest.ImageName)
+		if iso != nil {
+			sysVol.SizeInBytes = iso.SysDiskSize
+		}
+		if flavor := opts.Flavor; flavor != nil {
+			sysVol.SizeInBytes = max(sysVol.SizeInBytes, flavor.SysDiskSize)
+			if qos := flavor.VolumeQoS; qos != nil {
+				sysVol.ReadIOPS, sysVol.WriteIOPS = qos.ReadIOPS, qos.WriteIOPS
+				sysVol.ReadBPS, sysVol.WriteBPS = qos.ReadBPS, qos.WriteBPS
+			}
+		}
+		if iso != nil && sysVol.SizeInBytes <= 0 {
+			return nil, errors.Wrapf(terrors.ErrInvalidValue, "the size of sys disk is required to install from ISO")
+		}
 		if err := guest.AppendVols(sysVol); err != nil {
 			return nil, errors.WithMessagef(err, "Create: failed to append volume %s", sysVol)
 		}
@@ -376,6 +513,9 @@ func CreateGuest(opts types.GuestCreateOption, host *Host, vols []volume.Volume)
 	guest.Memory = opts.Mem
 	guest.DmiUUID = opts.DmiUUID
 	guest.JSONLabels = opts.Labels
+	if iso != nil {
+		guest.BootOrder = iso.GetBootOrder()
+	}
 
 	if opts.Lambda {
 		guest.LambdaOption = &LambdaOptions{
@@ -392,6 +532,19 @@ func CreateGuest(opts types.GuestCreateOption, host *Host, vols []volume.Volume)
 		guest.GPUEngineParams = &eParams
 	}
 
+	// eru core has allocated the dedicated pCPUs
+	if bs, ok := opts.Resources[types.PluginNameCPUMem]; ok {
+		var eParams cpumemtypes.EngineParams
+		if err := json.Unmarshal(bs, &eParams); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal cpumem params")
+		}
+		if len(eParams.CPUMap) > 0 {
+			if guest.CPUPinning, err = resources.GetManager().CPUPinningFromCPUMap(eParams.CPUMap); err != nil {
+				return nil, errors.Wrapf(err, "invalid cpu map")
+			}
+		}
+	}
+
 	if bs, ok := opts.Resources["bandwidth"]; ok {
 		var eParams bdtypes.EngineParams
 		if err := json.Unmarshal(bs, &eParams); err != nil {
@@ -402,12 +555,24 @@ func CreateGuest(opts types.GuestCreateOption, host *Host, vols []volume.Volume)
 	if err := guest.AppendVols(vols...); err != nil {
 		return nil, errors.WithMessagef(err, "CreateGuest: failed to append volumes %v", vols)
 	}
+	if err := guest.joinThrottleGroups(); err != nil {
+		return nil, errors.WithMessagef(err, "CreateGuest: failed to join throttle groups")
+	}
 
 	if err := guest.Check(); err != nil {
 		return nil, errors.WithMessagef(err, "CreateGuest: failed to check guest %v", guest)
 	}
 
+	// the pinning requested by label is allocated by defining the domain,
+	// the hugepages are checked on its nodes then.
+	if _, ok, _ := guest.CPUPinningRequest(); !ok || guest.CPUPinning != nil {
+		if err := guest.CheckHugepages(); err != nil {
+			return nil, errors.WithMessagef(err, "CreateGuest: no enough hugepages")
+		}
+	}
+
 	if err := guest.Create(); err != nil {
+		resources.GetManager().ReleaseHugepages(guest.ID)
 		return nil, err
 	}
 
@@ -70,6 +70,16 @@ func (g *Guest) ForwardMigrating() error {
 	return g.ForwardStatus(meta.StatusMigrating, false)
 }
 
+// ForwardHibernating .
+func (g *Guest) ForwardHibernating() error {
+	return g.ForwardStatus(meta.StatusHibernating, false)
+}
+
+// ForwardHibernated .
+func (g *Guest) ForwardHibernated() error {
+	return g.ForwardStatus(meta.StatusHibernated, false)
+}
+
 // ForwardStatus .
 func (g *Guest) ForwardStatus(st string, force bool) error {
 	if err := g.SetStatus(st, force); err != nil {
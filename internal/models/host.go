@@ -1,11 +1,17 @@
 package models
 
 import (
+	"context"
 	"fmt"
 
+	clientv3 "go.etcd.io/etcd/client/v3"
+
+	"github.com/cockroachdb/errors"
 	"github.com/projecteru2/yavirt/configs"
 	"github.com/projecteru2/yavirt/internal/eru/resources"
 	"github.com/projecteru2/yavirt/internal/meta"
+	"github.com/projecteru2/yavirt/pkg/store"
+	"github.com/projecteru2/yavirt/pkg/terrors"
 )
 
 // Host .
@@ -76,6 +82,24 @@ func (h *Host) String() string {
 		h.ID, h.Name, h.Subnet, h.CPU, h.Memory, h.Storage)
 }
 
+// DeleteHost removes the CPU definition kept for the host,
+// so a removed host doesn't stay in the baseline pool.
+func DeleteHost(hostName string) error {
+	ctx, cancel := meta.Context(context.Background())
+	defer cancel()
+
+	ops := []clientv3.Op{
+		clientv3.OpDelete(meta.HostCPUKey(hostName)),
+	}
+	switch succ, err := store.BatchOperate(ctx, ops); {
+	case err != nil:
+		return errors.Wrap(err, "failed to batch operate")
+	case !succ:
+		return errors.Wrapf(terrors.ErrBatchOperate, "delete host %s", hostName)
+	}
+	return nil
+}
+
 type hostGuest struct {
 	*meta.Ver
 	HostName string `json:"-"`
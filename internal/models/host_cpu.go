@@ -0,0 +1,58 @@
+package models
+
+import (
+	"context"
+	"strings"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/yavirt/internal/meta"
+	"github.com/projecteru2/yavirt/pkg/store"
+	"github.com/projecteru2/yavirt/pkg/terrors"
+)
+
+// SaveHostCPU saves the CPU definition of the host,
+// so the baseline CPU of all hosts can be computed.
+// etcd keys:
+//
+//	/hostcpus/<host name>
+func SaveHostCPU(hostName, cpuXML string) error {
+	ctx, cancel := meta.Context(context.Background())
+	defer cancel()
+
+	ops := []clientv3.Op{
+		clientv3.OpPut(meta.HostCPUKey(hostName), cpuXML),
+	}
+	switch succ, err := store.BatchOperate(ctx, ops); {
+	case err != nil:
+		return errors.Wrap(err, "failed to batch operate")
+	case !succ:
+		return errors.Wrapf(terrors.ErrBatchOperate, "put: %s", meta.HostCPUKey(hostName))
+	}
+	return nil
+}
+
+// GetHostCPUs returns the CPU definitions of all hosts, keyed by host name.
+func GetHostCPUs() (map[string]string, error) {
+	ctx, cancel := meta.Context(context.Background())
+	defer cancel()
+
+	cpus := map[string]string{}
+	data, _, err := store.GetPrefix(ctx, meta.HostCPUsPrefix(), 0)
+	switch {
+	case terrors.IsKeyNotExistsErr(err):
+		return cpus, nil
+	case err != nil:
+		return nil, errors.Wrap(err, "failed to get prefix")
+	}
+
+	for key, val := range data {
+		name := key[strings.LastIndex(key, "/")+1:]
+		if len(name) < 1 {
+			continue
+		}
+		cpus[name] = string(val)
+	}
+	return cpus, nil
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"encoding/json"
+
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/yavirt/internal/types"
+)
+
+// ISOInstall returns the ISO to install the OS, it's nil if the guest
+// is created from an image.
+func (g *Guest) ISOInstall() (*types.ISOInstall, error) {
+	return parseISOInstall(g.JSONLabels)
+}
+
+func parseISOInstall(labels map[string]string) (*types.ISOInstall, error) {
+	bs, ok := labels[types.ISOLabelKey]
+	if !ok {
+		return nil, nil //nolint:nilnil
+	}
+	iso := &types.ISOInstall{}
+	if err := json.Unmarshal([]byte(bs), iso); err != nil {
+		return nil, errors.Wrapf(err, "invalid label %s", types.ISOLabelKey)
+	}
+	if err := iso.Check(); err != nil {
+		return nil, err
+	}
+	return iso, nil
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"context"
+
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/yavirt/internal/meta"
+	"github.com/projecteru2/yavirt/pkg/store"
+	"github.com/projecteru2/yavirt/pkg/terrors"
+	"github.com/projecteru2/yavirt/pkg/utils"
+)
+
+// SaveRunningGuests records the guests which are running at the shutdown of host,
+// they're started again by the next startup.
+// etcd keys:
+//
+//	/lifecycle/<host name>
+func SaveRunningGuests(hostName string, ids []string) error {
+	bs, err := utils.JSONEncode(ids)
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+
+	ctx, cancel := meta.Context(context.Background())
+	defer cancel()
+
+	// overwrites the previous one
+	data := map[string]string{meta.LifecycleKey(hostName): string(bs)}
+	return errors.Wrapf(store.Update(ctx, data, nil), "failed to save running guests of %s", hostName)
+}
+
+// PopRunningGuests returns the recorded guests and removes the record,
+// so they aren't started again by a later startup.
+func PopRunningGuests(hostName string) ([]string, error) {
+	ctx, cancel := meta.Context(context.Background())
+	defer cancel()
+
+	key := meta.LifecycleKey(hostName)
+	var ids []string
+	switch _, err := store.Get(ctx, key, &ids); {
+	case terrors.IsKeyNotExistsErr(err):
+		return nil, nil
+	case err != nil:
+		return nil, errors.Wrapf(err, "failed to load running guests of %s", hostName)
+	}
+	if err := store.Delete(ctx, []string{key}, nil); err != nil {
+		return nil, errors.Wrapf(err, "failed to delete running guests of %s", hostName)
+	}
+	return ids, nil
+}
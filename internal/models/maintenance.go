@@ -0,0 +1,75 @@
+package models
+
+import (
+	"context"
+
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/yavirt/internal/meta"
+	"github.com/projecteru2/yavirt/internal/types"
+	"github.com/projecteru2/yavirt/pkg/store"
+	"github.com/projecteru2/yavirt/pkg/terrors"
+	"github.com/projecteru2/yavirt/pkg/utils"
+)
+
+// CreateMaintenance puts the host into maintenance, it fails if the host is in maintenance.
+// etcd keys:
+//
+//	/maintenance/<host name>
+func CreateMaintenance(hostName string, m *types.Maintenance) error {
+	bs, err := utils.JSONEncode(m, "\t")
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+
+	ctx, cancel := meta.Context(context.Background())
+	defer cancel()
+
+	data := map[string]string{meta.MaintenanceKey(hostName): string(bs)}
+	switch err := store.Create(ctx, data); {
+	case errors.Is(err, terrors.ErrKeyExists):
+		return errors.Wrapf(types.ErrHostInMaintenance, "%s", hostName)
+	case err != nil:
+		return errors.Wrapf(err, "failed to create maintenance of %s", hostName)
+	}
+	return nil
+}
+
+// LoadMaintenance returns the maintenance of host with its version,
+// it's nil if the host isn't in maintenance.
+func LoadMaintenance(hostName string) (*types.Maintenance, int64, error) {
+	ctx, cancel := meta.Context(context.Background())
+	defer cancel()
+
+	m := &types.Maintenance{}
+	switch ver, err := store.Get(ctx, meta.MaintenanceKey(hostName), m); {
+	case terrors.IsKeyNotExistsErr(err):
+		return nil, 0, nil
+	case err != nil:
+		return nil, 0, errors.Wrapf(err, "failed to load maintenance of %s", hostName)
+	default:
+		return m, ver, nil
+	}
+}
+
+// UpdateMaintenance saves m if it's still the version ver.
+func UpdateMaintenance(hostName string, m *types.Maintenance, ver int64) error {
+	bs, err := utils.JSONEncode(m, "\t")
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+
+	ctx, cancel := meta.Context(context.Background())
+	defer cancel()
+
+	key := meta.MaintenanceKey(hostName)
+	data := map[string]string{key: string(bs)}
+	return errors.Wrapf(store.Update(ctx, data, map[string]int64{key: ver}), "failed to update maintenance of %s", hostName)
+}
+
+// DeleteMaintenance brings the host back from maintenance.
+func DeleteMaintenance(hostName string) error {
+	ctx, cancel := meta.Context(context.Background())
+	defer cancel()
+
+	return errors.Wrapf(store.Delete(ctx, []string{meta.MaintenanceKey(hostName)}, nil), "failed to delete maintenance of %s", hostName)
+}
@@ -0,0 +1,199 @@
+package models
+
+import (
+	"context"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/yavirt/internal/meta"
+	"github.com/projecteru2/yavirt/internal/types"
+	"github.com/projecteru2/yavirt/internal/volume"
+	"github.com/projecteru2/yavirt/internal/volume/local"
+	"github.com/projecteru2/yavirt/pkg/store"
+	"github.com/projecteru2/yavirt/pkg/terrors"
+	"github.com/projecteru2/yavirt/pkg/utils"
+)
+
+// CreateThrottleGroup saves a new throttle group, it fails if the name exists.
+// etcd keys:
+//
+//	/throttle-groups/<name>
+func CreateThrottleGroup(group *types.ThrottleGroup) error {
+	if err := group.Check(); err != nil {
+		return err
+	}
+	bs, err := utils.JSONEncode(group, "\t")
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+
+	ctx, cancel := meta.Context(context.Background())
+	defer cancel()
+
+	data := map[string]string{meta.ThrottleGroupKey(group.Name): string(bs)}
+	if err := store.Create(ctx, data); err != nil {
+		return errors.Wrapf(err, "failed to create throttle group %s", group.Name)
+	}
+	return nil
+}
+
+// LoadThrottleGroup .
+func LoadThrottleGroup(name string) (*types.ThrottleGroup, error) {
+	ctx, cancel := meta.Context(context.Background())
+	defer cancel()
+
+	group := &types.ThrottleGroup{}
+	if _, err := store.Get(ctx, meta.ThrottleGroupKey(name), group); err != nil {
+		return nil, errors.Wrapf(err, "failed to load throttle group %s", name)
+	}
+	return group, nil
+}
+
+// ListThrottleGroups returns all throttle groups sorted by name.
+func ListThrottleGroups() ([]*types.ThrottleGroup, error) {
+	ctx, cancel := meta.Context(context.Background())
+	defer cancel()
+
+	data, _, err := store.GetPrefix(ctx, meta.ThrottleGroupsPrefix(), 0)
+	switch {
+	case terrors.IsKeyNotExistsErr(err):
+		return nil, nil
+	case err != nil:
+		return nil, errors.Wrap(err, "failed to get prefix")
+	}
+
+	groups := make([]*types.ThrottleGroup, 0, len(data))
+	for key, val := range data {
+		group := &types.ThrottleGroup{}
+		if err := utils.JSONDecode(val, group); err != nil {
+			return nil, errors.Wrapf(err, "invalid throttle group %s", key)
+		}
+		groups = append(groups, group)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].Name < groups[j].Name
+	})
+	return groups, nil
+}
+
+// UpdateThrottleGroup changes the limits of an existing throttle group.
+func UpdateThrottleGroup(group *types.ThrottleGroup) error {
+	if err := group.Check(); err != nil {
+		return err
+	}
+	if _, err := LoadThrottleGroup(group.Name); err != nil {
+		return err
+	}
+	bs, err := utils.JSONEncode(group, "\t")
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+
+	ctx, cancel := meta.Context(context.Background())
+	defer cancel()
+
+	data := map[string]string{meta.ThrottleGroupKey(group.Name): string(bs)}
+	return errors.Wrapf(store.Update(ctx, data, nil), "failed to update throttle group %s", group.Name)
+}
+
+// DeleteThrottleGroup deletes the group, it's refused while any volume is in it.
+func DeleteThrottleGroup(name string) error {
+	if _, err := LoadThrottleGroup(name); err != nil {
+		return err
+	}
+	ids, err := throttleGroupVolumeIDs(name)
+	if err != nil {
+		return err
+	}
+	if len(ids) > 0 {
+		return errors.Wrapf(terrors.ErrInvalidValue, "throttle group %s is used by volumes %s", name, strings.Join(ids, ", "))
+	}
+
+	ctx, cancel := meta.Context(context.Background())
+	defer cancel()
+
+	return errors.Wrapf(store.Delete(ctx, []string{meta.ThrottleGroupKey(name)}, nil), "failed to delete throttle group %s", name)
+}
+
+// throttleGroupVolumeIDs returns the IDs of the volumes of all hosts in the group.
+func throttleGroupVolumeIDs(name string) ([]string, error) {
+	ctx, cancel := meta.Context(context.Background())
+	defer cancel()
+
+	data, _, err := store.GetPrefix(ctx, meta.VolumesPrefix(), 0)
+	switch {
+	case terrors.IsKeyNotExistsErr(err):
+		return nil, nil
+	case err != nil:
+		return nil, errors.Wrap(err, "failed to get prefix")
+	}
+
+	var ids []string
+	for key, val := range data {
+		vol := struct {
+			ThrottleGroup string `json:"throttle_group"`
+		}{}
+		// only the local volumes have the throttle group
+		if err := utils.JSONDecode(val, &vol); err != nil || vol.ThrottleGroup != name {
+			continue
+		}
+		ids = append(ids, path.Base(key))
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// JoinLabeledThrottleGroup puts the local volume into the throttle group
+// which is given to its mount dir by the label, unless it's in a group already.
+func (g *Guest) JoinLabeledThrottleGroup(vol volume.Volume) error {
+	lv, ok := vol.(*local.Volume)
+	if !ok || lv.ThrottleGroup != "" {
+		return nil
+	}
+	groups, err := types.ParseThrottleGroups(g.JSONLabels)
+	if err != nil {
+		return err
+	}
+	name, ok := groups[lv.GetMountDir()]
+	if !ok {
+		return nil
+	}
+	group, err := LoadThrottleGroup(name)
+	if err != nil {
+		return err
+	}
+	lv.JoinThrottleGroup(group)
+	return nil
+}
+
+// joinThrottleGroups puts the volumes into the throttle groups of label.
+func (g *Guest) joinThrottleGroups() error {
+	groups, err := types.ParseThrottleGroups(g.JSONLabels)
+	if err != nil {
+		return err
+	}
+	for mnt, name := range groups {
+		vol, ok := g.localVolume(mnt)
+		if !ok {
+			return errors.Wrapf(terrors.ErrInvalidValue, "no local volume %s for throttle group %s", mnt, name)
+		}
+		group, err := LoadThrottleGroup(name)
+		if err != nil {
+			return err
+		}
+		vol.JoinThrottleGroup(group)
+	}
+	return nil
+}
+
+func (g *Guest) localVolume(mnt string) (*local.Volume, bool) {
+	for _, vol := range g.Vols {
+		if vol.GetMountDir() == mnt {
+			lv, ok := vol.(*local.Volume)
+			return lv, ok
+		}
+	}
+	return nil, false
+}
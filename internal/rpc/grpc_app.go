@@ -5,11 +5,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"strconv"
 	"sync"
 
 	pb "github.com/projecteru2/libyavirt/grpc/gen"
 	"github.com/projecteru2/libyavirt/types"
 	"github.com/samber/lo"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 
 	"github.com/cockroachdb/errors"
 	"github.com/projecteru2/core/log"
@@ -17,6 +20,7 @@ import (
 	"github.com/projecteru2/yavirt/internal/service"
 	intertypes "github.com/projecteru2/yavirt/internal/types"
 	"github.com/projecteru2/yavirt/internal/utils"
+	"github.com/projecteru2/yavirt/pkg/terrors"
 	vmiFact "github.com/projecteru2/yavirt/pkg/vmimage/factory"
 )
 
@@ -91,17 +95,39 @@ func (y *GRPCYavirtd) GetGuestIDList(ctx context.Context, _ *pb.GetGuestIDListOp
 }
 
 // Events
-func (y *GRPCYavirtd) Events(_ *pb.EventsOptions, server pb.YavirtdRPC_EventsServer) error {
+// Events streams the events of guests, the filters are:
+//
+//	since:  replays the events in the journal after the seq before the live ones.
+//	id:     only the events of the guest.
+//	format: "json" carries the whole event in Type as JSON, which has the seq
+//	        to resume from, the status, the error and the detail.
+//
+// EventMessage can't tell failures otherwise, so the action of a failed
+// operation is suffixed with "-failed", e.g. "start-failed".
+func (y *GRPCYavirtd) Events(opts *pb.EventsOptions, server pb.YavirtdRPC_EventsServer) error {
 	ctx := server.Context()
 
 	log.Info(ctx, "[grpcserver] events method calling")
 	defer log.Info(ctx, "[grpcserver] events method completed")
 
+	filter, err := newEventFilter(opts.Filters)
+	if err != nil {
+		return err
+	}
+
+	// watch before replaying, so no event is missed between them
 	watcher, err := y.service.WatchGuestEvents(ctx)
 	if err != nil {
 		return err
 	}
 
+	if filter.replay {
+		if err := y.replayEvents(ctx, server, watcher, filter); err != nil {
+			watcher.Stop()
+			return err
+		}
+	}
+
 	var wg sync.WaitGroup
 	defer wg.Wait()
 
@@ -114,7 +140,7 @@ func (y *GRPCYavirtd) Events(_ *pb.EventsOptions, server pb.YavirtdRPC_EventsSer
 		for {
 			select {
 			case event := <-watcher.Events():
-				if err := server.Send(parseEvent(event)); err != nil {
+				if err := filter.send(server, event); err != nil {
 					log.Error(ctx, err)
 					return
 				}
@@ -134,13 +160,114 @@ func (y *GRPCYavirtd) Events(_ *pb.EventsOptions, server pb.YavirtdRPC_EventsSer
 	return nil
 }
 
-func parseEvent(event intertypes.Event) *pb.EventMessage {
-	return &pb.EventMessage{
+// replayEvents sends the events in the journal, the live events are buffered
+// meanwhile, so the watchers aren't blocked by sending over the network.
+func (y *GRPCYavirtd) replayEvents(ctx context.Context, server pb.YavirtdRPC_EventsServer, watcher *utils.Watcher, filter *eventFilter) error {
+	var live []intertypes.Event
+	stop, drained := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(drained)
+		for {
+			select {
+			case event := <-watcher.Events():
+				live = append(live, event)
+			case <-stop:
+				return
+			case <-watcher.Done():
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	events, err := y.service.ListEvents(ctx, filter.since, 0)
+	if err == nil {
+		for _, event := range events {
+			if err = filter.send(server, event); err != nil {
+				break
+			}
+		}
+	}
+	close(stop)
+	<-drained
+	if err != nil {
+		return err
+	}
+
+	for _, event := range live {
+		if err := filter.send(server, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type eventFilter struct {
+	id     string
+	json   bool
+	replay bool
+	since  uint64
+}
+
+func newEventFilter(filters map[string]string) (*eventFilter, error) {
+	f := &eventFilter{}
+	if id := filters["id"]; id != "" {
+		f.id = types.GuestReq{ID: id}.VirtID()
+	}
+	switch format := filters["format"]; format {
+	case "":
+	case "json":
+		f.json = true
+	default:
+		return nil, errors.Wrapf(terrors.ErrInvalidValue, "invalid format %s", format)
+	}
+	if since, ok := filters["since"]; ok {
+		seq, err := strconv.ParseUint(since, 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(terrors.ErrInvalidValue, "invalid since %s", since)
+		}
+		f.replay, f.since = true, seq
+	}
+	return f, nil
+}
+
+// send skips the events which have been replayed.
+func (f *eventFilter) send(server pb.YavirtdRPC_EventsServer, event intertypes.Event) error {
+	if f.id != "" && event.ID != f.id {
+		return nil
+	}
+	if event.Seq > 0 {
+		if f.replay && event.Seq <= f.since {
+			return nil
+		}
+		f.since = event.Seq
+	}
+	msg, err := parseEvent(event, f.json)
+	if err != nil {
+		return err
+	}
+	return server.Send(msg)
+}
+
+func parseEvent(event intertypes.Event, asJSON bool) (*pb.EventMessage, error) {
+	msg := &pb.EventMessage{
 		Id:       types.EruID(event.ID),
 		Type:     event.Type,
 		Action:   string(event.Op),
 		TimeNano: event.Time.UnixNano(),
 	}
+	if event.Failed() {
+		msg.Action += "-failed"
+	}
+	if asJSON {
+		bs, err := json.Marshal(event)
+		if err != nil {
+			return nil, errors.Wrap(err, "")
+		}
+		msg.Type = string(bs)
+	}
+	return msg, nil
 }
 
 // GetGuestUUID .
@@ -240,10 +367,35 @@ func (y *GRPCYavirtd) AttachGuest(server pb.YavirtdRPC_AttachGuestServer) (err e
 		ID:     opts.Id,
 		server: server,
 	}
-	flags := intertypes.NewOpenConsoleFlags(opts.Force, opts.Safe, opts.Commands)
+	flags, err := consoleFlags(ctx, opts)
+	if err != nil {
+		return err
+	}
 	return y.service.AttachGuest(ctx, utils.VirtID(opts.Id), serverStream, flags)
 }
 
+// consoleFlags takes the claimed user and the read-only flag from the metadata, as the
+// options have no fields for them. The user is always the peer address, since
+// the claimed one can't be verified.
+func consoleFlags(ctx context.Context, opts *pb.AttachGuestOptions) (intertypes.OpenConsoleFlags, error) {
+	flags := intertypes.NewOpenConsoleFlags(opts.Force, opts.Safe, opts.Commands)
+	if p, ok := peer.FromContext(ctx); ok {
+		flags.User = p.Addr.String()
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	if vals := md.Get(ConsoleUserMetadataKey); len(vals) > 0 {
+		flags.ClaimedUser = vals[0]
+	}
+	if vals := md.Get(ConsoleReadOnlyMetadataKey); len(vals) > 0 {
+		readOnly, err := strconv.ParseBool(vals[0])
+		if err != nil {
+			return flags, errors.Wrapf(terrors.ErrInvalidValue, "invalid metadata %s: %s", ConsoleReadOnlyMetadataKey, vals[0])
+		}
+		flags.ReadOnly = readOnly
+	}
+	return flags, nil
+}
+
 // ResizeConsoleWindow .
 func (y *GRPCYavirtd) ResizeConsoleWindow(ctx context.Context, opts *pb.ResizeWindowOptions) (*pb.Empty, error) {
 	req := types.GuestReq{ID: opts.Id}
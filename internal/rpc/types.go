@@ -2,6 +2,15 @@ package grpcserver
 
 import pb "github.com/projecteru2/libyavirt/grpc/gen"
 
+// The gRPC metadata of AttachGuest.
+const (
+	// ConsoleUserMetadataKey is who the client claims to be, it's recorded
+	// along with the peer address for the audit.
+	ConsoleUserMetadataKey = "yavirt-console-user"
+	// ConsoleReadOnlyMetadataKey attaches as a viewer if it's true.
+	ConsoleReadOnlyMetadataKey = "yavirt-console-read-only"
+)
+
 // ExecuteGuestServerStream .
 type ExecuteGuestServerStream struct {
 	ID     string
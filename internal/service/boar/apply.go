@@ -0,0 +1,342 @@
+package boar
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/core/log"
+	"github.com/projecteru2/libyavirt/types"
+	"github.com/projecteru2/yavirt/internal/models"
+	"github.com/projecteru2/yavirt/internal/network"
+	intertypes "github.com/projecteru2/yavirt/internal/types"
+	"github.com/projecteru2/yavirt/internal/virt/guest"
+	"github.com/projecteru2/yavirt/internal/volume/local"
+	"github.com/projecteru2/yavirt/pkg/idgen"
+	"github.com/projecteru2/yavirt/pkg/terrors"
+
+	stotypes "github.com/projecteru2/resource-storage/storage/types"
+)
+
+// ApplyGuest converges the guest to the spec by running only the needed operations,
+// nothing is changed if dryRun is true.
+func (svc *Boar) ApplyGuest(ctx context.Context, spec *intertypes.GuestSpec, dryRun bool) (*intertypes.ApplyPlan, error) {
+	if err := resolveSpecFlavor(spec); err != nil {
+		return nil, err
+	}
+	// a new guest is created if the spec has no ID, the IDs are generated,
+	// so a missing guest can't be created with the ID.
+	var current *intertypes.GuestSpec
+	if spec.ID != "" {
+		g, err := svc.loadGuest(ctx, spec.ID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to load guest %s", spec.ID)
+		}
+		current = guestSpec(g)
+	}
+
+	plan, err := spec.Diff(current)
+	if err != nil {
+		return nil, err
+	}
+	if err := svc.checkEruPlan(plan); err != nil {
+		return nil, err
+	}
+	if dryRun {
+		return plan, nil
+	}
+
+	if current == nil {
+		id, err := svc.createBySpec(ctx, spec)
+		plan.ID = id
+		return plan, err
+	}
+	for _, action := range plan.Actions {
+		log.WithFunc("boar.ApplyGuest").Infof(ctx, "apply %s to %s", action, plan.ID)
+		if err := svc.applyAction(ctx, plan.ID, spec, action); err != nil {
+			return plan, errors.Wrapf(err, "failed to %s", action)
+		}
+	}
+	return plan, nil
+}
+
+// resolveSpecFlavor takes the CPU and memory of spec from its flavor.
+func resolveSpecFlavor(spec *intertypes.GuestSpec) error {
+	if spec.Flavor == "" {
+		return nil
+	}
+	flavor, err := models.LoadFlavor(spec.Flavor)
+	if err != nil {
+		return err
+	}
+	mem, err := spec.MemoryInBytes()
+	if err != nil {
+		return err
+	}
+	if (spec.CPU != 0 && spec.CPU != flavor.CPU) || (mem != 0 && mem != flavor.Memory) {
+		return errors.Wrapf(terrors.ErrInvalidValue,
+			"the CPU %d and memory %s conflict with flavor %s", spec.CPU, spec.Memory, flavor.Name)
+	}
+	spec.CPU = flavor.CPU
+	spec.Memory = strconv.FormatInt(flavor.Memory, 10)
+	return nil
+}
+
+// guestSpec describes the current state of guest.
+func guestSpec(g *guest.Guest) *intertypes.GuestSpec {
+	spec := &intertypes.GuestSpec{
+		ID:      g.ID,
+		Image:   g.ImageName,
+		Flavor:  g.JSONLabels[intertypes.FlavorLabelKey],
+		CPU:     g.CPU,
+		Memory:  strconv.FormatInt(g.Memory, 10),
+		Network: g.NetworkMode,
+		Labels:  g.JSONLabels,
+	}
+	for _, netw := range g.ExtraNetworks {
+		spec.ExtraNetworks = append(spec.ExtraNetworks, intertypes.NetworkSpec{Name: netw.Name})
+	}
+	for _, vol := range g.Vols {
+		if vol.IsSys() {
+			continue
+		}
+		volSpec := intertypes.VolumeSpec{
+			Mount: vol.GetMountDir(),
+			Size:  strconv.FormatInt(vol.GetSize(), 10),
+		}
+		if lv, ok := vol.(*local.Volume); ok {
+			volSpec.ThrottleGroup = lv.ThrottleGroup
+		}
+		spec.Volumes = append(spec.Volumes, volSpec)
+	}
+	if bs, ok := g.JSONLabels["instance/cloud-init"]; ok {
+		ciSpec := &intertypes.CloudInitSpec{}
+		if err := json.Unmarshal([]byte(bs), ciSpec); err == nil {
+			spec.CloudInit = ciSpec
+		}
+	}
+	return spec
+}
+
+func (svc *Boar) createBySpec(ctx context.Context, spec *intertypes.GuestSpec) (string, error) {
+	mem, err := spec.MemoryInBytes()
+	if err != nil {
+		return "", err
+	}
+	opts := intertypes.GuestCreateOption{
+		CPU:       spec.CPU,
+		Mem:       mem,
+		ImageName: spec.Image,
+		ImageUser: spec.ImageUser,
+		Labels:    map[string]string{},
+		Resources: map[string][]byte{},
+	}
+	for key, val := range spec.Labels {
+		opts.Labels[key] = val
+	}
+	if spec.Network != "" {
+		opts.Labels[network.ModeLabelKey] = spec.Network
+	}
+	if spec.Flavor != "" {
+		opts.Labels[intertypes.FlavorLabelKey] = spec.Flavor
+	}
+	if spec.CloudInit != nil {
+		bs, err := json.Marshal(spec.CloudInit.Config())
+		if err != nil {
+			return "", errors.Wrap(err, "")
+		}
+		opts.Labels["instance/cloud-init"] = string(bs)
+	}
+	if err := specThrottleGroups(spec, opts.Labels); err != nil {
+		return "", err
+	}
+	if len(spec.Volumes) > 0 {
+		eParams := stotypes.EngineParams{}
+		for i := range spec.Volumes {
+			eParams.Volumes = append(eParams.Volumes, spec.Volumes[i].Binding())
+		}
+		bs, err := json.Marshal(eParams)
+		if err != nil {
+			return "", errors.Wrap(err, "")
+		}
+		opts.Resources[intertypes.PluginNameStorage] = bs
+	}
+
+	g, err := svc.CreateGuest(ctx, opts)
+	if err != nil {
+		return "", err
+	}
+	for _, netw := range spec.ExtraNetworks {
+		if _, err := svc.ConnectNetwork(ctx, g.ID, netw.Name, netw.IPv4); err != nil {
+			return g.ID, errors.Wrapf(err, "failed to connect network %s", netw.Name)
+		}
+	}
+	return g.ID, nil
+}
+
+func (svc *Boar) applyAction(ctx context.Context, id string, spec *intertypes.GuestSpec, action intertypes.ApplyAction) error {
+	switch action.Op {
+	case intertypes.ApplyOpResize:
+		return svc.applyResize(ctx, id, spec)
+	case intertypes.ApplyOpDetachVolume:
+		return svc.ctrl(ctx, id, intertypes.ResizeOp, func(g *guest.Guest) error {
+			return g.DetachVolume(action.Target)
+		}, nil)
+	case intertypes.ApplyOpAttachVolume, intertypes.ApplyOpAmplifyVolume:
+		volSpec := specVolume(spec, action.Target)
+		if volSpec == nil {
+			return errors.Wrapf(terrors.ErrInvalidValue, "no volume %s in spec", action.Target)
+		}
+		return svc.ctrl(ctx, id, intertypes.ResizeOp, func(g *guest.Guest) error {
+			if action.Op == intertypes.ApplyOpAmplifyVolume {
+				size, err := volSpec.SizeInBytes()
+				if err != nil {
+					return err
+				}
+				return g.AmplifyVolume(action.Target, size)
+			}
+			vol, err := local.NewVolumeFromStr(volSpec.Binding())
+			if err != nil {
+				return errors.Wrap(err, "")
+			}
+			if volSpec.ThrottleGroup != "" {
+				group, err := models.LoadThrottleGroup(volSpec.ThrottleGroup)
+				if err != nil {
+					return err
+				}
+				vol.JoinThrottleGroup(group)
+			}
+			return g.AttachVolume(vol)
+		}, nil)
+	case intertypes.ApplyOpDisconnectNetwork:
+		return svc.DisconnectNetwork(ctx, id, action.Target)
+	case intertypes.ApplyOpConnectNetwork:
+		_, err := svc.ConnectNetwork(ctx, id, action.Target, action.Detail)
+		return err
+	case intertypes.ApplyOpUpdateLabels:
+		return svc.ctrl(ctx, id, intertypes.MiscOp, func(g *guest.Guest) error {
+			g.JSONLabels = spec.MergeLabels(g.JSONLabels)
+			return g.Save()
+		}, nil)
+	default:
+		return errors.Errorf("invalid action %s", action.Op)
+	}
+}
+
+func (svc *Boar) applyResize(ctx context.Context, id string, spec *intertypes.GuestSpec) error {
+	if spec.Flavor != "" {
+		return svc.resizeFlavor(ctx, id, spec.Flavor)
+	}
+	mem, err := spec.MemoryInBytes()
+	if err != nil {
+		return err
+	}
+	if svc.cfg.Eru.Enable && idgen.CheckID(id) {
+		g, err := svc.loadGuest(ctx, id)
+		if err != nil {
+			return err
+		}
+		// eru core resizes the guest by calling ResizeGuest back,
+		// so it's reallocated without the guest locked.
+		if err := reallocSpec(ctx, g, spec.CPU, mem); err != nil {
+			return errors.Wrapf(err, "failed to realloc the resources of guest %s", id)
+		}
+	}
+	return svc.ctrl(ctx, id, intertypes.ResizeOp, func(g *guest.Guest) error {
+		cpu := spec.CPU
+		if cpu == 0 {
+			cpu = g.CPU
+		}
+		if mem == 0 {
+			mem = g.Memory
+		}
+		return g.ResizeSpec(cpu, mem)
+	}, nil)
+}
+
+// reallocSpec requests the deltas of CPU and memory from eru core,
+// zero means unchanged.
+func reallocSpec(ctx context.Context, g *guest.Guest, cpu int, mem int64) error {
+	if cpu == 0 {
+		cpu = g.CPU
+	}
+	if mem == 0 {
+		mem = g.Memory
+	}
+	if cpu == g.CPU && mem == g.Memory {
+		return nil
+	}
+	return reallocCPUMem(ctx, g.ID, cpu-g.CPU, mem-g.Memory)
+}
+
+// checkEruPlan rejects the volume actions of the guests managed by eru,
+// their volumes are allocated by eru core, so they must be changed
+// through eru.
+func (svc *Boar) checkEruPlan(plan *intertypes.ApplyPlan) error {
+	if !svc.cfg.Eru.Enable || !idgen.CheckID(plan.ID) {
+		return nil
+	}
+	for _, action := range plan.Actions {
+		switch action.Op {
+		case intertypes.ApplyOpAttachVolume, intertypes.ApplyOpAmplifyVolume, intertypes.ApplyOpDetachVolume:
+			return errors.Wrapf(terrors.ErrInvalidValue,
+				"can't %s of guest %s managed by eru, resize it through eru instead", action, plan.ID)
+		}
+	}
+	return nil
+}
+
+// specThrottleGroups adds the throttle groups of volumes to the labels.
+func specThrottleGroups(spec *intertypes.GuestSpec, labels map[string]string) error {
+	groups, err := intertypes.ParseThrottleGroups(labels)
+	if err != nil {
+		return err
+	}
+	for _, vol := range spec.Volumes {
+		if vol.ThrottleGroup == "" {
+			continue
+		}
+		if groups == nil {
+			groups = map[string]string{}
+		}
+		groups[vol.Mount] = vol.ThrottleGroup
+	}
+	if len(groups) == 0 {
+		return nil
+	}
+	bs, err := json.Marshal(groups)
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+	labels[intertypes.ThrottleGroupsLabelKey] = string(bs)
+	return nil
+}
+
+func specVolume(spec *intertypes.GuestSpec, mountDir string) *intertypes.VolumeSpec {
+	for i := range spec.Volumes {
+		if spec.Volumes[i].Mount == mountDir {
+			return &spec.Volumes[i]
+		}
+	}
+	return nil
+}
+
+func (svc *Boar) rawApplyGuest(ctx context.Context, id string, params []byte) (types.RawEngineResp, error) {
+	args := &struct {
+		Spec   intertypes.GuestSpec `json:"spec"`
+		DryRun bool                 `json:"dry_run"`
+	}{}
+	if err := json.Unmarshal(params, args); err != nil {
+		return types.RawEngineResp{}, errors.Wrapf(err, "failed to unmarshal params")
+	}
+	if id != "" {
+		args.Spec.ID = id
+	}
+	plan, err := svc.ApplyGuest(ctx, &args.Spec, args.DryRun)
+	if err != nil {
+		return types.RawEngineResp{}, err
+	}
+	bs, _ := json.Marshal(plan)
+	return types.RawEngineResp{Data: bs}, nil
+}
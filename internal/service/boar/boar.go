@@ -15,6 +15,7 @@ import (
 	"github.com/cockroachdb/errors"
 	"github.com/projecteru2/core/log"
 	"github.com/projecteru2/yavirt/configs"
+	"github.com/projecteru2/yavirt/internal/console"
 	"github.com/projecteru2/yavirt/internal/eru/agent"
 	"github.com/projecteru2/yavirt/internal/eru/recycle"
 	"github.com/projecteru2/yavirt/internal/eru/resources"
@@ -25,11 +26,13 @@ import (
 	intertypes "github.com/projecteru2/yavirt/internal/types"
 	interutils "github.com/projecteru2/yavirt/internal/utils"
 	"github.com/projecteru2/yavirt/internal/ver"
+	"github.com/projecteru2/yavirt/internal/virt/balloon"
 	"github.com/projecteru2/yavirt/internal/virt/guest"
 	"github.com/projecteru2/yavirt/internal/vmcache"
 	"github.com/projecteru2/yavirt/pkg/idgen"
 	"github.com/projecteru2/yavirt/pkg/notify/bison"
 	"github.com/projecteru2/yavirt/pkg/store"
+	"github.com/projecteru2/yavirt/pkg/terrors"
 	"github.com/projecteru2/yavirt/pkg/utils"
 	vmiFact "github.com/projecteru2/yavirt/pkg/vmimage/factory"
 	vmitypes "github.com/projecteru2/yavirt/pkg/vmimage/types"
@@ -46,10 +49,13 @@ type Boar struct {
 	RecoverGuestCh chan<- string
 
 	watchers *interutils.Watchers
+	journal  *models.EventJournal
 
 	imageMutex sync.Mutex
 	agt        *agent.Manager
 	mCol       *MetricsCollector
+	consoles   *console.Hub
+	dumps      *dumpTracker
 }
 
 func New(ctx context.Context, cfg *configs.Config, t *testing.T) (br *Boar, err error) {
@@ -60,6 +66,8 @@ func New(ctx context.Context, cfg *configs.Config, t *testing.T) (br *Boar, err
 		mCol:         &MetricsCollector{},
 		pid2ExitCode: utils.NewSyncMap(),
 		watchers:     interutils.NewWatchers(),
+		dumps:        newDumpTracker(),
+		consoles:     console.NewHub(cfg.Console.RecordDir, cfg.Console.RecordRetention),
 	}
 	// setup notify
 	if err := bison.Setup(&cfg.Notify, t); err != nil {
@@ -91,6 +99,10 @@ func New(ctx context.Context, cfg *configs.Config, t *testing.T) (br *Boar, err
 	if err := vmcache.Setup(ctx, cfg, br.watchers); err != nil {
 		return br, errors.Wrap(err, "failed to setup vmcache")
 	}
+	if cfg.Resource.Balloon.Enable {
+		go balloon.Run(ctx, &cfg.Resource.Balloon)
+	}
+	go br.syncThrottleGroups(ctx, cfg.ThrottleGroupSyncInterval)
 	if err := vmiFact.Setup(&cfg.ImageHub); err != nil {
 		return br, errors.Wrap(err, "failed to setup vmimage")
 	}
@@ -241,6 +253,13 @@ func (svc *Boar) CaptureGuest(ctx context.Context, id string, imgName string, ov
 func (svc *Boar) ResizeGuest(ctx context.Context, id string, opts *intertypes.GuestResizeOption) (err error) {
 	defer logErr(err)
 
+	if opts.Flavor != "" {
+		if len(opts.Resources) > 0 {
+			return errors.Wrapf(terrors.ErrInvalidValue, "flavor %s can't be combined with resources", opts.Flavor)
+		}
+		return svc.resizeFlavor(ctx, id, opts.Flavor)
+	}
+
 	vols, err := extractVols(opts.Resources)
 	if err != nil {
 		return err
@@ -253,12 +272,28 @@ func (svc *Boar) ResizeGuest(ctx context.Context, id string, opts *intertypes.Gu
 	if err != nil {
 		return err
 	}
+	bd, err := extractBandwidth(opts.Resources)
+	if err != nil {
+		return err
+	}
 	g, err := svc.loadGuest(ctx, id)
 	if err != nil {
 		return err
 	}
+	if accepted, err := acceptReleasedCPUMem(g, cpumem); err != nil || accepted {
+		return err
+	}
 	do := func(_ context.Context) (any, error) {
-		return nil, g.Resize(cpumem, gpu, vols)
+		if err := g.Resize(cpumem, gpu, vols); err != nil {
+			return nil, err
+		}
+		if bd == nil {
+			return nil, nil
+		}
+		// keeps the bandwidth allocated by eru, the reallocation is
+		// calculated from it.
+		g.BDEngineParams = bd
+		return nil, g.Save()
 	}
 	_, err = svc.do(ctx, id, intertypes.ResizeOp, do, nil)
 	return
@@ -386,21 +421,33 @@ func (svc *Boar) do(ctx context.Context, id string, op intertypes.Operator, fn d
 	case <-ctx1.Done():
 		err = ctx1.Err()
 	}
-	if err != nil {
-		metrics.IncrError()
-		return
-	}
-
-	svc.watchers.Watched(intertypes.Event{
+	event := intertypes.Event{
 		ID:   id,
 		Type: guestEventType,
 		Op:   op,
 		Time: time.Now().UTC(),
-	})
+	}
+	if err != nil {
+		metrics.IncrError()
+		event.Error = err.Error()
+	} else {
+		event.Status = guestStatus(id)
+	}
+	svc.watchers.Watched(event)
 
 	return
 }
 
+// guestStatus returns the saved status of guest, it's empty
+// if the guest has gone, e.g. it's destroyed.
+func guestStatus(id string) string {
+	g, err := models.LoadGuest(id)
+	if err != nil {
+		return ""
+	}
+	return g.Status
+}
+
 const guestEventType = "guest"
 
 func checkLibvirtSocket() error {
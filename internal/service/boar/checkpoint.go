@@ -0,0 +1,115 @@
+package boar
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/libyavirt/types"
+	"github.com/projecteru2/yavirt/internal/meta"
+	"github.com/projecteru2/yavirt/internal/models"
+	intertypes "github.com/projecteru2/yavirt/internal/types"
+	"github.com/projecteru2/yavirt/internal/virt/guest"
+)
+
+// CreateCheckpoint captures the whole guest including the memory if it's running.
+func (svc *Boar) CreateCheckpoint(ctx context.Context, id, name, desc string) (cp *intertypes.Checkpoint, err error) {
+	err = svc.ctrl(ctx, id, intertypes.CreateCheckpointOp, func(g *guest.Guest) error {
+		cp, err = g.CreateCheckpoint(ctx, name, desc)
+		return err
+	}, nil)
+	return
+}
+
+// ListCheckpoints .
+func (svc *Boar) ListCheckpoints(ctx context.Context, id string) ([]*intertypes.Checkpoint, error) {
+	if _, err := svc.loadGuest(ctx, id); err != nil {
+		return nil, errors.Wrap(err, "")
+	}
+	return models.ListCheckpoints(id)
+}
+
+// RevertCheckpoint .
+func (svc *Boar) RevertCheckpoint(ctx context.Context, id, name string) error {
+	var running bool
+	if err := svc.ctrl(ctx, id, intertypes.RevertCheckpointOp, func(g *guest.Guest) error {
+		if err := g.RevertCheckpoint(ctx, name); err != nil {
+			return err
+		}
+		running = g.Status == meta.StatusRunning
+		return nil
+	}, nil); err != nil {
+		return err
+	}
+
+	// eru agent only tracks start and die events, the guest is a new qemu
+	// process if it's reverted to a running checkpoint, otherwise it's stopped.
+	op := intertypes.DieOp
+	if running {
+		op = intertypes.StartOp
+	}
+	svc.watchers.Watched(intertypes.Event{
+		ID:   id,
+		Type: guestEventType,
+		Op:   op,
+		Time: time.Now().UTC(),
+	})
+	return nil
+}
+
+// DeleteCheckpoint .
+func (svc *Boar) DeleteCheckpoint(ctx context.Context, id, name string) error {
+	return svc.ctrl(ctx, id, intertypes.DeleteCheckpointOp, func(g *guest.Guest) error {
+		return g.DeleteCheckpoint(ctx, name)
+	}, nil)
+}
+
+type checkpointArgs struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+func (svc *Boar) rawCreateCheckpoint(ctx context.Context, id string, params []byte) (types.RawEngineResp, error) {
+	args := &checkpointArgs{}
+	if err := json.Unmarshal(params, args); err != nil {
+		return types.RawEngineResp{}, errors.Wrapf(err, "failed to unmarshal params")
+	}
+	cp, err := svc.CreateCheckpoint(ctx, id, args.Name, args.Description)
+	if err != nil {
+		return types.RawEngineResp{}, err
+	}
+	bs, _ := json.Marshal(cp)
+	return types.RawEngineResp{Data: bs}, nil
+}
+
+func (svc *Boar) rawListCheckpoints(ctx context.Context, id string) (types.RawEngineResp, error) {
+	cps, err := svc.ListCheckpoints(ctx, id)
+	if err != nil {
+		return types.RawEngineResp{}, err
+	}
+	bs, _ := json.Marshal(cps)
+	return types.RawEngineResp{Data: bs}, nil
+}
+
+func (svc *Boar) rawRevertCheckpoint(ctx context.Context, id string, params []byte) (types.RawEngineResp, error) {
+	args := &checkpointArgs{}
+	if err := json.Unmarshal(params, args); err != nil {
+		return types.RawEngineResp{}, errors.Wrapf(err, "failed to unmarshal params")
+	}
+	if err := svc.RevertCheckpoint(ctx, id, args.Name); err != nil {
+		return types.RawEngineResp{}, err
+	}
+	return types.RawEngineResp{Data: []byte(`{"success":true}`)}, nil
+}
+
+func (svc *Boar) rawDeleteCheckpoint(ctx context.Context, id string, params []byte) (types.RawEngineResp, error) {
+	args := &checkpointArgs{}
+	if err := json.Unmarshal(params, args); err != nil {
+		return types.RawEngineResp{}, errors.Wrapf(err, "failed to unmarshal params")
+	}
+	if err := svc.DeleteCheckpoint(ctx, id, args.Name); err != nil {
+		return types.RawEngineResp{}, err
+	}
+	return types.RawEngineResp{Data: []byte(`{"success":true}`)}, nil
+}
@@ -2,11 +2,16 @@ package boar
 
 import (
 	"context"
+	"encoding/json"
 	"io"
 
 	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/libyavirt/types"
+	"github.com/projecteru2/yavirt/internal/console"
 	"github.com/projecteru2/yavirt/internal/meta"
 	intertypes "github.com/projecteru2/yavirt/internal/types"
+	"github.com/projecteru2/yavirt/internal/vmcache"
+	"github.com/projecteru2/yavirt/pkg/terrors"
 )
 
 // AttachGuest .
@@ -25,5 +30,98 @@ func (svc *Boar) AttachGuest(ctx context.Context, id string, stream io.ReadWrite
 		flags.Commands = g.LambdaOption.Cmd
 	}
 
-	return g.AttachConsole(ctx, stream, flags)
+	// the console is shared by the clients, it's opened with the flags of the first one,
+	// but the one running commands isn't shared.
+	return svc.consoles.Attach(ctx, id, stream, flags, func(ctx context.Context, upstream io.ReadWriteCloser) error {
+		return g.AttachConsole(ctx, upstream, flags)
+	})
+}
+
+// defaultConsoleLogKB is the size to fetch if it isn't specified.
+const defaultConsoleLogKB = 64
+
+// ConsoleLog returns the last kb KiB of the serial console log of guest,
+// it's available after the guest stopped or crashed.
+func (svc *Boar) ConsoleLog(ctx context.Context, id string, kb int) ([]byte, error) {
+	if _, err := svc.loadGuest(ctx, id); err != nil {
+		return nil, errors.Wrap(err, "")
+	}
+	if kb <= 0 {
+		kb = defaultConsoleLogKB
+	}
+	bs, err := console.ReadSerialLog(id, int64(kb)*1024)
+	return bs, errors.Wrapf(err, "failed to read console log of guest %s", id)
+}
+
+func (svc *Boar) rawConsoleLog(ctx context.Context, id string, params []byte) (types.RawEngineResp, error) {
+	args := &struct {
+		KB int `json:"kb"`
+	}{}
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, args); err != nil {
+			return types.RawEngineResp{}, errors.Wrapf(err, "failed to unmarshal params")
+		}
+	}
+	bs, err := svc.ConsoleLog(ctx, id, args.KB)
+	if err != nil {
+		return types.RawEngineResp{}, err
+	}
+	bs, _ = json.Marshal(map[string]string{"log": string(bs)})
+	return types.RawEngineResp{Data: bs}, nil
+}
+
+// Screenshot captures the display of the running guest in PNG.
+func (svc *Boar) Screenshot(ctx context.Context, id string) ([]byte, error) {
+	g, err := svc.loadGuest(ctx, id)
+	if err != nil {
+		return nil, errors.Wrap(err, "")
+	}
+	if g.Status != meta.StatusRunning {
+		return nil, errors.Wrapf(terrors.ErrInvalidValue, "guest %s isn't running", id)
+	}
+	return g.Screenshot(ctx)
+}
+
+func (svc *Boar) rawScreenshot(ctx context.Context, id string) (types.RawEngineResp, error) {
+	png, err := svc.Screenshot(ctx, id)
+	if err != nil {
+		return types.RawEngineResp{}, err
+	}
+	// the PNG is encoded in base64
+	bs, _ := json.Marshal(struct {
+		Mime string `json:"mime"`
+		Data []byte `json:"data"`
+	}{Mime: "image/png", Data: png})
+	return types.RawEngineResp{Data: bs}, nil
+}
+
+// CreateConsoleToken issues a short-lived token to open the browser console
+// of the running guest, kind is vnc or serial.
+func (svc *Boar) CreateConsoleToken(ctx context.Context, id string, opts intertypes.ConsoleTokenOptions) (*intertypes.ConsoleToken, error) {
+	g, err := svc.loadGuest(ctx, id)
+	if err != nil {
+		return nil, errors.Wrap(err, "")
+	}
+	if g.Status != meta.StatusRunning {
+		return nil, errors.Wrapf(terrors.ErrInvalidValue, "guest %s isn't running", id)
+	}
+	if opts.Kind == intertypes.ConsoleKindVNC {
+		if entry := vmcache.FetchDomainEntry(id); entry == nil || entry.VNCPort <= 0 {
+			return nil, errors.Wrapf(terrors.ErrInvalidValue, "guest %s has no VNC display", id)
+		}
+	}
+	return console.DefaultSigner().Issue(id, opts)
+}
+
+func (svc *Boar) rawCreateConsoleToken(ctx context.Context, id string, params []byte) (types.RawEngineResp, error) {
+	opts := intertypes.ConsoleTokenOptions{}
+	if err := json.Unmarshal(params, &opts); err != nil {
+		return types.RawEngineResp{}, errors.Wrapf(err, "failed to unmarshal params")
+	}
+	tok, err := svc.CreateConsoleToken(ctx, id, opts)
+	if err != nil {
+		return types.RawEngineResp{}, err
+	}
+	bs, _ := json.Marshal(tok)
+	return types.RawEngineResp{Data: bs}, nil
 }
@@ -35,6 +35,8 @@ func (svc *Boar) ControlGuest(ctx context.Context, id, operation string, force b
 		err = svc.suspendGuest(ctx, id)
 	case types.OpResume:
 		err = svc.resumeGuest(ctx, id)
+	case intertypes.HibernateOp.String():
+		err = svc.hibernateGuest(ctx, id)
 	}
 
 	if err != nil {
@@ -95,11 +97,22 @@ func (svc *Boar) stopGuest(ctx context.Context, id string, force bool) error {
 // startGuest boots a guest.
 func (svc *Boar) startGuest(ctx context.Context, id string, force bool) error {
 	logger := log.WithFunc("boar.startGuest")
+	// the CPU and memory released while hibernated must be taken back first.
+	rollback, err := svc.requestCPUMem(ctx, id)
+	if err != nil {
+		return err
+	}
 	do := func(ctx context.Context) (any, error) {
 		g, err := svc.loadGuest(ctx, id, models.IgnoreLoadImageErrOption())
 		if err != nil {
 			return nil, errors.Wrap(err, "")
 		}
+		if g.CPUMemReleased {
+			g.CPUMemReleased = false
+			if err := g.Save(); err != nil {
+				return nil, errors.Wrap(err, "")
+			}
+		}
 		// we need to release the creation session locker here
 		lck := utils.NewCreateSessionFlock(g.ID)
 		defer func() {
@@ -128,7 +141,7 @@ func (svc *Boar) startGuest(ctx context.Context, id string, force bool) error {
 		return nil, nil //nolint
 	}
 	defer logger.Debugf(ctx, "exit startGuest")
-	_, err := svc.do(ctx, id, intertypes.StartOp, do, nil)
+	_, err = svc.do(ctx, id, intertypes.StartOp, do, rollback)
 	return err
 }
 
@@ -163,3 +176,30 @@ func (svc *Boar) resumeGuest(ctx context.Context, id string) error {
 	_, err := svc.do(ctx, id, intertypes.ResumeOp, do, nil)
 	return err
 }
+
+// hibernateGuest saves the memory of a running guest to disk and stops it.
+func (svc *Boar) hibernateGuest(ctx context.Context, id string) error {
+	do := func(ctx context.Context) (any, error) {
+		g, err := svc.loadGuest(ctx, id, models.IgnoreLoadImageErrOption())
+		if err != nil {
+			return nil, errors.Wrap(err, "")
+		}
+		if err := g.Hibernate(ctx); err != nil {
+			return nil, errors.Wrap(err, "")
+		}
+		return nil, nil //nolint
+	}
+	if _, err := svc.do(ctx, id, intertypes.HibernateOp, do, nil); err != nil {
+		return err
+	}
+
+	// the guest isn't running, so eru agent marks its workload as stopped.
+	svc.watchers.Watched(intertypes.Event{
+		ID:   id,
+		Type: guestEventType,
+		Op:   intertypes.DieOp,
+		Time: time.Now().UTC(),
+	})
+	// its memory is kept on disk, so the CPU and memory are freed back to eru.
+	return svc.releaseCPUMem(ctx, id)
+}
@@ -0,0 +1,124 @@
+package boar
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/core/log"
+	"github.com/projecteru2/yavirt/configs"
+	"github.com/projecteru2/yavirt/internal/models"
+	intertypes "github.com/projecteru2/yavirt/internal/types"
+	interutils "github.com/projecteru2/yavirt/internal/utils"
+	"github.com/projecteru2/yavirt/pkg/notify/bison"
+)
+
+// HandleCrashes watches the watchdog and panic events of guests in the
+// background, collects the crash dumps and takes the actions of their crash
+// policies, it's only for yavirtd.
+func (svc *Boar) HandleCrashes(ctx context.Context) {
+	logger := log.WithFunc("boar.HandleCrashes")
+	watcher, err := svc.WatchGuestEvents(ctx)
+	if err != nil {
+		logger.Warnf(ctx, "failed to watch guest events: %s", err)
+		return
+	}
+	go func() {
+		defer watcher.Stop()
+		// the guests which are being handled, the repeated events are ignored
+		var handling sync.Map
+		for {
+			select {
+			case event := <-watcher.Events():
+				if event.Op != intertypes.CrashOp && event.Op != intertypes.WatchdogOp {
+					continue
+				}
+				if _, loaded := handling.LoadOrStore(event.ID, struct{}{}); loaded {
+					continue
+				}
+				if err := interutils.Pool.Submit(func() {
+					defer handling.Delete(event.ID)
+					svc.handleCrash(ctx, event)
+				}); err != nil {
+					handling.Delete(event.ID)
+					logger.Warnf(ctx, "failed to handle the %s of guest %s: %s", event.Op, event.ID, err)
+				}
+			case <-watcher.Done():
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// handleCrash dumps the guest and takes the action if yavirtd dumps first,
+// otherwise libvirt has taken the action already, then notifies it.
+func (svc *Boar) handleCrash(ctx context.Context, event intertypes.Event) {
+	logger := log.WithFunc("boar.handleCrash")
+	g, err := models.LoadGuest(event.ID)
+	if err != nil {
+		// not managed by yavirt
+		return
+	}
+	policy, err := g.CrashPolicy()
+	if err != nil {
+		logger.Warnf(ctx, "failed to get crash policy of guest %s: %s", event.ID, err)
+		return
+	}
+	logger.Warnf(ctx, "guest %s crashed by %s, action %s", event.ID, event.Op, policy.Action)
+
+	var dump string
+	if policy.DumpFirst() {
+		d, dumpErr := svc.DumpGuest(ctx, event.ID, nil, nil)
+		if dumpErr == nil {
+			dump = d.Path
+		}
+		// the action is taken even if the dump fails, so the guest doesn't hang
+		err = errors.CombineErrors(dumpErr, svc.takeCrashAction(ctx, event.ID, policy.Action))
+		if err != nil {
+			logger.Errorf(ctx, err, "failed to handle the crash of guest %s", event.ID)
+		}
+	}
+	svc.notifyCrash(ctx, event, policy, dump, err)
+}
+
+func (svc *Boar) takeCrashAction(ctx context.Context, id, action string) error {
+	switch action {
+	case configs.CrashActionPause:
+		return nil
+	case configs.CrashActionPoweroff:
+		return svc.stopGuest(ctx, id, true)
+	default:
+		if err := svc.stopGuest(ctx, id, true); err != nil {
+			return err
+		}
+		return svc.startGuest(ctx, id, false)
+	}
+}
+
+func (svc *Boar) notifyCrash(ctx context.Context, event intertypes.Event, policy *intertypes.CrashPolicy, dump string, err error) {
+	notifier := bison.GetService()
+	if notifier == nil {
+		return
+	}
+	text := fmt.Sprintf(`
+<font color=#FF3300 size=10>guest crashed</font>
+---
+
+- **node:** %s
+- **id:** %s
+- **event:** %s
+- **action:** %s
+`, configs.Hostname(), event.ID, event.Op, policy.Action)
+	if dump != "" {
+		text += fmt.Sprintf("- **dump:** %s\n", dump)
+	}
+	if err != nil {
+		text += fmt.Sprintf("- **error:** %s\n", err)
+	}
+	if err := notifier.SendMarkdown(ctx, "guest crashed", text); err != nil {
+		log.WithFunc("boar.notifyCrash").Warnf(ctx, "failed to send crash message: %v", err)
+	}
+}
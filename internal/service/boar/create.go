@@ -19,6 +19,12 @@ import (
 // CreateGuest .
 func (svc *Boar) CreateGuest(ctx context.Context, opts intertypes.GuestCreateOption) (*types.Guest, error) {
 	logger := log.WithFunc("boar.CreateGuest")
+	if err := svc.checkMaintenance(ctx); err != nil {
+		return nil, err
+	}
+	if err := applyFlavor(&opts); err != nil {
+		return nil, err
+	}
 	if opts.CPU == 0 {
 		opts.CPU = utils.Min(svc.Host.CPU, configs.Conf.Resource.MaxCPU)
 	}
@@ -0,0 +1,208 @@
+package boar
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/core/log"
+	"github.com/projecteru2/libyavirt/types"
+	intertypes "github.com/projecteru2/yavirt/internal/types"
+	"github.com/projecteru2/yavirt/internal/virt/guest"
+	"github.com/projecteru2/yavirt/pkg/terrors"
+)
+
+// maxDumpReadSize bounds a read of dump, which is returned in one RPC message.
+const maxDumpReadSize = 1 << 20
+
+// DumpGuest writes the memory of guest into the dump dir of host, the progress
+// is sent to progress if it isn't nil, which isn't closed after dumping.
+// The progress can also be polled by GetGuestDumpStatus.
+func (svc *Boar) DumpGuest(ctx context.Context, id string, opts *intertypes.DumpOptions, progress chan<- intertypes.DumpProgress) (dump *intertypes.Dump, err error) {
+	if err := svc.dumps.start(id); err != nil {
+		return nil, err
+	}
+	defer func() { svc.dumps.done(id, dump, err) }()
+
+	ch := make(chan intertypes.DumpProgress)
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		for {
+			select {
+			case p := <-ch:
+				svc.dumps.update(id, p)
+				if progress == nil {
+					continue
+				}
+				select {
+				case progress <- p:
+				default:
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	err = svc.ctrl(ctx, id, intertypes.DumpOp, func(g *guest.Guest) error {
+		dump, err = g.Dump(ctx, opts, ch)
+		return err
+	}, nil)
+	return
+}
+
+// GetGuestDumpStatus returns the progress of the running dump of guest,
+// or the result of the last dump since yavirtd started.
+func (svc *Boar) GetGuestDumpStatus(ctx context.Context, id string) (*intertypes.DumpStatus, error) {
+	if _, err := svc.loadGuest(ctx, id); err != nil {
+		return nil, errors.Wrap(err, "")
+	}
+	return svc.dumps.get(id), nil
+}
+
+// ListGuestDumps .
+func (svc *Boar) ListGuestDumps(ctx context.Context, id string) ([]*intertypes.Dump, error) {
+	if _, err := svc.loadGuest(ctx, id); err != nil {
+		return nil, errors.Wrap(err, "")
+	}
+	return guest.ListDumps(id)
+}
+
+// ReadGuestDump reads at most size bytes of the dump name from offset,
+// so the dumps can be fetched by chunks remotely.
+func (svc *Boar) ReadGuestDump(ctx context.Context, id, name string, offset, size int64) ([]byte, bool, error) {
+	if _, err := svc.loadGuest(ctx, id); err != nil {
+		return nil, false, errors.Wrap(err, "")
+	}
+	return guest.ReadDump(id, name, offset, min(size, maxDumpReadSize))
+}
+
+func (svc *Boar) rawDumpGuest(ctx context.Context, id string, params []byte) (types.RawEngineResp, error) {
+	args := &dumpParams{}
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, args); err != nil {
+			return types.RawEngineResp{}, errors.Wrapf(err, "failed to unmarshal params")
+		}
+	}
+	if args.Async {
+		if svc.dumps.get(id).Running {
+			return types.RawEngineResp{}, errors.Wrapf(terrors.ErrInvalidValue, "guest %s is being dumped", id)
+		}
+		// the RPC returns at once, the progress and the result are
+		// polled by vm-dump-status.
+		go func() {
+			ctx := context.Background()
+			if _, err := svc.DumpGuest(ctx, id, &args.DumpOptions, nil); err != nil {
+				log.WithFunc("rawDumpGuest").Errorf(ctx, err, "failed to dump guest %s", id)
+			}
+		}()
+		return types.RawEngineResp{Data: []byte(`{"success":true}`)}, nil
+	}
+	dump, err := svc.DumpGuest(ctx, id, &args.DumpOptions, nil)
+	if err != nil {
+		return types.RawEngineResp{}, err
+	}
+	bs, _ := json.Marshal(dump)
+	return types.RawEngineResp{Data: bs}, nil
+}
+
+func (svc *Boar) rawGetGuestDumpStatus(ctx context.Context, id string) (types.RawEngineResp, error) {
+	st, err := svc.GetGuestDumpStatus(ctx, id)
+	if err != nil {
+		return types.RawEngineResp{}, err
+	}
+	bs, _ := json.Marshal(st)
+	return types.RawEngineResp{Data: bs}, nil
+}
+
+func (svc *Boar) rawListGuestDumps(ctx context.Context, id string) (types.RawEngineResp, error) {
+	dumps, err := svc.ListGuestDumps(ctx, id)
+	if err != nil {
+		return types.RawEngineResp{}, err
+	}
+	bs, _ := json.Marshal(dumps)
+	return types.RawEngineResp{Data: bs}, nil
+}
+
+func (svc *Boar) rawReadGuestDump(ctx context.Context, id string, params []byte) (types.RawEngineResp, error) {
+	args := &dumpReadParams{}
+	if err := json.Unmarshal(params, args); err != nil {
+		return types.RawEngineResp{}, errors.Wrapf(err, "failed to unmarshal params")
+	}
+	data, eof, err := svc.ReadGuestDump(ctx, id, args.Name, args.Offset, args.Size)
+	if err != nil {
+		return types.RawEngineResp{}, err
+	}
+	bs, _ := json.Marshal(&dumpReadResult{Data: data, EOF: eof})
+	return types.RawEngineResp{Data: bs}, nil
+}
+
+type dumpParams struct {
+	intertypes.DumpOptions
+	Async bool `json:"async"`
+}
+
+type dumpReadParams struct {
+	Name   string `json:"name"`
+	Offset int64  `json:"offset"`
+	Size   int64  `json:"size"`
+}
+
+type dumpReadResult struct {
+	// encoded in base64 by JSON
+	Data []byte `json:"data"`
+	EOF  bool   `json:"eof"`
+}
+
+// dumpTracker keeps the status of the last dump of guests.
+type dumpTracker struct {
+	mu       sync.Mutex
+	statuses map[string]*intertypes.DumpStatus
+}
+
+func newDumpTracker() *dumpTracker {
+	return &dumpTracker{statuses: map[string]*intertypes.DumpStatus{}}
+}
+
+func (t *dumpTracker) start(id string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if st, ok := t.statuses[id]; ok && st.Running {
+		return errors.Wrapf(terrors.ErrInvalidValue, "guest %s is being dumped", id)
+	}
+	t.statuses[id] = &intertypes.DumpStatus{Running: true}
+	return nil
+}
+
+func (t *dumpTracker) update(id string, p intertypes.DumpProgress) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if st, ok := t.statuses[id]; ok {
+		st.Progress = p
+	}
+}
+
+func (t *dumpTracker) done(id string, dump *intertypes.Dump, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	st := &intertypes.DumpStatus{Dump: dump}
+	if old, ok := t.statuses[id]; ok {
+		st.Progress = old.Progress
+	}
+	if err != nil {
+		st.Error = err.Error()
+	}
+	t.statuses[id] = st
+}
+
+func (t *dumpTracker) get(id string) *intertypes.DumpStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if st, ok := t.statuses[id]; ok {
+		cp := *st
+		return &cp
+	}
+	return &intertypes.DumpStatus{}
+}
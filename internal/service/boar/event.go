@@ -0,0 +1,56 @@
+package boar
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/libyavirt/types"
+	"github.com/projecteru2/yavirt/internal/models"
+	intertypes "github.com/projecteru2/yavirt/internal/types"
+)
+
+// EnableEventJournal saves the events of guests into the journal of host,
+// so the clients resume from the last one they got, it's only for yavirtd.
+func (svc *Boar) EnableEventJournal(ctx context.Context) error {
+	if svc.cfg.EventJournalSize < 1 {
+		return nil
+	}
+	journal, err := models.NewEventJournal(ctx, svc.Host.Name, svc.cfg.EventJournalSize)
+	if err != nil {
+		return errors.Wrap(err, "failed to load event journal")
+	}
+	svc.journal = journal
+	svc.watchers.SetJournal(journal)
+	return nil
+}
+
+// ListEvents returns the events in the journal whose seq is greater than since,
+// at most limit ones if it's positive.
+func (svc *Boar) ListEvents(_ context.Context, since uint64, limit int) ([]intertypes.Event, error) {
+	// the journal of yavirtd also has the events which aren't written yet
+	if svc.journal != nil {
+		return svc.journal.List(since, limit)
+	}
+	return models.ListEvents(svc.Host.Name, since, limit)
+}
+
+type listEventsParams struct {
+	Since uint64 `json:"since"`
+	Limit int    `json:"limit"`
+}
+
+func (svc *Boar) rawListEvents(ctx context.Context, params []byte) (types.RawEngineResp, error) {
+	var args listEventsParams
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &args); err != nil {
+			return types.RawEngineResp{}, errors.Wrapf(err, "failed to unmarshal params")
+		}
+	}
+	events, err := svc.ListEvents(ctx, args.Since, args.Limit)
+	if err != nil {
+		return types.RawEngineResp{}, err
+	}
+	bs, _ := json.Marshal(events)
+	return types.RawEngineResp{Data: bs}, nil
+}
@@ -0,0 +1,181 @@
+package boar
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/libyavirt/types"
+	stotypes "github.com/projecteru2/resource-storage/storage/types"
+	"github.com/projecteru2/yavirt/internal/eru/resources"
+	"github.com/projecteru2/yavirt/internal/models"
+	intertypes "github.com/projecteru2/yavirt/internal/types"
+	"github.com/projecteru2/yavirt/internal/virt/guest"
+	"github.com/projecteru2/yavirt/internal/volume/local"
+	"github.com/projecteru2/yavirt/pkg/idgen"
+)
+
+// CreateFlavor .
+func (svc *Boar) CreateFlavor(_ context.Context, flavor *intertypes.Flavor) error {
+	return models.CreateFlavor(flavor)
+}
+
+// GetFlavor .
+func (svc *Boar) GetFlavor(_ context.Context, name string) (*intertypes.Flavor, error) {
+	return models.LoadFlavor(name)
+}
+
+// ListFlavors .
+func (svc *Boar) ListFlavors(_ context.Context) ([]*intertypes.Flavor, error) {
+	return models.ListFlavors()
+}
+
+// DeleteFlavor .
+func (svc *Boar) DeleteFlavor(_ context.Context, name string) error {
+	return models.DeleteFlavor(name)
+}
+
+// applyFlavor resolves the flavor label of create options.
+func applyFlavor(opts *intertypes.GuestCreateOption) error {
+	name, ok := opts.Labels[intertypes.FlavorLabelKey]
+	if !ok || name == "" {
+		return nil
+	}
+	flavor, err := models.LoadFlavor(name)
+	if err != nil {
+		return err
+	}
+	return flavor.ApplyCreateOption(opts)
+}
+
+// resizeFlavor resizes the guest to the flavor, and records the flavor
+// in the labels of guest.
+func (svc *Boar) resizeFlavor(ctx context.Context, id, name string) error {
+	flavor, err := models.LoadFlavor(name)
+	if err != nil {
+		return err
+	}
+	g, err := svc.loadGuest(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := g.CheckFlavor(flavor); err != nil {
+		return err
+	}
+	if svc.cfg.Eru.Enable && idgen.CheckID(id) {
+		// eru core resizes the guest by calling ResizeGuest back,
+		// so it's reallocated without the guest locked.
+		if err := reallocFlavor(ctx, g, flavor); err != nil {
+			return errors.Wrapf(err, "failed to realloc the resources of guest %s", id)
+		}
+	}
+	return svc.ctrl(ctx, id, intertypes.ResizeOp, func(g *guest.Guest) error {
+		return g.ResizeFlavor(ctx, flavor)
+	}, nil)
+}
+
+// reallocFlavor requests the deltas of CPU, memory, bandwidth
+// and the local sys volume to the flavor from eru core.
+func reallocFlavor(ctx context.Context, g *guest.Guest, flavor *intertypes.Flavor) error {
+	deltas := map[string]map[string]any{}
+	if cpu, mem := flavor.CPU-g.CPU, flavor.Memory-g.Memory; cpu != 0 || mem != 0 {
+		deltas[intertypes.PluginNameCPUMem] = map[string]any{
+			"cpu-request":    cpu,
+			"cpu-limit":      cpu,
+			"memory-request": mem,
+			"memory-limit":   mem,
+			"keep-cpu-bind":  true,
+		}
+	}
+
+	var bw int64
+	if flavor.Bandwidth != nil {
+		bw = flavor.Bandwidth.Average
+	}
+	if delta := bw - allocatedBandwidth(g); delta != 0 {
+		deltas[intertypes.PluginNameBandwidth] = map[string]any{"bandwidth": delta}
+	}
+
+	sysVol, err := g.SysVolume()
+	if err != nil {
+		return err
+	}
+	if lv, ok := sysVol.(*local.Volume); ok && flavor.SysDiskSize > lv.SizeInBytes {
+		vb := stotypes.VolumeBinding{
+			Source:      lv.Source,
+			Destination: lv.Destination,
+			Flags:       lv.Flags,
+			SizeInBytes: flavor.SysDiskSize - lv.SizeInBytes,
+		}
+		vols := []string{vb.ToString(false)}
+		deltas[intertypes.PluginNameStorage] = map[string]any{
+			"volumes-request": vols,
+			"volumes":         vols,
+		}
+	}
+
+	if len(deltas) == 0 {
+		return nil
+	}
+	return resources.GetManager().ReallocWorkload(ctx, g.ID, deltas)
+}
+
+func (svc *Boar) rawCreateFlavor(ctx context.Context, params []byte) (types.RawEngineResp, error) {
+	flavor := &intertypes.Flavor{}
+	if err := json.Unmarshal(params, flavor); err != nil {
+		return types.RawEngineResp{}, errors.Wrapf(err, "failed to unmarshal params")
+	}
+	if err := svc.CreateFlavor(ctx, flavor); err != nil {
+		return types.RawEngineResp{}, err
+	}
+	return types.RawEngineResp{Data: []byte(`{"success":true}`)}, nil
+}
+
+func (svc *Boar) rawGetFlavor(ctx context.Context, params []byte) (types.RawEngineResp, error) {
+	args := &flavorParams{}
+	if err := json.Unmarshal(params, args); err != nil {
+		return types.RawEngineResp{}, errors.Wrapf(err, "failed to unmarshal params")
+	}
+	flavor, err := svc.GetFlavor(ctx, args.Name)
+	if err != nil {
+		return types.RawEngineResp{}, err
+	}
+	bs, _ := json.Marshal(flavor)
+	return types.RawEngineResp{Data: bs}, nil
+}
+
+func (svc *Boar) rawListFlavors(ctx context.Context) (types.RawEngineResp, error) {
+	flavors, err := svc.ListFlavors(ctx)
+	if err != nil {
+		return types.RawEngineResp{}, err
+	}
+	bs, _ := json.Marshal(flavors)
+	return types.RawEngineResp{Data: bs}, nil
+}
+
+func (svc *Boar) rawDeleteFlavor(ctx context.Context, params []byte) (types.RawEngineResp, error) {
+	args := &flavorParams{}
+	if err := json.Unmarshal(params, args); err != nil {
+		return types.RawEngineResp{}, errors.Wrapf(err, "failed to unmarshal params")
+	}
+	if err := svc.DeleteFlavor(ctx, args.Name); err != nil {
+		return types.RawEngineResp{}, err
+	}
+	return types.RawEngineResp{Data: []byte(`{"success":true}`)}, nil
+}
+
+// rawResizeFlavor is for gRPC, whose ResizeGuest has no field for the flavor.
+func (svc *Boar) rawResizeFlavor(ctx context.Context, id string, params []byte) (types.RawEngineResp, error) {
+	args := &flavorParams{}
+	if err := json.Unmarshal(params, args); err != nil {
+		return types.RawEngineResp{}, errors.Wrapf(err, "failed to unmarshal params")
+	}
+	if err := svc.resizeFlavor(ctx, id, args.Name); err != nil {
+		return types.RawEngineResp{}, err
+	}
+	return types.RawEngineResp{Data: []byte(`{"success":true}`)}, nil
+}
+
+type flavorParams struct {
+	Name string `json:"name"`
+}
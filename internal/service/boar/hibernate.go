@@ -0,0 +1,116 @@
+package boar
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/core/log"
+	cpumemtypes "github.com/projecteru2/core/resource/plugins/cpumem/types"
+	"github.com/projecteru2/yavirt/internal/eru/resources"
+	"github.com/projecteru2/yavirt/internal/meta"
+	"github.com/projecteru2/yavirt/internal/models"
+	intertypes "github.com/projecteru2/yavirt/internal/types"
+	"github.com/projecteru2/yavirt/internal/virt/guest"
+	"github.com/projecteru2/yavirt/pkg/idgen"
+	"github.com/projecteru2/yavirt/pkg/terrors"
+)
+
+// releaseCPUMem gives the CPU and memory of the hibernated guest back to eru,
+// they're requested again before the guest is started, see requestCPUMem.
+func (svc *Boar) releaseCPUMem(ctx context.Context, id string) error {
+	if !svc.cfg.Eru.Enable || !idgen.CheckID(id) {
+		return nil
+	}
+	g, err := svc.loadGuest(ctx, id, models.IgnoreLoadImageErrOption())
+	if err != nil {
+		return err
+	}
+	if g.CPUMemReleased {
+		return nil
+	}
+	// eru core calls ResizeGuest back, so it's reallocated without the guest locked.
+	if err := reallocCPUMem(ctx, id, -g.CPU, -g.Memory); err != nil {
+		return errors.Wrapf(err, "failed to release the cpu and memory of guest %s", id)
+	}
+	rollback := func() {
+		ctx := context.Background()
+		if err := reallocCPUMem(ctx, id, g.CPU, g.Memory); err != nil {
+			log.WithFunc("boar.releaseCPUMem").Errorf(ctx, err, "failed to take back the cpu and memory of guest %s", id)
+		}
+	}
+	return svc.ctrl(ctx, id, intertypes.MiscOp, func(g *guest.Guest) error {
+		g.CPUMemReleased = true
+		return g.Save()
+	}, rollback)
+}
+
+// requestCPUMem requests the released CPU and memory of guest from eru,
+// the guest mustn't be started if eru refuses. The returned rollback
+// releases them again.
+func (svc *Boar) requestCPUMem(ctx context.Context, id string) (rollbackFunc, error) {
+	if !svc.cfg.Eru.Enable || !idgen.CheckID(id) {
+		return nil, nil
+	}
+	g, err := svc.loadGuest(ctx, id, models.IgnoreLoadImageErrOption())
+	if err != nil {
+		return nil, err
+	}
+	if !g.CPUMemReleased {
+		return nil, nil
+	}
+	if err := reallocCPUMem(ctx, id, g.CPU, g.Memory); err != nil {
+		return nil, errors.Wrapf(err, "eru refused the cpu and memory of guest %s", id)
+	}
+	return func() {
+		ctx := context.Background()
+		if err := reallocCPUMem(ctx, id, -g.CPU, -g.Memory); err != nil {
+			log.WithFunc("boar.requestCPUMem").Errorf(ctx, err, "failed to release the cpu and memory of guest %s", id)
+			return
+		}
+		if err := svc.ctrl(ctx, id, intertypes.MiscOp, func(g *guest.Guest) error {
+			g.CPUMemReleased = true
+			return g.Save()
+		}, nil); err != nil {
+			log.WithFunc("boar.requestCPUMem").Errorf(ctx, err, "failed to mark the cpu and memory of guest %s released", id)
+		}
+	}, nil
+}
+
+func reallocCPUMem(ctx context.Context, id string, cpu int, mem int64) error {
+	return resources.GetManager().ReallocWorkload(ctx, id, map[string]map[string]any{
+		intertypes.PluginNameCPUMem: {
+			"cpu-request":    cpu,
+			"cpu-limit":      cpu,
+			"memory-request": mem,
+			"memory-limit":   mem,
+			"keep-cpu-bind":  true,
+		},
+	})
+}
+
+// acceptReleasedCPUMem tells whether the resizing called back by eru core
+// is releasing or requesting the CPU and memory of a hibernated guest,
+// which isn't applied to the guest. The pCPUs bound by eru must be the ones
+// the guest is pinned to, since the pinning is restored with the memory.
+func acceptReleasedCPUMem(g *guest.Guest, cpumem *cpumemtypes.EngineParams) (bool, error) {
+	if cpumem == nil || (g.Status != meta.StatusHibernated && !g.CPUMemReleased) {
+		return false, nil
+	}
+	if cpumem.CPU == 0 && cpumem.Memory == 0 {
+		return true, nil
+	}
+	if int(cpumem.CPU) != g.CPU || cpumem.Memory != g.Memory {
+		return false, errors.Wrapf(terrors.ErrForwardStatus, "guest %s is hibernated, it can't be resized", g.ID)
+	}
+	if len(cpumem.CPUMap) > 0 && g.CPUPinning != nil {
+		pinning, err := resources.GetManager().CPUPinningFromCPUMap(cpumem.CPUMap)
+		if err != nil {
+			return false, err
+		}
+		if !reflect.DeepEqual(pinning.Cells, g.CPUPinning.Cells) {
+			return false, errors.Wrapf(terrors.ErrInvalidValue, "guest %s is pinned to the other pCPUs", g.ID)
+		}
+	}
+	return true, nil
+}
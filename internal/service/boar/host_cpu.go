@@ -0,0 +1,142 @@
+package boar
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/core/log"
+	"github.com/projecteru2/libyavirt/types"
+	"github.com/projecteru2/yavirt/internal/models"
+	intertypes "github.com/projecteru2/yavirt/internal/types"
+	"github.com/projecteru2/yavirt/pkg/libvirt"
+	"github.com/projecteru2/yavirt/pkg/terrors"
+	"libvirt.org/go/libvirtxml"
+)
+
+// HostCPU returns the CPU definition of this host from the libvirt capabilities.
+func (svc *Boar) HostCPU(_ context.Context) (string, error) {
+	virt, err := libvirt.Connect("qemu:///system")
+	if err != nil {
+		return "", errors.Wrap(err, "")
+	}
+	defer virt.Close() //nolint
+
+	doc, err := virt.GetCapabilities()
+	if err != nil {
+		return "", err
+	}
+	caps := &libvirtxml.Caps{}
+	if err := caps.Unmarshal(doc); err != nil {
+		return "", errors.Wrap(err, "")
+	}
+	if caps.Host.CPU == nil {
+		return "", errors.New("no host CPU in capabilities")
+	}
+	return caps.Host.CPU.Marshal()
+}
+
+// BaselineCPU computes the CPU model which is supported by all hosts registered in etcd,
+// it can be used as the custom CPU model of the host pool.
+func (svc *Boar) BaselineCPU(ctx context.Context) (*intertypes.CPUModel, error) {
+	hostCPUs, err := models.GetHostCPUs()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := hostCPUs[svc.Host.Name]; !ok {
+		cpu, err := svc.HostCPU(ctx)
+		if err != nil {
+			return nil, err
+		}
+		hostCPUs[svc.Host.Name] = cpu
+	}
+	cpus := make([]string, 0, len(hostCPUs))
+	for _, cpu := range hostCPUs {
+		cpus = append(cpus, cpu)
+	}
+
+	virt, err := libvirt.Connect("qemu:///system")
+	if err != nil {
+		return nil, errors.Wrap(err, "")
+	}
+	defer virt.Close() //nolint
+
+	doc, err := virt.BaselineCPU(cpus, true)
+	if err != nil {
+		return nil, err
+	}
+	return parseBaselineCPU(doc)
+}
+
+func parseBaselineCPU(doc string) (*intertypes.CPUModel, error) {
+	cpu := &libvirtxml.DomainCPU{}
+	if err := xml.Unmarshal([]byte(doc), cpu); err != nil {
+		return nil, errors.Wrap(err, "")
+	}
+	if cpu.Model == nil || cpu.Model.Value == "" {
+		return nil, errors.New("no model in baseline CPU")
+	}
+	model := &intertypes.CPUModel{
+		Mode:  intertypes.CPUModeCustom,
+		Model: cpu.Model.Value,
+	}
+	for _, feat := range cpu.Features {
+		model.Features = append(model.Features, feat.Policy+":"+feat.Name)
+	}
+	return model, nil
+}
+
+func (svc *Boar) rawHostCPU(ctx context.Context) (types.RawEngineResp, error) {
+	cpu, err := svc.HostCPU(ctx)
+	if err != nil {
+		return types.RawEngineResp{}, err
+	}
+	bs, _ := json.Marshal(map[string]string{
+		"cpu": cpu,
+	})
+	return types.RawEngineResp{Data: bs}, nil
+}
+
+func (svc *Boar) rawBaselineCPU(ctx context.Context) (types.RawEngineResp, error) {
+	model, err := svc.BaselineCPU(ctx)
+	if err != nil {
+		return types.RawEngineResp{}, err
+	}
+	bs, _ := json.Marshal(model)
+	return types.RawEngineResp{Data: bs}, nil
+}
+
+// PublishHostCPU saves the CPU of this host to etcd for computing the baseline,
+// it's only for yavirtd, so the CLI doesn't publish a deregistered host again.
+func (svc *Boar) PublishHostCPU(ctx context.Context) {
+	logger := log.WithFunc("boar.PublishHostCPU")
+	cpu, err := svc.HostCPU(ctx)
+	if err != nil {
+		logger.Warnf(ctx, "failed to get host CPU: %s", err)
+		return
+	}
+	if err := models.SaveHostCPU(svc.Host.Name, cpu); err != nil {
+		logger.Warnf(ctx, "failed to save host CPU: %s", err)
+	}
+}
+
+// DeregisterHost removes this host from the pool, its CPU isn't taken
+// into the baseline any more, the host mustn't have any guests.
+func (svc *Boar) DeregisterHost(ctx context.Context) error {
+	ids, err := svc.ListLocalIDs(ctx, false)
+	if err != nil {
+		return err
+	}
+	if len(ids) > 0 {
+		return errors.Wrapf(terrors.ErrInvalidValue, "host %s still has %d guests", svc.Host.Name, len(ids))
+	}
+	return models.DeleteHost(svc.Host.Name)
+}
+
+func (svc *Boar) rawDeregisterHost(ctx context.Context) (types.RawEngineResp, error) {
+	if err := svc.DeregisterHost(ctx); err != nil {
+		return types.RawEngineResp{}, err
+	}
+	return types.RawEngineResp{Data: []byte(`{"success":true}`)}, nil
+}
@@ -0,0 +1,65 @@
+package boar
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/libyavirt/types"
+	intertypes "github.com/projecteru2/yavirt/internal/types"
+	"github.com/projecteru2/yavirt/internal/virt/guest"
+)
+
+// AttachISO inserts the ISO into the guest, src is an image name or a host path.
+func (svc *Boar) AttachISO(ctx context.Context, id, src string) error {
+	return svc.ctrl(ctx, id, intertypes.AttachISOOp, func(g *guest.Guest) error {
+		return g.AttachISO(ctx, src)
+	}, nil)
+}
+
+// EjectISO .
+func (svc *Boar) EjectISO(ctx context.Context, id string) error {
+	return svc.ctrl(ctx, id, intertypes.EjectISOOp, func(g *guest.Guest) error {
+		return g.EjectISO()
+	}, nil)
+}
+
+// SetBootOrder changes the boot devices of the guest, e.g. back to the sys disk after installing.
+func (svc *Boar) SetBootOrder(ctx context.Context, id string, order []string) error {
+	return svc.ctrl(ctx, id, intertypes.SetBootOrderOp, func(g *guest.Guest) error {
+		return g.SetBootOrder(order)
+	}, nil)
+}
+
+func (svc *Boar) rawAttachISO(ctx context.Context, id string, params []byte) (types.RawEngineResp, error) {
+	args := &struct {
+		Source string `json:"source"`
+	}{}
+	if err := json.Unmarshal(params, args); err != nil {
+		return types.RawEngineResp{}, errors.Wrapf(err, "failed to unmarshal params")
+	}
+	if err := svc.AttachISO(ctx, id, args.Source); err != nil {
+		return types.RawEngineResp{}, err
+	}
+	return types.RawEngineResp{Data: []byte(`{"success":true}`)}, nil
+}
+
+func (svc *Boar) rawEjectISO(ctx context.Context, id string) (types.RawEngineResp, error) {
+	if err := svc.EjectISO(ctx, id); err != nil {
+		return types.RawEngineResp{}, err
+	}
+	return types.RawEngineResp{Data: []byte(`{"success":true}`)}, nil
+}
+
+func (svc *Boar) rawSetBootOrder(ctx context.Context, id string, params []byte) (types.RawEngineResp, error) {
+	args := &struct {
+		BootOrder []string `json:"boot_order"`
+	}{}
+	if err := json.Unmarshal(params, args); err != nil {
+		return types.RawEngineResp{}, errors.Wrapf(err, "failed to unmarshal params")
+	}
+	if err := svc.SetBootOrder(ctx, id, args.BootOrder); err != nil {
+		return types.RawEngineResp{}, err
+	}
+	return types.RawEngineResp{Data: []byte(`{"success":true}`)}, nil
+}
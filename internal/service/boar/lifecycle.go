@@ -0,0 +1,196 @@
+package boar
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/projecteru2/core/log"
+	"github.com/projecteru2/yavirt/configs"
+	"github.com/projecteru2/yavirt/internal/meta"
+	"github.com/projecteru2/yavirt/internal/models"
+	intertypes "github.com/projecteru2/yavirt/internal/types"
+	"github.com/projecteru2/yavirt/pkg/sh"
+)
+
+// Shutdown handles the running guests by the shutdown action of lifecycle
+// in parallel if the host is shutting down, the ones which aren't done before
+// the timeout are left as they are. A restart of yavirtd leaves them running.
+// The running guests are recorded, so they're started again by the next startup.
+func (svc *Boar) Shutdown(ctx context.Context) error {
+	logger := log.WithFunc("boar.Shutdown")
+	cfg := &svc.cfg.Lifecycle
+
+	ids, err := svc.runningGuests(ctx)
+	if err != nil {
+		return err
+	}
+	if err := models.SaveRunningGuests(svc.Host.Name, ids); err != nil {
+		return err
+	}
+	if cfg.ShutdownAction == configs.ShutdownActionNone || len(ids) == 0 {
+		return nil
+	}
+	if !hostShuttingDown(ctx) {
+		logger.Infof(ctx, "the host isn't shutting down, %d guests are left running", len(ids))
+		return nil
+	}
+
+	// the loop of watchers has exited with the signal,
+	// stop them so the events of guests don't block.
+	svc.watchers.Stop()
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.ShutdownTimeout)
+	defer cancel()
+
+	logger.Infof(ctx, "%s %d guests", cfg.ShutdownAction, len(ids))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, cfg.ShutdownConcurrency)
+	for _, id := range ids {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				logger.Warnf(ctx, "guest %s is left running: %s", id, ctx.Err())
+				return
+			}
+
+			var err error
+			switch cfg.ShutdownAction {
+			case configs.ShutdownActionStop:
+				err = svc.stopGuest(ctx, id, false)
+			case configs.ShutdownActionHibernate:
+				err = svc.hibernateGuest(ctx, id)
+			}
+			if err != nil {
+				logger.Warnf(ctx, "failed to %s guest %s: %s", cfg.ShutdownAction, id, err)
+			}
+		}(id)
+	}
+	wg.Wait()
+	return nil
+}
+
+// hostShuttingDown checks whether yavirtd is stopped by the shutdown of host,
+// systemd reports stopping until the host is down.
+func hostShuttingDown(ctx context.Context) bool {
+	// is-system-running exits with non-zero unless it's running
+	so, _, _ := sh.ExecInOut(ctx, nil, nil, "systemctl", "is-system-running")
+	return strings.TrimSpace(string(so)) == "stopping"
+}
+
+// RestoreGuests starts the guests which were running at the last shutdown
+// in the background if it's enabled, it's only for yavirtd.
+func (svc *Boar) RestoreGuests(ctx context.Context) {
+	if svc.cfg.Lifecycle.StartupRestore {
+		go svc.restoreGuests(ctx)
+	}
+}
+
+// runningGuests returns the guests of this host which are running.
+func (svc *Boar) runningGuests(ctx context.Context) ([]string, error) {
+	ids, err := svc.ListLocalIDs(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	var ans []string
+	for _, id := range ids {
+		g, err := models.LoadGuest(id)
+		if err != nil {
+			// not managed by yavirt
+			continue
+		}
+		if g.Status == meta.StatusRunning {
+			ans = append(ans, id)
+		}
+	}
+	return ans, nil
+}
+
+// restoreGuests starts the guests which were running at the last shutdown,
+// they're started in groups ordered by the boot labels.
+func (svc *Boar) restoreGuests(ctx context.Context) {
+	logger := log.WithFunc("boar.restoreGuests")
+	cfg := &svc.cfg.Lifecycle
+
+	if err := svc.checkMaintenance(ctx); err != nil {
+		logger.Infof(ctx, "the guests aren't restored: %s", err)
+		return
+	}
+
+	ids, err := models.PopRunningGuests(svc.Host.Name)
+	if err != nil {
+		logger.Warnf(ctx, "failed to load the running guests of last shutdown: %s", err)
+		return
+	}
+
+	entries := make([]intertypes.BootEntry, 0, len(ids))
+	for _, id := range ids {
+		g, err := models.LoadGuest(id)
+		if err != nil {
+			logger.Warnf(ctx, "failed to load guest %s: %s", id, err)
+			continue
+		}
+		entry, err := intertypes.ParseBootEntry(id, g.JSONLabels)
+		if err != nil {
+			logger.Warnf(ctx, "ignore the boot labels: %s", err)
+			entry = intertypes.BootEntry{ID: id, Delay: -1}
+		}
+		entries = append(entries, entry)
+	}
+
+	groups, err := intertypes.PlanBoot(entries, cfg.StartupDelay)
+	if err != nil {
+		logger.Warnf(ctx, "ignore the boot dependencies: %s", err)
+		for i := range entries {
+			entries[i].After = nil
+		}
+		if groups, err = intertypes.PlanBoot(entries, cfg.StartupDelay); err != nil {
+			logger.Errorf(ctx, err, "failed to plan the startup")
+			return
+		}
+	}
+
+	for i, group := range groups {
+		logger.Infof(ctx, "starting group %d/%d: %v", i+1, len(groups), group.IDs)
+		var wg sync.WaitGroup
+		for _, id := range group.IDs {
+			wg.Add(1)
+			go func(id string) {
+				defer wg.Done()
+				svc.restoreGuest(ctx, id)
+			}(id)
+		}
+		wg.Wait()
+
+		if i == len(groups)-1 {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			logger.Warnf(ctx, "startup is interrupted: %s", ctx.Err())
+			return
+		case <-time.After(group.Delay):
+		}
+	}
+}
+
+func (svc *Boar) restoreGuest(ctx context.Context, id string) {
+	logger := log.WithFunc("boar.restoreGuest")
+	g, err := svc.loadGuest(ctx, id, models.IgnoreLoadImageErrOption())
+	if err != nil {
+		logger.Warnf(ctx, "failed to load guest %s: %s", id, err)
+		return
+	}
+	// e.g. yavirtd was restarted without the reboot of host
+	if g.Status == meta.StatusRunning {
+		return
+	}
+	if err := svc.startGuest(ctx, id, false); err != nil {
+		logger.Warnf(ctx, "failed to start guest %s: %s", id, err)
+	}
+}
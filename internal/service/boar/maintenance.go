@@ -0,0 +1,174 @@
+package boar
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/core/log"
+	"github.com/projecteru2/libyavirt/types"
+	"github.com/projecteru2/yavirt/internal/models"
+	intertypes "github.com/projecteru2/yavirt/internal/types"
+	"github.com/projecteru2/yavirt/pkg/terrors"
+)
+
+// EnterMaintenance puts this host into maintenance, no guest can be created,
+// and the node is unavailable to eru. The running guests are evacuated in
+// the background if opts.Evacuate is set, see GetMaintenance for the progress.
+func (svc *Boar) EnterMaintenance(ctx context.Context, opts intertypes.MaintenanceOptions) (*intertypes.Maintenance, error) {
+	if err := opts.Check(); err != nil {
+		return nil, err
+	}
+	m := &intertypes.Maintenance{
+		MaintenanceOptions: opts,
+		Since:              time.Now().UTC(),
+	}
+	var ids []string
+	if opts.Evacuate != intertypes.EvacuateNone {
+		var err error
+		if ids, err = svc.runningGuests(ctx); err != nil {
+			return nil, err
+		}
+		m.Evacuation = intertypes.NewEvacuation(len(ids))
+	}
+	if err := models.CreateMaintenance(svc.Host.Name, m); err != nil {
+		return nil, err
+	}
+	svc.reportNodeStatus(ctx)
+
+	if len(ids) > 0 {
+		// outlives the request
+		go svc.evacuate(context.WithoutCancel(ctx), opts.Evacuate, ids)
+	}
+	return m, nil
+}
+
+// ExitMaintenance brings this host back, the evacuated guests are left as they are.
+func (svc *Boar) ExitMaintenance(ctx context.Context) error {
+	m, err := svc.GetMaintenance(ctx)
+	switch {
+	case err != nil:
+		return err
+	case m == nil:
+		return errors.Wrapf(terrors.ErrInvalidValue, "host %s isn't in maintenance", svc.Host.Name)
+	}
+	if err := models.DeleteMaintenance(svc.Host.Name); err != nil {
+		return err
+	}
+	svc.reportNodeStatus(ctx)
+	return nil
+}
+
+// GetMaintenance returns nil if this host isn't in maintenance.
+func (svc *Boar) GetMaintenance(_ context.Context) (*intertypes.Maintenance, error) {
+	m, _, err := models.LoadMaintenance(svc.Host.Name)
+	return m, err
+}
+
+// checkMaintenance fails if this host is in maintenance.
+func (svc *Boar) checkMaintenance(ctx context.Context) error {
+	m, err := svc.GetMaintenance(ctx)
+	switch {
+	case err != nil:
+		return err
+	case m != nil:
+		return errors.Wrapf(intertypes.ErrHostInMaintenance, "%s since %s: %s", svc.Host.Name, m.Since.Format(time.RFC3339), m.Reason)
+	default:
+		return nil
+	}
+}
+
+func (svc *Boar) reportNodeStatus(ctx context.Context) {
+	if svc.agt != nil {
+		svc.agt.ReportNodeStatus(ctx)
+	}
+}
+
+// evacuate stops or hibernates the guests, the progress is saved after every guest.
+// It gives up the guests which aren't started if the maintenance is exited.
+func (svc *Boar) evacuate(ctx context.Context, action string, ids []string) {
+	logger := log.WithFunc("boar.evacuate")
+
+	var mu sync.Mutex
+	// returns false if the host isn't in maintenance anymore
+	record := func(id string, err error) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		m, ver, lerr := models.LoadMaintenance(svc.Host.Name)
+		if lerr != nil {
+			logger.Warnf(ctx, "failed to load maintenance: %s", lerr)
+			return true
+		}
+		if m == nil || m.Evacuation == nil {
+			return false
+		}
+		if id != "" {
+			m.Evacuation.Record(id, err)
+			if uerr := models.UpdateMaintenance(svc.Host.Name, m, ver); uerr != nil {
+				logger.Warnf(ctx, "failed to save the progress of evacuation: %s", uerr)
+			}
+		}
+		return true
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, svc.cfg.Lifecycle.ShutdownConcurrency)
+	for _, id := range ids {
+		sem <- struct{}{}
+		if !record("", nil) {
+			<-sem
+			logger.Infof(ctx, "maintenance is exited, the evacuation is stopped")
+			break
+		}
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			var err error
+			switch action {
+			case intertypes.EvacuateStop:
+				err = svc.stopGuest(ctx, id, false)
+			case intertypes.EvacuateHibernate:
+				err = svc.hibernateGuest(ctx, id)
+			}
+			if err != nil {
+				logger.Warnf(ctx, "failed to %s guest %s: %s", action, id, err)
+			}
+			record(id, err)
+		}(id)
+	}
+	wg.Wait()
+}
+
+func (svc *Boar) rawEnterMaintenance(ctx context.Context, params []byte) (types.RawEngineResp, error) {
+	opts := intertypes.MaintenanceOptions{}
+	if err := json.Unmarshal(params, &opts); err != nil {
+		return types.RawEngineResp{}, errors.Wrapf(err, "failed to unmarshal params")
+	}
+	m, err := svc.EnterMaintenance(ctx, opts)
+	if err != nil {
+		return types.RawEngineResp{}, err
+	}
+	bs, _ := json.Marshal(m)
+	return types.RawEngineResp{Data: bs}, nil
+}
+
+func (svc *Boar) rawExitMaintenance(ctx context.Context) (types.RawEngineResp, error) {
+	if err := svc.ExitMaintenance(ctx); err != nil {
+		return types.RawEngineResp{}, err
+	}
+	return types.RawEngineResp{Data: []byte(`{"success":true}`)}, nil
+}
+
+// rawGetMaintenance returns null if the host isn't in maintenance.
+func (svc *Boar) rawGetMaintenance(ctx context.Context) (types.RawEngineResp, error) {
+	m, err := svc.GetMaintenance(ctx)
+	if err != nil {
+		return types.RawEngineResp{}, err
+	}
+	bs, _ := json.Marshal(m)
+	return types.RawEngineResp{Data: bs}, nil
+}
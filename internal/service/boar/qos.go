@@ -0,0 +1,68 @@
+package boar
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/core/log"
+	"github.com/projecteru2/libyavirt/types"
+	"github.com/projecteru2/yavirt/internal/eru/resources"
+	intertypes "github.com/projecteru2/yavirt/internal/types"
+	"github.com/projecteru2/yavirt/internal/virt/guest"
+	"github.com/projecteru2/yavirt/pkg/idgen"
+)
+
+// UpdateGuestQoS changes the CPU, volume and NIC QoS of guest without restart.
+// The NIC bandwidth of eru managed guest is reallocated by eru core at first,
+// and it's given back if the updating fails.
+func (svc *Boar) UpdateGuestQoS(ctx context.Context, id string, qos *intertypes.GuestQoS) error {
+	var rollback rollbackFunc
+	if qos.Bandwidth != nil && svc.cfg.Eru.Enable && idgen.CheckID(id) {
+		g, err := svc.loadGuest(ctx, id)
+		if err != nil {
+			return err
+		}
+		if delta := qos.Bandwidth.Average - allocatedBandwidth(g); delta != 0 {
+			// eru core resizes the guest by calling ResizeGuest back,
+			// so it's reallocated without the guest locked.
+			if err := reallocBandwidth(ctx, id, delta); err != nil {
+				return errors.Wrapf(err, "failed to realloc the bandwidth of guest %s", id)
+			}
+			rollback = func() {
+				ctx := context.Background()
+				if err := reallocBandwidth(ctx, id, -delta); err != nil {
+					log.WithFunc("boar.UpdateGuestQoS").Errorf(ctx, err, "failed to give back the bandwidth of guest %s", id)
+				}
+			}
+		}
+	}
+	return svc.ctrl(ctx, id, intertypes.UpdateQoSOp, func(g *guest.Guest) error {
+		return g.UpdateQoS(ctx, qos)
+	}, rollback)
+}
+
+// allocatedBandwidth is the average NIC bandwidth of guest allocated by eru.
+func allocatedBandwidth(g *guest.Guest) int64 {
+	if g.BDEngineParams == nil {
+		return 0
+	}
+	return g.BDEngineParams.Average
+}
+
+func reallocBandwidth(ctx context.Context, id string, delta int64) error {
+	return resources.GetManager().ReallocWorkload(ctx, id, map[string]map[string]any{
+		intertypes.PluginNameBandwidth: {"bandwidth": delta},
+	})
+}
+
+func (svc *Boar) rawUpdateGuestQoS(ctx context.Context, id string, params []byte) (types.RawEngineResp, error) {
+	qos := &intertypes.GuestQoS{}
+	if err := json.Unmarshal(params, qos); err != nil {
+		return types.RawEngineResp{}, errors.Wrapf(err, "failed to unmarshal params")
+	}
+	if err := svc.UpdateGuestQoS(ctx, id, qos); err != nil {
+		return types.RawEngineResp{}, err
+	}
+	return types.RawEngineResp{Data: []byte(`{"success":true}`)}, nil
+}
@@ -23,6 +23,12 @@ func (svc *Boar) RawEngine(ctx context.Context, id string, req types.RawEngineRe
 	switch req.Op {
 	case "vm-get-vnc-port":
 		return svc.getVNCPort(ctx, id)
+	case "vm-create-console-token":
+		return svc.rawCreateConsoleToken(ctx, id, req.Params)
+	case "vm-console-log":
+		return svc.rawConsoleLog(ctx, id, req.Params)
+	case "vm-screenshot":
+		return svc.rawScreenshot(ctx, id)
 	case "vm-init-sys-disk":
 		return svc.InitSysDisk(ctx, id, req.Params)
 	case "vm-fs-freeze-all":
@@ -33,6 +39,70 @@ func (svc *Boar) RawEngine(ctx context.Context, id string, req types.RawEngineRe
 		return svc.listVolumes(ctx, id)
 	case "vm-fs-freeze-status":
 		return svc.fsFreezeStatus(ctx, id)
+	case "vm-apply":
+		return svc.rawApplyGuest(ctx, id, req.Params)
+	case "vm-rescue":
+		return svc.rawRescueGuest(ctx, id, req.Params)
+	case "vm-unrescue":
+		return svc.rawUnrescueGuest(ctx, id)
+	case "vm-attach-iso":
+		return svc.rawAttachISO(ctx, id, req.Params)
+	case "vm-eject-iso":
+		return svc.rawEjectISO(ctx, id)
+	case "vm-set-boot-order":
+		return svc.rawSetBootOrder(ctx, id, req.Params)
+	case "vm-checkpoint-create":
+		return svc.rawCreateCheckpoint(ctx, id, req.Params)
+	case "vm-checkpoint-list":
+		return svc.rawListCheckpoints(ctx, id)
+	case "vm-checkpoint-revert":
+		return svc.rawRevertCheckpoint(ctx, id, req.Params)
+	case "vm-checkpoint-delete":
+		return svc.rawDeleteCheckpoint(ctx, id, req.Params)
+	case "vm-dump":
+		return svc.rawDumpGuest(ctx, id, req.Params)
+	case "vm-dump-status":
+		return svc.rawGetGuestDumpStatus(ctx, id)
+	case "vm-dump-list":
+		return svc.rawListGuestDumps(ctx, id)
+	case "vm-dump-read":
+		return svc.rawReadGuestDump(ctx, id, req.Params)
+	case "vm-update-qos":
+		return svc.rawUpdateGuestQoS(ctx, id, req.Params)
+	case "vm-resize-flavor":
+		return svc.rawResizeFlavor(ctx, id, req.Params)
+	case "flavor-create":
+		return svc.rawCreateFlavor(ctx, req.Params)
+	case "flavor-get":
+		return svc.rawGetFlavor(ctx, req.Params)
+	case "flavor-list":
+		return svc.rawListFlavors(ctx)
+	case "flavor-delete":
+		return svc.rawDeleteFlavor(ctx, req.Params)
+	case "throttle-group-create":
+		return svc.rawCreateThrottleGroup(ctx, req.Params)
+	case "throttle-group-get":
+		return svc.rawGetThrottleGroup(ctx, req.Params)
+	case "throttle-group-list":
+		return svc.rawListThrottleGroups(ctx)
+	case "throttle-group-update":
+		return svc.rawUpdateThrottleGroup(ctx, req.Params)
+	case "throttle-group-delete":
+		return svc.rawDeleteThrottleGroup(ctx, req.Params)
+	case "host-cpu":
+		return svc.rawHostCPU(ctx)
+	case "host-cpu-baseline":
+		return svc.rawBaselineCPU(ctx)
+	case "host-deregister":
+		return svc.rawDeregisterHost(ctx)
+	case "host-maintenance-enter":
+		return svc.rawEnterMaintenance(ctx, req.Params)
+	case "host-maintenance-exit":
+		return svc.rawExitMaintenance(ctx)
+	case "host-maintenance-get":
+		return svc.rawGetMaintenance(ctx)
+	case "event-list":
+		return svc.rawListEvents(ctx, req.Params)
 	default:
 		return types.RawEngineResp{}, errors.Errorf("invalid operation %s", req.Op)
 	}
@@ -106,7 +176,7 @@ func (svc *Boar) fsFreezeAll(ctx context.Context, id string) (types.RawEngineRes
 			return nFS, nil
 		}
 	}
-	nFSRaw, err := svc.do(ctx, id, intertypes.FSThawOP, do, nil)
+	nFSRaw, err := svc.do(ctx, id, intertypes.FSFreezeOP, do, nil)
 	if err != nil {
 		return types.RawEngineResp{}, errors.Wrap(err, "")
 	}
@@ -190,7 +260,7 @@ func (svc *Boar) InitSysDisk(ctx context.Context, id string, rawParams []byte) (
 
 		return nil, nil //nolint
 	}
-	if _, err := svc.do(ctx, id, intertypes.StopOp, do, nil); err != nil {
+	if _, err := svc.do(ctx, id, intertypes.ResetSysDiskOp, do, nil); err != nil {
 		return types.RawEngineResp{}, errors.Wrap(err, "")
 	}
 	msg := `{"success":true}`
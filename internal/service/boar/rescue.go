@@ -0,0 +1,61 @@
+package boar
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/libyavirt/types"
+	"github.com/projecteru2/yavirt/configs"
+	intertypes "github.com/projecteru2/yavirt/internal/types"
+	"github.com/projecteru2/yavirt/internal/virt/guest"
+	"github.com/projecteru2/yavirt/pkg/terrors"
+	vmiFact "github.com/projecteru2/yavirt/pkg/vmimage/factory"
+)
+
+// RescueGuest boots the guest from the rescue image, the default one
+// of config is used if imgName is empty.
+func (svc *Boar) RescueGuest(ctx context.Context, id, imgName string) error {
+	if imgName == "" {
+		imgName = configs.Conf.RescueImage
+	}
+	if imgName == "" {
+		return errors.Wrapf(terrors.ErrInvalidValue, "no rescue image specified")
+	}
+	img, err := vmiFact.LoadImage(ctx, imgName)
+	if err != nil {
+		return errors.Wrapf(err, "failed to load image %s", imgName)
+	}
+	return svc.ctrl(ctx, id, intertypes.RescueOp, func(g *guest.Guest) error {
+		return g.Rescue(ctx, img)
+	}, nil)
+}
+
+// UnrescueGuest boots the guest from its system volume again.
+func (svc *Boar) UnrescueGuest(ctx context.Context, id string) error {
+	return svc.ctrl(ctx, id, intertypes.UnrescueOp, func(g *guest.Guest) error {
+		return g.Unrescue(ctx)
+	}, nil)
+}
+
+func (svc *Boar) rawRescueGuest(ctx context.Context, id string, params []byte) (types.RawEngineResp, error) {
+	args := &struct {
+		Image string `json:"image"`
+	}{}
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, args); err != nil {
+			return types.RawEngineResp{}, errors.Wrapf(err, "failed to unmarshal params")
+		}
+	}
+	if err := svc.RescueGuest(ctx, id, args.Image); err != nil {
+		return types.RawEngineResp{}, err
+	}
+	return types.RawEngineResp{Data: []byte(`{"success":true}`)}, nil
+}
+
+func (svc *Boar) rawUnrescueGuest(ctx context.Context, id string) (types.RawEngineResp, error) {
+	if err := svc.UnrescueGuest(ctx, id); err != nil {
+		return types.RawEngineResp{}, err
+	}
+	return types.RawEngineResp{Data: []byte(`{"success":true}`)}, nil
+}
@@ -0,0 +1,180 @@
+package boar
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/core/log"
+	"github.com/projecteru2/libyavirt/types"
+	"github.com/projecteru2/yavirt/internal/models"
+	intertypes "github.com/projecteru2/yavirt/internal/types"
+	"github.com/projecteru2/yavirt/internal/virt/guest"
+)
+
+// CreateThrottleGroup .
+func (svc *Boar) CreateThrottleGroup(_ context.Context, group *intertypes.ThrottleGroup) error {
+	if group.Scope == "" {
+		group.Scope = intertypes.ThrottleScopeGuest
+	}
+	return models.CreateThrottleGroup(group)
+}
+
+// GetThrottleGroup .
+func (svc *Boar) GetThrottleGroup(_ context.Context, name string) (*intertypes.ThrottleGroup, error) {
+	group, err := models.LoadThrottleGroup(name)
+	if err != nil {
+		return nil, err
+	}
+	group.Scope = intertypes.ThrottleScopeGuest
+	return group, nil
+}
+
+// ListThrottleGroups .
+func (svc *Boar) ListThrottleGroups(_ context.Context) ([]*intertypes.ThrottleGroup, error) {
+	groups, err := models.ListThrottleGroups()
+	if err != nil {
+		return nil, err
+	}
+	for _, group := range groups {
+		group.Scope = intertypes.ThrottleScopeGuest
+	}
+	return groups, nil
+}
+
+// UpdateThrottleGroup saves the new limits of group, and applies them to
+// the guests of this host without restart. The guests of the other hosts
+// take them by syncThrottleGroups on their hosts.
+func (svc *Boar) UpdateThrottleGroup(ctx context.Context, group *intertypes.ThrottleGroup) error {
+	if group.Scope == "" {
+		group.Scope = intertypes.ThrottleScopeGuest
+	}
+	if err := models.UpdateThrottleGroup(group); err != nil {
+		return err
+	}
+	return svc.applyThrottleGroups(ctx, []*intertypes.ThrottleGroup{group})
+}
+
+// syncThrottleGroups applies the throttle groups to the guests of this host
+// every interval, so the updates on the other hosts take effect here.
+func (svc *Boar) syncThrottleGroups(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	logger := log.WithFunc("boar.syncThrottleGroups")
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+		groups, err := models.ListThrottleGroups()
+		if err != nil {
+			logger.Warnf(ctx, "failed to list throttle groups: %s", err)
+			continue
+		}
+		if err := svc.applyThrottleGroups(ctx, groups); err != nil {
+			logger.Warnf(ctx, "failed to sync throttle groups: %s", err)
+		}
+	}
+}
+
+// applyThrottleGroups updates the volumes of local guests whose limits differ from their groups.
+func (svc *Boar) applyThrottleGroups(ctx context.Context, groups []*intertypes.ThrottleGroup) error {
+	if len(groups) == 0 {
+		return nil
+	}
+	ids, err := svc.ListLocalIDs(ctx, false)
+	if err != nil {
+		return err
+	}
+
+	logger := log.WithFunc("boar.applyThrottleGroups")
+	var errs error
+	for _, id := range ids {
+		g, err := svc.loadGuest(ctx, id, models.IgnoreLoadImageErrOption())
+		if err != nil {
+			// not managed by yavirt
+			continue
+		}
+		for _, group := range groups {
+			if len(g.StaleThrottleGroupVolumes(group)) == 0 {
+				continue
+			}
+			if err := svc.ctrl(ctx, id, intertypes.UpdateQoSOp, func(g *guest.Guest) error {
+				return g.UpdateThrottleGroup(ctx, group)
+			}, nil); err != nil {
+				logger.Warnf(ctx, "failed to update throttle group %s of guest %s: %s", group.Name, id, err)
+				errs = errors.CombineErrors(errs, err)
+			}
+		}
+	}
+	return errs
+}
+
+// DeleteThrottleGroup .
+func (svc *Boar) DeleteThrottleGroup(_ context.Context, name string) error {
+	return models.DeleteThrottleGroup(name)
+}
+
+type throttleGroupParams struct {
+	Name string `json:"name"`
+}
+
+func (svc *Boar) rawCreateThrottleGroup(ctx context.Context, params []byte) (types.RawEngineResp, error) {
+	group := &intertypes.ThrottleGroup{}
+	if err := json.Unmarshal(params, group); err != nil {
+		return types.RawEngineResp{}, errors.Wrapf(err, "failed to unmarshal params")
+	}
+	if err := svc.CreateThrottleGroup(ctx, group); err != nil {
+		return types.RawEngineResp{}, err
+	}
+	return types.RawEngineResp{Data: []byte(`{"success":true}`)}, nil
+}
+
+func (svc *Boar) rawGetThrottleGroup(ctx context.Context, params []byte) (types.RawEngineResp, error) {
+	args := &throttleGroupParams{}
+	if err := json.Unmarshal(params, args); err != nil {
+		return types.RawEngineResp{}, errors.Wrapf(err, "failed to unmarshal params")
+	}
+	group, err := svc.GetThrottleGroup(ctx, args.Name)
+	if err != nil {
+		return types.RawEngineResp{}, err
+	}
+	bs, _ := json.Marshal(group)
+	return types.RawEngineResp{Data: bs}, nil
+}
+
+func (svc *Boar) rawListThrottleGroups(ctx context.Context) (types.RawEngineResp, error) {
+	groups, err := svc.ListThrottleGroups(ctx)
+	if err != nil {
+		return types.RawEngineResp{}, err
+	}
+	bs, _ := json.Marshal(groups)
+	return types.RawEngineResp{Data: bs}, nil
+}
+
+func (svc *Boar) rawUpdateThrottleGroup(ctx context.Context, params []byte) (types.RawEngineResp, error) {
+	group := &intertypes.ThrottleGroup{}
+	if err := json.Unmarshal(params, group); err != nil {
+		return types.RawEngineResp{}, errors.Wrapf(err, "failed to unmarshal params")
+	}
+	if err := svc.UpdateThrottleGroup(ctx, group); err != nil {
+		return types.RawEngineResp{}, err
+	}
+	return types.RawEngineResp{Data: []byte(`{"success":true}`)}, nil
+}
+
+func (svc *Boar) rawDeleteThrottleGroup(ctx context.Context, params []byte) (types.RawEngineResp, error) {
+	args := &throttleGroupParams{}
+	if err := json.Unmarshal(params, args); err != nil {
+		return types.RawEngineResp{}, errors.Wrapf(err, "failed to unmarshal params")
+	}
+	if err := svc.DeleteThrottleGroup(ctx, args.Name); err != nil {
+		return types.RawEngineResp{}, err
+	}
+	return types.RawEngineResp{Data: []byte(`{"success":true}`)}, nil
+}
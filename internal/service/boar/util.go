@@ -19,6 +19,7 @@ import (
 
 	cpumemtypes "github.com/projecteru2/core/resource/plugins/cpumem/types"
 	stotypes "github.com/projecteru2/resource-storage/storage/types"
+	bdtypes "github.com/yuyang0/resource-bandwidth/bandwidth/types"
 	gputypes "github.com/yuyang0/resource-gpu/gpu/types"
 	hostdirtypes "github.com/yuyang0/resource-hostdir/hostdir/types"
 	rbdtypes "github.com/yuyang0/resource-rbd/rbd/types"
@@ -44,6 +45,16 @@ func extractGPU(resources map[string][]byte) (eParams *gputypes.EngineParams, er
 	return &ans, err
 }
 
+func extractBandwidth(resources map[string][]byte) (eParams *bdtypes.EngineParams, err error) {
+	bdRaw, ok := resources[intertypes.PluginNameBandwidth]
+	if !ok {
+		return nil, nil //nolint
+	}
+	var ans bdtypes.EngineParams
+	err = json.Unmarshal(bdRaw, &ans)
+	return &ans, err
+}
+
 func extractVols(resources map[string][]byte) ([]volume.Volume, error) { //nolint
 	var sysVol volume.Volume
 	vols := make([]volume.Volume, 1) // first place is for sys volume
@@ -21,6 +21,36 @@ type Service struct {
 	mock.Mock
 }
 
+// ApplyGuest provides a mock function with given fields: ctx, spec, dryRun
+func (_m *Service) ApplyGuest(ctx context.Context, spec *types.GuestSpec, dryRun bool) (*types.ApplyPlan, error) {
+	ret := _m.Called(ctx, spec, dryRun)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ApplyGuest")
+	}
+
+	var r0 *types.ApplyPlan
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *types.GuestSpec, bool) (*types.ApplyPlan, error)); ok {
+		return rf(ctx, spec, dryRun)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *types.GuestSpec, bool) *types.ApplyPlan); ok {
+		r0 = rf(ctx, spec, dryRun)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.ApplyPlan)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *types.GuestSpec, bool) error); ok {
+		r1 = rf(ctx, spec, dryRun)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // AttachGuest provides a mock function with given fields: ctx, id, stream, flags
 func (_m *Service) AttachGuest(ctx context.Context, id string, stream io.ReadWriteCloser, flags types.OpenConsoleFlags) error {
 	ret := _m.Called(ctx, id, stream, flags)
@@ -39,6 +69,54 @@ func (_m *Service) AttachGuest(ctx context.Context, id string, stream io.ReadWri
 	return r0
 }
 
+// AttachISO provides a mock function with given fields: ctx, id, src
+func (_m *Service) AttachISO(ctx context.Context, id string, src string) error {
+	ret := _m.Called(ctx, id, src)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AttachISO")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, id, src)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// BaselineCPU provides a mock function with given fields: ctx
+func (_m *Service) BaselineCPU(ctx context.Context) (*types.CPUModel, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for BaselineCPU")
+	}
+
+	var r0 *types.CPUModel
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (*types.CPUModel, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) *types.CPUModel); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.CPUModel)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CaptureGuest provides a mock function with given fields: ctx, id, imgName, overridden
 func (_m *Service) CaptureGuest(ctx context.Context, id string, imgName string, overridden bool) (*vmimagetypes.Image, error) {
 	ret := _m.Called(ctx, id, imgName, overridden)
@@ -151,6 +229,36 @@ func (_m *Service) ConnectNetwork(ctx context.Context, id string, network string
 	return r0, r1
 }
 
+// ConsoleLog provides a mock function with given fields: ctx, id, kb
+func (_m *Service) ConsoleLog(ctx context.Context, id string, kb int) ([]byte, error) {
+	ret := _m.Called(ctx, id, kb)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ConsoleLog")
+	}
+
+	var r0 []byte
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) ([]byte, error)); ok {
+		return rf(ctx, id, kb)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) []byte); ok {
+		r0 = rf(ctx, id, kb)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int) error); ok {
+		r1 = rf(ctx, id, kb)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ControlGuest provides a mock function with given fields: ctx, id, operation, force
 func (_m *Service) ControlGuest(ctx context.Context, id string, operation string, force bool) error {
 	ret := _m.Called(ctx, id, operation, force)
@@ -187,6 +295,84 @@ func (_m *Service) CopyToGuest(ctx context.Context, id string, dest string, cont
 	return r0
 }
 
+// CreateCheckpoint provides a mock function with given fields: ctx, id, name, desc
+func (_m *Service) CreateCheckpoint(ctx context.Context, id string, name string, desc string) (*types.Checkpoint, error) {
+	ret := _m.Called(ctx, id, name, desc)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateCheckpoint")
+	}
+
+	var r0 *types.Checkpoint
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) (*types.Checkpoint, error)); ok {
+		return rf(ctx, id, name, desc)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) *types.Checkpoint); ok {
+		r0 = rf(ctx, id, name, desc)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.Checkpoint)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string) error); ok {
+		r1 = rf(ctx, id, name, desc)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateConsoleToken provides a mock function with given fields: ctx, id, opts
+func (_m *Service) CreateConsoleToken(ctx context.Context, id string, opts types.ConsoleTokenOptions) (*types.ConsoleToken, error) {
+	ret := _m.Called(ctx, id, opts)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateConsoleToken")
+	}
+
+	var r0 *types.ConsoleToken
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, types.ConsoleTokenOptions) (*types.ConsoleToken, error)); ok {
+		return rf(ctx, id, opts)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, types.ConsoleTokenOptions) *types.ConsoleToken); ok {
+		r0 = rf(ctx, id, opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.ConsoleToken)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, types.ConsoleTokenOptions) error); ok {
+		r1 = rf(ctx, id, opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateFlavor provides a mock function with given fields: ctx, flavor
+func (_m *Service) CreateFlavor(ctx context.Context, flavor *types.Flavor) error {
+	ret := _m.Called(ctx, flavor)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateFlavor")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *types.Flavor) error); ok {
+		r0 = rf(ctx, flavor)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // CreateGuest provides a mock function with given fields: ctx, opts
 func (_m *Service) CreateGuest(ctx context.Context, opts types.GuestCreateOption) (*libyavirttypes.Guest, error) {
 	ret := _m.Called(ctx, opts)
@@ -235,6 +421,96 @@ func (_m *Service) CreateSnapshot(ctx context.Context, req libyavirttypes.Create
 	return r0
 }
 
+// CreateThrottleGroup provides a mock function with given fields: ctx, group
+func (_m *Service) CreateThrottleGroup(ctx context.Context, group *types.ThrottleGroup) error {
+	ret := _m.Called(ctx, group)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateThrottleGroup")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *types.ThrottleGroup) error); ok {
+		r0 = rf(ctx, group)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteCheckpoint provides a mock function with given fields: ctx, id, name
+func (_m *Service) DeleteCheckpoint(ctx context.Context, id string, name string) error {
+	ret := _m.Called(ctx, id, name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteCheckpoint")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, id, name)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteFlavor provides a mock function with given fields: ctx, name
+func (_m *Service) DeleteFlavor(ctx context.Context, name string) error {
+	ret := _m.Called(ctx, name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteFlavor")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, name)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteThrottleGroup provides a mock function with given fields: ctx, name
+func (_m *Service) DeleteThrottleGroup(ctx context.Context, name string) error {
+	ret := _m.Called(ctx, name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteThrottleGroup")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, name)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeregisterHost provides a mock function with given fields: ctx
+func (_m *Service) DeregisterHost(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeregisterHost")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DigestImage provides a mock function with given fields: ctx, imageName, local
 func (_m *Service) DigestImage(ctx context.Context, imageName string, local bool) ([]string, error) {
 	ret := _m.Called(ctx, imageName, local)
@@ -283,6 +559,84 @@ func (_m *Service) DisconnectNetwork(ctx context.Context, id string, network str
 	return r0
 }
 
+// DumpGuest provides a mock function with given fields: ctx, id, opts, progress
+func (_m *Service) DumpGuest(ctx context.Context, id string, opts *types.DumpOptions, progress chan<- types.DumpProgress) (*types.Dump, error) {
+	ret := _m.Called(ctx, id, opts, progress)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DumpGuest")
+	}
+
+	var r0 *types.Dump
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *types.DumpOptions, chan<- types.DumpProgress) (*types.Dump, error)); ok {
+		return rf(ctx, id, opts, progress)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *types.DumpOptions, chan<- types.DumpProgress) *types.Dump); ok {
+		r0 = rf(ctx, id, opts, progress)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.Dump)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *types.DumpOptions, chan<- types.DumpProgress) error); ok {
+		r1 = rf(ctx, id, opts, progress)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// EjectISO provides a mock function with given fields: ctx, id
+func (_m *Service) EjectISO(ctx context.Context, id string) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for EjectISO")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// EnterMaintenance provides a mock function with given fields: ctx, opts
+func (_m *Service) EnterMaintenance(ctx context.Context, opts types.MaintenanceOptions) (*types.Maintenance, error) {
+	ret := _m.Called(ctx, opts)
+
+	if len(ret) == 0 {
+		panic("no return value specified for EnterMaintenance")
+	}
+
+	var r0 *types.Maintenance
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, types.MaintenanceOptions) (*types.Maintenance, error)); ok {
+		return rf(ctx, opts)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, types.MaintenanceOptions) *types.Maintenance); ok {
+		r0 = rf(ctx, opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.Maintenance)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, types.MaintenanceOptions) error); ok {
+		r1 = rf(ctx, opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ExecExitCode provides a mock function with given fields: id, pid
 func (_m *Service) ExecExitCode(id string, pid int) (int, error) {
 	ret := _m.Called(id, pid)
@@ -299,11 +653,325 @@ func (_m *Service) ExecExitCode(id string, pid int) (int, error) {
 	if rf, ok := ret.Get(0).(func(string, int) int); ok {
 		r0 = rf(id, pid)
 	} else {
-		r0 = ret.Get(0).(int)
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, int) error); ok {
+		r1 = rf(id, pid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ExecuteGuest provides a mock function with given fields: ctx, id, commands
+func (_m *Service) ExecuteGuest(ctx context.Context, id string, commands []string) (*libyavirttypes.ExecuteGuestMessage, error) {
+	ret := _m.Called(ctx, id, commands)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExecuteGuest")
+	}
+
+	var r0 *libyavirttypes.ExecuteGuestMessage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string) (*libyavirttypes.ExecuteGuestMessage, error)); ok {
+		return rf(ctx, id, commands)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string) *libyavirttypes.ExecuteGuestMessage); ok {
+		r0 = rf(ctx, id, commands)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*libyavirttypes.ExecuteGuestMessage)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, []string) error); ok {
+		r1 = rf(ctx, id, commands)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ExitMaintenance provides a mock function with given fields: ctx
+func (_m *Service) ExitMaintenance(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExitMaintenance")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetFlavor provides a mock function with given fields: ctx, name
+func (_m *Service) GetFlavor(ctx context.Context, name string) (*types.Flavor, error) {
+	ret := _m.Called(ctx, name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetFlavor")
+	}
+
+	var r0 *types.Flavor
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*types.Flavor, error)); ok {
+		return rf(ctx, name)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *types.Flavor); ok {
+		r0 = rf(ctx, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.Flavor)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetGuest provides a mock function with given fields: ctx, id
+func (_m *Service) GetGuest(ctx context.Context, id string) (*libyavirttypes.Guest, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetGuest")
+	}
+
+	var r0 *libyavirttypes.Guest
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*libyavirttypes.Guest, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *libyavirttypes.Guest); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*libyavirttypes.Guest)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetGuestDumpStatus provides a mock function with given fields: ctx, id
+func (_m *Service) GetGuestDumpStatus(ctx context.Context, id string) (*types.DumpStatus, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetGuestDumpStatus")
+	}
+
+	var r0 *types.DumpStatus
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*types.DumpStatus, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *types.DumpStatus); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.DumpStatus)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetGuestIDList provides a mock function with given fields: ctx
+func (_m *Service) GetGuestIDList(ctx context.Context) ([]string, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetGuestIDList")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]string, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []string); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetGuestUUID provides a mock function with given fields: ctx, id
+func (_m *Service) GetGuestUUID(ctx context.Context, id string) (string, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetGuestUUID")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (string, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) string); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetMaintenance provides a mock function with given fields: ctx
+func (_m *Service) GetMaintenance(ctx context.Context) (*types.Maintenance, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetMaintenance")
+	}
+
+	var r0 *types.Maintenance
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (*types.Maintenance, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) *types.Maintenance); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.Maintenance)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetThrottleGroup provides a mock function with given fields: ctx, name
+func (_m *Service) GetThrottleGroup(ctx context.Context, name string) (*types.ThrottleGroup, error) {
+	ret := _m.Called(ctx, name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetThrottleGroup")
+	}
+
+	var r0 *types.ThrottleGroup
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*types.ThrottleGroup, error)); ok {
+		return rf(ctx, name)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *types.ThrottleGroup); ok {
+		r0 = rf(ctx, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.ThrottleGroup)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// HostCPU provides a mock function with given fields: ctx
+func (_m *Service) HostCPU(ctx context.Context) (string, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for HostCPU")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (string, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) string); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Info provides a mock function with given fields:
+func (_m *Service) Info() (*libyavirttypes.HostInfo, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Info")
+	}
+
+	var r0 *libyavirttypes.HostInfo
+	var r1 error
+	if rf, ok := ret.Get(0).(func() (*libyavirttypes.HostInfo, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() *libyavirttypes.HostInfo); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*libyavirttypes.HostInfo)
+		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string, int) error); ok {
-		r1 = rf(id, pid)
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -311,54 +979,42 @@ func (_m *Service) ExecExitCode(id string, pid int) (int, error) {
 	return r0, r1
 }
 
-// ExecuteGuest provides a mock function with given fields: ctx, id, commands
-func (_m *Service) ExecuteGuest(ctx context.Context, id string, commands []string) (*libyavirttypes.ExecuteGuestMessage, error) {
-	ret := _m.Called(ctx, id, commands)
+// IsHealthy provides a mock function with given fields: ctx
+func (_m *Service) IsHealthy(ctx context.Context) bool {
+	ret := _m.Called(ctx)
 
 	if len(ret) == 0 {
-		panic("no return value specified for ExecuteGuest")
-	}
-
-	var r0 *libyavirttypes.ExecuteGuestMessage
-	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, []string) (*libyavirttypes.ExecuteGuestMessage, error)); ok {
-		return rf(ctx, id, commands)
-	}
-	if rf, ok := ret.Get(0).(func(context.Context, string, []string) *libyavirttypes.ExecuteGuestMessage); ok {
-		r0 = rf(ctx, id, commands)
-	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*libyavirttypes.ExecuteGuestMessage)
-		}
+		panic("no return value specified for IsHealthy")
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string, []string) error); ok {
-		r1 = rf(ctx, id, commands)
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context) bool); ok {
+		r0 = rf(ctx)
 	} else {
-		r1 = ret.Error(1)
+		r0 = ret.Get(0).(bool)
 	}
 
-	return r0, r1
+	return r0
 }
 
-// GetGuest provides a mock function with given fields: ctx, id
-func (_m *Service) GetGuest(ctx context.Context, id string) (*libyavirttypes.Guest, error) {
+// ListCheckpoints provides a mock function with given fields: ctx, id
+func (_m *Service) ListCheckpoints(ctx context.Context, id string) ([]*types.Checkpoint, error) {
 	ret := _m.Called(ctx, id)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetGuest")
+		panic("no return value specified for ListCheckpoints")
 	}
 
-	var r0 *libyavirttypes.Guest
+	var r0 []*types.Checkpoint
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) (*libyavirttypes.Guest, error)); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]*types.Checkpoint, error)); ok {
 		return rf(ctx, id)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string) *libyavirttypes.Guest); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*types.Checkpoint); ok {
 		r0 = rf(ctx, id)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*libyavirttypes.Guest)
+			r0 = ret.Get(0).([]*types.Checkpoint)
 		}
 	}
 
@@ -371,29 +1027,29 @@ func (_m *Service) GetGuest(ctx context.Context, id string) (*libyavirttypes.Gue
 	return r0, r1
 }
 
-// GetGuestIDList provides a mock function with given fields: ctx
-func (_m *Service) GetGuestIDList(ctx context.Context) ([]string, error) {
-	ret := _m.Called(ctx)
+// ListEvents provides a mock function with given fields: ctx, since, limit
+func (_m *Service) ListEvents(ctx context.Context, since uint64, limit int) ([]types.Event, error) {
+	ret := _m.Called(ctx, since, limit)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetGuestIDList")
+		panic("no return value specified for ListEvents")
 	}
 
-	var r0 []string
+	var r0 []types.Event
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context) ([]string, error)); ok {
-		return rf(ctx)
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, int) ([]types.Event, error)); ok {
+		return rf(ctx, since, limit)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context) []string); ok {
-		r0 = rf(ctx)
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, int) []types.Event); ok {
+		r0 = rf(ctx, since, limit)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]string)
+			r0 = ret.Get(0).([]types.Event)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
-		r1 = rf(ctx)
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, int) error); ok {
+		r1 = rf(ctx, since, limit)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -401,27 +1057,29 @@ func (_m *Service) GetGuestIDList(ctx context.Context) ([]string, error) {
 	return r0, r1
 }
 
-// GetGuestUUID provides a mock function with given fields: ctx, id
-func (_m *Service) GetGuestUUID(ctx context.Context, id string) (string, error) {
-	ret := _m.Called(ctx, id)
+// ListFlavors provides a mock function with given fields: ctx
+func (_m *Service) ListFlavors(ctx context.Context) ([]*types.Flavor, error) {
+	ret := _m.Called(ctx)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetGuestUUID")
+		panic("no return value specified for ListFlavors")
 	}
 
-	var r0 string
+	var r0 []*types.Flavor
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) (string, error)); ok {
-		return rf(ctx, id)
+	if rf, ok := ret.Get(0).(func(context.Context) ([]*types.Flavor, error)); ok {
+		return rf(ctx)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string) string); ok {
-		r0 = rf(ctx, id)
+	if rf, ok := ret.Get(0).(func(context.Context) []*types.Flavor); ok {
+		r0 = rf(ctx)
 	} else {
-		r0 = ret.Get(0).(string)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*types.Flavor)
+		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = rf(ctx, id)
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -429,29 +1087,29 @@ func (_m *Service) GetGuestUUID(ctx context.Context, id string) (string, error)
 	return r0, r1
 }
 
-// Info provides a mock function with given fields:
-func (_m *Service) Info() (*libyavirttypes.HostInfo, error) {
-	ret := _m.Called()
+// ListGuestDumps provides a mock function with given fields: ctx, id
+func (_m *Service) ListGuestDumps(ctx context.Context, id string) ([]*types.Dump, error) {
+	ret := _m.Called(ctx, id)
 
 	if len(ret) == 0 {
-		panic("no return value specified for Info")
+		panic("no return value specified for ListGuestDumps")
 	}
 
-	var r0 *libyavirttypes.HostInfo
+	var r0 []*types.Dump
 	var r1 error
-	if rf, ok := ret.Get(0).(func() (*libyavirttypes.HostInfo, error)); ok {
-		return rf()
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]*types.Dump, error)); ok {
+		return rf(ctx, id)
 	}
-	if rf, ok := ret.Get(0).(func() *libyavirttypes.HostInfo); ok {
-		r0 = rf()
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*types.Dump); ok {
+		r0 = rf(ctx, id)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*libyavirttypes.HostInfo)
+			r0 = ret.Get(0).([]*types.Dump)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func() error); ok {
-		r1 = rf()
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -459,24 +1117,6 @@ func (_m *Service) Info() (*libyavirttypes.HostInfo, error) {
 	return r0, r1
 }
 
-// IsHealthy provides a mock function with given fields: ctx
-func (_m *Service) IsHealthy(ctx context.Context) bool {
-	ret := _m.Called(ctx)
-
-	if len(ret) == 0 {
-		panic("no return value specified for IsHealthy")
-	}
-
-	var r0 bool
-	if rf, ok := ret.Get(0).(func(context.Context) bool); ok {
-		r0 = rf(ctx)
-	} else {
-		r0 = ret.Get(0).(bool)
-	}
-
-	return r0
-}
-
 // ListImage provides a mock function with given fields: ctx, filter
 func (_m *Service) ListImage(ctx context.Context, filter string) ([]*vmimagetypes.Image, error) {
 	ret := _m.Called(ctx, filter)
@@ -537,6 +1177,36 @@ func (_m *Service) ListSnapshot(ctx context.Context, req libyavirttypes.ListSnap
 	return r0, r1
 }
 
+// ListThrottleGroups provides a mock function with given fields: ctx
+func (_m *Service) ListThrottleGroups(ctx context.Context) ([]*types.ThrottleGroup, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListThrottleGroups")
+	}
+
+	var r0 []*types.ThrottleGroup
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]*types.ThrottleGroup, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []*types.ThrottleGroup); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*types.ThrottleGroup)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Log provides a mock function with given fields: ctx, id, logPath, n, dest
 func (_m *Service) Log(ctx context.Context, id string, logPath string, n int, dest io.WriteCloser) error {
 	ret := _m.Called(ctx, id, logPath, n, dest)
@@ -702,6 +1372,43 @@ func (_m *Service) RawEngine(ctx context.Context, id string, req libyavirttypes.
 	return r0, r1
 }
 
+// ReadGuestDump provides a mock function with given fields: ctx, id, name, offset, size
+func (_m *Service) ReadGuestDump(ctx context.Context, id string, name string, offset int64, size int64) ([]byte, bool, error) {
+	ret := _m.Called(ctx, id, name, offset, size)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReadGuestDump")
+	}
+
+	var r0 []byte
+	var r1 bool
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int64, int64) ([]byte, bool, error)); ok {
+		return rf(ctx, id, name, offset, size)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int64, int64) []byte); ok {
+		r0 = rf(ctx, id, name, offset, size)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, int64, int64) bool); ok {
+		r1 = rf(ctx, id, name, offset, size)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, string, int64, int64) error); ok {
+		r2 = rf(ctx, id, name, offset, size)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // RemoveImage provides a mock function with given fields: ctx, imageName, force, prune
 func (_m *Service) RemoveImage(ctx context.Context, imageName string, force bool, prune bool) ([]string, error) {
 	ret := _m.Called(ctx, imageName, force, prune)
@@ -732,6 +1439,24 @@ func (_m *Service) RemoveImage(ctx context.Context, imageName string, force bool
 	return r0, r1
 }
 
+// RescueGuest provides a mock function with given fields: ctx, id, imgName
+func (_m *Service) RescueGuest(ctx context.Context, id string, imgName string) error {
+	ret := _m.Called(ctx, id, imgName)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RescueGuest")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, id, imgName)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // ResizeConsoleWindow provides a mock function with given fields: ctx, id, height, width
 func (_m *Service) ResizeConsoleWindow(ctx context.Context, id string, height uint, width uint) error {
 	ret := _m.Called(ctx, id, height, width)
@@ -786,6 +1511,126 @@ func (_m *Service) RestoreSnapshot(ctx context.Context, req libyavirttypes.Resto
 	return r0
 }
 
+// RevertCheckpoint provides a mock function with given fields: ctx, id, name
+func (_m *Service) RevertCheckpoint(ctx context.Context, id string, name string) error {
+	ret := _m.Called(ctx, id, name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevertCheckpoint")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, id, name)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Screenshot provides a mock function with given fields: ctx, id
+func (_m *Service) Screenshot(ctx context.Context, id string) ([]byte, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Screenshot")
+	}
+
+	var r0 []byte
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]byte, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []byte); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetBootOrder provides a mock function with given fields: ctx, id, order
+func (_m *Service) SetBootOrder(ctx context.Context, id string, order []string) error {
+	ret := _m.Called(ctx, id, order)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetBootOrder")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string) error); ok {
+		r0 = rf(ctx, id, order)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UnrescueGuest provides a mock function with given fields: ctx, id
+func (_m *Service) UnrescueGuest(ctx context.Context, id string) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UnrescueGuest")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateGuestQoS provides a mock function with given fields: ctx, id, qos
+func (_m *Service) UpdateGuestQoS(ctx context.Context, id string, qos *types.GuestQoS) error {
+	ret := _m.Called(ctx, id, qos)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateGuestQoS")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *types.GuestQoS) error); ok {
+		r0 = rf(ctx, id, qos)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateThrottleGroup provides a mock function with given fields: ctx, group
+func (_m *Service) UpdateThrottleGroup(ctx context.Context, group *types.ThrottleGroup) error {
+	ret := _m.Called(ctx, group)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateThrottleGroup")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *types.ThrottleGroup) error); ok {
+		r0 = rf(ctx, group)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Wait provides a mock function with given fields: ctx, id, block
 func (_m *Service) Wait(ctx context.Context, id string, block bool) (string, int, error) {
 	ret := _m.Called(ctx, id, block)
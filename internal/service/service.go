@@ -24,11 +24,21 @@ type Service interface { //nolint:interfacebloat
 	CreateGuest(ctx context.Context, opts intertypes.GuestCreateOption) (*types.Guest, error)
 	CaptureGuest(ctx context.Context, id string, imgName string, overridden bool) (uimg *vmitypes.Image, err error)
 	ResizeGuest(ctx context.Context, id string, opts *intertypes.GuestResizeOption) (err error)
+	UpdateGuestQoS(ctx context.Context, id string, qos *intertypes.GuestQoS) error
 	ControlGuest(ctx context.Context, id, operation string, force bool) (err error)
 	AttachGuest(ctx context.Context, id string, stream io.ReadWriteCloser, flags intertypes.OpenConsoleFlags) (err error)
 	ResizeConsoleWindow(ctx context.Context, id string, height, width uint) (err error)
+	CreateConsoleToken(ctx context.Context, id string, opts intertypes.ConsoleTokenOptions) (*intertypes.ConsoleToken, error)
+	ConsoleLog(ctx context.Context, id string, kb int) ([]byte, error)
+	Screenshot(ctx context.Context, id string) ([]byte, error)
 	Wait(ctx context.Context, id string, block bool) (msg string, code int, err error)
 	WatchGuestEvents(context.Context) (*utils.Watcher, error)
+	ApplyGuest(ctx context.Context, spec *intertypes.GuestSpec, dryRun bool) (*intertypes.ApplyPlan, error)
+	RescueGuest(ctx context.Context, id, imgName string) error
+	UnrescueGuest(ctx context.Context, id string) error
+	AttachISO(ctx context.Context, id, src string) error
+	EjectISO(ctx context.Context, id string) error
+	SetBootOrder(ctx context.Context, id string, order []string) error
 
 	// Guest utilities
 	ExecuteGuest(ctx context.Context, id string, commands []string) (*types.ExecuteGuestMessage, error)
@@ -44,6 +54,18 @@ type Service interface { //nolint:interfacebloat
 	CommitSnapshotByDay(ctx context.Context, id, volID string, day int) (err error)
 	RestoreSnapshot(ctx context.Context, req types.RestoreSnapshotReq) (err error)
 
+	// Checkpoint
+	CreateCheckpoint(ctx context.Context, id, name, desc string) (*intertypes.Checkpoint, error)
+	ListCheckpoints(ctx context.Context, id string) ([]*intertypes.Checkpoint, error)
+	RevertCheckpoint(ctx context.Context, id, name string) error
+	DeleteCheckpoint(ctx context.Context, id, name string) error
+
+	// Dump
+	DumpGuest(ctx context.Context, id string, opts *intertypes.DumpOptions, progress chan<- intertypes.DumpProgress) (*intertypes.Dump, error)
+	GetGuestDumpStatus(ctx context.Context, id string) (*intertypes.DumpStatus, error)
+	ListGuestDumps(ctx context.Context, id string) ([]*intertypes.Dump, error)
+	ReadGuestDump(ctx context.Context, id, name string, offset, size int64) ([]byte, bool, error)
+
 	// Network
 	NetworkList(ctx context.Context, drivers []string) ([]*types.Network, error)
 	ConnectNetwork(ctx context.Context, id, network, ipv4 string) (cidr string, err error)
@@ -57,4 +79,26 @@ type Service interface { //nolint:interfacebloat
 	DigestImage(ctx context.Context, imageName string, local bool) (digest []string, err error)
 
 	RawEngine(ctx context.Context, id string, req types.RawEngineReq) (types.RawEngineResp, error)
+
+	// Flavor
+	CreateFlavor(ctx context.Context, flavor *intertypes.Flavor) error
+	GetFlavor(ctx context.Context, name string) (*intertypes.Flavor, error)
+	ListFlavors(ctx context.Context) ([]*intertypes.Flavor, error)
+	DeleteFlavor(ctx context.Context, name string) error
+
+	// Throttle group
+	CreateThrottleGroup(ctx context.Context, group *intertypes.ThrottleGroup) error
+	GetThrottleGroup(ctx context.Context, name string) (*intertypes.ThrottleGroup, error)
+	ListThrottleGroups(ctx context.Context) ([]*intertypes.ThrottleGroup, error)
+	UpdateThrottleGroup(ctx context.Context, group *intertypes.ThrottleGroup) error
+	DeleteThrottleGroup(ctx context.Context, name string) error
+
+	// Host
+	HostCPU(ctx context.Context) (string, error)
+	BaselineCPU(ctx context.Context) (*intertypes.CPUModel, error)
+	DeregisterHost(ctx context.Context) error
+	EnterMaintenance(ctx context.Context, opts intertypes.MaintenanceOptions) (*intertypes.Maintenance, error)
+	ExitMaintenance(ctx context.Context) error
+	GetMaintenance(ctx context.Context) (*intertypes.Maintenance, error)
+	ListEvents(ctx context.Context, since uint64, limit int) ([]intertypes.Event, error)
 }
@@ -0,0 +1,53 @@
+package types
+
+import (
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/yavirt/pkg/terrors"
+)
+
+var checkpointNameRegexp = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+
+// Checkpoint is a whole-guest snapshot, it includes the memory and the device
+// state if the guest was running, so reverting resumes it where it was.
+type Checkpoint struct {
+	Name        string    `json:"name"`
+	GuestID     string    `json:"guest_id"`
+	Description string    `json:"description,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	// Running tells whether the memory is captured.
+	Running bool `json:"running"`
+
+	// the spec which the checkpoint is taken with, reverting requires the same one.
+	CPU     int      `json:"cpu"`
+	Memory  int64    `json:"memory"`
+	Volumes []string `json:"volumes"`
+}
+
+// CheckCheckpointName .
+func CheckCheckpointName(name string) error {
+	if !checkpointNameRegexp.MatchString(name) {
+		return errors.Wrapf(terrors.ErrInvalidValue, "invalid checkpoint name %q", name)
+	}
+	return nil
+}
+
+// CheckSpec checks the guest has the same CPU, memory and volumes as the checkpoint,
+// the domain of checkpoint can't be restored otherwise.
+func (c *Checkpoint) CheckSpec(cpu int, memory int64, volumes []string) error {
+	if c.CPU != cpu || c.Memory != memory {
+		return errors.Wrapf(terrors.ErrInvalidValue,
+			"checkpoint %s has %d CPUs and %d bytes memory, but the guest has %d and %d",
+			c.Name, c.CPU, c.Memory, cpu, memory)
+	}
+	a, b := slices.Clone(c.Volumes), slices.Clone(volumes)
+	slices.Sort(a)
+	slices.Sort(b)
+	if !slices.Equal(a, b) {
+		return errors.Wrapf(terrors.ErrInvalidValue, "the volumes are changed since checkpoint %s", c.Name)
+	}
+	return nil
+}
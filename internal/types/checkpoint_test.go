@@ -0,0 +1,27 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/projecteru2/yavirt/pkg/test/assert"
+)
+
+func TestCheckpointName(t *testing.T) {
+	for _, name := range []string{"pre-upgrade", "qa.v1_2", "0"} {
+		assert.NilErr(t, CheckCheckpointName(name))
+	}
+	for _, name := range []string{"", "-a", "a/b", "a b", string(make([]byte, 65))} {
+		assert.Err(t, CheckCheckpointName(name))
+	}
+}
+
+func TestCheckpointSpec(t *testing.T) {
+	cp := &Checkpoint{Name: "cp", CPU: 2, Memory: 1 << 30, Volumes: []string{"v1", "v2"}}
+	assert.NilErr(t, cp.CheckSpec(2, 1<<30, []string{"v2", "v1"}))
+	assert.Err(t, cp.CheckSpec(4, 1<<30, []string{"v1", "v2"}))
+	assert.Err(t, cp.CheckSpec(2, 2<<30, []string{"v1", "v2"}))
+	assert.Err(t, cp.CheckSpec(2, 1<<30, []string{"v1"}))
+	assert.Err(t, cp.CheckSpec(2, 1<<30, []string{"v1", "v3"}))
+	// the original volumes are untouched
+	assert.Equal(t, []string{"v1", "v2"}, cp.Volumes)
+}
@@ -50,6 +50,12 @@ type CloudInitConfig struct {
 	Files      map[string][]byte `json:"files"`
 	Commands   []string          `json:"commands"`
 
+	// user supplied user-data, it can be a cloud-config, a multipart MIME archive or a shell script.
+	// it is merged with the generated user-data by default, or replaces it when mode is "replace".
+	UserData     string `json:"user_data,omitempty"`
+	UserDataMode string `json:"user_data_mode,omitempty"`
+	VendorData   string `json:"vendor_data,omitempty"`
+
 	MAC       string           `json:"-"`
 	CIDR      string           `json:"-"`
 	MTU       int              `json:"-"`
@@ -108,6 +114,10 @@ func (ciCfg *CloudInitConfig) GenFilesContent() (string, string, string, error)
 	if err != nil {
 		return "", "", "", err
 	}
+	uData, err := ciCfg.finalizeUserData(string(uDataBS))
+	if err != nil {
+		return "", "", "", err
+	}
 
 	mDataBS, err := template.Render(mdataTmplFile, metaData, dataMap)
 	if err != nil {
@@ -117,7 +127,13 @@ func (ciCfg *CloudInitConfig) GenFilesContent() (string, string, string, error)
 	if err != nil {
 		return "", "", "", err
 	}
-	return string(uDataBS), string(mDataBS), string(networkBS), nil
+	return uData, string(mDataBS), string(networkBS), nil
+}
+
+// IsNoCloud tells whether the config has a payload for the NoCloud ISO,
+// the vendor-data alone is enough.
+func (ciCfg *CloudInitConfig) IsNoCloud() bool {
+	return ciCfg.Username != "" || ciCfg.Password != "" || ciCfg.UserData != "" || ciCfg.VendorData != ""
 }
 
 func (ciCfg *CloudInitConfig) GenerateISO(fname string) (err error) {
@@ -129,11 +145,20 @@ func (ciCfg *CloudInitConfig) GenerateISO(fname string) (err error) {
 	udataFname := filepath.Join(dir, "user-data")
 	mdataFname := filepath.Join(dir, "meta-data")
 	networkFname := filepath.Join(dir, "network-config")
+	vdataFname := filepath.Join(dir, "vendor-data")
 
 	udata, mdata, ndata, err := ciCfg.GenFilesContent()
 	if err != nil {
 		return
 	}
+	if ciCfg.VendorData != "" {
+		if err := ValidateUserData(ciCfg.VendorData); err != nil {
+			return errors.Wrap(err, "invalid vendor-data")
+		}
+		if err := os.WriteFile(vdataFname, []byte(ciCfg.VendorData), 0600); err != nil {
+			return errors.Wrap(err, "")
+		}
+	}
 	if err := os.WriteFile(udataFname, []byte(udata), 0600); err != nil {
 		return errors.Wrap(err, "")
 	}
@@ -147,16 +172,24 @@ func (ciCfg *CloudInitConfig) GenerateISO(fname string) (err error) {
 	// args := []string{
 	// 	"genisoimage", "-output", fname, "-V", "cidata", "-r", "-J", "user-data", "meta-data",
 	// }
+	return cloudLocalDS(dir, fname)
+}
+
+func cloudLocalDS(dir, fname string) error {
 	args := []string{
-		"cloud-localds", "--network-config=network-config", fname, "user-data", "meta-data",
+		"cloud-localds", "--network-config=network-config",
 	}
+	if _, err := os.Stat(filepath.Join(dir, "vendor-data")); err == nil {
+		args = append(args, "--vendor-data=vendor-data")
+	}
+	args = append(args, fname, "user-data", "meta-data")
 	cmd := exec.Command(args[0], args[1:]...) //nolint
 	cmd.Dir = dir
 	out, err := cmd.CombinedOutput()
 	if err != nil {
 		return errors.Wrapf(err, "failed to exec genisoimage %s", out)
 	}
-	return
+	return nil
 }
 
 func extractISO(isoPath, outputDir string) error {
@@ -190,17 +223,11 @@ func (ciCfg *CloudInitConfig) ReplaceUserData(fname string) (err error) {
 	udataFname := filepath.Join(dir, "user-data")
 
 	udata, _, _, err := ciCfg.GenFilesContent()
+	if err != nil {
+		return err
+	}
 	if err := os.WriteFile(udataFname, []byte(udata), 0600); err != nil {
 		return errors.Wrap(err, "")
 	}
-	args := []string{
-		"cloud-localds", "--network-config=network-config", fname, "user-data", "meta-data",
-	}
-	cmd := exec.Command(args[0], args[1:]...) //nolint
-	cmd.Dir = dir
-	out, err := cmd.CombinedOutput()
-	if err != nil {
-		return errors.Wrapf(err, "failed to exec genisoimage %s", out)
-	}
-	return
+	return cloudLocalDS(dir, fname)
 }
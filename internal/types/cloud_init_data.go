@@ -0,0 +1,242 @@
+package types
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/textproto"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/yavirt/pkg/terrors"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// UserDataModeMerge merges user supplied user-data with the generated one.
+	UserDataModeMerge = "merge"
+	// UserDataModeReplace uses user supplied user-data as is.
+	UserDataModeReplace = "replace"
+
+	cloudConfigHeader = "#cloud-config"
+	mimeBoundary      = "==YAVIRT-BOUNDARY=="
+)
+
+// user-data formats, see https://cloudinit.readthedocs.io/en/latest/explanation/format.html
+const (
+	userDataCloudConfig = "text/cloud-config"
+	userDataShellScript = "text/x-shellscript"
+	userDataBoothook    = "text/cloud-boothook"
+	userDataIncludeURL  = "text/x-include-url"
+	userDataMultipart   = "multipart/mixed"
+)
+
+// detectUserDataType returns the MIME type of the user-data by its first line.
+func detectUserDataType(data string) (string, error) {
+	trimmed := strings.TrimLeft(data, " \t\r\n")
+	switch {
+	case strings.HasPrefix(trimmed, cloudConfigHeader):
+		return userDataCloudConfig, nil
+	case strings.HasPrefix(trimmed, "#cloud-boothook"):
+		return userDataBoothook, nil
+	case strings.HasPrefix(trimmed, "#include"):
+		return userDataIncludeURL, nil
+	case strings.HasPrefix(trimmed, "#!"):
+		return userDataShellScript, nil
+	case strings.HasPrefix(trimmed, "Content-Type:"), strings.HasPrefix(trimmed, "MIME-Version:"):
+		return userDataMultipart, nil
+	default:
+		return "", errors.Wrapf(terrors.ErrInvalidValue, "unknown user-data format")
+	}
+}
+
+// finalizeUserData merges the user supplied user-data into the generated one
+// according to the mode, and validates the result.
+func (ciCfg *CloudInitConfig) finalizeUserData(generated string) (string, error) {
+	ans := generated
+	if ciCfg.UserData != "" {
+		var err error
+		switch ciCfg.UserDataMode {
+		case UserDataModeReplace:
+			ans = ciCfg.UserData
+		case UserDataModeMerge, "":
+			if ans, err = mergeUserData(generated, ciCfg.UserData); err != nil {
+				return "", err
+			}
+		default:
+			return "", errors.Wrapf(terrors.ErrInvalidValue, "invalid user-data mode %s", ciCfg.UserDataMode)
+		}
+	}
+	if err := ValidateUserData(ans); err != nil {
+		return "", errors.Wrap(err, "invalid user-data")
+	}
+	return ans, nil
+}
+
+// mergeUserData merges two user-data, the values of `user` take precedence.
+// Two cloud-configs are merged into one cloud-config, otherwise a multipart
+// MIME archive which contains both of them is generated.
+func mergeUserData(generated, user string) (string, error) {
+	userType, err := detectUserDataType(user)
+	if err != nil {
+		return "", err
+	}
+	if userType == userDataCloudConfig {
+		return mergeCloudConfig(generated, user)
+	}
+
+	parts := []userDataPart{{contentType: userDataCloudConfig, content: generated}}
+	if userType == userDataMultipart {
+		userParts, err := splitMultipart(user)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, userParts...)
+	} else {
+		parts = append(parts, userDataPart{contentType: userType, content: user})
+	}
+	return buildMultipart(parts)
+}
+
+func mergeCloudConfig(generated, user string) (string, error) {
+	base := map[string]any{}
+	if err := yaml.Unmarshal([]byte(generated), &base); err != nil {
+		return "", errors.Wrap(err, "failed to parse generated cloud-config")
+	}
+	override := map[string]any{}
+	if err := yaml.Unmarshal([]byte(user), &override); err != nil {
+		return "", errors.Wrap(err, "failed to parse user cloud-config")
+	}
+	bs, err := yaml.Marshal(mergeYAMLMap(base, override))
+	if err != nil {
+		return "", errors.Wrap(err, "")
+	}
+	return fmt.Sprintf("%s\n%s", cloudConfigHeader, bs), nil
+}
+
+// mergeYAMLMap merges src into dst, lists are concatenated,
+// maps are merged recursively and other values of src will overwrite dst's.
+func mergeYAMLMap(dst, src map[string]any) map[string]any {
+	if dst == nil {
+		dst = map[string]any{}
+	}
+	for k, sv := range src {
+		dv, ok := dst[k]
+		if !ok {
+			dst[k] = sv
+			continue
+		}
+		switch sv := sv.(type) {
+		case []any:
+			if dl, ok := dv.([]any); ok {
+				dst[k] = append(dl, sv...)
+				continue
+			}
+		case map[string]any:
+			if dm, ok := dv.(map[string]any); ok {
+				dst[k] = mergeYAMLMap(dm, sv)
+				continue
+			}
+		}
+		dst[k] = sv
+	}
+	return dst
+}
+
+type userDataPart struct {
+	contentType string
+	content     string
+}
+
+func splitMultipart(data string) ([]userDataPart, error) {
+	msg, err := mail.ReadMessage(strings.NewReader(strings.TrimLeft(data, " \t\r\n")))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse multipart user-data")
+	}
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse multipart user-data")
+	}
+	if !strings.HasPrefix(mediaType, "multipart/") {
+		return nil, errors.Wrapf(terrors.ErrInvalidValue, "unsupported user-data content type %s", mediaType)
+	}
+
+	var parts []userDataPart
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read multipart user-data")
+		}
+		bs, err := io.ReadAll(p)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read multipart user-data")
+		}
+		parts = append(parts, userDataPart{
+			contentType: p.Header.Get("Content-Type"),
+			content:     string(bs),
+		})
+	}
+	return parts, nil
+}
+
+func buildMultipart(parts []userDataPart) (string, error) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	if err := mw.SetBoundary(mimeBoundary); err != nil {
+		return "", errors.Wrap(err, "")
+	}
+	for _, part := range parts {
+		hdr := textproto.MIMEHeader{}
+		hdr.Set("Content-Type", fmt.Sprintf("%s; charset=\"utf-8\"", part.contentType))
+		hdr.Set("MIME-Version", "1.0")
+		w, err := mw.CreatePart(hdr)
+		if err != nil {
+			return "", errors.Wrap(err, "")
+		}
+		if _, err := io.WriteString(w, part.content); err != nil {
+			return "", errors.Wrap(err, "")
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return "", errors.Wrap(err, "")
+	}
+	return fmt.Sprintf("Content-Type: %s; boundary=\"%s\"\nMIME-Version: 1.0\n\n%s",
+		userDataMultipart, mimeBoundary, body.String()), nil
+}
+
+// ValidateUserData checks all cloud-configs in user-data(or vendor-data) are valid YAML.
+func ValidateUserData(data string) error {
+	typ, err := detectUserDataType(data)
+	if err != nil {
+		return err
+	}
+	switch typ {
+	case userDataCloudConfig:
+		obj := map[string]any{}
+		if err := yaml.Unmarshal([]byte(data), &obj); err != nil {
+			return errors.Wrap(err, "invalid cloud-config")
+		}
+	case userDataMultipart:
+		parts, err := splitMultipart(data)
+		if err != nil {
+			return err
+		}
+		for _, part := range parts {
+			if !strings.HasPrefix(part.contentType, userDataCloudConfig) {
+				continue
+			}
+			obj := map[string]any{}
+			if err := yaml.Unmarshal([]byte(part.content), &obj); err != nil {
+				return errors.Wrap(err, "invalid cloud-config part")
+			}
+		}
+	}
+	return nil
+}
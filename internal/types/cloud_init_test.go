@@ -58,3 +58,72 @@ func TestNetwork(t *testing.T) {
 	assert.True(t, strings.Contains(network, "via: 10.10.10.111"))
 	assert.True(t, strings.Contains(network, "on-link: true"))
 }
+
+func TestMergeUserData(t *testing.T) {
+	cfg := &CloudInitConfig{
+		Username: "root",
+		Password: "passwd",
+		Commands: []string{"echo hello"},
+		UserData: "#cloud-config\nruncmd:\n  - echo world\ntimezone: Asia/Shanghai\n",
+		OS: &vmitypes.OSInfo{
+			Type: "linux",
+		},
+	}
+	user, _, _, err := cfg.GenFilesContent()
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(user, "#cloud-config\n"))
+	assert.True(t, strings.Contains(user, "echo hello"))
+	assert.True(t, strings.Contains(user, "echo world"))
+	assert.True(t, strings.Contains(user, "timezone: Asia/Shanghai"))
+	assert.True(t, strings.Contains(user, "plain_text_passwd: passwd"))
+
+	// shell script is merged as a multipart archive
+	cfg.UserData = "#!/bin/bash\necho shell\n"
+	user, _, _, err = cfg.GenFilesContent()
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(user, "Content-Type: multipart/mixed"))
+	assert.True(t, strings.Contains(user, "Content-Type: text/cloud-config"))
+	assert.True(t, strings.Contains(user, "Content-Type: text/x-shellscript"))
+	assert.True(t, strings.Contains(user, "echo shell"))
+	parts, err := splitMultipart(user)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(parts))
+
+	// multipart is flattened
+	cfg.UserData = user
+	user, _, _, err = cfg.GenFilesContent()
+	assert.Nil(t, err)
+	parts, err = splitMultipart(user)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(parts))
+
+	cfg.UserDataMode = UserDataModeReplace
+	cfg.UserData = "#cloud-config\nhostname: foo\n"
+	user, _, _, err = cfg.GenFilesContent()
+	assert.Nil(t, err)
+	assert.Equal(t, cfg.UserData, user)
+
+	cfg.UserData = "#cloud-config\nruncmd: [\n"
+	_, _, _, err = cfg.GenFilesContent()
+	assert.Err(t, err)
+
+	cfg.UserDataMode = UserDataModeMerge
+	cfg.UserData = "unknown format"
+	_, _, _, err = cfg.GenFilesContent()
+	assert.Err(t, err)
+}
+
+func TestVendorDataOnly(t *testing.T) {
+	cfg := &CloudInitConfig{
+		OS: &vmitypes.OSInfo{
+			Type: "linux",
+		},
+	}
+	assert.False(t, cfg.IsNoCloud())
+
+	cfg.VendorData = "#cloud-config\nruncmd:\n  - echo vendor\n"
+	assert.True(t, cfg.IsNoCloud())
+	user, _, _, err := cfg.GenFilesContent()
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(user, "#cloud-config\n"))
+}
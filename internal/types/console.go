@@ -2,6 +2,7 @@ package types
 
 import (
 	"io"
+	"time"
 
 	"github.com/projecteru2/yavirt/pkg/libvirt"
 )
@@ -16,6 +17,13 @@ type OpenConsoleFlags struct {
 	libvirt.ConsoleFlags
 	Devname  string
 	Commands []string
+	// User is who attaches, it's recorded for the audit.
+	User string
+	// ClaimedUser is who the client claims to be, it isn't verified,
+	// so it's only recorded along with User.
+	ClaimedUser string
+	// ReadOnly attaches as a viewer, only one client can write at a time.
+	ReadOnly bool
 }
 
 // NewOpenConsoleFlags .
@@ -28,3 +36,28 @@ func NewOpenConsoleFlags(force, safe bool, cmds []string) OpenConsoleFlags {
 		Commands: cmds,
 	}
 }
+
+const (
+	ConsoleKindVNC    = "vnc"
+	ConsoleKindSerial = "serial"
+
+	// SerialConsoleDevname is the alias of the serial port, it's the one of `virsh console`.
+	SerialConsoleDevname = "serial0"
+)
+
+// ConsoleTokenOptions .
+type ConsoleTokenOptions struct {
+	Kind     string `json:"kind"`
+	User     string `json:"user,omitempty"`
+	ReadOnly bool   `json:"read_only,omitempty"` // only for the serial console
+}
+
+// ConsoleToken grants the access to a browser console of the guest.
+type ConsoleToken struct {
+	Token     string    `json:"token"`
+	Kind      string    `json:"kind"`
+	User      string    `json:"user,omitempty"`
+	ReadOnly  bool      `json:"read_only,omitempty"`
+	Path      string    `json:"path"` // the WebSocket endpoint including the token
+	ExpiresAt time.Time `json:"expires_at"`
+}
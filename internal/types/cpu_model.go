@@ -0,0 +1,93 @@
+package types
+
+import (
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/yavirt/pkg/terrors"
+)
+
+// CPUModelLabelKey is the label to select the CPU model of guest,
+// its value is a JSON of CPUModel.
+const CPUModelLabelKey = "instance/cpu-model"
+
+const (
+	// CPUModeHostPassthrough exposes the host CPU as is, the guest can't be migrated.
+	CPUModeHostPassthrough = "host-passthrough"
+	// CPUModeHostModel uses the named model which is closest to the host CPU.
+	CPUModeHostModel = "host-model"
+	// CPUModeCustom uses a named model, e.g. the baseline of a host pool.
+	CPUModeCustom = "custom"
+)
+
+// CPUModel .
+type CPUModel struct {
+	Mode  string `json:"mode"`
+	Model string `json:"model,omitempty"`
+	// +name requires the feature, -name disables it,
+	// or <policy>:<name> with policy force, require, optional, disable or forbid.
+	Features []string `json:"features,omitempty"`
+}
+
+// CPUFeature .
+type CPUFeature struct {
+	Name   string
+	Policy string
+}
+
+// Check .
+func (m *CPUModel) Check() error {
+	switch m.Mode {
+	case CPUModeHostPassthrough, CPUModeHostModel:
+		if m.Model != "" {
+			return errors.Wrapf(terrors.ErrInvalidValue, "the model can't be set in %s mode", m.Mode)
+		}
+	case CPUModeCustom:
+		if m.Model == "" {
+			return errors.Wrapf(terrors.ErrInvalidValue, "the model is required in %s mode", m.Mode)
+		}
+	default:
+		return errors.Wrapf(terrors.ErrInvalidValue, "invalid CPU mode %s", m.Mode)
+	}
+	_, err := m.ParseFeatures()
+	return err
+}
+
+// ParseFeatures .
+func (m *CPUModel) ParseFeatures() ([]CPUFeature, error) {
+	ans := make([]CPUFeature, 0, len(m.Features))
+	for _, raw := range m.Features {
+		feat, err := parseCPUFeature(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, err
+		}
+		ans = append(ans, feat)
+	}
+	return ans, nil
+}
+
+func parseCPUFeature(s string) (CPUFeature, error) {
+	var feat CPUFeature
+	switch {
+	case strings.HasPrefix(s, "+"):
+		feat = CPUFeature{Name: s[1:], Policy: "require"}
+	case strings.HasPrefix(s, "-"):
+		feat = CPUFeature{Name: s[1:], Policy: "disable"}
+	default:
+		policy, name, found := strings.Cut(s, ":")
+		if !found {
+			return feat, errors.Wrapf(terrors.ErrInvalidValue, "invalid CPU feature %s", s)
+		}
+		feat = CPUFeature{Name: name, Policy: policy}
+	}
+
+	switch feat.Policy {
+	case "force", "require", "optional", "disable", "forbid":
+	default:
+		return feat, errors.Wrapf(terrors.ErrInvalidValue, "invalid policy of CPU feature %s", s)
+	}
+	if feat.Name == "" {
+		return feat, errors.Wrapf(terrors.ErrInvalidValue, "invalid CPU feature %s", s)
+	}
+	return feat, nil
+}
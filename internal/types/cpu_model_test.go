@@ -0,0 +1,40 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/projecteru2/yavirt/pkg/test/assert"
+)
+
+func TestCPUModel(t *testing.T) {
+	m := &CPUModel{Mode: CPUModeHostPassthrough}
+	assert.NilErr(t, m.Check())
+
+	m = &CPUModel{Mode: CPUModeHostModel, Model: "Skylake-Server"}
+	assert.Err(t, m.Check())
+
+	m = &CPUModel{Mode: CPUModeCustom}
+	assert.Err(t, m.Check())
+
+	m = &CPUModel{Mode: "foo"}
+	assert.Err(t, m.Check())
+
+	m = &CPUModel{
+		Mode:     CPUModeCustom,
+		Model:    "Cascadelake-Server",
+		Features: []string{"+avx512f", "-vmx", "optional:pdpe1gb"},
+	}
+	assert.NilErr(t, m.Check())
+	feats, err := m.ParseFeatures()
+	assert.NilErr(t, err)
+	assert.Equal(t, []CPUFeature{
+		{Name: "avx512f", Policy: "require"},
+		{Name: "vmx", Policy: "disable"},
+		{Name: "pdpe1gb", Policy: "optional"},
+	}, feats)
+
+	for _, feat := range []string{"avx2", "+", "bad:avx2", "require:"} {
+		m.Features = []string{feat}
+		assert.Err(t, m.Check())
+	}
+}
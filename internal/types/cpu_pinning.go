@@ -0,0 +1,131 @@
+package types
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/yavirt/pkg/terrors"
+)
+
+// CPUPinningLabelKey is the label to request dedicated pCPUs for guest,
+// its value is a JSON of CPUPinningRequest.
+const CPUPinningLabelKey = "instance/cpu-pinning"
+
+// CPUPinningRequest .
+type CPUPinningRequest struct {
+	// nil means following the host's config
+	DedicatedEmulator *bool `json:"dedicated_emulator,omitempty"`
+}
+
+// NUMACell is a group of pCPUs which belong to the same host NUMA node,
+// it's mapped to a guest NUMA cell.
+type NUMACell struct {
+	Node int   `json:"node"`
+	CPUs []int `json:"cpus"`
+}
+
+// CPUPinning is the dedicated pCPUs of a guest,
+// the vCPUs are pinned to the pCPUs of cells in order.
+type CPUPinning struct {
+	Cells        []NUMACell `json:"cells"`
+	EmulatorCPUs []int      `json:"emulator_cpus,omitempty"`
+}
+
+// VCPUs returns the pCPUs which the vCPUs are pinned to, indexed by vCPU id.
+func (p *CPUPinning) VCPUs() []int {
+	var ans []int
+	for _, cell := range p.Cells {
+		ans = append(ans, cell.CPUs...)
+	}
+	return ans
+}
+
+// PCPUs returns all pCPUs occupied by the guest, including the emulator's.
+func (p *CPUPinning) PCPUs() []int {
+	return append(p.VCPUs(), p.EmulatorCPUs...)
+}
+
+// Nodes returns the host NUMA nodes of the guest.
+func (p *CPUPinning) Nodes() []int {
+	ans := make([]int, 0, len(p.Cells))
+	for _, cell := range p.Cells {
+		ans = append(ans, cell.Node)
+	}
+	return ans
+}
+
+// CellMemory distributes the memory to cells in proportion to their vCPUs,
+// the last cell takes the remainder.
+func (p *CPUPinning) CellMemory(memory int64) []int64 {
+	vcpus := len(p.VCPUs())
+	ans := make([]int64, len(p.Cells))
+	var allocated int64
+	for idx, cell := range p.Cells {
+		ans[idx] = memory * int64(len(cell.CPUs)) / int64(vcpus)
+		if idx == len(p.Cells)-1 {
+			ans[idx] = memory - allocated
+		}
+		allocated += ans[idx]
+	}
+	return ans
+}
+
+// ParseCPUSet parses the cpuset list format, e.g. 0-3,8,10-11.
+func ParseCPUSet(s string) ([]int, error) {
+	var ans []int
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		lo, hi, found := strings.Cut(part, "-")
+		start, err := strconv.Atoi(lo)
+		if err != nil {
+			return nil, errors.Wrapf(terrors.ErrInvalidValue, "invalid cpuset %s", s)
+		}
+		end := start
+		if found {
+			if end, err = strconv.Atoi(hi); err != nil || end < start {
+				return nil, errors.Wrapf(terrors.ErrInvalidValue, "invalid cpuset %s", s)
+			}
+		}
+		for id := start; id <= end; id++ {
+			ans = append(ans, id)
+		}
+	}
+	return ans, nil
+}
+
+// FormatCPUSet formats the pCPUs into cpuset list format.
+func FormatCPUSet(cpus []int) string {
+	if len(cpus) == 0 {
+		return ""
+	}
+	sorted := append([]int(nil), cpus...)
+	sort.Ints(sorted)
+
+	var parts []string
+	start, prev := sorted[0], sorted[0]
+	flush := func() {
+		if start == prev {
+			parts = append(parts, strconv.Itoa(start))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d-%d", start, prev))
+		}
+	}
+	for _, id := range sorted[1:] {
+		if id == prev {
+			continue
+		}
+		if id != prev+1 {
+			flush()
+			start = id
+		}
+		prev = id
+	}
+	flush()
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,34 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCPUSet(t *testing.T) {
+	cpus, err := ParseCPUSet("0-3, 8,10-11")
+	assert.Nil(t, err)
+	assert.Equal(t, []int{0, 1, 2, 3, 8, 10, 11}, cpus)
+	assert.Equal(t, "0-3,8,10-11", FormatCPUSet([]int{11, 10, 8, 3, 2, 1, 0, 1}))
+	assert.Equal(t, "", FormatCPUSet(nil))
+
+	cpus, err = ParseCPUSet("")
+	assert.Nil(t, err)
+	assert.Len(t, cpus, 0)
+
+	for _, s := range []string{"a", "3-1", "1-b"} {
+		_, err = ParseCPUSet(s)
+		assert.NotNil(t, err, s)
+	}
+}
+
+func TestCellMemory(t *testing.T) {
+	pinning := &CPUPinning{
+		Cells: []NUMACell{
+			{Node: 0, CPUs: []int{0, 1}},
+			{Node: 1, CPUs: []int{8}},
+		},
+	}
+	assert.Equal(t, []int64{682, 342}, pinning.CellMemory(1024))
+}
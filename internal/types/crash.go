@@ -0,0 +1,77 @@
+package types
+
+import (
+	"encoding/json"
+
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/yavirt/configs"
+	"github.com/projecteru2/yavirt/pkg/terrors"
+)
+
+// CrashPolicyLabelKey is the label to override the crash config of guest,
+// its value is a JSON of CrashPolicy, the absent fields take the config.
+const CrashPolicyLabelKey = "instance/crash-policy"
+
+// CrashPolicy is how a guest is watched and handled when it crashes.
+type CrashPolicy struct {
+	Watchdog bool   `json:"watchdog"`
+	PVPanic  bool   `json:"pvpanic"`
+	Action   string `json:"action"`
+	AutoDump bool   `json:"auto_dump"`
+}
+
+// NewCrashPolicy returns the policy of config overridden by the label.
+func NewCrashPolicy(cfg *configs.CrashConfig, labels map[string]string) (*CrashPolicy, error) {
+	p := &CrashPolicy{
+		Watchdog: cfg.Watchdog,
+		PVPanic:  cfg.PVPanic,
+		Action:   cfg.Action,
+		AutoDump: cfg.AutoDump,
+	}
+	if bs, ok := labels[CrashPolicyLabelKey]; ok {
+		if err := json.Unmarshal([]byte(bs), p); err != nil {
+			return nil, errors.Wrapf(terrors.ErrInvalidValue, "invalid label %s: %s", CrashPolicyLabelKey, bs)
+		}
+	}
+	if p.Action == "" {
+		p.Action = configs.CrashActionReset
+	}
+	if err := configs.CheckCrashAction(p.Action); err != nil {
+		return nil, errors.Wrapf(terrors.ErrInvalidValue, "%s", err)
+	}
+	return p, nil
+}
+
+// DumpFirst reports whether yavirtd collects the crash dump before the action,
+// then libvirt only pauses the guest and yavirtd takes the action after dumping.
+func (p *CrashPolicy) DumpFirst() bool {
+	return p.AutoDump || p.Action == configs.CrashActionDump
+}
+
+// WatchdogAction is the action of <watchdog> when it expires.
+func (p *CrashPolicy) WatchdogAction() string {
+	switch {
+	case p.DumpFirst():
+		return "pause"
+	case p.Action == configs.CrashActionPoweroff:
+		return "poweroff"
+	case p.Action == configs.CrashActionPause:
+		return "pause"
+	default:
+		return "reset"
+	}
+}
+
+// OnCrash is the <on_crash> of domain which is taken when the guest panics.
+func (p *CrashPolicy) OnCrash() string {
+	switch {
+	case p.DumpFirst():
+		return "preserve"
+	case p.Action == configs.CrashActionPoweroff:
+		return "destroy"
+	case p.Action == configs.CrashActionPause:
+		return "preserve"
+	default:
+		return "restart"
+	}
+}
@@ -0,0 +1,45 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/projecteru2/yavirt/configs"
+	"github.com/projecteru2/yavirt/pkg/test/assert"
+)
+
+func TestCrashPolicy(t *testing.T) {
+	cfg := &configs.CrashConfig{Watchdog: true, Action: configs.CrashActionReset}
+	p, err := NewCrashPolicy(cfg, nil)
+	assert.NilErr(t, err)
+	assert.True(t, p.Watchdog)
+	assert.False(t, p.DumpFirst())
+	assert.Equal(t, "reset", p.WatchdogAction())
+	assert.Equal(t, "restart", p.OnCrash())
+
+	labels := map[string]string{CrashPolicyLabelKey: `{"pvpanic":true,"action":"poweroff"}`}
+	p, err = NewCrashPolicy(cfg, labels)
+	assert.NilErr(t, err)
+	assert.True(t, p.Watchdog)
+	assert.True(t, p.PVPanic)
+	assert.Equal(t, "poweroff", p.WatchdogAction())
+	assert.Equal(t, "destroy", p.OnCrash())
+
+	labels[CrashPolicyLabelKey] = `{"action":"dump"}`
+	p, err = NewCrashPolicy(cfg, labels)
+	assert.NilErr(t, err)
+	assert.True(t, p.DumpFirst())
+	assert.Equal(t, "pause", p.WatchdogAction())
+	assert.Equal(t, "preserve", p.OnCrash())
+
+	labels[CrashPolicyLabelKey] = `{"action":"poweroff","auto_dump":true}`
+	p, err = NewCrashPolicy(cfg, labels)
+	assert.NilErr(t, err)
+	assert.True(t, p.DumpFirst())
+
+	labels[CrashPolicyLabelKey] = `{"action":"reboot"}`
+	_, err = NewCrashPolicy(cfg, labels)
+	assert.Err(t, err)
+	labels[CrashPolicyLabelKey] = `reset`
+	_, err = NewCrashPolicy(cfg, labels)
+	assert.Err(t, err)
+}
@@ -7,4 +7,24 @@ const (
 	Ubuntu = "ubuntu"
 	// CentOS .
 	CentOS = "centos"
+	// Debian .
+	Debian = "debian"
+	// Rocky .
+	Rocky = "rocky"
+	// AlmaLinux .
+	AlmaLinux = "almalinux"
+	// RHEL .
+	RHEL = "rhel"
+	// Fedora .
+	Fedora = "fedora"
+	// Alpine is the name used by image OSInfo.
+	Alpine = "alpine"
+	// AlpineLinux is the name reported by libguestfs.
+	AlpineLinux = "alpinelinux"
+	// Windows .
+	Windows = "windows"
 )
+
+// NetworkBackendLabelKey is the label to override the network backend of guest,
+// e.g. netplan, its value is the name of backend.
+const NetworkBackendLabelKey = "instance/network-backend"
@@ -0,0 +1,35 @@
+package types
+
+import "time"
+
+// DumpOptions .
+type DumpOptions struct {
+	// Format is one of the configs.DumpFormat*, the default is the one of config.
+	Format string `json:"format"`
+}
+
+// Dump is a memory dump of guest on host, which is named as
+// <created time>-<random suffix>.<format> in the dir of guest.
+type Dump struct {
+	GuestID   string    `json:"guest_id"`
+	Name      string    `json:"name"`
+	Path      string    `json:"path"`
+	Format    string    `json:"format"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// DumpProgress is the bytes of memory which have been dumped.
+type DumpProgress struct {
+	Processed uint64 `json:"processed"`
+	Total     uint64 `json:"total"`
+}
+
+// DumpStatus is the progress of the running dump of a guest,
+// or the result of the last dump if it's done.
+type DumpStatus struct {
+	Running  bool         `json:"running"`
+	Progress DumpProgress `json:"progress"`
+	Dump     *Dump        `json:"dump,omitempty"`
+	Error    string       `json:"error,omitempty"`
+}
@@ -3,22 +3,39 @@ package types
 import "time"
 
 const (
-	DestroyOp         Operator = "destroy"
-	DieOp             Operator = "die"
-	StopOp            Operator = "stop"
-	StartOp           Operator = "start"
-	SuspendOp         Operator = "suspend"
-	ResumeOp          Operator = "resume"
-	CreateOp          Operator = "create"
-	ExecuteOp         Operator = "execute"
-	ResizeOp          Operator = "resize"
-	ResetSysDiskOp    Operator = "reset-sys-disk"
-	FSFreezeOP        Operator = "fs-freeze"
-	FSThawOP          Operator = "fs-thaw"
-	MiscOp            Operator = "misc"
-	CreateSnapshotOp  Operator = "create-snapshot"
-	CommitSnapshotOp  Operator = "commit-snapshot"
-	RestoreSnapshotOp Operator = "restore-snapshot"
+	DestroyOp          Operator = "destroy"
+	DieOp              Operator = "die"
+	StopOp             Operator = "stop"
+	StartOp            Operator = "start"
+	SuspendOp          Operator = "suspend"
+	ResumeOp           Operator = "resume"
+	CreateOp           Operator = "create"
+	ExecuteOp          Operator = "execute"
+	ResizeOp           Operator = "resize"
+	ResetSysDiskOp     Operator = "reset-sys-disk"
+	FSFreezeOP         Operator = "fs-freeze"
+	FSThawOP           Operator = "fs-thaw"
+	MiscOp             Operator = "misc"
+	CreateSnapshotOp   Operator = "create-snapshot"
+	CommitSnapshotOp   Operator = "commit-snapshot"
+	RestoreSnapshotOp  Operator = "restore-snapshot"
+	RescueOp           Operator = "rescue"
+	UnrescueOp         Operator = "unrescue"
+	AttachISOOp        Operator = "attach-iso"
+	EjectISOOp         Operator = "eject-iso"
+	SetBootOrderOp     Operator = "set-boot-order"
+	HibernateOp        Operator = "hibernate"
+	CreateCheckpointOp Operator = "create-checkpoint"
+	RevertCheckpointOp Operator = "revert-checkpoint"
+	DeleteCheckpointOp Operator = "delete-checkpoint"
+	UpdateQoSOp        Operator = "update-qos"
+	CrashOp            Operator = "crash"
+	WatchdogOp         Operator = "watchdog"
+	DumpOp             Operator = "dump"
+	PauseOp            Operator = "pause"
+	AgentConnectOp     Operator = "agent-connect"
+	AgentDisconnectOp  Operator = "agent-disconnect"
+	BlockJobOp         Operator = "block-job"
 )
 
 const (
@@ -31,9 +48,24 @@ func (op Operator) String() string {
 	return string(op)
 }
 
+// Event is what happened to a guest, it's either done by yavirt or
+// observed from libvirt. Seq is assigned by the journal of yavirtd,
+// it increases monotonically per host, so the clients resume from it.
 type Event struct {
-	ID   string
-	Type string
-	Op   Operator
-	Time time.Time
+	Seq  uint64    `json:"seq,omitempty"`
+	ID   string    `json:"id"`
+	Type string    `json:"type"`
+	Op   Operator  `json:"op"`
+	Time time.Time `json:"time"`
+
+	// Status is the status of guest after the event, Error is why the operation failed,
+	// Detail describes the event, e.g. the reason of pause, the disk of block job.
+	Status string `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// Failed reports whether the operation of event failed.
+func (e *Event) Failed() bool {
+	return e.Error != ""
 }
@@ -0,0 +1,40 @@
+package types
+
+import (
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/yavirt/pkg/terrors"
+)
+
+const (
+	// FirmwareBIOS .
+	FirmwareBIOS = "bios"
+	// FirmwareUEFI .
+	FirmwareUEFI = "uefi"
+)
+
+// FirmwareConfig is the boot firmware of guest, the image's one can be
+// overridden by the label `instance/firmware`.
+type FirmwareConfig struct {
+	Type       string `json:"type"`
+	SecureBoot bool   `json:"secure_boot"`
+	TPM        bool   `json:"tpm"`
+}
+
+// IsUEFI .
+func (fw *FirmwareConfig) IsUEFI() bool {
+	return fw.Type == FirmwareUEFI
+}
+
+// Check .
+func (fw *FirmwareConfig) Check() error {
+	switch fw.Type {
+	case FirmwareBIOS:
+		if fw.SecureBoot {
+			return errors.Wrapf(terrors.ErrInvalidValue, "secure boot requires %s firmware", FirmwareUEFI)
+		}
+	case FirmwareUEFI:
+	default:
+		return errors.Wrapf(terrors.ErrInvalidValue, "invalid firmware %s", fw.Type)
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package types
+
+import (
+	"encoding/json"
+	"regexp"
+
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/yavirt/configs"
+	"github.com/projecteru2/yavirt/pkg/terrors"
+)
+
+// FlavorLabelKey is the label to create guest by a named instance type,
+// its value is the flavor name.
+const FlavorLabelKey = "instance/flavor"
+
+// NICBandwidthLabelKey is the label to limit the bandwidth of NIC,
+// its value is a JSON of NICBandwidth.
+const NICBandwidthLabelKey = "instance/nic-bandwidth"
+
+var flavorNameRegexp = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// VolumeQoS limits the IO of a volume, 0 means unlimited.
+type VolumeQoS struct {
+	ReadIOPS  int64 `json:"read_iops,omitempty"`
+	WriteIOPS int64 `json:"write_iops,omitempty"`
+	ReadBPS   int64 `json:"read_bps,omitempty"`
+	WriteBPS  int64 `json:"write_bps,omitempty"`
+}
+
+// Check .
+func (q *VolumeQoS) Check() error {
+	if q.ReadIOPS < 0 || q.WriteIOPS < 0 || q.ReadBPS < 0 || q.WriteBPS < 0 {
+		return errors.Wrapf(terrors.ErrInvalidValue, "invalid volume QoS %+v", *q)
+	}
+	return nil
+}
+
+// NICBandwidth is in bits per second.
+type NICBandwidth struct {
+	Average int64 `json:"average,omitempty"`
+	Peak    int64 `json:"peak,omitempty"`
+}
+
+// Check guards the bandwidth by limit, the bandwidth of host.
+func (bw *NICBandwidth) Check(limit int64) error {
+	if bw.Average < 0 || bw.Peak < 0 || bw.Average > limit || bw.Peak > limit {
+		return errors.Wrapf(terrors.ErrInvalidValue,
+			"invalid bandwidth %+v, it should be [0, %d]", *bw, limit)
+	}
+	if bw.Peak > 0 && bw.Peak < bw.Average {
+		return errors.Wrapf(terrors.ErrInvalidValue, "the peak bandwidth is less than the average one")
+	}
+	return nil
+}
+
+// Flavor is a named instance type, e.g. c4.m16.
+type Flavor struct {
+	Name   string `json:"name"`
+	CPU    int    `json:"cpu"`
+	Memory int64  `json:"memory"`
+	// in bytes, 0 means the virtual size of image
+	SysDiskSize int64              `json:"sys_disk_size,omitempty"`
+	VolumeQoS   *VolumeQoS         `json:"volume_qos,omitempty"`
+	Bandwidth   *NICBandwidth      `json:"bandwidth,omitempty"`
+	CPUPinning  *CPUPinningRequest `json:"cpu_pinning,omitempty"`
+	Hugepages   *HugepagesRequest  `json:"hugepages,omitempty"`
+}
+
+// Check guards the flavor by the resource limits of host.
+func (f *Flavor) Check(cfg *configs.ResourceConfig) error {
+	if !flavorNameRegexp.MatchString(f.Name) {
+		return errors.Wrapf(terrors.ErrInvalidValue, "invalid flavor name %q", f.Name)
+	}
+	if f.CPU < cfg.MinCPU || f.CPU > cfg.MaxCPU {
+		return errors.Wrapf(terrors.ErrInvalidValue,
+			"invalid CPU num: %d, it should be [%d, %d]", f.CPU, cfg.MinCPU, cfg.MaxCPU)
+	}
+	if f.Memory < cfg.MinMemory || f.Memory > cfg.MaxMemory {
+		return errors.Wrapf(terrors.ErrInvalidValue,
+			"invalid memory: %d, it should be [%d, %d]", f.Memory, cfg.MinMemory, cfg.MaxMemory)
+	}
+	if f.SysDiskSize != 0 && (f.SysDiskSize < cfg.MinVolumeCap || f.SysDiskSize > cfg.MaxVolumeCap) {
+		return errors.Wrapf(terrors.ErrInvalidValue,
+			"invalid sys disk size: %d, it should be [%d, %d]", f.SysDiskSize, cfg.MinVolumeCap, cfg.MaxVolumeCap)
+	}
+	if f.VolumeQoS != nil {
+		if err := f.VolumeQoS.Check(); err != nil {
+			return err
+		}
+	}
+	if f.Bandwidth != nil {
+		if err := f.Bandwidth.Check(cfg.Bandwidth); err != nil {
+			return err
+		}
+	}
+	if f.Hugepages != nil {
+		if _, err := f.Hugepages.SizeInKiB(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ApplyCreateOption fills the create options by the flavor,
+// the CPU and memory given explicitly must be consistent with the flavor,
+// and the labels given explicitly take precedence over the flavor.
+func (f *Flavor) ApplyCreateOption(opts *GuestCreateOption) error {
+	if (opts.CPU != 0 && opts.CPU != f.CPU) || (opts.Mem != 0 && opts.Mem != f.Memory) {
+		return errors.Wrapf(terrors.ErrInvalidValue,
+			"the CPU %d and memory %d conflict with flavor %s", opts.CPU, opts.Mem, f.Name)
+	}
+	opts.CPU = f.CPU
+	opts.Mem = f.Memory
+	opts.Flavor = f
+
+	if opts.Labels == nil {
+		opts.Labels = map[string]string{}
+	}
+	opts.Labels[FlavorLabelKey] = f.Name
+	labels := map[string]any{}
+	if f.Bandwidth != nil {
+		labels[NICBandwidthLabelKey] = f.Bandwidth
+	}
+	if f.CPUPinning != nil {
+		labels[CPUPinningLabelKey] = f.CPUPinning
+	}
+	if f.Hugepages != nil {
+		labels[HugepagesLabelKey] = f.Hugepages
+	}
+	for key, val := range labels {
+		if _, ok := opts.Labels[key]; ok {
+			continue
+		}
+		bs, err := json.Marshal(val)
+		if err != nil {
+			return errors.Wrap(err, "")
+		}
+		opts.Labels[key] = string(bs)
+	}
+	return nil
+}
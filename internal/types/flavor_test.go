@@ -0,0 +1,66 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/projecteru2/yavirt/configs"
+	"github.com/projecteru2/yavirt/pkg/test/assert"
+)
+
+func TestFlavorCheck(t *testing.T) {
+	cfg := &configs.ResourceConfig{
+		MinCPU:       1,
+		MaxCPU:       16,
+		MinMemory:    512 << 20,
+		MaxMemory:    64 << 30,
+		MinVolumeCap: 1 << 30,
+		MaxVolumeCap: 1 << 40,
+		Bandwidth:    10000000000,
+	}
+	f := &Flavor{Name: "c4.m16", CPU: 4, Memory: 16 << 30}
+	assert.NilErr(t, f.Check(cfg))
+
+	f.SysDiskSize = 40 << 30
+	f.Bandwidth = &NICBandwidth{Average: 1000000000, Peak: 2000000000}
+	f.Hugepages = &HugepagesRequest{Size: "2M"}
+	assert.NilErr(t, f.Check(cfg))
+
+	for _, invalid := range []*Flavor{
+		{Name: "", CPU: 4, Memory: 16 << 30},
+		{Name: "c4/m16", CPU: 4, Memory: 16 << 30},
+		{Name: "c32.m16", CPU: 32, Memory: 16 << 30},
+		{Name: "c4.m128", CPU: 4, Memory: 128 << 30},
+		{Name: "c4.m16", CPU: 4, Memory: 16 << 30, SysDiskSize: 1 << 20},
+		{Name: "c4.m16", CPU: 4, Memory: 16 << 30, Bandwidth: &NICBandwidth{Average: 20000000000}},
+		{Name: "c4.m16", CPU: 4, Memory: 16 << 30, Bandwidth: &NICBandwidth{Average: 2000, Peak: 1000}},
+		{Name: "c4.m16", CPU: 4, Memory: 16 << 30, Hugepages: &HugepagesRequest{Size: "4K"}},
+	} {
+		assert.Err(t, invalid.Check(cfg))
+	}
+}
+
+func TestFlavorApplyCreateOption(t *testing.T) {
+	f := &Flavor{
+		Name:       "c4.m16",
+		CPU:        4,
+		Memory:     16 << 30,
+		Bandwidth:  &NICBandwidth{Average: 1000000000},
+		CPUPinning: &CPUPinningRequest{},
+	}
+	opts := &GuestCreateOption{
+		Labels: map[string]string{CPUPinningLabelKey: `{"dedicated_emulator":true}`},
+	}
+	assert.NilErr(t, f.ApplyCreateOption(opts))
+	assert.Equal(t, 4, opts.CPU)
+	assert.Equal(t, int64(16<<30), opts.Mem)
+	assert.Equal(t, f, opts.Flavor)
+	assert.Equal(t, "c4.m16", opts.Labels[FlavorLabelKey])
+	assert.Equal(t, `{"average":1000000000}`, opts.Labels[NICBandwidthLabelKey])
+	// the explicit label takes precedence
+	assert.Equal(t, `{"dedicated_emulator":true}`, opts.Labels[CPUPinningLabelKey])
+	_, ok := opts.Labels[HugepagesLabelKey]
+	assert.False(t, ok)
+
+	opts = &GuestCreateOption{CPU: 8}
+	assert.Err(t, f.ApplyCreateOption(opts))
+}
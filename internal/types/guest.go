@@ -17,6 +17,8 @@ type GuestCreateOption struct {
 	Lambda    bool
 	Stdin     bool
 	Resources map[string][]byte
+	// resolved from the flavor label
+	Flavor *Flavor
 }
 
 func ConvertGRPCCreateOptions(opts *pb.CreateGuestOptions) GuestCreateOption {
@@ -47,6 +49,8 @@ type GuestResizeOption struct {
 	Mem       int64
 	Volumes   []virttypes.Volume
 	Resources map[string][]byte
+	// resize to the CPU and memory of the flavor
+	Flavor string
 }
 
 func ConvertGRPCResizeOptions(opts *pb.ResizeGuestOptions) *GuestResizeOption {
@@ -0,0 +1,381 @@
+package types
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	coreutils "github.com/projecteru2/core/utils"
+	"github.com/projecteru2/yavirt/pkg/terrors"
+)
+
+const (
+	// ApplyOpCreate .
+	ApplyOpCreate = "create"
+	// ApplyOpResize .
+	ApplyOpResize = "resize"
+	// ApplyOpDetachVolume .
+	ApplyOpDetachVolume = "detach-volume"
+	// ApplyOpAttachVolume .
+	ApplyOpAttachVolume = "attach-volume"
+	// ApplyOpAmplifyVolume .
+	ApplyOpAmplifyVolume = "amplify-volume"
+	// ApplyOpDisconnectNetwork .
+	ApplyOpDisconnectNetwork = "disconnect-network"
+	// ApplyOpConnectNetwork .
+	ApplyOpConnectNetwork = "connect-network"
+	// ApplyOpUpdateLabels .
+	ApplyOpUpdateLabels = "update-labels"
+)
+
+// GuestSpec is the desired state of a guest, an empty ID means creating a new guest.
+type GuestSpec struct {
+	ID        string `json:"id,omitempty" yaml:"id,omitempty"`
+	Image     string `json:"image" yaml:"image"`
+	ImageUser string `json:"image_user,omitempty" yaml:"image_user,omitempty"`
+	// CPU and memory are taken from the flavor if it's set
+	Flavor string `json:"flavor,omitempty" yaml:"flavor,omitempty"`
+	CPU    int    `json:"cpu,omitempty" yaml:"cpu,omitempty"`
+	// in bytes or human readable, e.g. 4G
+	Memory string `json:"memory,omitempty" yaml:"memory,omitempty"`
+	// the mode of the primary network, e.g. calico
+	Network       string            `json:"network,omitempty" yaml:"network,omitempty"`
+	ExtraNetworks []NetworkSpec     `json:"extra_networks,omitempty" yaml:"extra_networks,omitempty"`
+	Volumes       []VolumeSpec      `json:"volumes,omitempty" yaml:"volumes,omitempty"`
+	Labels        map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	CloudInit     *CloudInitSpec    `json:"cloud_init,omitempty" yaml:"cloud_init,omitempty"`
+}
+
+// NetworkSpec is an extra network of guest.
+type NetworkSpec struct {
+	Name string `json:"name" yaml:"name"`
+	// empty means allocating one
+	IPv4 string `json:"ipv4,omitempty" yaml:"ipv4,omitempty"`
+}
+
+// VolumeSpec is a data volume of guest, it's identified by the mount dir.
+type VolumeSpec struct {
+	Source string `json:"source,omitempty" yaml:"source,omitempty"`
+	Mount  string `json:"mount" yaml:"mount"`
+	// in bytes or human readable, e.g. 50G
+	Size string `json:"size" yaml:"size"`
+	// the name of throttle group which the volume shares the IO budget of
+	ThrottleGroup string `json:"throttle_group,omitempty" yaml:"throttle_group,omitempty"`
+}
+
+// CloudInitSpec is the part of CloudInitConfig which can be declared,
+// it only takes effect while creating guest.
+type CloudInitSpec struct {
+	Username     string `json:"username,omitempty" yaml:"username,omitempty"`
+	Password     string `json:"password,omitempty" yaml:"password,omitempty"`
+	SSHPubKey    string `json:"ssh_pub_key,omitempty" yaml:"ssh_pub_key,omitempty"`
+	Hostname     string `json:"hostname,omitempty" yaml:"hostname,omitempty"`
+	UserData     string `json:"user_data,omitempty" yaml:"user_data,omitempty"`
+	UserDataMode string `json:"user_data_mode,omitempty" yaml:"user_data_mode,omitempty"`
+	VendorData   string `json:"vendor_data,omitempty" yaml:"vendor_data,omitempty"`
+}
+
+// Config converts the spec to the config of cloud-init label.
+func (s *CloudInitSpec) Config() *CloudInitConfig {
+	return &CloudInitConfig{
+		Username:     s.Username,
+		Password:     s.Password,
+		SSHPubKey:    s.SSHPubKey,
+		Hostname:     s.Hostname,
+		UserData:     s.UserData,
+		UserDataMode: s.UserDataMode,
+		VendorData:   s.VendorData,
+	}
+}
+
+// appliedTo reports whether the fields set in the spec equal to the current ones,
+// the current config contains the generated fields, e.g. password.
+func (s *CloudInitSpec) appliedTo(current *CloudInitSpec) bool {
+	if current == nil {
+		current = &CloudInitSpec{}
+	}
+	for _, pair := range [][2]string{
+		{s.Username, current.Username},
+		{s.Password, current.Password},
+		{s.SSHPubKey, current.SSHPubKey},
+		{s.Hostname, current.Hostname},
+		{s.UserData, current.UserData},
+		{s.UserDataMode, current.UserDataMode},
+		{s.VendorData, current.VendorData},
+	} {
+		if pair[0] != "" && pair[0] != pair[1] {
+			return false
+		}
+	}
+	return true
+}
+
+// ApplyAction is a step to converge the guest to its spec.
+type ApplyAction struct {
+	Op     string `json:"op"`
+	Target string `json:"target,omitempty"`
+	Detail string `json:"detail,omitempty"`
+}
+
+func (a ApplyAction) String() string {
+	return strings.TrimSpace(fmt.Sprintf("%s %s %s", a.Op, a.Target, a.Detail))
+}
+
+// ApplyPlan .
+type ApplyPlan struct {
+	ID      string        `json:"id,omitempty"`
+	Actions []ApplyAction `json:"actions"`
+	// the differences which can't be applied to an existing guest
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// MemoryInBytes .
+func (s *GuestSpec) MemoryInBytes() (int64, error) {
+	mem, err := coreutils.ParseRAMInHuman(s.Memory)
+	if err != nil {
+		return 0, errors.Wrapf(terrors.ErrInvalidValue, "invalid memory %s", s.Memory)
+	}
+	return mem, nil
+}
+
+// SizeInBytes .
+func (v *VolumeSpec) SizeInBytes() (int64, error) {
+	size, err := coreutils.ParseRAMInHuman(v.Size)
+	if err != nil {
+		return 0, errors.Wrapf(terrors.ErrInvalidValue, "invalid size %s of volume %s", v.Size, v.Mount)
+	}
+	return size, nil
+}
+
+// Binding returns the volume in the format of storage plugin, src:dst:flags:size.
+func (v *VolumeSpec) Binding() string {
+	return fmt.Sprintf("%s:%s:rw:%s", v.Source, v.Mount, v.Size)
+}
+
+// Check .
+func (s *GuestSpec) Check() error {
+	if s.Image == "" {
+		return errors.Wrapf(terrors.ErrInvalidValue, "image is required")
+	}
+	if _, err := s.MemoryInBytes(); err != nil {
+		return err
+	}
+	mounts := map[string]struct{}{}
+	for i := range s.Volumes {
+		vol := &s.Volumes[i]
+		if vol.Mount == "" || vol.Mount == "/" {
+			return errors.Wrapf(terrors.ErrInvalidValue, "invalid mount dir %q of volume", vol.Mount)
+		}
+		if _, ok := mounts[vol.Mount]; ok {
+			return errors.Wrapf(terrors.ErrInvalidValue, "duplicated volume %s", vol.Mount)
+		}
+		mounts[vol.Mount] = struct{}{}
+		if size, err := vol.SizeInBytes(); err != nil {
+			return err
+		} else if size <= 0 {
+			return errors.Wrapf(terrors.ErrInvalidValue, "the size of volume %s is required", vol.Mount)
+		}
+	}
+	names := map[string]struct{}{}
+	for _, netw := range s.ExtraNetworks {
+		if netw.Name == "" {
+			return errors.Wrapf(terrors.ErrInvalidValue, "network name is required")
+		}
+		if _, ok := names[netw.Name]; ok {
+			return errors.Wrapf(terrors.ErrInvalidValue, "duplicated network %s", netw.Name)
+		}
+		names[netw.Name] = struct{}{}
+	}
+	return nil
+}
+
+// Diff plans the actions which converge the current guest to the spec,
+// a nil current means the guest doesn't exist.
+func (s *GuestSpec) Diff(current *GuestSpec) (*ApplyPlan, error) {
+	if err := s.Check(); err != nil {
+		return nil, err
+	}
+	if current == nil {
+		return &ApplyPlan{
+			Actions: []ApplyAction{{Op: ApplyOpCreate, Target: s.Image, Detail: fmt.Sprintf("cpu %d, memory %s", s.CPU, s.Memory)}},
+		}, nil
+	}
+
+	plan := &ApplyPlan{ID: current.ID, Actions: []ApplyAction{}}
+	if imageFullname(s.Image) != imageFullname(current.Image) {
+		plan.warnf("image %s -> %s requires recreating the guest, skipped", current.Image, s.Image)
+	}
+	if s.Network != "" && s.Network != current.Network {
+		plan.warnf("network %s -> %s requires recreating the guest, skipped", current.Network, s.Network)
+	}
+	if s.CloudInit != nil && !s.CloudInit.appliedTo(current.CloudInit) {
+		plan.warnf("cloud-init only takes effect while creating the guest, skipped")
+	}
+
+	if err := s.diffSpec(current, plan); err != nil {
+		return nil, err
+	}
+	if err := s.diffVolumes(current, plan); err != nil {
+		return nil, err
+	}
+	s.diffNetworks(current, plan)
+	s.diffLabels(current, plan)
+	return plan, nil
+}
+
+func (s *GuestSpec) diffSpec(current *GuestSpec, plan *ApplyPlan) error {
+	mem, err := s.MemoryInBytes()
+	if err != nil {
+		return err
+	}
+	curMem, err := current.MemoryInBytes()
+	if err != nil {
+		return err
+	}
+	cpu := s.CPU
+	if cpu == 0 {
+		cpu = current.CPU
+	}
+	if mem == 0 {
+		mem = curMem
+	}
+	if cpu != current.CPU || mem != curMem {
+		plan.Actions = append(plan.Actions, ApplyAction{
+			Op:     ApplyOpResize,
+			Detail: fmt.Sprintf("cpu %d -> %d, memory %d -> %d", current.CPU, cpu, curMem, mem),
+		})
+	}
+	return nil
+}
+
+func (s *GuestSpec) diffVolumes(current *GuestSpec, plan *ApplyPlan) error {
+	desired := map[string]*VolumeSpec{}
+	for i := range s.Volumes {
+		desired[s.Volumes[i].Mount] = &s.Volumes[i]
+	}
+	existing := map[string]*VolumeSpec{}
+	for i := range current.Volumes {
+		vol := &current.Volumes[i]
+		existing[vol.Mount] = vol
+		if _, ok := desired[vol.Mount]; !ok {
+			plan.Actions = append(plan.Actions, ApplyAction{Op: ApplyOpDetachVolume, Target: vol.Mount})
+		}
+	}
+
+	var amplified []ApplyAction
+	for i := range s.Volumes {
+		vol := &s.Volumes[i]
+		size, err := vol.SizeInBytes()
+		if err != nil {
+			return err
+		}
+		cur, ok := existing[vol.Mount]
+		if !ok {
+			plan.Actions = append(plan.Actions, ApplyAction{
+				Op:     ApplyOpAttachVolume,
+				Target: vol.Mount,
+				Detail: fmt.Sprintf("size %d", size),
+			})
+			continue
+		}
+		if vol.ThrottleGroup != cur.ThrottleGroup {
+			plan.warnf("throttle group of volume %s %q -> %q only takes effect while attaching, skipped",
+				vol.Mount, cur.ThrottleGroup, vol.ThrottleGroup)
+		}
+		curSize, err := cur.SizeInBytes()
+		if err != nil {
+			return err
+		}
+		switch {
+		case size > curSize:
+			amplified = append(amplified, ApplyAction{
+				Op:     ApplyOpAmplifyVolume,
+				Target: vol.Mount,
+				Detail: fmt.Sprintf("size %d -> %d", curSize, size),
+			})
+		case size < curSize:
+			plan.warnf("volume %s can't be shrunk from %d to %d, skipped", vol.Mount, curSize, size)
+		}
+	}
+	plan.Actions = append(plan.Actions, amplified...)
+	return nil
+}
+
+func (s *GuestSpec) diffNetworks(current *GuestSpec, plan *ApplyPlan) {
+	desired := map[string]struct{}{}
+	for _, netw := range s.ExtraNetworks {
+		desired[netw.Name] = struct{}{}
+	}
+	existing := map[string]struct{}{}
+	for _, netw := range current.ExtraNetworks {
+		existing[netw.Name] = struct{}{}
+		if _, ok := desired[netw.Name]; !ok {
+			plan.Actions = append(plan.Actions, ApplyAction{Op: ApplyOpDisconnectNetwork, Target: netw.Name})
+		}
+	}
+	for _, netw := range s.ExtraNetworks {
+		if _, ok := existing[netw.Name]; !ok {
+			plan.Actions = append(plan.Actions, ApplyAction{Op: ApplyOpConnectNetwork, Target: netw.Name, Detail: netw.IPv4})
+		}
+	}
+}
+
+func (s *GuestSpec) diffLabels(current *GuestSpec, plan *ApplyPlan) {
+	var changed []string
+	for key, val := range s.Labels {
+		if IsManagedLabel(key) {
+			continue
+		}
+		if cur, ok := current.Labels[key]; !ok || cur != val {
+			changed = append(changed, "+"+key)
+		}
+	}
+	for key := range current.Labels {
+		if IsManagedLabel(key) {
+			continue
+		}
+		if _, ok := s.Labels[key]; !ok {
+			changed = append(changed, "-"+key)
+		}
+	}
+	if len(changed) == 0 {
+		return
+	}
+	sort.Strings(changed)
+	plan.Actions = append(plan.Actions, ApplyAction{Op: ApplyOpUpdateLabels, Detail: strings.Join(changed, ",")})
+}
+
+// MergeLabels returns the labels of spec along with the managed ones of current labels.
+func (s *GuestSpec) MergeLabels(current map[string]string) map[string]string {
+	labels := map[string]string{}
+	for key, val := range current {
+		if IsManagedLabel(key) {
+			labels[key] = val
+		}
+	}
+	for key, val := range s.Labels {
+		if !IsManagedLabel(key) {
+			labels[key] = val
+		}
+	}
+	return labels
+}
+
+// IsManagedLabel reports whether the label is generated by yavirt,
+// e.g. the flavor and network mode, it can't be changed by apply.
+func IsManagedLabel(key string) bool {
+	return strings.HasPrefix(key, "instance/") || strings.HasPrefix(key, "network/")
+}
+
+// imageFullname appends the default tag, so ubuntu equals to ubuntu:latest.
+func imageFullname(name string) string {
+	if !strings.Contains(name[strings.LastIndex(name, "/")+1:], ":") {
+		return name + ":latest"
+	}
+	return name
+}
+
+func (p *ApplyPlan) warnf(format string, args ...any) {
+	p.Warnings = append(p.Warnings, fmt.Sprintf(format, args...))
+}
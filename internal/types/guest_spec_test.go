@@ -0,0 +1,104 @@
+package types
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/projecteru2/yavirt/pkg/test/assert"
+)
+
+func TestGuestSpecDiff(t *testing.T) {
+	spec := &GuestSpec{}
+	assert.NilErr(t, yaml.Unmarshal([]byte(`
+id: guest0
+image: ubuntu
+cpu: 4
+memory: 8G
+extra_networks:
+  - name: vpc1
+    ipv4: 10.0.0.10
+volumes:
+  - mount: /data
+    size: 100G
+  - mount: /logs
+    size: 10G
+labels:
+  app: web
+cloud_init:
+  username: ubuntu
+`), spec))
+
+	plan, err := spec.Diff(nil)
+	assert.NilErr(t, err)
+	assert.Equal(t, 1, len(plan.Actions))
+	assert.Equal(t, ApplyOpCreate, plan.Actions[0].Op)
+
+	current := &GuestSpec{
+		ID:     "guest0",
+		Image:  "ubuntu:latest",
+		CPU:    2,
+		Memory: "4294967296",
+		ExtraNetworks: []NetworkSpec{
+			{Name: "vpc0"},
+		},
+		Volumes: []VolumeSpec{
+			{Mount: "/data", Size: "53687091200"},
+			{Mount: "/tmp", Size: "1073741824"},
+		},
+		Labels: map[string]string{
+			"app":                 "api",
+			"owner":               "foo",
+			FlavorLabelKey:        "c2.m4",
+			"network/mode":        "calico",
+			"instance/cloud-init": `{"username":"ubuntu","password":"generated"}`,
+		},
+		CloudInit: &CloudInitSpec{Username: "ubuntu", Password: "generated"},
+	}
+	plan, err = spec.Diff(current)
+	assert.NilErr(t, err)
+	assert.Equal(t, "guest0", plan.ID)
+	assert.Equal(t, 0, len(plan.Warnings))
+	assert.Equal(t, []ApplyAction{
+		{Op: ApplyOpResize, Detail: "cpu 2 -> 4, memory 4294967296 -> 8589934592"},
+		{Op: ApplyOpDetachVolume, Target: "/tmp"},
+		{Op: ApplyOpAttachVolume, Target: "/logs", Detail: "size 10737418240"},
+		{Op: ApplyOpAmplifyVolume, Target: "/data", Detail: "size 53687091200 -> 107374182400"},
+		{Op: ApplyOpDisconnectNetwork, Target: "vpc0"},
+		{Op: ApplyOpConnectNetwork, Target: "vpc1", Detail: "10.0.0.10"},
+		{Op: ApplyOpUpdateLabels, Detail: "+app,-owner"},
+	}, plan.Actions)
+
+	labels := spec.MergeLabels(current.Labels)
+	assert.Equal(t, "web", labels["app"])
+	assert.Equal(t, "c2.m4", labels[FlavorLabelKey])
+	_, ok := labels["owner"]
+	assert.False(t, ok)
+
+	// converged
+	current = &GuestSpec{
+		ID:            "guest0",
+		Image:         "ubuntu:latest",
+		CPU:           4,
+		Memory:        "8589934592",
+		ExtraNetworks: []NetworkSpec{{Name: "vpc1"}},
+		Volumes:       []VolumeSpec{{Mount: "/logs", Size: "10G"}, {Mount: "/data", Size: "200G"}},
+		Labels:        map[string]string{"app": "web"},
+	}
+	spec.Image = "centos"
+	plan, err = spec.Diff(current)
+	assert.NilErr(t, err)
+	assert.Equal(t, 0, len(plan.Actions))
+	// image, cloud-init and shrinking volume
+	assert.Equal(t, 3, len(plan.Warnings))
+
+	spec.Volumes[0].ThrottleGroup = "g1"
+	plan, err = spec.Diff(current)
+	assert.NilErr(t, err)
+	assert.Equal(t, 4, len(plan.Warnings))
+	spec.Volumes[0].ThrottleGroup = ""
+
+	spec.Volumes = append(spec.Volumes, VolumeSpec{Mount: "/data", Size: "1G"})
+	_, err = spec.Diff(current)
+	assert.Err(t, err)
+}
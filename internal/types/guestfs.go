@@ -5,8 +5,16 @@ import guestfstypes "github.com/projecteru2/yavirt/internal/virt/guestfs/types"
 const (
 	// FstabFile .
 	FstabFile = guestfstypes.FstabFile
-	// EthUbuntuFileFmt .
+	// EthUbuntuFileFmt is also used by Debian's ifupdown.
 	EthUbuntuFileFmt = "/etc/network/interfaces.d/%s.cfg"
 	// EthCentOSFileFmt .
 	EthCentOSFileFmt = "/etc/sysconfig/network-scripts/ifcfg-%s"
+	// EthNetplanFileFmt .
+	EthNetplanFileFmt = "/etc/netplan/60-yavirt-%s.yaml"
+	// EthNMKeyfileFmt .
+	EthNMKeyfileFmt = "/etc/NetworkManager/system-connections/yavirt-%s.nmconnection"
+	// EthNetworkdFileFmt .
+	EthNetworkdFileFmt = "/etc/systemd/network/10-%s.network"
+	// EthAlpineFile .
+	EthAlpineFile = "/etc/network/interfaces"
 )
@@ -0,0 +1,58 @@
+package types
+
+import (
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/dustin/go-humanize"
+	"github.com/projecteru2/yavirt/pkg/terrors"
+)
+
+// HugepagesLabelKey is the label to back guest memory with hugepages,
+// its value is a JSON of HugepagesRequest.
+const HugepagesLabelKey = "instance/hugepages"
+
+// HugepagesRequest .
+type HugepagesRequest struct {
+	// 2M or 1G
+	Size string `json:"size"`
+}
+
+// SizeInKiB returns the page size in KiB.
+func (r *HugepagesRequest) SizeInKiB() (int64, error) {
+	size := strings.TrimSpace(r.Size)
+	if size == "" {
+		return 0, errors.Wrapf(terrors.ErrInvalidValue, "hugepage size is required")
+	}
+	// 2M, 2MB and 2MiB all mean 2MiB here
+	size = strings.TrimSuffix(strings.TrimSuffix(strings.ToUpper(size), "B"), "I")
+	bytes, err := humanize.ParseBytes(size + "iB")
+	if err != nil {
+		return 0, errors.Wrapf(terrors.ErrInvalidValue, "invalid hugepage size %s", r.Size)
+	}
+	switch kib := int64(bytes >> 10); kib {
+	case 2 << 10, 1 << 20:
+		return kib, nil
+	default:
+		return 0, errors.Wrapf(terrors.ErrInvalidValue, "unsupported hugepage size %s, it should be 2M or 1G", r.Size)
+	}
+}
+
+// HugepageInfo is the hugepage pool of a page size, from /sys/kernel/mm/hugepages.
+type HugepageInfo struct {
+	SizeKiB  int64 `json:"size_kib"`
+	Total    int64 `json:"total"`
+	Free     int64 `json:"free"`
+	Reserved int64 `json:"reserved"`
+	Surplus  int64 `json:"surplus"`
+}
+
+// Available returns the pages which can be allocated by new guests.
+func (info *HugepageInfo) Available() int64 {
+	return info.Free - info.Reserved
+}
+
+// Used .
+func (info *HugepageInfo) Used() int64 {
+	return info.Total - info.Free
+}
@@ -0,0 +1,108 @@
+package types
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/yavirt/pkg/terrors"
+)
+
+const (
+	// ISOLabelKey asks to install the guest from an ISO, the value is ISOInstall in JSON.
+	ISOLabelKey = "instance/iso"
+
+	BootDeviceHD    = "hd"
+	BootDeviceCDROM = "cdrom"
+)
+
+// ISOInstall indicates creating the guest with a blank system volume,
+// and booting from the ISO to install the OS.
+type ISOInstall struct {
+	// Source is an image name of the image hub or an absolute host path,
+	// which must be under the ISO dir of host.
+	Source      string   `json:"source"`
+	SysDiskSize int64    `json:"sys_disk_size,omitempty"`
+	BootOrder   []string `json:"boot_order,omitempty"`
+}
+
+// Check .
+func (i *ISOInstall) Check() error {
+	if i.Source == "" {
+		return errors.Wrapf(terrors.ErrInvalidValue, "ISO source is required")
+	}
+	if i.SysDiskSize < 0 {
+		return errors.Wrapf(terrors.ErrInvalidValue, "invalid sys disk size %d", i.SysDiskSize)
+	}
+	return CheckBootOrder(i.BootOrder)
+}
+
+// GetBootOrder returns the boot order, it's CD-ROM first by default.
+func (i *ISOInstall) GetBootOrder() []string {
+	if len(i.BootOrder) == 0 {
+		return []string{BootDeviceCDROM, BootDeviceHD}
+	}
+	return i.BootOrder
+}
+
+// IsHostPathISO tells whether src is a host path rather than an image name.
+func IsHostPathISO(src string) bool {
+	return filepath.IsAbs(src)
+}
+
+// ResolveHostPathISO returns the real path of the host path ISO, which must be
+// a regular file under dir after resolving symlinks, so the other host files,
+// e.g. the disks of other guests or block devices, can't be exposed to guests.
+func ResolveHostPathISO(dir, src string) (string, error) {
+	if dir == "" {
+		return "", errors.Wrapf(terrors.ErrInvalidValue, "host path ISO %s isn't allowed", src)
+	}
+	root, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid ISO dir %s", dir)
+	}
+	path, err := filepath.EvalSymlinks(filepath.Clean(src))
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid ISO %s", src)
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", errors.Wrapf(terrors.ErrInvalidValue, "ISO %s isn't under %s", src, dir)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid ISO %s", src)
+	}
+	if !info.Mode().IsRegular() {
+		return "", errors.Wrapf(terrors.ErrInvalidValue, "ISO %s isn't a regular file", src)
+	}
+	return path, nil
+}
+
+// CheckBootOrder checks the devices are known and not duplicated.
+func CheckBootOrder(order []string) error {
+	seen := map[string]struct{}{}
+	for _, dev := range order {
+		switch dev {
+		case BootDeviceHD, BootDeviceCDROM:
+		default:
+			return errors.Wrapf(terrors.ErrInvalidValue, "invalid boot device %s", dev)
+		}
+		if _, ok := seen[dev]; ok {
+			return errors.Wrapf(terrors.ErrInvalidValue, "duplicated boot device %s", dev)
+		}
+		seen[dev] = struct{}{}
+	}
+	return nil
+}
+
+// BootIndex returns the 1-based boot index of dev, 0 means not bootable.
+func BootIndex(order []string, dev string) uint {
+	for i, d := range order {
+		if d == dev {
+			return uint(i + 1)
+		}
+	}
+	return 0
+}
@@ -0,0 +1,75 @@
+package types
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/projecteru2/yavirt/pkg/test/assert"
+)
+
+func TestISOInstall(t *testing.T) {
+	iso := &ISOInstall{Source: "ubuntu-22.04-server.iso"}
+	assert.NilErr(t, iso.Check())
+	assert.Equal(t, []string{BootDeviceCDROM, BootDeviceHD}, iso.GetBootOrder())
+	assert.False(t, IsHostPathISO(iso.Source))
+	assert.True(t, IsHostPathISO("/data/iso/ubuntu.iso"))
+
+	iso.BootOrder = []string{BootDeviceHD, BootDeviceCDROM}
+	assert.NilErr(t, iso.Check())
+	assert.Equal(t, uint(1), BootIndex(iso.BootOrder, BootDeviceHD))
+	assert.Equal(t, uint(2), BootIndex(iso.BootOrder, BootDeviceCDROM))
+	assert.Equal(t, uint(0), BootIndex([]string{BootDeviceHD}, BootDeviceCDROM))
+
+	for _, invalid := range []*ISOInstall{
+		{},
+		{Source: "a.iso", SysDiskSize: -1},
+		{Source: "a.iso", BootOrder: []string{"floppy"}},
+		{Source: "a.iso", BootOrder: []string{BootDeviceHD, BootDeviceHD}},
+	} {
+		assert.Err(t, invalid.Check())
+	}
+}
+
+func TestResolveHostPathISO(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "iso")
+	assert.NilErr(t, os.MkdirAll(filepath.Join(dir, "ubuntu"), 0755))
+	iso := filepath.Join(dir, "ubuntu", "22.04.iso")
+	assert.NilErr(t, os.WriteFile(iso, nil, 0600))
+	disk := filepath.Join(root, "guest-0.vol")
+	assert.NilErr(t, os.WriteFile(disk, nil, 0600))
+
+	path, err := ResolveHostPathISO(dir, iso)
+	assert.NilErr(t, err)
+	assert.Equal(t, iso, path)
+	path, err = ResolveHostPathISO(dir, filepath.Join(dir, "ubuntu", "..", "ubuntu", "22.04.iso"))
+	assert.NilErr(t, err)
+	assert.Equal(t, iso, path)
+
+	// the symlink into the ISO dir is resolved
+	link := filepath.Join(root, "link.iso")
+	assert.NilErr(t, os.Symlink(iso, link))
+	path, err = ResolveHostPathISO(dir, link)
+	assert.NilErr(t, err)
+	assert.Equal(t, iso, path)
+	// the symlink out of the ISO dir is refused
+	escape := filepath.Join(dir, "escape.iso")
+	assert.NilErr(t, os.Symlink(disk, escape))
+	_, err = ResolveHostPathISO(dir, escape)
+	assert.Err(t, err)
+
+	for _, src := range []string{
+		disk,
+		filepath.Join(dir, "..", "guest-0.vol"),
+		filepath.Join(dir, "ubuntu"),
+		dir,
+		"/dev/null",
+	} {
+		_, err = ResolveHostPathISO(dir, src)
+		assert.Err(t, err)
+	}
+
+	_, err = ResolveHostPathISO("", iso)
+	assert.Err(t, err)
+}
@@ -0,0 +1,142 @@
+package types
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/yavirt/pkg/terrors"
+)
+
+const (
+	// BootOrderLabelKey is the label to order the guests which are restarted
+	// by the startup of host, the smaller ones are started first, default is 0.
+	BootOrderLabelKey = "instance/boot-order"
+	// BootAfterLabelKey is the label to start guest after the others,
+	// its value is the comma separated guest IDs.
+	BootAfterLabelKey = "instance/boot-after"
+	// BootDelayLabelKey is the label to wait after starting guest before the
+	// next group, e.g. 30s, the longest one of group is taken.
+	BootDelayLabelKey = "instance/boot-delay"
+)
+
+// BootEntry is a guest to be started by the startup of host.
+type BootEntry struct {
+	ID    string
+	Order int
+	After []string
+	Delay time.Duration
+}
+
+// BootGroup is the guests which are started together.
+type BootGroup struct {
+	IDs []string
+	// Delay is how long to wait before the next group.
+	Delay time.Duration
+}
+
+// ParseBootEntry parses the boot labels of guest.
+func ParseBootEntry(id string, labels map[string]string) (BootEntry, error) {
+	entry := BootEntry{ID: id, Delay: -1}
+	if s, ok := labels[BootOrderLabelKey]; ok {
+		order, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil {
+			return entry, errors.Wrapf(terrors.ErrInvalidValue, "invalid %s %q of %s", BootOrderLabelKey, s, id)
+		}
+		entry.Order = order
+	}
+	if s, ok := labels[BootAfterLabelKey]; ok {
+		for _, dep := range strings.Split(s, ",") {
+			if dep = strings.TrimSpace(dep); dep != "" && dep != id {
+				entry.After = append(entry.After, dep)
+			}
+		}
+	}
+	if s, ok := labels[BootDelayLabelKey]; ok {
+		delay, err := time.ParseDuration(strings.TrimSpace(s))
+		if err != nil || delay < 0 {
+			return entry, errors.Wrapf(terrors.ErrInvalidValue, "invalid %s %q of %s", BootDelayLabelKey, s, id)
+		}
+		entry.Delay = delay
+	}
+	return entry, nil
+}
+
+// PlanBoot groups the entries by order, a guest is moved to a later group than
+// the ones it's after, the dependencies which aren't in entries are ignored.
+// The delay of group is defaultDelay if none of its guests has one.
+func PlanBoot(entries []BootEntry, defaultDelay time.Duration) ([]BootGroup, error) {
+	byID := make(map[string]*BootEntry, len(entries))
+	var orders []int
+	for i := range entries {
+		byID[entries[i].ID] = &entries[i]
+		orders = append(orders, entries[i].Order)
+	}
+	slices.Sort(orders)
+	orders = slices.Compact(orders)
+
+	const (
+		visiting = -1
+		unknown  = 0
+	)
+	// levels are 1-based so the zero value is unknown
+	levels := map[string]int{}
+	var visit func(e *BootEntry) (int, error)
+	visit = func(e *BootEntry) (int, error) {
+		switch levels[e.ID] {
+		case visiting:
+			return 0, errors.Wrapf(terrors.ErrInvalidValue, "boot dependency cycle at %s", e.ID)
+		case unknown:
+		default:
+			return levels[e.ID], nil
+		}
+		levels[e.ID] = visiting
+		level, _ := slices.BinarySearch(orders, e.Order)
+		level++
+		for _, id := range e.After {
+			dep, ok := byID[id]
+			if !ok {
+				continue
+			}
+			l, err := visit(dep)
+			if err != nil {
+				return 0, err
+			}
+			level = max(level, l+1)
+		}
+		levels[e.ID] = level
+		return level, nil
+	}
+
+	grouped := map[int]*BootGroup{}
+	for i := range entries {
+		e := &entries[i]
+		level, err := visit(e)
+		if err != nil {
+			return nil, err
+		}
+		g, ok := grouped[level]
+		if !ok {
+			g = &BootGroup{Delay: -1}
+			grouped[level] = g
+		}
+		g.IDs = append(g.IDs, e.ID)
+		g.Delay = max(g.Delay, e.Delay)
+	}
+
+	var groups []BootGroup
+	for i := 1; len(groups) < len(grouped); i++ {
+		g, ok := grouped[i]
+		if !ok {
+			continue
+		}
+		slices.Sort(g.IDs)
+		if g.Delay < 0 {
+			g.Delay = defaultDelay
+		}
+		groups = append(groups, *g)
+	}
+	return groups, nil
+}
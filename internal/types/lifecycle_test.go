@@ -0,0 +1,68 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/projecteru2/yavirt/pkg/test/assert"
+)
+
+func TestParseBootEntry(t *testing.T) {
+	e, err := ParseBootEntry("g1", nil)
+	assert.NilErr(t, err)
+	assert.Equal(t, BootEntry{ID: "g1", Delay: -1}, e)
+
+	e, err = ParseBootEntry("g1", map[string]string{
+		BootOrderLabelKey: " -2",
+		BootAfterLabelKey: "db, g1,,cache",
+		BootDelayLabelKey: "30s",
+	})
+	assert.NilErr(t, err)
+	assert.Equal(t, -2, e.Order)
+	assert.Equal(t, []string{"db", "cache"}, e.After)
+	assert.Equal(t, 30*time.Second, e.Delay)
+
+	_, err = ParseBootEntry("g1", map[string]string{BootOrderLabelKey: "first"})
+	assert.Err(t, err)
+	_, err = ParseBootEntry("g1", map[string]string{BootDelayLabelKey: "-1s"})
+	assert.Err(t, err)
+}
+
+func TestPlanBoot(t *testing.T) {
+	groups, err := PlanBoot(nil, time.Second)
+	assert.NilErr(t, err)
+	assert.Equal(t, 0, len(groups))
+
+	groups, err = PlanBoot([]BootEntry{
+		{ID: "app2", Order: 10, Delay: -1},
+		{ID: "app1", Order: 10, Delay: -1},
+		{ID: "db", Order: -5, Delay: 30 * time.Second},
+		{ID: "cache", Order: -5, Delay: time.Second},
+	}, 10*time.Second)
+	assert.NilErr(t, err)
+	assert.Equal(t, []BootGroup{
+		{IDs: []string{"cache", "db"}, Delay: 30 * time.Second},
+		{IDs: []string{"app1", "app2"}, Delay: 10 * time.Second},
+	}, groups)
+
+	// web is moved after app, and the missing dependency is ignored
+	groups, err = PlanBoot([]BootEntry{
+		{ID: "web", After: []string{"app", "gone"}, Delay: -1},
+		{ID: "app", After: []string{"db"}, Delay: -1},
+		{ID: "db", Delay: -1},
+		{ID: "other", Order: 1, Delay: -1},
+	}, 0)
+	assert.NilErr(t, err)
+	assert.Equal(t, []BootGroup{
+		{IDs: []string{"db"}},
+		{IDs: []string{"app", "other"}},
+		{IDs: []string{"web"}},
+	}, groups)
+
+	_, err = PlanBoot([]BootEntry{
+		{ID: "a", After: []string{"b"}},
+		{ID: "b", After: []string{"c"}},
+		{ID: "c", After: []string{"a"}},
+	}, 0)
+	assert.Err(t, err)
+}
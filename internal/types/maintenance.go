@@ -0,0 +1,77 @@
+package types
+
+import (
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/yavirt/pkg/terrors"
+)
+
+const (
+	// EvacuateNone leaves the guests running.
+	EvacuateNone = ""
+	// EvacuateStop shuts the guests down.
+	EvacuateStop = "stop"
+	// EvacuateHibernate saves the guests to disk.
+	EvacuateHibernate = "hibernate"
+	// EvacuateMigrate moves the guests to the other hosts.
+	EvacuateMigrate = "migrate"
+)
+
+// ErrHostInMaintenance .
+var ErrHostInMaintenance = errors.New("host is in maintenance")
+
+// MaintenanceOptions .
+type MaintenanceOptions struct {
+	Reason   string `json:"reason"`
+	Evacuate string `json:"evacuate"`
+}
+
+// Check .
+func (o *MaintenanceOptions) Check() error {
+	switch o.Evacuate {
+	case EvacuateNone, EvacuateStop, EvacuateHibernate:
+		return nil
+	case EvacuateMigrate:
+		// there is no live migration between hosts yet
+		return errors.Wrapf(terrors.ErrInvalidValue, "evacuating by migration isn't supported")
+	default:
+		return errors.Wrapf(terrors.ErrInvalidValue, "invalid evacuation %q", o.Evacuate)
+	}
+}
+
+// Maintenance is the state of a host in maintenance, it accepts no new guests,
+// and is unavailable to eru.
+type Maintenance struct {
+	MaintenanceOptions
+	Since      time.Time   `json:"since"`
+	Evacuation *Evacuation `json:"evacuation,omitempty"`
+}
+
+// Evacuation is the progress of evacuating the running guests.
+type Evacuation struct {
+	Total    int               `json:"total"`
+	Done     []string          `json:"done"`
+	Failed   map[string]string `json:"failed,omitempty"`
+	Finished bool              `json:"finished"`
+}
+
+// NewEvacuation .
+func NewEvacuation(total int) *Evacuation {
+	return &Evacuation{
+		Total:    total,
+		Done:     []string{},
+		Failed:   map[string]string{},
+		Finished: total == 0,
+	}
+}
+
+// Record records the result of evacuating guest id.
+func (e *Evacuation) Record(id string, err error) {
+	if err != nil {
+		e.Failed[id] = err.Error()
+	} else {
+		e.Done = append(e.Done, id)
+	}
+	e.Finished = len(e.Done)+len(e.Failed) >= e.Total
+}
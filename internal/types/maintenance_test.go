@@ -0,0 +1,32 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/yavirt/pkg/test/assert"
+)
+
+func TestMaintenanceOptions(t *testing.T) {
+	for _, evacuate := range []string{EvacuateNone, EvacuateStop, EvacuateHibernate} {
+		opts := MaintenanceOptions{Evacuate: evacuate}
+		assert.NilErr(t, opts.Check())
+	}
+	for _, evacuate := range []string{EvacuateMigrate, "reboot"} {
+		opts := MaintenanceOptions{Evacuate: evacuate}
+		assert.Err(t, opts.Check())
+	}
+}
+
+func TestEvacuation(t *testing.T) {
+	e := NewEvacuation(2)
+	assert.False(t, e.Finished)
+	e.Record("g1", nil)
+	assert.False(t, e.Finished)
+	e.Record("g2", errors.New("timeout"))
+	assert.True(t, e.Finished)
+	assert.Equal(t, []string{"g1"}, e.Done)
+	assert.Equal(t, map[string]string{"g2": "timeout"}, e.Failed)
+
+	assert.True(t, NewEvacuation(0).Finished)
+}
@@ -0,0 +1,76 @@
+package types
+
+import (
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/yavirt/configs"
+	"github.com/projecteru2/yavirt/pkg/terrors"
+)
+
+// the ranges of libvirt cputune
+const (
+	minCPUShares = 2
+	maxCPUShares = 262144
+	minCPUPeriod = 1000
+	maxCPUPeriod = 1000000
+	minCPUQuota  = 1000
+)
+
+// CPUQoS is the <cputune> of guest, 0 means the default of host.
+type CPUQoS struct {
+	// Shares is the relative weight to the other guests.
+	Shares uint64 `json:"shares,omitempty"`
+	// Period and Quota are in microseconds, the vCPUs can run at most
+	// Quota in every Period, a negative Quota means unlimited.
+	Period uint64 `json:"period,omitempty"`
+	Quota  int64  `json:"quota,omitempty"`
+}
+
+// Check .
+func (q *CPUQoS) Check() error {
+	if q.Shares != 0 && (q.Shares < minCPUShares || q.Shares > maxCPUShares) {
+		return errors.Wrapf(terrors.ErrInvalidValue, "invalid CPU shares %d, it should be [%d, %d]", q.Shares, minCPUShares, maxCPUShares)
+	}
+	if q.Period != 0 && (q.Period < minCPUPeriod || q.Period > maxCPUPeriod) {
+		return errors.Wrapf(terrors.ErrInvalidValue, "invalid CPU period %d, it should be [%d, %d]", q.Period, minCPUPeriod, maxCPUPeriod)
+	}
+	if q.Quota > 0 && q.Quota < minCPUQuota {
+		return errors.Wrapf(terrors.ErrInvalidValue, "invalid CPU quota %d, it should be at least %d", q.Quota, minCPUQuota)
+	}
+	return nil
+}
+
+// IsZero .
+func (q *CPUQoS) IsZero() bool {
+	return q == nil || *q == CPUQoS{}
+}
+
+// GuestQoS is the QoS to update of a guest, the nil ones are untouched.
+type GuestQoS struct {
+	CPU *CPUQoS `json:"cpu,omitempty"`
+	// Volumes is keyed by the volume ID or the device, e.g. vda.
+	Volumes   map[string]VolumeQoS `json:"volumes,omitempty"`
+	Bandwidth *NICBandwidth        `json:"bandwidth,omitempty"`
+}
+
+// Check .
+func (q *GuestQoS) Check(cfg *configs.ResourceConfig) error {
+	if q.CPU == nil && len(q.Volumes) == 0 && q.Bandwidth == nil {
+		return errors.Wrapf(terrors.ErrInvalidValue, "nothing to update")
+	}
+	if q.CPU != nil {
+		if err := q.CPU.Check(); err != nil {
+			return err
+		}
+	}
+	for _, vq := range q.Volumes {
+		if err := vq.Check(); err != nil {
+			return err
+		}
+	}
+	if q.Bandwidth != nil {
+		if err := q.Bandwidth.Check(cfg.Bandwidth); err != nil {
+			return err
+		}
+	}
+	return nil
+}
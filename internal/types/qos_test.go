@@ -0,0 +1,56 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/projecteru2/yavirt/configs"
+	"github.com/projecteru2/yavirt/pkg/test/assert"
+)
+
+func TestCPUQoS(t *testing.T) {
+	for _, q := range []CPUQoS{
+		{},
+		{Shares: 1024},
+		{Period: 100000, Quota: 50000},
+		{Quota: -1},
+	} {
+		assert.NilErr(t, q.Check())
+	}
+	for _, q := range []CPUQoS{
+		{Shares: 1},
+		{Shares: 1 << 20},
+		{Period: 100},
+		{Period: 2000000},
+		{Quota: 10},
+	} {
+		assert.Err(t, q.Check())
+	}
+
+	var q *CPUQoS
+	assert.True(t, q.IsZero())
+	assert.True(t, (&CPUQoS{}).IsZero())
+	assert.False(t, (&CPUQoS{Shares: 2}).IsZero())
+}
+
+func TestGuestQoS(t *testing.T) {
+	cfg := &configs.ResourceConfig{Bandwidth: 1000}
+
+	q := &GuestQoS{}
+	assert.Err(t, q.Check(cfg))
+
+	q = &GuestQoS{
+		CPU:       &CPUQoS{Shares: 512},
+		Volumes:   map[string]VolumeQoS{"vda": {ReadIOPS: 100}},
+		Bandwidth: &NICBandwidth{Average: 500, Peak: 1000},
+	}
+	assert.NilErr(t, q.Check(cfg))
+
+	q.Volumes["vdb"] = VolumeQoS{WriteBPS: -1}
+	assert.Err(t, q.Check(cfg))
+	delete(q.Volumes, "vdb")
+
+	q.Bandwidth.Peak = 2000
+	assert.Err(t, q.Check(cfg))
+	q.Bandwidth.Peak = 100
+	assert.Err(t, q.Check(cfg))
+}
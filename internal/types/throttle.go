@@ -0,0 +1,58 @@
+package types
+
+import (
+	"encoding/json"
+
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/yavirt/pkg/terrors"
+)
+
+// ThrottleGroupsLabelKey is the label to put the volumes into throttle groups,
+// its value is a JSON object from the mount dir of volume to the group name,
+// the mount dir of sys volume is /.
+const ThrottleGroupsLabelKey = "instance/throttle-groups"
+
+// ThrottleScopeGuest is the only scope of throttle groups, QEMU throttles
+// per guest, so the budget isn't shared across guests.
+const ThrottleScopeGuest = "guest"
+
+// ThrottleGroup is an IO budget shared by the volumes in it, 0 means unlimited.
+// QEMU throttles per guest, so every guest referring to the group has its own
+// budget which is shared by the volumes of the guest in the group.
+type ThrottleGroup struct {
+	Name string `json:"name"`
+	// Scope tells who shares the budget, it's always ThrottleScopeGuest.
+	Scope string `json:"scope"`
+	VolumeQoS
+}
+
+// Check .
+func (g *ThrottleGroup) Check() error {
+	if !flavorNameRegexp.MatchString(g.Name) {
+		return errors.Wrapf(terrors.ErrInvalidValue, "invalid throttle group name %q", g.Name)
+	}
+	if g.Scope != "" && g.Scope != ThrottleScopeGuest {
+		return errors.Wrapf(terrors.ErrInvalidValue,
+			"throttle group %s can't be shared across guests, only the scope %s is supported", g.Name, ThrottleScopeGuest)
+	}
+	if err := g.VolumeQoS.Check(); err != nil {
+		return err
+	}
+	if g.VolumeQoS == (VolumeQoS{}) {
+		return errors.Wrapf(terrors.ErrInvalidValue, "throttle group %s has no limit", g.Name)
+	}
+	return nil
+}
+
+// ParseThrottleGroups returns the groups of volumes keyed by the mount dir.
+func ParseThrottleGroups(labels map[string]string) (map[string]string, error) {
+	bs, ok := labels[ThrottleGroupsLabelKey]
+	if !ok {
+		return nil, nil
+	}
+	groups := map[string]string{}
+	if err := json.Unmarshal([]byte(bs), &groups); err != nil {
+		return nil, errors.Wrapf(terrors.ErrInvalidValue, "invalid label %s: %s", ThrottleGroupsLabelKey, bs)
+	}
+	return groups, nil
+}
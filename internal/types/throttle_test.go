@@ -0,0 +1,37 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/projecteru2/yavirt/pkg/test/assert"
+)
+
+func TestThrottleGroupCheck(t *testing.T) {
+	g := &ThrottleGroup{Name: "tenant-a", VolumeQoS: VolumeQoS{ReadIOPS: 1000, WriteIOPS: 500}}
+	assert.NilErr(t, g.Check())
+	g.Scope = ThrottleScopeGuest
+	assert.NilErr(t, g.Check())
+
+	for _, invalid := range []*ThrottleGroup{
+		{Name: "", VolumeQoS: VolumeQoS{ReadIOPS: 1000}},
+		{Name: "a/b", VolumeQoS: VolumeQoS{ReadIOPS: 1000}},
+		{Name: "tenant-a"},
+		{Name: "tenant-a", VolumeQoS: VolumeQoS{ReadBPS: -1}},
+		{Name: "tenant-a", Scope: "host", VolumeQoS: VolumeQoS{ReadIOPS: 1000}},
+	} {
+		assert.Err(t, invalid.Check())
+	}
+}
+
+func TestParseThrottleGroups(t *testing.T) {
+	groups, err := ParseThrottleGroups(nil)
+	assert.NilErr(t, err)
+	assert.Nil(t, groups)
+
+	groups, err = ParseThrottleGroups(map[string]string{ThrottleGroupsLabelKey: `{"/":"g1","/data":"g1"}`})
+	assert.NilErr(t, err)
+	assert.Equal(t, map[string]string{"/": "g1", "/data": "g1"}, groups)
+
+	_, err = ParseThrottleGroups(map[string]string{ThrottleGroupsLabelKey: "g1"})
+	assert.Err(t, err)
+}
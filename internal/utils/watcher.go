@@ -3,6 +3,7 @@ package utils
 import (
 	"context"
 	"sync"
+	"sync/atomic"
 
 	"github.com/alphadose/haxmap"
 	"github.com/cockroachdb/errors"
@@ -13,10 +14,17 @@ import (
 
 var ErrTooManyWatchers = errors.New("too many watchers")
 
+// Journal persists the events and assigns their sequence numbers,
+// Append is called before notifying the watchers, so it mustn't block on IO.
+type Journal interface {
+	Append(event *types.Event) error
+}
+
 type Watchers struct {
-	index  utils.AtomicInt64
-	wchMap *haxmap.Map[int64, *Watcher]
-	events chan types.Event
+	index   utils.AtomicInt64
+	wchMap  *haxmap.Map[int64, *Watcher]
+	events  chan types.Event
+	journal atomic.Value
 
 	done struct {
 		sync.Once
@@ -33,6 +41,11 @@ func NewWatchers() *Watchers {
 	return ws
 }
 
+// SetJournal records the events into j before notifying the watchers.
+func (ws *Watchers) SetJournal(j Journal) {
+	ws.journal.Store(j)
+}
+
 func (ws *Watchers) Len() int {
 	return int(ws.wchMap.Len())
 }
@@ -54,6 +67,7 @@ func (ws *Watchers) Run(ctx context.Context) {
 	for {
 		select {
 		case event := <-ws.events:
+			ws.record(&event)
 			ws.Notify(event)
 		case <-ws.Done():
 			return
@@ -73,6 +87,16 @@ func (ws *Watchers) Watched(event types.Event) {
 	}
 }
 
+func (ws *Watchers) record(event *types.Event) {
+	j, ok := ws.journal.Load().(Journal)
+	if !ok {
+		return
+	}
+	if err := j.Append(event); err != nil {
+		log.Warnf(context.TODO(), "failed to record the event %v: %s", event, err)
+	}
+}
+
 func (ws *Watchers) Notify(event types.Event) {
 	defer log.Info(context.TODO(), "watchers notification has done")
 
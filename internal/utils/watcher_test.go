@@ -180,3 +180,33 @@ func TestWatchers_WatchedEvent(t *testing.T) {
 
 	ws.Stop()
 }
+
+type fakeJournal struct {
+	seq uint64
+}
+
+func (j *fakeJournal) Append(event *types.Event) error {
+	j.seq++
+	event.Seq = j.seq
+	return nil
+}
+
+func TestWatchers_Journal(t *testing.T) {
+	ws := NewWatchers()
+	ws.SetJournal(&fakeJournal{})
+	go ws.Run(context.Background())
+	defer ws.Stop()
+
+	watcher, err := ws.Get()
+	assert.NilErr(t, err)
+
+	for i := 1; i <= 3; i++ {
+		go ws.Watched(types.Event{ID: "guest", Op: types.StartOp})
+		select {
+		case event := <-watcher.Events():
+			assert.Equal(t, uint64(i), event.Seq)
+		case <-time.After(time.Second):
+			assert.Fail(t, "event %d isn't received", i)
+		}
+	}
+}
@@ -33,6 +33,7 @@ type Interface interface { //nolint
 	FSFreezeAll(ctx context.Context) (int, error)
 	FSThawAll(ctx context.Context) (int, error)
 	FSFreezeStatus(ctx context.Context) (string, error)
+	SetOnlineVCPUs(ctx context.Context, count int) error
 }
 
 // Agent .
@@ -523,6 +523,24 @@ func (_m *Interface) SeekFile(ctx context.Context, handle int, offset int, whenc
 	return r0, r1, r2
 }
 
+// SetOnlineVCPUs provides a mock function with given fields: ctx, count
+func (_m *Interface) SetOnlineVCPUs(ctx context.Context, count int) error {
+	ret := _m.Called(ctx, count)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetOnlineVCPUs")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, count)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Touch provides a mock function with given fields: ctx, filepath
 func (_m *Interface) Touch(ctx context.Context, filepath string) error {
 	ret := _m.Called(ctx, filepath)
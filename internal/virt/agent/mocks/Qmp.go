@@ -6,6 +6,8 @@ import (
 	context "context"
 
 	mock "github.com/stretchr/testify/mock"
+
+	types "github.com/projecteru2/yavirt/internal/virt/agent/types"
 )
 
 // Qmp is an autogenerated mock type for the Qmp type
@@ -257,6 +259,36 @@ func (_m *Qmp) GetName() string {
 	return r0
 }
 
+// GetVCPUs provides a mock function with given fields: ctx
+func (_m *Qmp) GetVCPUs(ctx context.Context) ([]types.GuestVCPU, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetVCPUs")
+	}
+
+	var r0 []types.GuestVCPU
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]types.GuestVCPU, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []types.GuestVCPU); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]types.GuestVCPU)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // OpenFile provides a mock function with given fields: ctx, path, mode
 func (_m *Qmp) OpenFile(ctx context.Context, path string, mode string) ([]byte, error) {
 	ret := _m.Called(ctx, path, mode)
@@ -357,6 +389,34 @@ func (_m *Qmp) SeekFile(ctx context.Context, handle int, offset int, whence int)
 	return r0, r1, r2
 }
 
+// SetVCPUs provides a mock function with given fields: ctx, vcpus
+func (_m *Qmp) SetVCPUs(ctx context.Context, vcpus []types.GuestVCPU) (int, error) {
+	ret := _m.Called(ctx, vcpus)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetVCPUs")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []types.GuestVCPU) (int, error)); ok {
+		return rf(ctx, vcpus)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []types.GuestVCPU) int); ok {
+		r0 = rf(ctx, vcpus)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []types.GuestVCPU) error); ok {
+		r1 = rf(ctx, vcpus)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // WriteFile provides a mock function with given fields: ctx, handle, buf
 func (_m *Qmp) WriteFile(ctx context.Context, handle int, buf []byte) error {
 	ret := _m.Called(ctx, handle, buf)
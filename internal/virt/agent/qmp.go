@@ -11,6 +11,7 @@ import (
 
 	"github.com/cockroachdb/errors"
 	"github.com/projecteru2/core/log"
+	"github.com/projecteru2/yavirt/internal/virt/agent/types"
 	"github.com/projecteru2/yavirt/pkg/utils"
 
 	"github.com/projecteru2/yavirt/pkg/libvirt"
@@ -35,6 +36,8 @@ type Qmp interface { //nolint:interfacebloat
 	FSFreezeList(ctx context.Context, mountpoints []string) (nFS int, err error)
 	FSThawAll(ctx context.Context) (nFS int, err error)
 	FSFreezeStatus(ctx context.Context) (status string, err error)
+	GetVCPUs(ctx context.Context) ([]types.GuestVCPU, error)
+	SetVCPUs(ctx context.Context, vcpus []types.GuestVCPU) (int, error)
 	GetName() string
 }
 
@@ -237,6 +240,30 @@ func (q *qmp) FSFreezeStatus(ctx context.Context) (status string, err error) {
 	return
 }
 
+func (q *qmp) GetVCPUs(ctx context.Context) (vcpus []types.GuestVCPU, err error) {
+	q.Lock()
+	defer q.Unlock()
+
+	var bs []byte
+	if bs, err = q.exec(ctx, "guest-get-vcpus", nil); err != nil {
+		return
+	}
+	err = errors.Wrap(json.Unmarshal(bs, &vcpus), "")
+	return
+}
+
+func (q *qmp) SetVCPUs(ctx context.Context, vcpus []types.GuestVCPU) (n int, err error) {
+	q.Lock()
+	defer q.Unlock()
+
+	var bs []byte
+	if bs, err = q.exec(ctx, "guest-set-vcpus", map[string]any{"vcpus": vcpus}); err != nil {
+		return
+	}
+	n, err = strconv.Atoi(string(bs))
+	return
+}
+
 func (q *qmp) exec(ctx context.Context, cmd string, args map[string]any) ([]byte, error) {
 	if err := q.initIfNecessary(); err != nil {
 		return nil, err
@@ -30,6 +30,14 @@ type ExecStatus struct {
 	Pid int
 	Err error
 }
+
+// GuestVCPU is the state of a logical CPU inside guest.
+type GuestVCPU struct {
+	LogicalID  int   `json:"logical-id"`
+	Online     bool  `json:"online"`
+	CanOffline *bool `json:"can-offline,omitempty"`
+}
+
 type BlkidInfo struct {
 	ID   string `json:"id"`
 	Type string `json:"type"`
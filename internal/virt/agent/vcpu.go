@@ -0,0 +1,45 @@
+package agent
+
+import (
+	"context"
+	"sort"
+
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/yavirt/internal/virt/agent/types"
+)
+
+// SetOnlineVCPUs brings the first count vCPUs online and the others offline,
+// the vCPUs which can't be offlined are left as they are.
+func (a *Agent) SetOnlineVCPUs(ctx context.Context, count int) error {
+	vcpus, err := a.qmp.GetVCPUs(ctx)
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+	sort.Slice(vcpus, func(i, j int) bool {
+		return vcpus[i].LogicalID < vcpus[j].LogicalID
+	})
+
+	var changes []types.GuestVCPU
+	for i, vcpu := range vcpus {
+		online := i < count
+		if vcpu.Online == online {
+			continue
+		}
+		if !online && vcpu.CanOffline != nil && !*vcpu.CanOffline {
+			continue
+		}
+		changes = append(changes, types.GuestVCPU{LogicalID: vcpu.LogicalID, Online: online})
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+
+	n, err := a.qmp.SetVCPUs(ctx, changes)
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+	if n < len(changes) {
+		return errors.Newf("only %d of %d vCPUs are changed", n, len(changes))
+	}
+	return nil
+}
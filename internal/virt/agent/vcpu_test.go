@@ -0,0 +1,40 @@
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/projecteru2/yavirt/internal/virt/agent/mocks"
+	"github.com/projecteru2/yavirt/internal/virt/agent/types"
+	"github.com/projecteru2/yavirt/pkg/test/assert"
+)
+
+func TestSetOnlineVCPUs(t *testing.T) {
+	mockQmp := mocks.NewQmp(t)
+	ag := Agent{
+		qmp: mockQmp,
+	}
+	ctx := context.Background()
+	canOffline, cantOffline := true, false
+
+	mockQmp.On("GetVCPUs", ctx).Return([]types.GuestVCPU{
+		{LogicalID: 1, Online: true, CanOffline: &canOffline},
+		{LogicalID: 0, Online: true, CanOffline: &cantOffline},
+		{LogicalID: 2, Online: false, CanOffline: &canOffline},
+		{LogicalID: 3, Online: false, CanOffline: &canOffline},
+	}, nil).Once()
+	mockQmp.On("SetVCPUs", ctx, []types.GuestVCPU{
+		{LogicalID: 2, Online: true},
+	}).Return(1, nil).Once()
+	assert.NilErr(t, ag.SetOnlineVCPUs(ctx, 3))
+
+	// vCPU 0 can't be offlined
+	mockQmp.On("GetVCPUs", ctx).Return([]types.GuestVCPU{
+		{LogicalID: 0, Online: true, CanOffline: &cantOffline},
+		{LogicalID: 1, Online: true, CanOffline: &canOffline},
+	}, nil).Once()
+	mockQmp.On("SetVCPUs", ctx, []types.GuestVCPU{
+		{LogicalID: 1, Online: false},
+	}).Return(0, nil).Once()
+	assert.Err(t, ag.SetOnlineVCPUs(ctx, 0))
+}
@@ -0,0 +1,134 @@
+package balloon
+
+import (
+	"context"
+	"time"
+
+	golibvirt "github.com/digitalocean/go-libvirt"
+	"github.com/projecteru2/core/log"
+	"github.com/projecteru2/yavirt/configs"
+	"github.com/projecteru2/yavirt/internal/vmcache"
+	"github.com/projecteru2/yavirt/pkg/libvirt"
+)
+
+// the memory stats of a guest in KiB
+type memStats struct {
+	current uint64
+	maximum uint64
+	usable  uint64
+}
+
+// Run reclaims the idle memory of guests by balloon periodically,
+// and gives it back when the guest is short of memory.
+func Run(ctx context.Context, cfg *configs.BalloonConfig) {
+	logger := log.WithFunc("balloon.Run")
+	ticker := time.NewTicker(cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Infof(ctx, "balloon exits")
+			return
+		case <-ticker.C:
+			if err := adjust(ctx, cfg); err != nil {
+				logger.Warnf(ctx, "failed to adjust balloon: %s", err)
+			}
+		}
+	}
+}
+
+func adjust(ctx context.Context, cfg *configs.BalloonConfig) error {
+	targets := map[string]uint64{}
+	for name, resp := range vmcache.FetchStats() {
+		if !resp.IsRunning() || hugepageBacked(&resp.DomainCacheEntry) {
+			continue
+		}
+		stats, ok := parseStats(resp.Stats)
+		if !ok {
+			continue
+		}
+		if target, ok := Target(cfg, stats.current, stats.maximum, stats.usable); ok {
+			targets[name] = target
+		}
+	}
+	if len(targets) == 0 {
+		return nil
+	}
+
+	virt, err := libvirt.Connect("qemu:///system")
+	if err != nil {
+		return err
+	}
+	defer virt.Close() //nolint
+
+	logger := log.WithFunc("balloon.adjust")
+	for name, target := range targets {
+		dom, err := virt.LookupDomain(name)
+		if err != nil {
+			logger.Warnf(ctx, "failed to lookup domain %s: %s", name, err)
+			continue
+		}
+		if err := dom.SetMemoryFlags(target, libvirt.DomainMemLive); err != nil {
+			logger.Warnf(ctx, "failed to set balloon of %s to %dKiB: %s", name, target, err)
+			continue
+		}
+		logger.Debugf(ctx, "set balloon of %s to %dKiB", name, target)
+	}
+	return nil
+}
+
+// Target returns the new current memory in KiB, false means nothing to do.
+func Target(cfg *configs.BalloonConfig, current, maximum, usable uint64) (uint64, bool) {
+	if current == 0 || maximum == 0 || usable > current {
+		return 0, false
+	}
+	used := current - usable
+	// the memory which keeps TargetFree of it usable
+	want := uint64(float64(used) / (1 - cfg.TargetFree))
+	ratio := float64(usable) / float64(current)
+
+	var target uint64
+	switch {
+	case ratio > cfg.HighWatermark:
+		target = max(want, uint64(float64(maximum)*cfg.MinRatio))
+		if target >= current {
+			return 0, false
+		}
+	case ratio < cfg.LowWatermark && current < maximum:
+		target = want
+		if target <= current {
+			target = current + (maximum-current)/2
+		}
+		target = min(target, maximum)
+	default:
+		return 0, false
+	}
+	return target, true
+}
+
+func parseStats(stats map[string]golibvirt.TypedParam) (*memStats, bool) {
+	var ans memStats
+	for key, val := range map[string]*uint64{
+		"balloon.current": &ans.current,
+		"balloon.maximum": &ans.maximum,
+		"balloon.usable":  &ans.usable,
+	} {
+		p, ok := stats[key]
+		if !ok {
+			return nil, false
+		}
+		v, err := vmcache.ToUint64(p)
+		if err != nil {
+			return nil, false
+		}
+		*val = v
+	}
+	return &ans, true
+}
+
+// the hugepages can't be returned to host by balloon
+func hugepageBacked(entry *vmcache.DomainCacheEntry) bool {
+	schema := entry.Schema
+	return schema != nil && schema.MemoryBacking != nil && schema.MemoryBacking.MemoryHugePages != nil
+}
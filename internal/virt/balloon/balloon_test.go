@@ -0,0 +1,47 @@
+package balloon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/projecteru2/yavirt/configs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTarget(t *testing.T) {
+	cfg := &configs.BalloonConfig{
+		Interval:      time.Minute,
+		HighWatermark: 0.5,
+		LowWatermark:  0.1,
+		TargetFree:    0.5,
+		MinRatio:      0.25,
+	}
+	const gib = 1 << 20
+
+	// idle: 3GiB of 4GiB is usable
+	target, ok := Target(cfg, 4*gib, 4*gib, 3*gib)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(2*gib), target)
+
+	// never shrinks below min ratio
+	target, ok = Target(cfg, 4*gib, 8*gib, 3900*1024)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(2*gib), target)
+
+	// busy: gives the memory back
+	target, ok = Target(cfg, 2*gib, 4*gib, 100*1024)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(2*(2*gib-100*1024)), target)
+
+	// busy but already at maximum
+	_, ok = Target(cfg, 4*gib, 4*gib, 100*1024)
+	assert.False(t, ok)
+
+	// between watermarks
+	_, ok = Target(cfg, 4*gib, 4*gib, gib)
+	assert.False(t, ok)
+
+	// no stats
+	_, ok = Target(cfg, 0, 0, 0)
+	assert.False(t, ok)
+}
@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/yavirt/pkg/libvirt"
+	"github.com/projecteru2/yavirt/pkg/terrors"
+	"libvirt.org/go/libvirtxml"
+)
+
+// CheckCheckpoint checks whether the domain can be checkpointed by internal
+// snapshots, which can't be taken of RBD disks or UEFI variables in pflash.
+func (d *VirtDomain) CheckCheckpoint() error {
+	dom, err := d.Lookup()
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+	domcfg, err := getDomainConfig(dom)
+	if err != nil {
+		return err
+	}
+	return errors.Wrapf(checkCheckpointConfig(domcfg), "guest %s can't be checkpointed", d.guest.ID)
+}
+
+func checkCheckpointConfig(domcfg *libvirtxml.Domain) error {
+	if domOS := domcfg.OS; domOS != nil && domOS.Loader != nil && domOS.Loader.Type == "pflash" {
+		return errors.Wrap(terrors.ErrInvalidValue, "the firmware is in pflash")
+	}
+	if domcfg.Devices == nil {
+		return nil
+	}
+	for _, disk := range domcfg.Devices.Disks {
+		if disk.Source == nil || disk.Source.Network == nil {
+			continue
+		}
+		if disk.Source.Network.Protocol == "rbd" {
+			return errors.Wrapf(terrors.ErrInvalidValue, "disk %s is a RBD disk", diskTarget(disk))
+		}
+	}
+	return nil
+}
+
+func diskTarget(disk libvirtxml.DomainDisk) string {
+	if disk.Target == nil {
+		return ""
+	}
+	return disk.Target.Dev
+}
+
+// CreateCheckpoint takes an internal snapshot of the domain, the memory and
+// the device state are included if it's running. The disks must be qcow2.
+func (d *VirtDomain) CreateCheckpoint(name, desc string) error {
+	dom, err := d.Lookup()
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+	st, err := dom.GetState()
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+	snap := &libvirtxml.DomainSnapshot{
+		Name:        name,
+		Description: desc,
+		Memory:      &libvirtxml.DomainSnapshotMemory{Snapshot: "no"},
+	}
+	if st == libvirt.DomainRunning {
+		snap.Memory.Snapshot = "internal"
+	}
+	snapXML, err := snap.Marshal()
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+	return errors.Wrapf(dom.SnapshotCreateXML(snapXML), "failed to create checkpoint %s of guest %s", name, d.guest.ID)
+}
+
+// RevertCheckpoint reverts the disks, and the memory if any, the domain is
+// running after that if it was running at the checkpoint.
+func (d *VirtDomain) RevertCheckpoint(name string) error {
+	dom, err := d.Lookup()
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+	return errors.Wrapf(dom.RevertToSnapshot(name), "failed to revert guest %s to checkpoint %s", d.guest.ID, name)
+}
+
+// DeleteCheckpoint .
+func (d *VirtDomain) DeleteCheckpoint(name string) error {
+	dom, err := d.Lookup()
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+	return errors.Wrapf(dom.DeleteSnapshot(name), "failed to delete checkpoint %s of guest %s", name, d.guest.ID)
+}
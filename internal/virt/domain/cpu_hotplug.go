@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"fmt"
+
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/yavirt/configs"
+	"github.com/projecteru2/yavirt/pkg/libvirt"
+	"github.com/projecteru2/yavirt/pkg/terrors"
+	"libvirt.org/go/libvirtxml"
+)
+
+// maxVCPUs returns the vCPU ceiling of the guest,
+// the guest with pinned CPUs has no headroom.
+func (d *VirtDomain) maxVCPUs(cpu int) int {
+	cfg := &configs.Conf.Resource.CPUHotplug
+	if !cfg.Enable || d.guest.CPUPinning != nil {
+		return cpu
+	}
+	return max(cpu, min(int(float64(cpu)*cfg.MaxCPURatio), configs.Conf.Resource.MaxCPU))
+}
+
+// hotplugCPU changes the vCPUs of a running domain within its ceiling.
+func (d *VirtDomain) hotplugCPU(cpu int, dom libvirt.Domain) error {
+	domcfg, err := getDomainConfig(dom)
+	if err != nil {
+		return err
+	}
+	if domcfg.VCPU == nil || cpu > int(domcfg.VCPU.Value) {
+		return errors.Wrapf(terrors.ErrLiveResizeUnavailable,
+			"%d vCPUs exceeds the ceiling of the running guest", cpu)
+	}
+	if boot := bootVCPUs(domcfg); cpu < boot {
+		return errors.Wrapf(terrors.ErrLiveResizeUnavailable,
+			"%d vCPUs is less than the %d vCPUs which the guest booted with", cpu, boot)
+	}
+	err = dom.SetVcpusFlags(uint(cpu), libvirt.DomainVcpuLive|libvirt.DomainVcpuConfig)
+	if err != nil && cpu < currentVCPUs(domcfg) {
+		// the guest may refuse to unplug the vCPUs
+		return errors.Wrapf(terrors.ErrLiveResizeUnavailable, "failed to unplug vCPUs: %s", err)
+	}
+	return err
+}
+
+// bootVCPUs returns the number of vCPUs which aren't hotpluggable, they
+// can't be unplugged. The live XML of libvirt lists them in <vcpus>,
+// all the current vCPUs are taken as the boot ones if it's absent.
+func bootVCPUs(domcfg *libvirtxml.Domain) int {
+	if domcfg.VCPUs == nil {
+		return currentVCPUs(domcfg)
+	}
+	var n int
+	for _, vcpu := range domcfg.VCPUs.VCPU {
+		if vcpu.Enabled == "yes" && vcpu.Hotpluggable == "no" {
+			n++
+		}
+	}
+	return n
+}
+
+func currentVCPUs(domcfg *libvirtxml.Domain) int {
+	if domcfg.VCPU.Current > 0 {
+		return int(domcfg.VCPU.Current)
+	}
+	return int(domcfg.VCPU.Value)
+}
+
+// resizeNUMACPU redefines the domain whose vCPUs are all in one NUMA cell,
+// the cell must contain all vCPUs up to the ceiling.
+func (d *VirtDomain) resizeNUMACPU(cpu int, domcfg *libvirtxml.Domain) error {
+	maxCPU := d.maxVCPUs(cpu)
+	domcfg.VCPU.Value = uint(maxCPU)
+	domcfg.VCPU.Current = uint(cpu)
+	domcfg.CPU.Numa.Cell[0].CPUs = fmt.Sprintf("0-%d", maxCPU-1)
+	xmldoc, err := domcfg.Marshal()
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+	_, err = d.virt.DefineDomain(xmldoc)
+	return errors.Wrap(err, "")
+}
+
+func getDomainConfig(dom libvirt.Domain) (*libvirtxml.Domain, error) {
+	xmldoc, err := dom.GetXMLDesc(0)
+	if err != nil {
+		return nil, errors.Wrap(err, "")
+	}
+	domcfg := &libvirtxml.Domain{}
+	if err := domcfg.Unmarshal(xmldoc); err != nil {
+		return nil, errors.Wrap(err, "")
+	}
+	return domcfg, nil
+}
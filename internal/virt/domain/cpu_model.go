@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/yavirt/configs"
+	"github.com/projecteru2/yavirt/internal/types"
+)
+
+// cpuModel resolves the CPU model of the guest, the label takes precedence over the host's config.
+func (d *VirtDomain) cpuModel() (*types.CPUModel, error) {
+	cfg := &configs.Conf.CPUModel
+	model := &types.CPUModel{
+		Mode:     cfg.Mode,
+		Model:    cfg.Model,
+		Features: cfg.Features,
+	}
+	if bs, ok := d.guest.JSONLabels[types.CPUModelLabelKey]; ok {
+		model = &types.CPUModel{}
+		if err := json.Unmarshal([]byte(bs), model); err != nil {
+			return nil, errors.Wrapf(err, "invalid label %s", types.CPUModelLabelKey)
+		}
+	}
+	if model.Mode == "" {
+		model.Mode = types.CPUModeHostPassthrough
+	}
+	if err := model.Check(); err != nil {
+		return nil, err
+	}
+	return model, nil
+}
+
+// cpuModelXML generates the attributes of <cpu> and its <model> and <feature> elements.
+func (d *VirtDomain) cpuModelXML(model *types.CPUModel) (attrs, inner string, err error) {
+	feats, err := model.ParseFeatures()
+	if err != nil {
+		return "", "", err
+	}
+
+	check := "partial"
+	if model.Mode == types.CPUModeHostPassthrough {
+		check = "full"
+	}
+	attrs = fmt.Sprintf("mode='%s' check='%s'", model.Mode, check)
+
+	var buf strings.Builder
+	if model.Mode == types.CPUModeCustom {
+		attrs += " match='exact'"
+		// never fall back to another model silently, or the guest can't be migrated.
+		fmt.Fprintf(&buf, "<model fallback='forbid'>%s</model>\n", model.Model)
+	}
+	for _, feat := range feats {
+		fmt.Fprintf(&buf, "    <feature policy='%s' name='%s'/>\n", feat.Policy, feat.Name)
+	}
+	return attrs, strings.TrimSpace(buf.String()), nil
+}
@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/projecteru2/yavirt/internal/eru/resources"
+	"github.com/projecteru2/yavirt/internal/types"
+)
+
+// cpuPinning returns the pinning of the guest,
+// the dedicated pCPUs are allocated at the first time.
+func (d *VirtDomain) cpuPinning() (*types.CPUPinning, error) {
+	if d.guest.CPUPinning != nil {
+		return d.guest.CPUPinning, nil
+	}
+	req, ok, err := d.guest.CPUPinningRequest()
+	if err != nil || !ok {
+		return nil, err
+	}
+	pinning, err := resources.GetManager().AllocCPUs(d.guest.CPU, req)
+	if err != nil {
+		return nil, err
+	}
+	d.guest.CPUPinning = pinning
+	// the hugepages must be on the nodes of the pCPUs
+	if err := d.guest.CheckHugepages(); err != nil {
+		d.guest.CPUPinning = nil
+		return nil, err
+	}
+	return pinning, nil
+}
+
+// cpuPinningXML generates <cputune>, <numatune> and the guest NUMA topology.
+func (d *VirtDomain) cpuPinningXML(pinning *types.CPUPinning) (cputune, numatune, numa string) {
+	if pinning == nil {
+		return
+	}
+	vcpus := pinning.VCPUs()
+	var buf strings.Builder
+	buf.WriteString("<cputune>\n")
+	for vcpu, pcpu := range vcpus {
+		fmt.Fprintf(&buf, "    <vcpupin vcpu='%d' cpuset='%d'/>\n", vcpu, pcpu)
+	}
+	emulatorCPUs := pinning.EmulatorCPUs
+	if len(emulatorCPUs) == 0 {
+		emulatorCPUs = vcpus
+	}
+	fmt.Fprintf(&buf, "    <emulatorpin cpuset='%s'/>\n  </cputune>", types.FormatCPUSet(emulatorCPUs))
+	cputune = buf.String()
+
+	buf.Reset()
+	fmt.Fprintf(&buf, "<numatune>\n    <memory mode='strict' nodeset='%s'/>\n", types.FormatCPUSet(pinning.Nodes()))
+	for cellID, cell := range pinning.Cells {
+		fmt.Fprintf(&buf, "    <memnode cellid='%d' mode='strict' nodeset='%d'/>\n", cellID, cell.Node)
+	}
+	buf.WriteString("  </numatune>")
+	numatune = buf.String()
+
+	// the memory is distributed to cells in proportion to vCPUs
+	buf.Reset()
+	buf.WriteString("<numa>\n")
+	var start int
+	cellMems := pinning.CellMemory(d.guest.MemoryInMiB())
+	for cellID, cell := range pinning.Cells {
+		end := start + len(cell.CPUs)
+		fmt.Fprintf(&buf, "      <cell id='%d' cpus='%d-%d' memory='%d' unit='MiB'/>\n", cellID, start, end-1, cellMems[cellID])
+		start = end
+	}
+	buf.WriteString("    </numa>")
+	numa = buf.String()
+	return
+}
@@ -0,0 +1,24 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// crashXML generates the <on_crash> of domain, and the watchdog and pvpanic
+// devices by the crash policy of guest.
+func (d *VirtDomain) crashXML() (onCrash, devices string, err error) {
+	policy, err := d.guest.CrashPolicy()
+	if err != nil {
+		return "", "", err
+	}
+	var buf strings.Builder
+	if policy.Watchdog {
+		fmt.Fprintf(&buf, "<watchdog model='i6300esb' action='%s'/>\n", policy.WatchdogAction())
+	}
+	if policy.PVPanic {
+		// the isa model is the pvpanic device of QEMU.
+		buf.WriteString("    <panic model='isa'>\n      <address type='isa' iobase='0x505'/>\n    </panic>\n")
+	}
+	return policy.OnCrash(), strings.TrimSpace(buf.String()), nil
+}
@@ -16,6 +16,7 @@ import (
 	pciaddr "github.com/jaypipes/ghw/pkg/pci/address"
 	"github.com/projecteru2/core/log"
 	"github.com/projecteru2/yavirt/configs"
+	"github.com/projecteru2/yavirt/internal/console"
 	"github.com/projecteru2/yavirt/internal/eru/resources"
 	"github.com/projecteru2/yavirt/internal/models"
 	"github.com/projecteru2/yavirt/internal/network"
@@ -53,6 +54,22 @@ type Domain interface { //nolint
 	GetConsoleTtyname() (string, error)
 	OpenConsole(devname string, flages types.OpenConsoleFlags) (*libvirt.Console, error)
 	ReplaceSysVolume(diskXML string) error
+	Rescue(diskXML string) error
+	Unrescue(diskXML string) error
+	ChangeMedia(path string) error
+	SetBootOrder(order []string) error
+	Screenshot() ([]byte, error)
+	Hibernate() error
+	DiscardHibernation() error
+	CheckCheckpoint() error
+	CreateCheckpoint(name, desc string) error
+	RevertCheckpoint(name string) error
+	DeleteCheckpoint(name string) error
+	SetCPUQoS(qos *types.CPUQoS) error
+	SetVolumeQoS(dev string, qos *types.VolumeQoS) error
+	SetThrottleGroup(dev string, group *types.ThrottleGroup) error
+	SetNICBandwidth(bw *types.NICBandwidth) error
+	CoreDump(ctx context.Context, path, format string, progress func(processed, total uint64)) error
 	AttachVolume(buf []byte) (st libvirt.DomainState, err error)
 	DetachVolume(dev string) (st libvirt.DomainState, err error)
 	AttachGPU(prod string, count int) (st libvirt.DomainState, err error)
@@ -108,6 +125,24 @@ func (d *VirtDomain) Define() error {
 		}()
 	}
 
+	// the same as GPU, the pinned pCPUs are fetched from vmcache,
+	// so the domain cache must be updated before unlocking.
+	if _, ok, _ := d.guest.CPUPinningRequest(); ok && d.guest.CPUPinning == nil {
+		resources.GetManager().LockCPU()
+		defer resources.GetManager().UnlockCPU()
+		defer func() {
+			if err := vmcache.UpdateDomain(d.guest.ID); err != nil {
+				log.Errorf(ctx, err, "[Define] failed to update domain cache")
+			}
+			// withdraw the pinned pCPUs from eru core at once
+			go func() {
+				if err := resources.GetManager().SyncPinnedCPUs(context.TODO()); err != nil {
+					log.Errorf(ctx, err, "[Define] failed to sync pinned pCPUs")
+				}
+			}()
+		}()
+	}
+
 	buf, err := d.render()
 	if err != nil {
 		return errors.Wrap(err, "")
@@ -136,7 +171,9 @@ func (d *VirtDomain) Boot(ctx context.Context) error {
 		return errors.Wrap(err, "")
 	}
 	defer func() {
-		_ = dom.SetAutostart(true)
+		// libvirt starts the autostart domains before yavirtd, the guests are
+		// left to yavirtd if it restores them in order.
+		_ = dom.SetAutostart(!configs.Conf.Lifecycle.StartupRestore)
 		if err := dom.SetMemoryStatsPeriod(configs.Conf.MemStatsPeriod, false, false); err != nil {
 			logger.Warnf(ctx, "failed to set memory stats period: %v", err)
 		}
@@ -347,7 +384,14 @@ func (d *VirtDomain) Undefine() error {
 	case st == libvirt.DomainPaused:
 		fallthrough
 	case st == expState:
-		return dom.UndefineFlags(libvirt.DomainUndefineManagedSave)
+		uuid, _ := dom.GetUUIDString()
+		if err := dom.UndefineFlags(libvirt.DomainUndefineManagedSave | libvirt.DomainUndefineNvram | libvirt.DomainUndefineSnapshotsMetadata); err != nil {
+			return errors.Wrap(err, "")
+		}
+		d.cleanupFirmware(uuid)
+		d.cleanupRescue()
+		console.RemoveSerialLog(d.guest.ID)
+		return nil
 
 	default:
 		return types.NewDomainStatesErr(st, expState)
@@ -378,6 +422,11 @@ func (d *VirtDomain) render() ([]byte, error) {
 	if err != nil {
 		return nil, errors.Wrap(err, "")
 	}
+	sysVolBootXML, err := d.sysVolBootXML(string(sysVolXML))
+	if err != nil {
+		return nil, err
+	}
+	isoSrcXML, isoBootXML := d.isoXML()
 	dataVols, err := d.dataVols()
 	if err != nil {
 		return nil, err
@@ -394,6 +443,41 @@ func (d *VirtDomain) render() ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
+	fw, err := d.firmware()
+	if err != nil {
+		return nil, err
+	}
+	pinning, err := d.cpuPinning()
+	if err != nil {
+		return nil, err
+	}
+	cputuneXML, numatuneXML, numaXML := d.cpuPinningXML(pinning)
+	cputuneXML = withCPUQoS(cputuneXML, d.guest.CPUQoS)
+	hugepageSize, err := d.hugepageSize()
+	if err != nil {
+		return nil, err
+	}
+	cpuModel, err := d.cpuModel()
+	if err != nil {
+		return nil, err
+	}
+	cpuAttrs, cpuModelXML, err := d.cpuModelXML(cpuModel)
+	if err != nil {
+		return nil, err
+	}
+	onCrash, crashDevicesXML, err := d.crashXML()
+	if err != nil {
+		return nil, err
+	}
+	maxCPU := d.maxVCPUs(d.guest.CPU)
+	maxMemXML, memDevXML := d.memoryHotplugXML(hugepageSize)
+	if maxMemXML != "" && numaXML == "" {
+		numaXML = d.singleNUMAXML(maxCPU)
+	}
+	var cpuset string
+	if pinning != nil {
+		cpuset = types.FormatCPUSet(pinning.VCPUs())
+	}
 
 	var gpus []map[string]string
 	if d.guest.GPUEngineParams.Count() > 0 {
@@ -407,18 +491,36 @@ func (d *VirtDomain) render() ([]byte, error) {
 		"uuid":              uuid,
 		"memory":            d.guest.MemoryInMiB(),
 		"cpu":               d.guest.CPU,
+		"max_cpu":           maxCPU,
 		"gpus":              gpus,
-		"sysvol":            string(sysVolXML),
+		"sysvol":            sysVolBootXML,
 		"datavols":          dataVols,
 		"interface":         d.getInterfaceType(),
 		"pair":              d.guest.NetworkPairName(),
 		"mac":               d.guest.MAC,
 		"bandwidth":         d.networkBandwidth(),
-		"cache_passthrough": configs.Conf.VirtCPUCachePassthrough,
+		"cache_passthrough": configs.Conf.VirtCPUCachePassthrough && cpuModel.Mode == types.CPUModeHostPassthrough,
+		"cpu_attrs":         cpuAttrs,
+		"cpu_model_xml":     cpuModelXML,
 		"metadata_xml":      metadataXML,
 		"cloud_init_xml":    ciXML,
 		"cdrom_src_xml":     cdromSrcXML,
+		"iso_src_xml":       isoSrcXML,
+		"iso_boot_xml":      isoBootXML,
 		"vnc":               vncXML,
+		"serial_log_xml":    d.serialLogXML(),
+		"loader_xml":        d.loaderXML(fw),
+		"secure_boot":       fw.SecureBoot,
+		"tpm_xml":           d.tpmXML(fw),
+		"cpuset":            cpuset,
+		"cputune_xml":       cputuneXML,
+		"numatune_xml":      numatuneXML,
+		"numa_xml":          numaXML,
+		"hugepage_size":     hugepageSize,
+		"max_memory_xml":    maxMemXML,
+		"memory_devices":    memDevXML,
+		"on_crash":          onCrash,
+		"crash_devices_xml": crashDevicesXML,
 	}
 
 	return template.Render(d.guestTemplateFilepath(), guestXML, args)
@@ -483,7 +585,7 @@ func (d *VirtDomain) cloudInitXML() (string, string, error) {
 	switch {
 	case obj.URL != "":
 		ciXML = fmt.Sprintf("<entry name='serial'>ds=nocloud-net;s=%s</entry>", obj.URL)
-	case obj.Username != "" || obj.Password != "":
+	case obj.IsNoCloud():
 		output := filepath.Join(configs.Conf.VirtCloudInitDir, fmt.Sprintf("%s.iso", d.guest.ID))
 		if err := obj.GenerateISO(output); err != nil {
 			return "", "", err
@@ -581,33 +683,29 @@ func (d *VirtDomain) vncConfig() (string, error) {
 	return vncXML, nil
 }
 
-func (d *VirtDomain) networkBandwidth() map[string]string {
-	// the Unit of libvirt is kbyte/s
-	// the default settings is avg: 2Gbps, peak: 3Gbps
-	// 1Gbps=1000Mbps=1000000Kbps=1000000000bit
-	ans := map[string]string{
-		"average": fmt.Sprintf("%d", 2000000/8),
-		"peak":    fmt.Sprintf("%d", 3000000/8),
-	}
-	ss, ok := d.guest.JSONLabels["instance/nic-bandwidth"]
-	if !ok {
-		return ans
+// hugepageSize returns the hugepage size in KiB, 0 means no hugepages.
+func (d *VirtDomain) hugepageSize() (int64, error) {
+	req, ok, err := d.guest.HugepagesRequest()
+	if err != nil || !ok {
+		return 0, err
 	}
+	return req.SizeInKiB()
+}
 
-	bandwidth := map[string]int64{}
-	err := json.Unmarshal([]byte(ss), &bandwidth)
-	if err != nil {
-		// just print log and use default values.
-		log.Warnf(context.TODO(), "Invalid bandwidth label: %s", ss)
-	} else {
-		if v, ok := bandwidth["average"]; ok {
-			ans["average"] = fmt.Sprintf("%d", v/8000)
-		}
-		if v, ok := bandwidth["peak"]; ok {
-			ans["peak"] = fmt.Sprintf("%d", v/8000)
+func (d *VirtDomain) networkBandwidth() map[string]string {
+	bw := &types.NICBandwidth{}
+	if ss, ok := d.guest.JSONLabels[types.NICBandwidthLabelKey]; ok {
+		if err := json.Unmarshal([]byte(ss), bw); err != nil {
+			// just print log and use default values.
+			log.Warnf(context.TODO(), "Invalid bandwidth label: %s", ss)
+			bw = nil
 		}
 	}
-	return ans
+	average, peak := nicBandwidthKB(bw)
+	return map[string]string{
+		"average": fmt.Sprintf("%d", average),
+		"peak":    fmt.Sprintf("%d", peak),
+	}
 }
 
 // GetXMLString .
@@ -668,9 +766,25 @@ func (d *VirtDomain) setCPU(cpu int, dom libvirt.Domain) error {
 		return nil
 	}
 
+	st, err := dom.GetState()
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+	if st == libvirt.DomainRunning {
+		return d.hotplugCPU(cpu, dom)
+	}
+
+	domcfg, err := getDomainConfig(dom)
+	if err != nil {
+		return err
+	}
+	if domcfg.VCPU != nil && domcfg.CPU != nil && domcfg.CPU.Numa != nil && len(domcfg.CPU.Numa.Cell) == 1 {
+		return d.resizeNUMACPU(cpu, domcfg)
+	}
+
 	flag := libvirt.DomainVcpuConfig
 	// Doesn't set with both Maximum and Current simultaneously.
-	if err := dom.SetVcpusFlags(uint(cpu), flag|libvirt.DomainVcpuMaximum); err != nil {
+	if err := dom.SetVcpusFlags(uint(d.maxVCPUs(cpu)), flag|libvirt.DomainVcpuMaximum); err != nil {
 		return errors.Wrap(err, "")
 	}
 	return dom.SetVcpusFlags(uint(cpu), flag|libvirt.DomainVcpuCurrent)
@@ -686,6 +800,14 @@ func (d *VirtDomain) setMemory(mem int64, dom libvirt.Domain) error {
 	// converts bytes unit to kilobytes
 	mem >>= 10
 
+	layout, err := getMemoryLayout(dom)
+	if err != nil {
+		return err
+	}
+	if layout != nil {
+		return d.hotplugMemory(dom, layout, uint64(mem))
+	}
+
 	flag := libvirt.DomainMemConfig
 	if err := dom.SetMemoryFlags(uint64(mem), flag|libvirt.DomainMemMaximum); err != nil {
 		return errors.Wrap(err, "")
@@ -6,12 +6,18 @@ import (
 	"testing"
 
 	"github.com/antchfx/xmlquery"
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/yavirt/internal/meta"
 	"github.com/projecteru2/yavirt/internal/models"
+	"github.com/projecteru2/yavirt/internal/types"
 	"github.com/projecteru2/yavirt/pkg/libvirt"
 	libmocks "github.com/projecteru2/yavirt/pkg/libvirt/mocks"
+	"github.com/projecteru2/yavirt/pkg/terrors"
 	"github.com/projecteru2/yavirt/pkg/test/assert"
 	"github.com/projecteru2/yavirt/pkg/test/mock"
 	"github.com/projecteru2/yavirt/pkg/utils"
+	vmitypes "github.com/projecteru2/yavirt/pkg/vmimage/types"
+	"libvirt.org/go/libvirtxml"
 )
 
 func TestSetSpec(t *testing.T) {
@@ -22,6 +28,8 @@ func TestSetSpec(t *testing.T) {
 	dom.virt.(*libmocks.Libvirt).On("LookupDomain", mock.Anything).Return(libdom, nil).Once()
 	defer func() { dom.virt.(*libmocks.Libvirt).AssertExpectations(t) }()
 
+	libdom.On("GetState").Return(libvirt.DomainShutoff, nil).Once()
+	libdom.On("GetXMLDesc", mock.Anything).Return("<domain type='kvm'><name>guest</name></domain>", nil).Twice()
 	libdom.On("SetVcpusFlags", uint(1), libvirt.DomainVcpuConfig|libvirt.DomainVcpuMaximum).Return(nil).Once()
 	libdom.On("SetVcpusFlags", uint(1), libvirt.DomainVcpuConfig|libvirt.DomainVcpuCurrent).Return(nil).Once()
 	libdom.On("SetMemoryFlags", uint64(utils.GB>>10), libvirt.DomainMemConfig|libvirt.DomainMemMaximum).Return(nil).Once()
@@ -30,6 +38,80 @@ func TestSetSpec(t *testing.T) {
 	assert.NilErr(t, dom.SetSpec(1, utils.GB))
 }
 
+func TestMemoryHotplug(t *testing.T) {
+	libdom := &libmocks.Domain{}
+	defer libdom.AssertExpectations(t)
+
+	dom := newMockedDomain(t)
+	dom.virt.(*libmocks.Libvirt).On("LookupDomain", mock.Anything).Return(libdom, nil).Once()
+	defer func() { dom.virt.(*libmocks.Libvirt).AssertExpectations(t) }()
+
+	x := `<domain type='kvm'>
+  <name>guest</name>
+  <maxMemory slots='16' unit='KiB'>4194304</maxMemory>
+  <cpu>
+    <numa>
+      <cell id='0' cpus='0' memory='1048576' unit='KiB'/>
+    </numa>
+  </cpu>
+  <devices>
+    <memory model='virtio-mem'>
+      <target>
+        <size unit='KiB'>3145728</size>
+        <node>0</node>
+        <block unit='KiB'>2048</block>
+        <requested unit='KiB'>0</requested>
+      </target>
+    </memory>
+  </devices>
+</domain>`
+	libdom.On("GetXMLDesc", mock.Anything).Return(x, nil).Once()
+	libdom.On("UpdateDevice", mock.MatchedBy(func(x string) bool {
+		return strings.Contains(x, `<requested unit="KiB">1048576</requested>`)
+	})).Return(libvirt.DomainRunning, nil).Once()
+	libdom.On("GetState").Return(libvirt.DomainRunning, nil).Once()
+	libdom.On("SetMemoryFlags", uint64(2*utils.GB>>10), libvirt.DomainMemConfig|libvirt.DomainMemLive).Return(nil).Once()
+
+	assert.NilErr(t, dom.SetSpec(0, 2*utils.GB))
+}
+
+func TestCPUHotplug(t *testing.T) {
+	libdom := &libmocks.Domain{}
+	defer libdom.AssertExpectations(t)
+
+	dom := newMockedDomain(t)
+	dom.virt.(*libmocks.Libvirt).On("LookupDomain", mock.Anything).Return(libdom, nil).Times(3)
+	defer func() { dom.virt.(*libmocks.Libvirt).AssertExpectations(t) }()
+
+	x := "<domain type='kvm'><name>guest</name><vcpu placement='static' current='2'>4</vcpu></domain>"
+	libdom.On("GetState").Return(libvirt.DomainRunning, nil)
+	libdom.On("GetXMLDesc", mock.Anything).Return(x, nil)
+	libdom.On("SetVcpusFlags", uint(4), libvirt.DomainVcpuLive|libvirt.DomainVcpuConfig).Return(nil).Once()
+	libdom.On("SetMemoryFlags", uint64(utils.GB>>10), libvirt.DomainMemConfig|libvirt.DomainMemMaximum).Return(nil).Once()
+	libdom.On("SetMemoryFlags", uint64(utils.GB>>10), libvirt.DomainMemConfig|libvirt.DomainMemCurrent).Return(nil).Once()
+	assert.NilErr(t, dom.SetSpec(4, utils.GB))
+
+	err := dom.SetSpec(8, utils.GB)
+	assert.True(t, errors.Is(err, terrors.ErrLiveResizeUnavailable))
+
+	// the vCPUs which the guest booted with can't be unplugged
+	err = dom.SetSpec(1, utils.GB)
+	assert.True(t, errors.Is(err, terrors.ErrLiveResizeUnavailable))
+}
+
+func TestBootVCPUs(t *testing.T) {
+	domcfg := &libvirtxml.Domain{}
+	assert.NilErr(t, domcfg.Unmarshal(`<domain type='kvm'><vcpu placement='static' current='3'>4</vcpu><vcpus>
+<vcpu id='0' enabled='yes' hotpluggable='no'/><vcpu id='1' enabled='yes' hotpluggable='no'/>
+<vcpu id='2' enabled='yes' hotpluggable='yes'/><vcpu id='3' enabled='no' hotpluggable='yes'/>
+</vcpus></domain>`))
+	assert.Equal(t, 2, bootVCPUs(domcfg))
+	assert.Equal(t, 3, currentVCPUs(domcfg))
+
+	domcfg.VCPUs = nil
+	assert.Equal(t, 3, bootVCPUs(domcfg))
+}
+
 // func TestAttachGPU(t *testing.T) {
 // 	libdom := &libmocks.Domain{}
 // 	defer libdom.AssertExpectations(t)
@@ -87,6 +169,163 @@ func TestExtractHostdevXML(t *testing.T) {
 	assert.Equal(t, `<hostdev mode="subsystem" type="pci" managed="yes"><source><address domain="0x0000" bus="0x81" slot="0x00" function="0x0"></address></source></hostdev>`, xml, "xml is incorrect")
 	fmt.Printf("%s\n", xml)
 }
+
+func TestFirmware(t *testing.T) {
+	dom := newMockedDomain(t)
+	dom.guest.Img = &vmitypes.Image{
+		OS: vmitypes.OSInfo{Firmware: types.FirmwareUEFI, SecureBoot: true},
+	}
+	fw, err := dom.firmware()
+	assert.NilErr(t, err)
+	assert.True(t, fw.IsUEFI())
+	assert.True(t, fw.SecureBoot)
+	assert.False(t, fw.TPM)
+	assert.True(t, strings.Contains(dom.loaderXML(fw), "secure='yes'"))
+	assert.Equal(t, "", dom.tpmXML(fw))
+
+	// the label takes precedence over the image
+	dom.guest.JSONLabels = map[string]string{firmwareLabelKey: `{"tpm": true}`}
+	fw, err = dom.firmware()
+	assert.NilErr(t, err)
+	assert.True(t, fw.SecureBoot)
+	assert.True(t, fw.TPM)
+	assert.True(t, strings.Contains(dom.tpmXML(fw), "emulator"))
+
+	dom.guest.JSONLabels = map[string]string{firmwareLabelKey: `{"type": "bios"}`}
+	_, err = dom.firmware()
+	assert.Err(t, err)
+
+	dom.guest.JSONLabels = map[string]string{firmwareLabelKey: `{"type": "bios", "secure_boot": false}`}
+	fw, err = dom.firmware()
+	assert.NilErr(t, err)
+	assert.Equal(t, "", dom.loaderXML(fw))
+}
+
+func TestCPUModel(t *testing.T) {
+	dom := newMockedDomain(t)
+	model, err := dom.cpuModel()
+	assert.NilErr(t, err)
+	assert.Equal(t, types.CPUModeHostPassthrough, model.Mode)
+	attrs, inner, err := dom.cpuModelXML(model)
+	assert.NilErr(t, err)
+	assert.Equal(t, "mode='host-passthrough' check='full'", attrs)
+	assert.Equal(t, "", inner)
+
+	dom.guest.JSONLabels = map[string]string{
+		types.CPUModelLabelKey: `{"mode": "custom", "model": "Skylake-Server", "features": ["+pcid", "-hle"]}`,
+	}
+	model, err = dom.cpuModel()
+	assert.NilErr(t, err)
+	attrs, inner, err = dom.cpuModelXML(model)
+	assert.NilErr(t, err)
+	assert.Equal(t, "mode='custom' check='partial' match='exact'", attrs)
+	assert.True(t, strings.Contains(inner, "<model fallback='forbid'>Skylake-Server</model>"))
+	assert.True(t, strings.Contains(inner, "<feature policy='require' name='pcid'/>"))
+	assert.True(t, strings.Contains(inner, "<feature policy='disable' name='hle'/>"))
+
+	dom.guest.JSONLabels = map[string]string{types.CPUModelLabelKey: `{"mode": "custom"}`}
+	_, err = dom.cpuModel()
+	assert.Err(t, err)
+}
+
+func TestRescueDomainConfig(t *testing.T) {
+	x := `<domain type='kvm'>
+  <name>guest</name>
+  <os>
+    <type arch='x86_64' machine='q35'>hvm</type>
+    <boot dev='hd'/>
+  </os>
+  <devices>
+    <disk type='file' device='disk'>
+      <source file='/opt/yavirtd/sys.vol'/>
+      <target dev='vda' bus='virtio'/>
+    </disk>
+    <disk type='file' device='disk'>
+      <source file='/opt/yavirtd/data.vol'/>
+      <target dev='vdb' bus='virtio'/>
+    </disk>
+  </devices>
+</domain>`
+	domcfg := &libvirtxml.Domain{}
+	assert.NilErr(t, domcfg.Unmarshal(x))
+	sysDisk := domcfg.Devices.Disks[0]
+
+	rescueDisk := libvirtxml.DomainDisk{
+		Source: &libvirtxml.DomainDiskSource{File: &libvirtxml.DomainDiskSourceFile{File: RescueFilepath("guest")}},
+	}
+	assert.NilErr(t, rescueDomainConfig(domcfg, rescueDisk))
+	disks := domcfg.Devices.Disks
+	assert.Equal(t, 3, len(disks))
+	assert.Equal(t, RescueFilepath("guest"), disks[0].Source.File.File)
+	assert.Equal(t, "vda", disks[0].Target.Dev)
+	assert.Equal(t, uint(1), disks[0].Boot.Order)
+	assert.Equal(t, "/opt/yavirtd/sys.vol", disks[1].Source.File.File)
+	assert.Equal(t, "vdc", disks[1].Target.Dev)
+	assert.Equal(t, 0, len(domcfg.OS.BootDevices))
+
+	assert.NilErr(t, unrescueDomainConfig(domcfg, sysDisk, nil))
+	disks = domcfg.Devices.Disks
+	assert.Equal(t, 2, len(disks))
+	assert.Equal(t, "/opt/yavirtd/sys.vol", disks[0].Source.File.File)
+	assert.Equal(t, "vda", disks[0].Target.Dev)
+	assert.Equal(t, "vdb", disks[1].Target.Dev)
+	assert.Equal(t, "hd", domcfg.OS.BootDevices[0].Dev)
+
+	// the RBD system disk has no source file
+	rbdDisk := libvirtxml.DomainDisk{
+		Device: "disk",
+		Source: &libvirtxml.DomainDiskSource{Network: &libvirtxml.DomainDiskSourceNetwork{Protocol: "rbd", Name: "pool/sys"}},
+		Target: &libvirtxml.DomainDiskTarget{Dev: "vda", Bus: "virtio"},
+	}
+	domcfg.Devices.Disks[0] = rbdDisk
+	assert.NilErr(t, rescueDomainConfig(domcfg, rescueDisk))
+	assert.Equal(t, 3, len(domcfg.Devices.Disks))
+	assert.NilErr(t, unrescueDomainConfig(domcfg, rbdDisk, nil))
+	disks = domcfg.Devices.Disks
+	assert.Equal(t, 2, len(disks))
+	assert.Equal(t, "pool/sys", disks[0].Source.Network.Name)
+	assert.Equal(t, "vdb", disks[1].Target.Dev)
+}
+
+func TestBootOrder(t *testing.T) {
+	dom := newMockedDomain(t)
+	srcXML, bootXML := dom.isoXML()
+	assert.Equal(t, "", srcXML)
+	assert.Equal(t, "", bootXML)
+	diskXML := "<disk type='file' device='disk'><target dev='vda' bus='virtio'/></disk>"
+	x, err := dom.sysVolBootXML(diskXML)
+	assert.NilErr(t, err)
+	assert.Equal(t, diskXML, x)
+
+	dom.guest.ISOPath = "/data/iso/ubuntu.iso"
+	dom.guest.BootOrder = []string{types.BootDeviceCDROM, types.BootDeviceHD}
+	srcXML, bootXML = dom.isoXML()
+	assert.Equal(t, "<source file='/data/iso/ubuntu.iso' />", srcXML)
+	assert.Equal(t, "<boot order='1'/>", bootXML)
+	x, err = dom.sysVolBootXML(diskXML)
+	assert.NilErr(t, err)
+	assert.True(t, strings.Contains(x, `<boot order="2"></boot>`))
+
+	domcfg := &libvirtxml.Domain{
+		OS: &libvirtxml.DomainOS{BootDevices: []libvirtxml.DomainBootDevice{{Dev: "hd"}}},
+		Devices: &libvirtxml.DomainDeviceList{
+			Disks: []libvirtxml.DomainDisk{
+				{Device: "disk", Target: &libvirtxml.DomainDiskTarget{Dev: "vda"}},
+				isoCDROM(""),
+			},
+		},
+	}
+	applyBootOrder(domcfg, dom.guest.BootOrder)
+	assert.Equal(t, 0, len(domcfg.OS.BootDevices))
+	assert.Equal(t, uint(2), domcfg.Devices.Disks[0].Boot.Order)
+	assert.Equal(t, uint(1), domcfg.Devices.Disks[1].Boot.Order)
+
+	applyBootOrder(domcfg, nil)
+	assert.Equal(t, "hd", domcfg.OS.BootDevices[0].Dev)
+	assert.Nil(t, domcfg.Devices.Disks[0].Boot)
+	assert.Nil(t, domcfg.Devices.Disks[1].Boot)
+}
+
 func newMockedDomain(t *testing.T) *VirtDomain {
 	gmod, err := models.NewGuest(nil, nil)
 	assert.NilErr(t, err)
@@ -96,3 +335,65 @@ func newMockedDomain(t *testing.T) *VirtDomain {
 		virt:  &libmocks.Libvirt{},
 	}
 }
+
+func TestCPUQoS(t *testing.T) {
+	assert.Equal(t, "", withCPUQoS("", nil))
+	assert.Equal(t, "<cputune>\n    <shares>512</shares>\n    <quota>-1</quota>\n  </cputune>",
+		withCPUQoS("", &types.CPUQoS{Shares: 512, Quota: -100}))
+
+	pinning := "<cputune>\n    <vcpupin vcpu='0' cpuset='2'/>\n  </cputune>"
+	assert.Equal(t, "<cputune>\n    <vcpupin vcpu='0' cpuset='2'/>\n    <period>50000</period>\n  </cputune>",
+		withCPUQoS(pinning, &types.CPUQoS{Period: 50000}))
+
+	libdom := &libmocks.Domain{}
+	defer libdom.AssertExpectations(t)
+
+	dom := newMockedDomain(t)
+	dom.virt.(*libmocks.Libvirt).On("LookupDomain", mock.Anything).Return(libdom, nil).Once()
+	defer func() { dom.virt.(*libmocks.Libvirt).AssertExpectations(t) }()
+
+	libdom.On("GetState").Return(libvirt.DomainRunning, nil).Once()
+	libdom.On("SetSchedulerParameters", libvirt.TypedParams{
+		"cpu_shares":  uint64(512),
+		"vcpu_period": uint64(defaultCPUPeriod),
+		"vcpu_quota":  int64(unlimitedQuota),
+	}, libvirt.DomainAffectLive|libvirt.DomainAffectConfig).Return(nil).Once()
+	assert.NilErr(t, dom.SetCPUQoS(&types.CPUQoS{Shares: 512}))
+}
+
+func TestCheckCheckpointConfig(t *testing.T) {
+	domcfg := &libvirtxml.Domain{
+		OS: &libvirtxml.DomainOS{},
+		Devices: &libvirtxml.DomainDeviceList{
+			Disks: []libvirtxml.DomainDisk{
+				{
+					Source: &libvirtxml.DomainDiskSource{File: &libvirtxml.DomainDiskSourceFile{File: "/virt/sys.vol"}},
+					Target: &libvirtxml.DomainDiskTarget{Dev: "vda"},
+				},
+			},
+		},
+	}
+	assert.NilErr(t, checkCheckpointConfig(domcfg))
+
+	domcfg.Devices.Disks = append(domcfg.Devices.Disks, libvirtxml.DomainDisk{
+		Source: &libvirtxml.DomainDiskSource{Network: &libvirtxml.DomainDiskSourceNetwork{Protocol: "rbd", Name: "pool/img"}},
+		Target: &libvirtxml.DomainDiskTarget{Dev: "vdb"},
+	})
+	assert.True(t, errors.Is(checkCheckpointConfig(domcfg), terrors.ErrInvalidValue))
+
+	domcfg.Devices.Disks = domcfg.Devices.Disks[:1]
+	domcfg.OS.Loader = &libvirtxml.DomainLoader{Type: "pflash", Path: "/usr/share/OVMF/OVMF_CODE.fd"}
+	assert.True(t, errors.Is(checkCheckpointConfig(domcfg), terrors.ErrInvalidValue))
+}
+
+func TestCloudInitWithoutImage(t *testing.T) {
+	dom := newMockedDomain(t)
+	// installed from a host path ISO
+	dom.guest.Img = nil
+	dom.guest.IPNets = meta.IPNets{&meta.IPNet{}}
+	dom.guest.JSONLabels = map[string]string{"instance/cloud-init": `{"url": "http://127.0.0.1/ci/"}`}
+	ciXML, cdromSrcXML, err := dom.cloudInitXML()
+	assert.NilErr(t, err)
+	assert.Equal(t, "<entry name='serial'>ds=nocloud-net;s=http://127.0.0.1/ci/</entry>", ciXML)
+	assert.Equal(t, "", cdromSrcXML)
+}
@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/yavirt/configs"
+	"github.com/projecteru2/yavirt/pkg/libvirt"
+)
+
+// dumpProgressInterval is how often the progress of dumping is polled.
+const dumpProgressInterval = time.Second
+
+var dumpFormats = map[string]libvirt.DomainCoreDumpFormat{
+	configs.DumpFormatELF:         libvirt.DomainCoreDumpFormatRaw,
+	configs.DumpFormatKdumpZlib:   libvirt.DomainCoreDumpFormatKdumpZlib,
+	configs.DumpFormatKdumpLzo:    libvirt.DomainCoreDumpFormatKdumpLzo,
+	configs.DumpFormatKdumpSnappy: libvirt.DomainCoreDumpFormatKdumpSnappy,
+}
+
+// CoreDump dumps the memory of guest into the host file path in format,
+// the guest is paused while dumping. The progress is polled if it isn't nil,
+// and the dumping is aborted once ctx is done.
+func (d *VirtDomain) CoreDump(ctx context.Context, path, format string, progress func(processed, total uint64)) error {
+	dumpFormat, ok := dumpFormats[format]
+	if !ok {
+		return errors.Errorf("invalid dump format %s", format)
+	}
+	dom, err := d.Lookup()
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		watchDumpJob(ctx, dom, done, progress)
+	}()
+	err = dom.CoreDumpWithFormat(path, dumpFormat, libvirt.DumpMemoryOnly)
+	close(done)
+	wg.Wait()
+	return errors.Wrapf(err, "failed to dump guest %s", d.guest.ID)
+}
+
+func watchDumpJob(ctx context.Context, dom libvirt.Domain, done <-chan struct{}, progress func(processed, total uint64)) {
+	ticker := time.NewTicker(dumpProgressInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ctx.Done():
+			// CoreDumpWithFormat returns the error of aborted job.
+			_ = dom.AbortJob()
+			return
+		case <-ticker.C:
+			if progress == nil {
+				continue
+			}
+			if info, err := dom.GetJobInfo(); err == nil && info.MemTotal > 0 {
+				progress(info.MemProcessed, info.MemTotal)
+			}
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/yavirt/configs"
+	"github.com/projecteru2/yavirt/internal/types"
+)
+
+const firmwareLabelKey = "instance/firmware"
+
+// NvramFilepath returns the UEFI variables file of the guest,
+// it's placed next to the local volumes.
+func NvramFilepath(guestID string) string {
+	return filepath.Join(configs.Conf.VirtDir, fmt.Sprintf("%s_VARS.fd", guestID))
+}
+
+// firmware resolves the firmware of the guest by the order:
+// guest label, image and host's default.
+func (d *VirtDomain) firmware() (*types.FirmwareConfig, error) {
+	fw := &types.FirmwareConfig{
+		Type: configs.Conf.Firmware.Default,
+	}
+	if img := d.guest.Img; img != nil && img.OS.Firmware != "" {
+		fw.Type = img.OS.Firmware
+		fw.SecureBoot = img.OS.SecureBoot
+		fw.TPM = img.OS.TPM
+	}
+	if bs, ok := d.guest.JSONLabels[firmwareLabelKey]; ok {
+		if err := json.Unmarshal([]byte(bs), fw); err != nil {
+			return nil, errors.Wrapf(err, "invalid label %s", firmwareLabelKey)
+		}
+	}
+	if fw.Type == "" {
+		fw.Type = types.FirmwareBIOS
+	}
+	if err := fw.Check(); err != nil {
+		return nil, err
+	}
+	return fw, nil
+}
+
+// loaderXML generates the <loader> and <nvram> elements of <os>,
+// libvirt creates the nvram file from the template at the first boot.
+func (d *VirtDomain) loaderXML(fw *types.FirmwareConfig) string {
+	if !fw.IsUEFI() {
+		return ""
+	}
+	cfg := &configs.Conf.Firmware
+	code, vars, secure := cfg.OVMFCode, cfg.OVMFVars, "no"
+	if fw.SecureBoot {
+		code, vars, secure = cfg.OVMFSecureCode, cfg.OVMFSecureVars, "yes"
+	}
+	return fmt.Sprintf(`<loader readonly='yes' secure='%s' type='pflash'>%s</loader>
+    <nvram template='%s'>%s</nvram>`, secure, code, vars, NvramFilepath(d.guest.ID))
+}
+
+// tpmXML generates an emulated TPM 2.0 device which is backed by swtpm.
+func (d *VirtDomain) tpmXML(fw *types.FirmwareConfig) string {
+	if !fw.TPM {
+		return ""
+	}
+	return fmt.Sprintf(`<tpm model='%s'>
+      <backend type='emulator' version='2.0'/>
+    </tpm>`, configs.Conf.Firmware.TPMModel)
+}
+
+// cleanupFirmware removes the nvram file and the swtpm state of the guest.
+func (d *VirtDomain) cleanupFirmware(uuid string) {
+	_ = os.Remove(NvramFilepath(d.guest.ID))
+	if uuid != "" {
+		_ = os.RemoveAll(filepath.Join(configs.Conf.Firmware.SwtpmStateDir, uuid))
+	}
+}
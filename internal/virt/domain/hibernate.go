@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"github.com/cockroachdb/errors"
+)
+
+// Hibernate saves the memory of the running domain to disk and stops it,
+// the next Boot restores it.
+func (d *VirtDomain) Hibernate() error {
+	if err := d.CheckRunning(); err != nil {
+		return err
+	}
+	dom, err := d.Lookup()
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+	// libvirt restores the saved autostart domains by the startup of host,
+	// the hibernated guest is restored by the next Boot only, which sets it again.
+	if err := dom.SetAutostart(false); err != nil {
+		return errors.Wrapf(err, "failed to disable autostart of guest %s", d.guest.ID)
+	}
+	return errors.Wrapf(dom.ManagedSave(), "failed to hibernate guest %s", d.guest.ID)
+}
+
+// DiscardHibernation removes the saved memory if any, so the next Boot is a cold one.
+func (d *VirtDomain) DiscardHibernation() error {
+	dom, err := d.Lookup()
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+	switch has, err := dom.HasManagedSaveImage(); {
+	case err != nil:
+		return errors.Wrap(err, "")
+	case !has:
+		return nil
+	}
+	return errors.Wrapf(dom.ManagedSaveRemove(), "failed to discard hibernation of guest %s", d.guest.ID)
+}
@@ -0,0 +1,145 @@
+package domain
+
+import (
+	"fmt"
+
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/yavirt/internal/types"
+	"github.com/projecteru2/yavirt/pkg/libvirt"
+	"github.com/projecteru2/yavirt/pkg/terrors"
+	"libvirt.org/go/libvirtxml"
+)
+
+// isoTargetDev is the CD-ROM for the installation media,
+// hda is taken by the cloud-init ISO.
+const isoTargetDev = "hdb"
+
+// ChangeMedia inserts the ISO at path into the ISO CD-ROM, it ejects if path is empty.
+func (d *VirtDomain) ChangeMedia(path string) error {
+	dom, err := d.Lookup()
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+	domcfg, err := getDomainConfig(dom)
+	if err != nil {
+		return err
+	}
+
+	idx := isoCDROMIndex(domcfg)
+	if idx < 0 {
+		// the guests which are defined before the ISO CD-ROM was introduced
+		st, err := dom.GetState()
+		if err != nil {
+			return errors.Wrap(err, "")
+		}
+		if st != libvirt.DomainShutoff {
+			return errors.Wrapf(terrors.ErrInvalidValue, "guest %s has no ISO CD-ROM, stop it to add one", d.guest.ID)
+		}
+		return d.redefine(func(domcfg *libvirtxml.Domain) error {
+			domcfg.Devices.Disks = append(domcfg.Devices.Disks, isoCDROM(path))
+			return nil
+		})
+	}
+
+	disk := domcfg.Devices.Disks[idx]
+	disk.Source = isoSource(path)
+	diskXML, err := disk.Marshal()
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+	_, err = dom.UpdateDevice(diskXML)
+	return errors.Wrapf(err, "failed to change media of guest %s", d.guest.ID)
+}
+
+// SetBootOrder changes the boot order of the persistent config, it takes
+// effect by the next boot, e.g. back to the sys disk after installing from the ISO.
+func (d *VirtDomain) SetBootOrder(order []string) error {
+	return d.redefine(func(domcfg *libvirtxml.Domain) error {
+		if domcfg.Devices == nil || len(domcfg.Devices.Disks) == 0 {
+			return errors.Wrapf(terrors.ErrSysVolumeNotExists, "no disk in domain %s", domcfg.Name)
+		}
+		applyBootOrder(domcfg, order)
+		return nil
+	})
+}
+
+// isoXML generates the <source> and <boot> elements of the ISO CD-ROM.
+func (d *VirtDomain) isoXML() (srcXML, bootXML string) {
+	if d.guest.ISOPath != "" {
+		srcXML = fmt.Sprintf("<source file='%s' />", d.guest.ISOPath)
+	}
+	if order := types.BootIndex(d.guest.BootOrder, types.BootDeviceCDROM); order > 0 {
+		bootXML = fmt.Sprintf("<boot order='%d'/>", order)
+	}
+	return
+}
+
+// sysVolBootXML adds the boot order to the sys disk if the guest has a boot order.
+func (d *VirtDomain) sysVolBootXML(diskXML string) (string, error) {
+	order := types.BootIndex(d.guest.BootOrder, types.BootDeviceHD)
+	if order == 0 {
+		return diskXML, nil
+	}
+	disk := &libvirtxml.DomainDisk{}
+	if err := disk.Unmarshal(diskXML); err != nil {
+		return "", errors.Wrapf(err, "failed to unmarshal sys disk xml")
+	}
+	disk.Boot = &libvirtxml.DomainDeviceBoot{Order: order}
+	return disk.Marshal()
+}
+
+// applyBootOrder sets the per-device boot order of the sys disk (always the first one)
+// and the ISO CD-ROM, the default is booting from the sys disk.
+func applyBootOrder(domcfg *libvirtxml.Domain, order []string) {
+	disks := domcfg.Devices.Disks
+	for i := range disks {
+		disks[i].Boot = nil
+	}
+	if len(order) == 0 {
+		if domcfg.OS != nil {
+			domcfg.OS.BootDevices = []libvirtxml.DomainBootDevice{{Dev: types.BootDeviceHD}}
+		}
+		return
+	}
+	// <os><boot> and per-device <boot> are mutually exclusive.
+	if domcfg.OS != nil {
+		domcfg.OS.BootDevices = nil
+	}
+	if o := types.BootIndex(order, types.BootDeviceHD); o > 0 && len(disks) > 0 {
+		disks[0].Boot = &libvirtxml.DomainDeviceBoot{Order: o}
+	}
+	if idx := isoCDROMIndex(domcfg); idx >= 0 {
+		if o := types.BootIndex(order, types.BootDeviceCDROM); o > 0 {
+			disks[idx].Boot = &libvirtxml.DomainDeviceBoot{Order: o}
+		}
+	}
+}
+
+func isoCDROMIndex(domcfg *libvirtxml.Domain) int {
+	if domcfg.Devices == nil {
+		return -1
+	}
+	for i, disk := range domcfg.Devices.Disks {
+		if disk.Device == "cdrom" && disk.Target != nil && disk.Target.Dev == isoTargetDev {
+			return i
+		}
+	}
+	return -1
+}
+
+func isoCDROM(path string) libvirtxml.DomainDisk {
+	return libvirtxml.DomainDisk{
+		Device:   "cdrom",
+		Driver:   &libvirtxml.DomainDiskDriver{Name: "qemu", Type: "raw"},
+		Source:   isoSource(path),
+		Target:   &libvirtxml.DomainDiskTarget{Dev: isoTargetDev, Bus: "sata"},
+		ReadOnly: &libvirtxml.DomainDiskReadOnly{},
+	}
+}
+
+func isoSource(path string) *libvirtxml.DomainDiskSource {
+	if path == "" {
+		return nil
+	}
+	return &libvirtxml.DomainDiskSource{File: &libvirtxml.DomainDiskSourceFile{File: path}}
+}
@@ -0,0 +1,225 @@
+package domain
+
+import (
+	"fmt"
+
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/yavirt/configs"
+	"github.com/projecteru2/yavirt/pkg/libvirt"
+	"github.com/projecteru2/yavirt/pkg/terrors"
+	"libvirt.org/go/libvirtxml"
+)
+
+const (
+	// MemoryHotplugVirtioMem plugs memory by a virtio-mem device whose requested size is adjusted.
+	MemoryHotplugVirtioMem = "virtio-mem"
+	// MemoryHotplugDIMM plugs memory by attaching pc-dimm devices.
+	MemoryHotplugDIMM = "dimm"
+)
+
+// memoryBlockSize returns the granularity of hotplug in KiB,
+// it can't be less than the hugepage size.
+func memoryBlockSize(hugepageSize int64) int64 {
+	return max(configs.Conf.Resource.MemoryHotplug.BlockSize>>10, hugepageSize, 1)
+}
+
+// maxMemory returns the max memory of the guest in KiB, 0 means hotplug is disabled.
+func (d *VirtDomain) maxMemory(hugepageSize int64) int64 {
+	cfg := &configs.Conf.Resource.MemoryHotplug
+	if (cfg.Mode != MemoryHotplugVirtioMem && cfg.Mode != MemoryHotplugDIMM) || cfg.MaxMemoryRatio <= 1 {
+		return 0
+	}
+	mem := d.guest.Memory >> 10
+	maxMem := min(int64(float64(mem)*cfg.MaxMemoryRatio), configs.Conf.Resource.MaxMemory>>10)
+	block := memoryBlockSize(hugepageSize)
+	maxMem = mem + (maxMem-mem)/block*block
+	if maxMem <= mem {
+		return 0
+	}
+	return maxMem
+}
+
+// memoryHotplugXML generates <maxMemory> and the virtio-mem device,
+// the hotpluggable memory must be assigned to a guest NUMA cell.
+func (d *VirtDomain) memoryHotplugXML(hugepageSize int64) (maxMemXML, memDevXML string) {
+	maxMem := d.maxMemory(hugepageSize)
+	if maxMem == 0 {
+		return
+	}
+	cfg := &configs.Conf.Resource.MemoryHotplug
+	maxMemXML = fmt.Sprintf("<maxMemory slots='%d' unit='KiB'>%d</maxMemory>", cfg.Slots, maxMem)
+	if cfg.Mode != MemoryHotplugVirtioMem {
+		return
+	}
+	memDevXML = fmt.Sprintf(`<memory model='virtio-mem'>
+      <target>
+        <size unit='KiB'>%d</size>
+        <node>0</node>
+        <block unit='KiB'>%d</block>
+        <requested unit='KiB'>0</requested>
+      </target>
+    </memory>`, maxMem-d.guest.Memory>>10, memoryBlockSize(hugepageSize))
+	return
+}
+
+// singleNUMAXML puts all vCPUs and memory into one guest NUMA cell.
+func (d *VirtDomain) singleNUMAXML(maxCPU int) string {
+	return fmt.Sprintf("<numa>\n      <cell id='0' cpus='0-%d' memory='%d' unit='MiB'/>\n    </numa>",
+		maxCPU-1, d.guest.MemoryInMiB())
+}
+
+// memoryLayout is the memory of a domain in KiB.
+type memoryLayout struct {
+	maxMemory uint64
+	slots     uint
+	// the memory which isn't hotplugged
+	base   uint64
+	dimms  []uint64
+	virtio *libvirtxml.DomainMemorydev
+}
+
+func (l *memoryLayout) total() uint64 {
+	total := l.base
+	for _, size := range l.dimms {
+		total += size
+	}
+	if l.virtio != nil && l.virtio.Target.Requested != nil {
+		total += toKiB(l.virtio.Target.Requested.Value, l.virtio.Target.Requested.Unit)
+	}
+	return total
+}
+
+// getMemoryLayout returns nil if the memory of the domain isn't hotpluggable.
+func getMemoryLayout(dom libvirt.Domain) (*memoryLayout, error) {
+	domcfg, err := getDomainConfig(dom)
+	if err != nil {
+		return nil, err
+	}
+	if domcfg.MaximumMemory == nil || domcfg.CPU == nil || domcfg.CPU.Numa == nil {
+		return nil, nil //nolint:nilnil
+	}
+
+	layout := &memoryLayout{
+		maxMemory: toKiB(domcfg.MaximumMemory.Value, domcfg.MaximumMemory.Unit),
+		slots:     domcfg.MaximumMemory.Slots,
+	}
+	for _, cell := range domcfg.CPU.Numa.Cell {
+		layout.base += toKiB(cell.Memory, cell.Unit)
+	}
+	if domcfg.Devices != nil {
+		for i := range domcfg.Devices.Memorydevs {
+			dev := &domcfg.Devices.Memorydevs[i]
+			if dev.Target == nil || dev.Target.Size == nil {
+				continue
+			}
+			switch dev.Model {
+			case MemoryHotplugVirtioMem:
+				layout.virtio = dev
+			case MemoryHotplugDIMM:
+				layout.dimms = append(layout.dimms, toKiB(dev.Target.Size.Value, dev.Target.Size.Unit))
+			}
+		}
+	}
+	return layout, nil
+}
+
+// hotplugMemory grows the memory by virtio-mem or DIMM,
+// and the balloon is used to shrink it.
+func (d *VirtDomain) hotplugMemory(dom libvirt.Domain, layout *memoryLayout, mem uint64) error {
+	if mem > layout.maxMemory {
+		return errors.Wrapf(terrors.ErrInvalidValue,
+			"invalid memory: %dKiB, it exceeds the max memory %dKiB", mem, layout.maxMemory)
+	}
+
+	switch {
+	case layout.virtio != nil:
+		if err := d.requestVirtioMem(dom, layout, mem); err != nil {
+			return err
+		}
+	case mem > layout.total():
+		if err := d.attachDIMM(dom, layout, mem-layout.total()); err != nil {
+			return err
+		}
+	}
+	return setBalloon(dom, mem)
+}
+
+func (d *VirtDomain) requestVirtioMem(dom libvirt.Domain, layout *memoryLayout, mem uint64) error {
+	target := layout.virtio.Target
+	size := toKiB(target.Size.Value, target.Size.Unit)
+	block := uint64(1)
+	if target.Block != nil {
+		block = max(toKiB(target.Block.Value, target.Block.Unit), 1)
+	}
+
+	var requested uint64
+	if mem > layout.base {
+		requested = (mem - layout.base + block - 1) / block * block
+	}
+	if requested > size {
+		return errors.Wrapf(terrors.ErrInvalidValue,
+			"invalid memory: %dKiB, the virtio-mem device can only provide %dKiB", mem, size)
+	}
+
+	target.Requested = &libvirtxml.DomainMemorydevTargetRequested{Value: uint(requested), Unit: "KiB"}
+	buf, err := layout.virtio.Marshal()
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+	_, err = dom.UpdateDevice(buf)
+	return errors.Wrap(err, "")
+}
+
+func (d *VirtDomain) attachDIMM(dom libvirt.Domain, layout *memoryLayout, delta uint64) error {
+	if uint(len(layout.dimms)) >= layout.slots {
+		return errors.Wrapf(terrors.ErrInvalidValue, "no free memory slot, %d in use", len(layout.dimms))
+	}
+	hugepageSize, err := d.hugepageSize()
+	if err != nil {
+		return err
+	}
+	block := uint64(memoryBlockSize(hugepageSize))
+	delta = (delta + block - 1) / block * block
+	if layout.total()+delta > layout.maxMemory {
+		return errors.Wrapf(terrors.ErrInvalidValue,
+			"invalid memory: the aligned one exceeds the max memory %dKiB", layout.maxMemory)
+	}
+
+	dimm := fmt.Sprintf(`<memory model='dimm'>
+  <target>
+    <size unit='KiB'>%d</size>
+    <node>0</node>
+  </target>
+</memory>`, delta)
+	_, err = dom.AttachDevice(dimm)
+	return errors.Wrap(err, "")
+}
+
+// setBalloon sets the current memory in KiB, it takes effect immediately if the domain is running.
+func setBalloon(dom libvirt.Domain, mem uint64) error {
+	st, err := dom.GetState()
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+	flags := libvirt.DomainMemConfig
+	if st == libvirt.DomainRunning {
+		flags |= libvirt.DomainMemLive
+	}
+	return errors.Wrap(dom.SetMemoryFlags(mem, flags), "")
+}
+
+func toKiB(value uint, unit string) uint64 {
+	v := uint64(value)
+	switch unit {
+	case "b", "bytes":
+		return v >> 10
+	case "M", "MiB":
+		return v << 10
+	case "G", "GiB":
+		return v << 20
+	case "T", "TiB":
+		return v << 30
+	default:
+		return v
+	}
+}
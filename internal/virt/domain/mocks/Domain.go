@@ -111,6 +111,42 @@ func (_m *Domain) Boot(ctx context.Context) error {
 	return r0
 }
 
+// ChangeMedia provides a mock function with given fields: path
+func (_m *Domain) ChangeMedia(path string) error {
+	ret := _m.Called(path)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ChangeMedia")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(path)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CheckCheckpoint provides a mock function with given fields:
+func (_m *Domain) CheckCheckpoint() error {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for CheckCheckpoint")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // CheckRunning provides a mock function with given fields:
 func (_m *Domain) CheckRunning() error {
 	ret := _m.Called()
@@ -147,6 +183,42 @@ func (_m *Domain) CheckShutoff() error {
 	return r0
 }
 
+// CoreDump provides a mock function with given fields: ctx, path, format, progress
+func (_m *Domain) CoreDump(ctx context.Context, path string, format string, progress func(uint64, uint64)) error {
+	ret := _m.Called(ctx, path, format, progress)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CoreDump")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, func(uint64, uint64)) error); ok {
+		r0 = rf(ctx, path, format, progress)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateCheckpoint provides a mock function with given fields: name, desc
+func (_m *Domain) CreateCheckpoint(name string, desc string) error {
+	ret := _m.Called(name, desc)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateCheckpoint")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(name, desc)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Define provides a mock function with given fields:
 func (_m *Domain) Define() error {
 	ret := _m.Called()
@@ -165,6 +237,24 @@ func (_m *Domain) Define() error {
 	return r0
 }
 
+// DeleteCheckpoint provides a mock function with given fields: name
+func (_m *Domain) DeleteCheckpoint(name string) error {
+	ret := _m.Called(name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteCheckpoint")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(name)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DetachGPU provides a mock function with given fields: prod, count
 func (_m *Domain) DetachGPU(prod string, count int) (libvirt.DomainState, error) {
 	ret := _m.Called(prod, count)
@@ -221,6 +311,24 @@ func (_m *Domain) DetachVolume(dev string) (libvirt.DomainState, error) {
 	return r0, r1
 }
 
+// DiscardHibernation provides a mock function with given fields:
+func (_m *Domain) DiscardHibernation() error {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for DiscardHibernation")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // GetConsoleTtyname provides a mock function with given fields:
 func (_m *Domain) GetConsoleTtyname() (string, error) {
 	ret := _m.Called()
@@ -305,6 +413,24 @@ func (_m *Domain) GetUUID() (string, error) {
 	return r0, r1
 }
 
+// Hibernate provides a mock function with given fields:
+func (_m *Domain) Hibernate() error {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Hibernate")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Lookup provides a mock function with given fields:
 func (_m *Domain) Lookup() (pkglibvirt.Domain, error) {
 	ret := _m.Called()
@@ -383,6 +509,24 @@ func (_m *Domain) ReplaceSysVolume(diskXML string) error {
 	return r0
 }
 
+// Rescue provides a mock function with given fields: diskXML
+func (_m *Domain) Rescue(diskXML string) error {
+	ret := _m.Called(diskXML)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Rescue")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(diskXML)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Resume provides a mock function with given fields:
 func (_m *Domain) Resume() error {
 	ret := _m.Called()
@@ -401,6 +545,108 @@ func (_m *Domain) Resume() error {
 	return r0
 }
 
+// RevertCheckpoint provides a mock function with given fields: name
+func (_m *Domain) RevertCheckpoint(name string) error {
+	ret := _m.Called(name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevertCheckpoint")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(name)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Screenshot provides a mock function with given fields:
+func (_m *Domain) Screenshot() ([]byte, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Screenshot")
+	}
+
+	var r0 []byte
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]byte, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []byte); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetBootOrder provides a mock function with given fields: order
+func (_m *Domain) SetBootOrder(order []string) error {
+	ret := _m.Called(order)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetBootOrder")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]string) error); ok {
+		r0 = rf(order)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetCPUQoS provides a mock function with given fields: qos
+func (_m *Domain) SetCPUQoS(qos *types.CPUQoS) error {
+	ret := _m.Called(qos)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetCPUQoS")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*types.CPUQoS) error); ok {
+		r0 = rf(qos)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetNICBandwidth provides a mock function with given fields: bw
+func (_m *Domain) SetNICBandwidth(bw *types.NICBandwidth) error {
+	ret := _m.Called(bw)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetNICBandwidth")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*types.NICBandwidth) error); ok {
+		r0 = rf(bw)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // SetSpec provides a mock function with given fields: cpu, mem
 func (_m *Domain) SetSpec(cpu int, mem int64) error {
 	ret := _m.Called(cpu, mem)
@@ -419,6 +665,42 @@ func (_m *Domain) SetSpec(cpu int, mem int64) error {
 	return r0
 }
 
+// SetThrottleGroup provides a mock function with given fields: dev, group
+func (_m *Domain) SetThrottleGroup(dev string, group *types.ThrottleGroup) error {
+	ret := _m.Called(dev, group)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetThrottleGroup")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, *types.ThrottleGroup) error); ok {
+		r0 = rf(dev, group)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetVolumeQoS provides a mock function with given fields: dev, qos
+func (_m *Domain) SetVolumeQoS(dev string, qos *types.VolumeQoS) error {
+	ret := _m.Called(dev, qos)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetVolumeQoS")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, *types.VolumeQoS) error); ok {
+		r0 = rf(dev, qos)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Shutdown provides a mock function with given fields: ctx, force
 func (_m *Domain) Shutdown(ctx context.Context, force bool) error {
 	ret := _m.Called(ctx, force)
@@ -473,6 +755,24 @@ func (_m *Domain) Undefine() error {
 	return r0
 }
 
+// Unrescue provides a mock function with given fields: diskXML
+func (_m *Domain) Unrescue(diskXML string) error {
+	ret := _m.Called(diskXML)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Unrescue")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(diskXML)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewDomain creates a new instance of Domain. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewDomain(t interface {
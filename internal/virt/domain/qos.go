@@ -0,0 +1,143 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/yavirt/internal/types"
+	"github.com/projecteru2/yavirt/pkg/libvirt"
+)
+
+// the defaults of libvirt, which the zero values of CPUQoS fall back to.
+const (
+	defaultCPUShares = 1024
+	defaultCPUPeriod = 100000
+	unlimitedQuota   = -1
+)
+
+// withCPUQoS merges the QoS into the <cputune> generated by CPU pinning.
+func withCPUQoS(cputune string, qos *types.CPUQoS) string {
+	if qos.IsZero() {
+		return cputune
+	}
+	var buf strings.Builder
+	if qos.Shares > 0 {
+		fmt.Fprintf(&buf, "    <shares>%d</shares>\n", qos.Shares)
+	}
+	if qos.Period > 0 {
+		fmt.Fprintf(&buf, "    <period>%d</period>\n", qos.Period)
+	}
+	if qos.Quota != 0 {
+		fmt.Fprintf(&buf, "    <quota>%d</quota>\n", cpuQuota(qos.Quota))
+	}
+	if cputune == "" {
+		return "<cputune>\n" + buf.String() + "  </cputune>"
+	}
+	return strings.Replace(cputune, "  </cputune>", buf.String()+"  </cputune>", 1)
+}
+
+func cpuQuota(quota int64) int64 {
+	if quota <= 0 {
+		return unlimitedQuota
+	}
+	return quota
+}
+
+// SetCPUQoS changes the <cputune> of domain, it takes effect at once if
+// the domain is running. The zero values restore the defaults.
+func (d *VirtDomain) SetCPUQoS(qos *types.CPUQoS) error {
+	dom, flags, err := d.lookupForQoS()
+	if err != nil {
+		return err
+	}
+	params := libvirt.TypedParams{
+		"cpu_shares":  uint64(defaultCPUShares),
+		"vcpu_period": uint64(defaultCPUPeriod),
+		"vcpu_quota":  cpuQuota(qos.Quota),
+	}
+	if qos.Shares > 0 {
+		params["cpu_shares"] = qos.Shares
+	}
+	if qos.Period > 0 {
+		params["vcpu_period"] = qos.Period
+	}
+	return errors.Wrapf(dom.SetSchedulerParameters(params, flags), "failed to set CPU QoS of guest %s", d.guest.ID)
+}
+
+// SetVolumeQoS changes the <iotune> of the disk dev, 0 means unlimited.
+func (d *VirtDomain) SetVolumeQoS(dev string, qos *types.VolumeQoS) error {
+	return d.setBlockIOTune(dev, qos, nil)
+}
+
+// SetThrottleGroup puts the disk dev into group, the disks in the same
+// group share its limits.
+func (d *VirtDomain) SetThrottleGroup(dev string, group *types.ThrottleGroup) error {
+	return d.setBlockIOTune(dev, &group.VolumeQoS, libvirt.TypedParams{"group_name": group.Name})
+}
+
+func (d *VirtDomain) setBlockIOTune(dev string, qos *types.VolumeQoS, params libvirt.TypedParams) error {
+	dom, flags, err := d.lookupForQoS()
+	if err != nil {
+		return err
+	}
+	if params == nil {
+		params = libvirt.TypedParams{}
+	}
+	params["read_iops_sec"] = uint64(qos.ReadIOPS)
+	params["write_iops_sec"] = uint64(qos.WriteIOPS)
+	params["read_bytes_sec"] = uint64(qos.ReadBPS)
+	params["write_bytes_sec"] = uint64(qos.WriteBPS)
+	return errors.Wrapf(dom.SetBlockIOTune(dev, params, flags), "failed to set QoS of %s of guest %s", dev, d.guest.ID)
+}
+
+// SetNICBandwidth changes the <bandwidth> of the default NIC,
+// the zero values fall back to the defaults as Define does.
+func (d *VirtDomain) SetNICBandwidth(bw *types.NICBandwidth) error {
+	dom, flags, err := d.lookupForQoS()
+	if err != nil {
+		return err
+	}
+	average, peak := nicBandwidthKB(bw)
+	params := libvirt.TypedParams{
+		"inbound.average":  average,
+		"inbound.peak":     peak,
+		"outbound.average": average,
+		"outbound.peak":    peak,
+	}
+	return errors.Wrapf(dom.SetInterfaceParameters(d.guest.MAC, params, flags), "failed to set bandwidth of guest %s", d.guest.ID)
+}
+
+// lookupForQoS returns the domain, and the flags to change both the live
+// and the persistent config if it's active, otherwise the persistent one only.
+func (d *VirtDomain) lookupForQoS() (libvirt.Domain, libvirt.DomainModificationImpact, error) {
+	dom, err := d.Lookup()
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "")
+	}
+	st, err := dom.GetState()
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "")
+	}
+	if st == libvirt.DomainRunning || st == libvirt.DomainPaused {
+		return dom, libvirt.DomainAffectLive | libvirt.DomainAffectConfig, nil
+	}
+	return dom, libvirt.DomainAffectConfig, nil
+}
+
+// nicBandwidthKB converts bw to kbytes/s which is the unit of libvirt,
+// the default is avg: 2Gbps, peak: 3Gbps.
+// 1Gbps=1000Mbps=1000000Kbps=1000000000bit
+func nicBandwidthKB(bw *types.NICBandwidth) (average, peak uint32) {
+	average, peak = 2000000/8, 3000000/8
+	if bw == nil {
+		return
+	}
+	if bw.Average > 0 {
+		average = uint32(bw.Average / 8000)
+	}
+	if bw.Peak > 0 {
+		peak = uint32(bw.Peak / 8000)
+	}
+	return
+}
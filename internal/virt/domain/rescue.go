@@ -0,0 +1,157 @@
+package domain
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/yavirt/configs"
+	"github.com/projecteru2/yavirt/pkg/terrors"
+	"libvirt.org/go/libvirtxml"
+)
+
+// RescueFilepath returns the rescue disk of the guest, it's an overlay
+// of the rescue image which is placed next to the local volumes.
+func RescueFilepath(guestID string) string {
+	return filepath.Join(configs.Conf.VirtDir, fmt.Sprintf("%s_rescue.qcow2", guestID))
+}
+
+// Rescue redefines the domain to boot from the rescue disk, the original
+// system disk is kept as a secondary disk.
+func (d *VirtDomain) Rescue(diskXML string) error {
+	return d.redefine(func(domcfg *libvirtxml.Domain) error {
+		rescueDisk := libvirtxml.DomainDisk{}
+		if err := rescueDisk.Unmarshal(diskXML); err != nil {
+			return errors.Wrapf(err, "failed to unmarshal rescue disk xml")
+		}
+		return rescueDomainConfig(domcfg, rescueDisk)
+	})
+}
+
+// Unrescue removes the rescue disk and restores the system disk as the boot disk.
+func (d *VirtDomain) Unrescue(diskXML string) error {
+	return d.redefine(func(domcfg *libvirtxml.Domain) error {
+		sysDisk := libvirtxml.DomainDisk{}
+		if err := sysDisk.Unmarshal(diskXML); err != nil {
+			return errors.Wrapf(err, "failed to unmarshal sys disk xml")
+		}
+		return unrescueDomainConfig(domcfg, sysDisk, d.guest.BootOrder)
+	})
+}
+
+// redefine applies fn to the persistent config of the domain.
+func (d *VirtDomain) redefine(fn func(*libvirtxml.Domain) error) error {
+	xmldoc, err := d.GetXMLString()
+	if err != nil {
+		return errors.Wrapf(err, "failed to get domain xml of guest %s", d.guest.ID)
+	}
+	domcfg := &libvirtxml.Domain{}
+	if err = domcfg.Unmarshal(xmldoc); err != nil {
+		return errors.Wrapf(err, "failed to unmarshal domain xml of guest %s", d.guest.ID)
+	}
+	if err := fn(domcfg); err != nil {
+		return err
+	}
+	newXMLDoc, err := domcfg.Marshal()
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal new domain xml for guest %s", d.guest.ID)
+	}
+	if _, err := d.virt.DefineDomain(newXMLDoc); err != nil {
+		return errors.Wrapf(err, "failed define domain for guest %s", d.guest.ID)
+	}
+	return nil
+}
+
+// rescueDomainConfig takes the target of the system disk (always the first one)
+// for the rescue disk, then moves the system disk to a free target.
+func rescueDomainConfig(domcfg *libvirtxml.Domain, rescueDisk libvirtxml.DomainDisk) error {
+	if domcfg.Devices == nil || len(domcfg.Devices.Disks) == 0 {
+		return errors.Wrapf(terrors.ErrSysVolumeNotExists, "no disk in domain %s", domcfg.Name)
+	}
+	disks := domcfg.Devices.Disks
+	for i := range disks {
+		disks[i].Boot = nil
+	}
+	sysDisk := disks[0]
+	if sysDisk.Target == nil {
+		return errors.Errorf("system disk of domain %s has no target", domcfg.Name)
+	}
+	dev, err := freeDiskTarget(disks)
+	if err != nil {
+		return err
+	}
+
+	rescueDisk.Target = &libvirtxml.DomainDiskTarget{Dev: sysDisk.Target.Dev, Bus: sysDisk.Target.Bus}
+	rescueDisk.Boot = &libvirtxml.DomainDeviceBoot{Order: 1}
+	// the PCI address of the system disk is taken by the rescue disk.
+	rescueDisk.Address, sysDisk.Address = sysDisk.Address, nil
+	sysDisk.Target = &libvirtxml.DomainDiskTarget{Dev: dev, Bus: sysDisk.Target.Bus}
+
+	domcfg.Devices.Disks = append([]libvirtxml.DomainDisk{rescueDisk, sysDisk}, disks[1:]...)
+	// <os><boot> and per-device <boot> are mutually exclusive.
+	if domcfg.OS != nil {
+		domcfg.OS.BootDevices = nil
+	}
+	return nil
+}
+
+// unrescueDomainConfig removes the rescue disk and the moved system disk,
+// then puts sysDisk back as the first disk and restores the boot order.
+func unrescueDomainConfig(domcfg *libvirtxml.Domain, sysDisk libvirtxml.DomainDisk, bootOrder []string) error {
+	if domcfg.Devices == nil || len(domcfg.Devices.Disks) < 2 {
+		return errors.Errorf("domain %s isn't in rescue mode", domcfg.Name)
+	}
+	if sysDisk.Source == nil {
+		return errors.Errorf("system disk of domain %s has no source", domcfg.Name)
+	}
+	disks := []libvirtxml.DomainDisk{sysDisk}
+	for _, disk := range domcfg.Devices.Disks[1:] {
+		if sameDiskSource(disk.Source, sysDisk.Source) {
+			continue
+		}
+		disks = append(disks, disk)
+	}
+	domcfg.Devices.Disks = disks
+	applyBootOrder(domcfg, bootOrder)
+	return nil
+}
+
+// sameDiskSource reports whether the disks are backed by the same file,
+// block device or network image, e.g. the RBD system volume.
+func sameDiskSource(a, b *libvirtxml.DomainDiskSource) bool {
+	switch {
+	case a == nil || b == nil:
+		return false
+	case a.File != nil && b.File != nil:
+		return a.File.File == b.File.File
+	case a.Block != nil && b.Block != nil:
+		return a.Block.Dev == b.Block.Dev
+	case a.Network != nil && b.Network != nil:
+		return a.Network.Protocol == b.Network.Protocol && a.Network.Name == b.Network.Name
+	default:
+		return false
+	}
+}
+
+// cleanupRescue removes the rescue disk of the guest if any.
+func (d *VirtDomain) cleanupRescue() {
+	_ = os.Remove(RescueFilepath(d.guest.ID))
+}
+
+// freeDiskTarget returns the first unused vdX target.
+func freeDiskTarget(disks []libvirtxml.DomainDisk) (string, error) {
+	used := map[string]struct{}{}
+	for _, disk := range disks {
+		if disk.Target != nil {
+			used[disk.Target.Dev] = struct{}{}
+		}
+	}
+	for c := 'a'; c <= 'z'; c++ {
+		dev := fmt.Sprintf("vd%c", c)
+		if _, ok := used[dev]; !ok {
+			return dev, nil
+		}
+	}
+	return "", errors.Errorf("no free disk target")
+}
@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/yavirt/pkg/utils"
+)
+
+const mimePNG = "image/png"
+
+// Screenshot captures the first display of the running domain in PNG.
+func (d *VirtDomain) Screenshot() ([]byte, error) {
+	if err := d.CheckRunning(); err != nil {
+		return nil, err
+	}
+	dom, err := d.Lookup()
+	if err != nil {
+		return nil, errors.Wrap(err, "")
+	}
+	mime, data, err := dom.Screenshot(0)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to take screenshot of guest %s", d.guest.ID)
+	}
+	if mime == mimePNG {
+		return data, nil
+	}
+	// image/x-portable-pixmap
+	return utils.PPMToPNG(data)
+}
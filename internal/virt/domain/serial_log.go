@@ -0,0 +1,13 @@
+package domain
+
+import (
+	"fmt"
+
+	"github.com/projecteru2/yavirt/internal/console"
+)
+
+// serialLogXML logs the serial console to a file, so the boot failures can be
+// read after the fact, the file is written and rotated by virtlogd.
+func (d *VirtDomain) serialLogXML() string {
+	return fmt.Sprintf("<log file='%s' append='on'/>", console.SerialLogFilepath(d.guest.ID))
+}
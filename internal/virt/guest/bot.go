@@ -35,10 +35,20 @@ type Bot interface { //nolint
 	Shutdown(ctx context.Context, force bool) error
 	Suspend() error
 	Resume() error
+	Hibernate() error
+	DiscardHibernation() error
+	CheckCheckpoint() error
+	CreateCheckpoint(name, desc string) error
+	RevertCheckpoint(name string) error
+	DeleteCheckpoint(name string) error
+	SetCPUQoS(qos *types.CPUQoS) error
+	SetNICBandwidth(bw *types.NICBandwidth) error
+	CoreDump(ctx context.Context, path, format string, progress func(processed, total uint64)) error
 	Resize(cpu int, mem int64) error
 
 	Migrate() error
 	OpenConsole(context.Context, types.OpenConsoleFlags) (*libvirt.Console, error)
+	Screenshot() ([]byte, error)
 	ExecuteCommand(context.Context, []string) (output []byte, exitCode, pid int, err error)
 	GetState() (libvirt.DomainState, error)
 	GetUUID() (string, error)
@@ -60,10 +70,16 @@ type Bot interface { //nolint
 
 	// storage-related functions
 	ReplaceSysVolume(vol volume.Volume) error
+	Rescue(rescuePath string) error
+	Unrescue(sysVol volume.Volume) error
+	ChangeMedia(isoPath string) error
+	SetBootOrder(order []string) error
 	AmplifyVolume(vol volume.Volume, delta int64) error
 	AttachVolume(volmod volume.Volume) (rollback func(), err error)
 	DetachVolume(vol volume.Volume) (err error)
 	CheckVolume(volume.Volume) error
+	SetVolumeQoS(vol volume.Volume, qos *types.VolumeQoS) error
+	SetThrottleGroup(vol volume.Volume, group *types.ThrottleGroup) error
 	RepairVolume(volume.Volume) error
 	CreateSnapshot(volume.Volume) error
 	CommitSnapshot(volume.Volume, string) error
@@ -122,6 +138,10 @@ func (v *bot) Migrate() error {
 func (v *bot) Boot(ctx context.Context) error {
 	logger := log.WithFunc("Boot").WithField("guest", v.guest.ID)
 
+	if v.guest.OSDistro == "" && v.guest.ISOPath == "" && v.guest.RescueImage == "" {
+		v.detectDistro(ctx)
+	}
+
 	logger.Info(ctx, "Boot: stage1 -> Domain boot...")
 	if err := v.dom.Boot(ctx); err != nil {
 		return err
@@ -302,6 +322,34 @@ func (v *bot) ReplaceSysVolume(vol volume.Volume) error {
 	return v.dom.ReplaceSysVolume(string(diskXML))
 }
 
+// Rescue boots the domain from the disk at rescuePath.
+func (v *bot) Rescue(rescuePath string) error {
+	diskXML := fmt.Sprintf(`<disk type='file' device='disk'>
+  <driver name='qemu' type='qcow2'/>
+  <source file='%s'/>
+</disk>`, rescuePath)
+	return v.dom.Rescue(diskXML)
+}
+
+// ChangeMedia inserts the ISO into the domain, it ejects if isoPath is empty.
+func (v *bot) ChangeMedia(isoPath string) error {
+	return v.dom.ChangeMedia(isoPath)
+}
+
+// SetBootOrder .
+func (v *bot) SetBootOrder(order []string) error {
+	return v.dom.SetBootOrder(order)
+}
+
+// Unrescue boots the domain from sysVol again.
+func (v *bot) Unrescue(sysVol volume.Volume) error {
+	diskXML, err := sysVol.GenerateXML()
+	if err != nil {
+		return err
+	}
+	return v.dom.Unrescue(string(diskXML))
+}
+
 // AmplifyVolume .
 func (v *bot) AmplifyVolume(vol volume.Volume, delta int64) (err error) {
 	dom, err := v.dom.Lookup()
@@ -369,8 +417,41 @@ func (v *bot) setupVols() (err error) {
 	return
 }
 
+// detectDistro inspects the sys volume before the domain runs, the distro
+// is kept in the guest, so it's inspected only once.
+func (v *bot) detectDistro(ctx context.Context) {
+	logger := log.WithFunc("detectDistro").WithField("guest", v.guest.ID)
+	sysVol, err := v.guest.sysVolume()
+	if err != nil {
+		logger.Warnf(ctx, "failed to get sys volume: %s", err)
+		return
+	}
+	distro := types.Unknown
+	if gfs, err := sysVol.GetGfx(); err != nil {
+		logger.Warnf(ctx, "failed to inspect sys volume: %s", err)
+	} else {
+		defer gfs.Close()
+		if distro, err = gfs.Distro(); err != nil {
+			logger.Warnf(ctx, "failed to get distro: %s", err)
+			distro = types.Unknown
+		}
+	}
+	v.guest.OSDistro = distro
+	if err := v.guest.Save(); err != nil {
+		logger.Warnf(ctx, "failed to save distro %s: %s", distro, err)
+	}
+}
+
 func (v *bot) setupNics(ctx context.Context) error {
-	if err := nic.NewNicList(v.guest.IPs, v.ga).Setup(ctx); err != nil {
+	var osInfo *vmitypes.OSInfo
+	if v.guest.Img != nil {
+		osInfo = &v.guest.Img.OS
+	}
+	backend, err := nic.SelectBackend(v.guest.JSONLabels[types.NetworkBackendLabelKey], v.guest.OSDistro, osInfo)
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+	if err := nic.NewNicList(v.guest.IPs, v.ga, backend).Setup(ctx); err != nil {
 		return errors.Wrap(err, "")
 	}
 	return nil
@@ -409,6 +490,66 @@ func (v *bot) reloadGA() error {
 	return nil
 }
 
+// Hibernate .
+func (v *bot) Hibernate() error {
+	return v.dom.Hibernate()
+}
+
+// DiscardHibernation .
+func (v *bot) DiscardHibernation() error {
+	return v.dom.DiscardHibernation()
+}
+
+// CheckCheckpoint .
+func (v *bot) CheckCheckpoint() error {
+	return v.dom.CheckCheckpoint()
+}
+
+// CreateCheckpoint .
+func (v *bot) CreateCheckpoint(name, desc string) error {
+	return v.dom.CreateCheckpoint(name, desc)
+}
+
+// RevertCheckpoint .
+func (v *bot) RevertCheckpoint(name string) error {
+	return v.dom.RevertCheckpoint(name)
+}
+
+// DeleteCheckpoint .
+func (v *bot) DeleteCheckpoint(name string) error {
+	return v.dom.DeleteCheckpoint(name)
+}
+
+// Screenshot .
+func (v *bot) Screenshot() ([]byte, error) {
+	return v.dom.Screenshot()
+}
+
+// SetCPUQoS .
+func (v *bot) SetCPUQoS(qos *types.CPUQoS) error {
+	return v.dom.SetCPUQoS(qos)
+}
+
+// SetNICBandwidth .
+func (v *bot) SetNICBandwidth(bw *types.NICBandwidth) error {
+	return v.dom.SetNICBandwidth(bw)
+}
+
+// CoreDump .
+func (v *bot) CoreDump(ctx context.Context, path, format string, progress func(processed, total uint64)) error {
+	return v.dom.CoreDump(ctx, path, format, progress)
+}
+
+// SetVolumeQoS .
+func (v *bot) SetVolumeQoS(vol volume.Volume, qos *types.VolumeQoS) error {
+	return v.dom.SetVolumeQoS(vol.GetDevice(), qos)
+}
+
+// SetThrottleGroup .
+func (v *bot) SetThrottleGroup(vol volume.Volume, group *types.ThrottleGroup) error {
+	return v.dom.SetThrottleGroup(vol.GetDevice(), group)
+}
+
 func (v *bot) OpenConsole(_ context.Context, flags types.OpenConsoleFlags) (*libvirt.Console, error) {
 	err := v.dom.CheckRunning()
 	if err != nil {
@@ -499,7 +640,25 @@ func (v *bot) GetState() (libvirt.DomainState, error) {
 }
 
 func (v *bot) Resize(cpu int, mem int64) error {
-	return v.dom.SetSpec(cpu, mem)
+	if err := v.dom.SetSpec(cpu, mem); err != nil {
+		return err
+	}
+	if cpu < 1 {
+		return nil
+	}
+	switch st, err := v.dom.GetState(); {
+	case err != nil:
+		return errors.Wrap(err, "")
+	case st != libvirt.DomainRunning:
+		return nil
+	}
+	// the hotplugged vCPUs may be onlined by udev rules already,
+	// so it's just a warning if the guest agent is unavailable.
+	ctx := context.TODO()
+	if err := v.ga.SetOnlineVCPUs(ctx, cpu); err != nil {
+		log.WithFunc("bot.Resize").Warnf(ctx, "failed to online vCPUs of %s: %s", v.guest.ID, err)
+	}
+	return nil
 }
 
 // OpenFile .
@@ -0,0 +1,127 @@
+package guest
+
+import (
+	"context"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/core/log"
+	"github.com/projecteru2/yavirt/configs"
+	"github.com/projecteru2/yavirt/internal/meta"
+	"github.com/projecteru2/yavirt/internal/models"
+	"github.com/projecteru2/yavirt/internal/types"
+	"github.com/projecteru2/yavirt/pkg/terrors"
+)
+
+// CreateCheckpoint captures the disks, and the memory and the device state
+// if the guest is running.
+func (g *Guest) CreateCheckpoint(ctx context.Context, name, desc string) (*types.Checkpoint, error) {
+	if err := types.CheckCheckpointName(name); err != nil {
+		return nil, err
+	}
+	running, err := g.checkCheckpointStatus()
+	if err != nil {
+		return nil, err
+	}
+	if err := g.botOperate(func(bot Bot) error {
+		return bot.CheckCheckpoint()
+	}); err != nil {
+		return nil, err
+	}
+	cps, err := models.ListCheckpoints(g.ID)
+	if err != nil {
+		return nil, err
+	}
+	if limit := configs.Conf.MaxCheckpointsCount; len(cps) >= limit {
+		return nil, errors.Wrapf(terrors.ErrInvalidValue, "guest %s has %d checkpoints already", g.ID, limit)
+	}
+
+	cp := &types.Checkpoint{
+		Name:        name,
+		GuestID:     g.ID,
+		Description: desc,
+		CreatedAt:   time.Now().UTC(),
+		Running:     running,
+		CPU:         g.CPU,
+		Memory:      g.Memory,
+		Volumes:     g.volumeIDs(),
+	}
+	// the metadata is created first since the name is unique
+	if err := models.CreateCheckpoint(cp); err != nil {
+		return nil, err
+	}
+	if err := g.botOperate(func(bot Bot) error {
+		return bot.CreateCheckpoint(name, desc)
+	}); err != nil {
+		if derr := models.DeleteCheckpoint(g.ID, name); derr != nil {
+			log.WithFunc("Guest.CreateCheckpoint").Warnf(ctx, "failed to delete checkpoint metadata: %s", derr)
+		}
+		return nil, err
+	}
+	return cp, nil
+}
+
+// RevertCheckpoint reverts the guest to the checkpoint, it resumes where it
+// was if the checkpoint was taken while running, otherwise it's stopped.
+func (g *Guest) RevertCheckpoint(_ context.Context, name string) error {
+	cp, err := models.LoadCheckpoint(g.ID, name)
+	if err != nil {
+		return err
+	}
+	if _, err := g.checkCheckpointStatus(); err != nil {
+		return err
+	}
+	if err := cp.CheckSpec(g.CPU, g.Memory, g.volumeIDs()); err != nil {
+		return err
+	}
+
+	return g.botOperate(func(bot Bot) error {
+		if err := bot.RevertCheckpoint(name); err != nil {
+			return err
+		}
+		if !cp.Running {
+			return g.ForwardStatus(meta.StatusStopped, true)
+		}
+		// it's a new qemu process
+		if err := g.joinEthernet(); err != nil {
+			return err
+		}
+		if err := g.limitBandwidth(); err != nil {
+			return err
+		}
+		return g.ForwardStatus(meta.StatusRunning, true)
+	})
+}
+
+// DeleteCheckpoint .
+func (g *Guest) DeleteCheckpoint(_ context.Context, name string) error {
+	if _, err := models.LoadCheckpoint(g.ID, name); err != nil {
+		return err
+	}
+	if err := g.botOperate(func(bot Bot) error {
+		return bot.DeleteCheckpoint(name)
+	}); err != nil {
+		return err
+	}
+	return models.DeleteCheckpoint(g.ID, name)
+}
+
+func (g *Guest) checkCheckpointStatus() (running bool, err error) {
+	switch g.Status {
+	case meta.StatusRunning:
+		return true, nil
+	case meta.StatusStopped:
+		return false, nil
+	default:
+		return false, errors.Wrapf(terrors.ErrForwardStatus,
+			"only stopped/running guest can be checkpointed, but it's %s", g.Status)
+	}
+}
+
+func (g *Guest) volumeIDs() []string {
+	ids := make([]string, len(g.Vols))
+	for i, vol := range g.Vols {
+		ids[i] = vol.GetID()
+	}
+	return ids
+}
@@ -0,0 +1,234 @@
+package guest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/yavirt/configs"
+	"github.com/projecteru2/yavirt/internal/meta"
+	"github.com/projecteru2/yavirt/internal/types"
+	interutils "github.com/projecteru2/yavirt/internal/utils"
+	"github.com/projecteru2/yavirt/pkg/terrors"
+)
+
+const dumpTimeLayout = "20060102T150405Z"
+
+// Dump writes the memory of guest into its dir under the dump dir, the guest
+// is paused while dumping. The expired dumps are removed before it, and it's
+// refused if the dumps would exceed the quota, which takes the memory of
+// guest as the size of dump. The progress is sent to progress if it isn't nil,
+// and it's dropped if the receiver isn't ready.
+func (g *Guest) Dump(ctx context.Context, opts *types.DumpOptions, progress chan<- types.DumpProgress) (*types.Dump, error) {
+	cfg := &configs.Conf.CoreDump
+	format := cfg.Format
+	if opts != nil && opts.Format != "" {
+		format = opts.Format
+	}
+	if err := configs.CheckDumpFormat(format); err != nil {
+		return nil, errors.Wrapf(terrors.ErrInvalidValue, "%s", err)
+	}
+	switch g.Status {
+	case meta.StatusRunning, meta.StatusPaused:
+	default:
+		return nil, errors.Wrapf(terrors.ErrForwardStatus,
+			"only running/paused guest can be dumped, but it's %s", g.Status)
+	}
+
+	now := time.Now().UTC()
+	if err := pruneDumps(cfg.Dir, cfg.Retention, now); err != nil {
+		return nil, err
+	}
+	if err := checkDumpQuota(cfg.Dir, cfg.Quota, g.Memory); err != nil {
+		return nil, err
+	}
+	dir := filepath.Join(cfg.Dir, g.ID)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, errors.Wrap(err, "")
+	}
+
+	// the suffix tells the dumps apart which are created in the same second
+	name := fmt.Sprintf("%s-%s.%s", now.Format(dumpTimeLayout), interutils.RandomString(8), format)
+	dump := &types.Dump{
+		GuestID:   g.ID,
+		Name:      name,
+		Path:      filepath.Join(dir, name),
+		Format:    format,
+		CreatedAt: now,
+	}
+	onProgress := func(processed, total uint64) {
+		if progress == nil {
+			return
+		}
+		select {
+		case progress <- types.DumpProgress{Processed: processed, Total: total}:
+		default:
+		}
+	}
+	if err := g.botOperate(func(bot Bot) error {
+		return bot.CoreDump(ctx, dump.Path, format, onProgress)
+	}); err != nil {
+		_ = os.Remove(dump.Path)
+		return nil, err
+	}
+
+	info, err := os.Stat(dump.Path)
+	if err != nil {
+		return nil, errors.Wrap(err, "")
+	}
+	dump.Size = info.Size()
+	return dump, nil
+}
+
+// ListDumps returns the dumps of guest id from the oldest one,
+// the expired dumps are removed before listing.
+func ListDumps(id string) ([]*types.Dump, error) {
+	cfg := &configs.Conf.CoreDump
+	if err := pruneDumps(cfg.Dir, cfg.Retention, time.Now()); err != nil {
+		return nil, err
+	}
+	return listDumps(cfg.Dir, id)
+}
+
+func listDumps(root, id string) ([]*types.Dump, error) {
+	dir := filepath.Join(root, id)
+	entries, err := os.ReadDir(dir)
+	switch {
+	case os.IsNotExist(err):
+		return nil, nil
+	case err != nil:
+		return nil, errors.Wrapf(err, "failed to read dump dir %s", dir)
+	}
+
+	var dumps []*types.Dump
+	for _, entry := range entries {
+		prefix, format, ok := strings.Cut(entry.Name(), ".")
+		if entry.IsDir() || !ok {
+			continue
+		}
+		ts, _, _ := strings.Cut(prefix, "-")
+		createdAt, err := time.Parse(dumpTimeLayout, ts)
+		if err != nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		dumps = append(dumps, &types.Dump{
+			GuestID:   id,
+			Name:      entry.Name(),
+			Path:      filepath.Join(dir, entry.Name()),
+			Format:    format,
+			Size:      info.Size(),
+			CreatedAt: createdAt,
+		})
+	}
+	sort.Slice(dumps, func(i, j int) bool { return dumps[i].CreatedAt.Before(dumps[j].CreatedAt) })
+	return dumps, nil
+}
+
+// ReadDump reads at most size bytes of the dump name of guest id from offset,
+// eof reports whether the end of dump is reached.
+func ReadDump(id, name string, offset, size int64) (data []byte, eof bool, err error) {
+	return readDump(configs.Conf.CoreDump.Dir, id, name, offset, size)
+}
+
+func readDump(root, id, name string, offset, size int64) ([]byte, bool, error) {
+	if name == "" || name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+		return nil, false, errors.Wrapf(terrors.ErrInvalidValue, "invalid dump name %q", name)
+	}
+	if offset < 0 || size <= 0 {
+		return nil, false, errors.Wrapf(terrors.ErrInvalidValue, "invalid offset %d or size %d", offset, size)
+	}
+	f, err := os.Open(filepath.Join(root, id, name))
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "failed to open dump %s of guest %s", name, id)
+	}
+	defer f.Close()
+
+	buf := make([]byte, size)
+	n, err := f.ReadAt(buf, offset)
+	switch {
+	case errors.Is(err, io.EOF):
+		return buf[:n], true, nil
+	case err != nil:
+		return nil, false, errors.Wrapf(err, "failed to read dump %s of guest %s", name, id)
+	}
+	return buf[:n], false, nil
+}
+
+// pruneDumps removes the dumps of all guests which were modified before
+// retention, they are kept forever if retention isn't positive.
+func pruneDumps(root string, retention time.Duration, now time.Time) error {
+	if retention <= 0 {
+		return nil
+	}
+	deadline := now.Add(-retention)
+	return walkDumps(root, func(path string, info os.FileInfo) error {
+		if !info.ModTime().Before(deadline) {
+			return nil
+		}
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return errors.Wrapf(err, "failed to remove dump %s", path)
+		}
+		return nil
+	})
+}
+
+// checkDumpQuota refuses the dump of size if the dumps would exceed quota.
+func checkDumpQuota(root string, quota, size int64) error {
+	if quota <= 0 {
+		return nil
+	}
+	used := size
+	if err := walkDumps(root, func(_ string, info os.FileInfo) error {
+		used += info.Size()
+		return nil
+	}); err != nil {
+		return err
+	}
+	if used > quota {
+		return errors.Wrapf(terrors.ErrDumpQuotaExceeded, "the dumps would take %d bytes, but the quota is %d", used, quota)
+	}
+	return nil
+}
+
+// walkDumps calls fn for the files in the dirs of guests under root.
+func walkDumps(root string, fn func(path string, info os.FileInfo) error) error {
+	dirs, err := os.ReadDir(root)
+	switch {
+	case os.IsNotExist(err):
+		return nil
+	case err != nil:
+		return errors.Wrapf(err, "failed to read dump dir %s", root)
+	}
+	for _, dir := range dirs {
+		if !dir.IsDir() {
+			continue
+		}
+		entries, err := os.ReadDir(filepath.Join(root, dir.Name()))
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil {
+				continue
+			}
+			if err := fn(filepath.Join(root, dir.Name(), entry.Name()), info); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package guest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/yavirt/pkg/terrors"
+	"github.com/projecteru2/yavirt/pkg/test/assert"
+)
+
+func writeDump(t *testing.T, root, id, name string, size int, mtime time.Time) {
+	dir := filepath.Join(root, id)
+	assert.NilErr(t, os.MkdirAll(dir, 0755))
+	path := filepath.Join(dir, name)
+	assert.NilErr(t, os.WriteFile(path, make([]byte, size), 0644))
+	assert.NilErr(t, os.Chtimes(path, mtime, mtime))
+}
+
+func TestDumps(t *testing.T) {
+	root := t.TempDir()
+	now := time.Now()
+	writeDump(t, root, "g1", "20240102T030405Z-0a1b2c3d.elf", 100, now.Add(-time.Hour))
+	writeDump(t, root, "g1", "20240101T030405Z.kdump-zlib", 50, now.Add(-2*time.Hour))
+	writeDump(t, root, "g1", "garbage", 10, now)
+	writeDump(t, root, "g2", "20230101T030405Z.elf", 200, now.Add(-48*time.Hour))
+
+	dumps, err := listDumps(root, "g1")
+	assert.NilErr(t, err)
+	assert.Equal(t, 2, len(dumps))
+	assert.Equal(t, "kdump-zlib", dumps[0].Format)
+	assert.Equal(t, int64(50), dumps[0].Size)
+	assert.Equal(t, "elf", dumps[1].Format)
+	assert.Equal(t, "20240102T030405Z-0a1b2c3d.elf", dumps[1].Name)
+	assert.Equal(t, filepath.Join(root, "g1", "20240102T030405Z-0a1b2c3d.elf"), dumps[1].Path)
+
+	data, eof, err := readDump(root, "g1", dumps[1].Name, 60, 30)
+	assert.NilErr(t, err)
+	assert.Equal(t, 30, len(data))
+	assert.False(t, eof)
+	data, eof, err = readDump(root, "g1", dumps[1].Name, 90, 30)
+	assert.NilErr(t, err)
+	assert.Equal(t, 10, len(data))
+	assert.True(t, eof)
+	_, _, err = readDump(root, "g1", "../g2/20230101T030405Z.elf", 0, 10)
+	assert.Err(t, err)
+
+	dumps, err = listDumps(root, "g3")
+	assert.NilErr(t, err)
+	assert.Equal(t, 0, len(dumps))
+
+	// 360 bytes are used
+	assert.NilErr(t, checkDumpQuota(root, 0, 1000))
+	assert.NilErr(t, checkDumpQuota(root, 400, 40))
+	err = checkDumpQuota(root, 400, 41)
+	assert.True(t, errors.Is(err, terrors.ErrDumpQuotaExceeded))
+
+	assert.NilErr(t, pruneDumps(root, 0, now))
+	assert.NilErr(t, pruneDumps(root, 24*time.Hour, now))
+	dumps, err = listDumps(root, "g2")
+	assert.NilErr(t, err)
+	assert.Equal(t, 0, len(dumps))
+	assert.NilErr(t, checkDumpQuota(root, 200, 40))
+}
@@ -8,6 +8,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"sync"
 	"time"
@@ -16,6 +17,7 @@ import (
 	"github.com/projecteru2/core/log"
 	cpumemtypes "github.com/projecteru2/core/resource/plugins/cpumem/types"
 	"github.com/projecteru2/yavirt/configs"
+	"github.com/projecteru2/yavirt/internal/eru/resources"
 	"github.com/projecteru2/yavirt/internal/meta"
 	"github.com/projecteru2/yavirt/internal/models"
 	"github.com/projecteru2/yavirt/internal/types"
@@ -24,6 +26,7 @@ import (
 	"github.com/projecteru2/yavirt/internal/volume"
 	"github.com/projecteru2/yavirt/internal/volume/base"
 	volFact "github.com/projecteru2/yavirt/internal/volume/factory"
+	"github.com/projecteru2/yavirt/internal/volume/local"
 	"github.com/projecteru2/yavirt/pkg/libvirt"
 	"github.com/projecteru2/yavirt/pkg/terrors"
 	"github.com/projecteru2/yavirt/pkg/utils"
@@ -113,6 +116,9 @@ func (g *Guest) UpdateStateIfNecessary() error {
 				_ = g.ForwardStatus(meta.StatusStopped, true)
 			case (g.Status == meta.StatusStopped) && dce.IsRunning():
 				_ = g.ForwardStatus(meta.StatusRunning, true)
+			case (g.Status == meta.StatusHibernated) && dce.IsRunning():
+				// restored by the autostart of libvirt
+				_ = g.ForwardStatus(meta.StatusRunning, true)
 			}
 		}
 		return nil
@@ -144,6 +150,8 @@ func (g *Guest) start(ctx context.Context) error {
 		if err := bot.Boot(ctx); err != nil {
 			return err
 		}
+		// the hugepages reserved for the guest are taken by the domain now
+		resources.GetManager().ReleaseHugepages(g.ID)
 		log.Debugf(ctx, "Entering joinEthernet")
 		if err := g.joinEthernet(); err != nil {
 			return err
@@ -181,11 +189,93 @@ func (g *Guest) Resize(cpumem *cpumemtypes.EngineParams, gpu *gputypes.EnginePar
 	}
 
 	log.WithFunc("Guest.Resize").Infof(context.TODO(), "Resize(%s): Resize cpu and memory if necessary", g.ID)
-	if int(cpumem.CPU) == g.CPU && cpumem.Memory == g.Memory {
+	return g.resizeCPUMem(int(cpumem.CPU), cpumem.Memory)
+}
+
+// ResizeSpec resizes the CPU and memory only, the volumes and GPUs are untouched.
+func (g *Guest) ResizeSpec(cpu int, mem int64) error {
+	if !g.CheckForwardStatus(meta.StatusResizing) {
+		return errors.Wrapf(terrors.ErrForwardStatus, "only stopped/running guest can be resized, but it's %s", g.Status)
+	}
+	return g.resizeCPUMem(cpu, mem)
+}
+
+// ResizeFlavor resizes the guest to flavor, the sys volume is grown to
+// the sys disk size of flavor, and the QoS of sys volume and the NIC bandwidth
+// are replaced by the ones of flavor, nil means unlimited.
+func (g *Guest) ResizeFlavor(ctx context.Context, flavor *types.Flavor) error {
+	if !g.CheckForwardStatus(meta.StatusResizing) {
+		return errors.Wrapf(terrors.ErrForwardStatus, "only stopped/running guest can be resized, but it's %s", g.Status)
+	}
+	if err := g.CheckFlavor(flavor); err != nil {
+		return err
+	}
+	sysVol, err := g.sysVolume()
+	if err != nil {
+		return err
+	}
+	if err := g.resizeCPUMem(flavor.CPU, flavor.Memory); err != nil {
+		return err
+	}
+	if flavor.SysDiskSize > 0 {
+		if err := g.amplifyOrigVol(sysVol, flavor.SysDiskSize); err != nil {
+			return err
+		}
+	}
+
+	qos := &types.GuestQoS{Bandwidth: &types.NICBandwidth{}}
+	if flavor.Bandwidth != nil {
+		*qos.Bandwidth = *flavor.Bandwidth
+	}
+	// the sys volume in a throttle group takes the QoS of group,
+	// it's refused by UpdateQoS only if the flavor limits it.
+	if lv, ok := sysVol.(*local.Volume); flavor.VolumeQoS != nil || (ok && lv.ThrottleGroup == "") {
+		vq := types.VolumeQoS{}
+		if flavor.VolumeQoS != nil {
+			vq = *flavor.VolumeQoS
+		}
+		qos.Volumes = map[string]types.VolumeQoS{sysVol.GetID(): vq}
+	}
+	if err := g.UpdateQoS(ctx, qos); err != nil {
+		return err
+	}
+
+	if g.JSONLabels == nil {
+		g.JSONLabels = map[string]string{}
+	}
+	g.JSONLabels[types.FlavorLabelKey] = flavor.Name
+	return g.Save()
+}
+
+// CheckFlavor refuses the flavor whose CPU pinning or hugepages differ from
+// the guest, they're decided at creation and can't be changed by resizing.
+func (g *Guest) CheckFlavor(flavor *types.Flavor) error {
+	pinning, _, err := g.CPUPinningRequest()
+	if err != nil {
+		return err
+	}
+	if !reflect.DeepEqual(pinning, flavor.CPUPinning) {
+		return errors.Wrapf(terrors.ErrInvalidValue, "can't change the CPU pinning of guest %s by flavor %s", g.ID, flavor.Name)
+	}
+	hugepages, _, err := g.HugepagesRequest()
+	if err != nil {
+		return err
+	}
+	if !reflect.DeepEqual(hugepages, flavor.Hugepages) {
+		return errors.Wrapf(terrors.ErrInvalidValue, "can't change the hugepages of guest %s by flavor %s", g.ID, flavor.Name)
+	}
+	return nil
+}
+
+func (g *Guest) resizeCPUMem(cpu int, mem int64) error {
+	if cpu == g.CPU && mem == g.Memory {
 		return nil
 	}
+	if g.CPUPinning != nil && cpu != g.CPU {
+		return errors.Wrapf(terrors.ErrInvalidValue, "can't change the CPU count of the guest with pinned CPUs")
+	}
 
-	return g.resizeSpec(int(cpumem.CPU), cpumem.Memory)
+	return g.resizeSpec(cpu, mem)
 }
 
 func (g *Guest) handleResizeGPU(eParams *gputypes.EngineParams) (err error) {
@@ -236,6 +326,16 @@ func (g *Guest) handleResizeVolumes(newVolMap map[string]volume.Volume) error {
 		}
 	}
 
+	// the targets of disks are shifted by the rescue disk,
+	// so it's refused before any volume is changed.
+	if g.RescueImage != "" {
+		for mountDir := range newVolMap {
+			if _, ok := existVolMap[mountDir]; !ok {
+				return errors.Wrapf(terrors.ErrInvalidValue, "guest %s is in rescue mode, unrescue it first", g.ID)
+			}
+		}
+	}
+
 	for _, mountDir := range detachMountDirs {
 		existVol := existVolMap[mountDir]
 		if err := g.detachVol(existVol); err != nil {
@@ -264,6 +364,48 @@ func (g *Guest) handleResizeVolumes(newVolMap map[string]volume.Volume) error {
 	return nil
 }
 
+// AttachVolume attaches a new data volume.
+func (g *Guest) AttachVolume(vol volume.Volume) error {
+	// the targets of disks are shifted by the rescue disk
+	if g.RescueImage != "" {
+		return errors.Wrapf(terrors.ErrInvalidValue, "guest %s is in rescue mode, unrescue it first", g.ID)
+	}
+	if _, err := g.volumeByMountDir(vol.GetMountDir()); err == nil {
+		return errors.Wrapf(terrors.ErrInvalidValue, "volume %s exists", vol.GetMountDir())
+	}
+	return g.attachVol(vol)
+}
+
+// DetachVolume detaches the data volume which is mounted at mountDir.
+func (g *Guest) DetachVolume(mountDir string) error {
+	vol, err := g.volumeByMountDir(mountDir)
+	if err != nil {
+		return err
+	}
+	if vol.IsSys() {
+		return errors.Wrapf(terrors.ErrInvalidValue, "can't detach the sys volume")
+	}
+	return g.detachVol(vol)
+}
+
+// AmplifyVolume grows the volume which is mounted at mountDir to size bytes.
+func (g *Guest) AmplifyVolume(mountDir string, size int64) error {
+	vol, err := g.volumeByMountDir(mountDir)
+	if err != nil {
+		return err
+	}
+	return g.amplifyOrigVol(vol, size)
+}
+
+func (g *Guest) volumeByMountDir(mountDir string) (volume.Volume, error) {
+	for _, vol := range g.Vols {
+		if vol.GetMountDir() == mountDir {
+			return vol, nil
+		}
+	}
+	return nil, errors.Wrapf(terrors.ErrInvalidValue, "no volume mounted at %s", mountDir)
+}
+
 func (g *Guest) amplifyOrigVol(existVol volume.Volume, expectSize int64) error {
 	ctx := context.TODO()
 
@@ -314,6 +456,9 @@ func (g *Guest) detachVol(existVol volume.Volume) error {
 }
 
 func (g *Guest) attachVol(vol volume.Volume) (err error) {
+	if err := g.JoinLabeledThrottleGroup(vol); err != nil {
+		return err
+	}
 	devName := g.nextVolumeName()
 
 	vol.SetGuestID(g.ID)
@@ -346,15 +491,36 @@ func (g *Guest) attachVol(vol volume.Volume) (err error) {
 }
 
 func (g *Guest) resizeSpec(cpu int, mem int64) error {
-	if err := g.botOperate(func(bot Bot) error {
+	ctx := context.TODO()
+	err := g.botOperate(func(bot Bot) error {
 		return bot.Resize(cpu, mem)
-	}); err != nil {
+	})
+	if errors.Is(err, terrors.ErrLiveResizeUnavailable) && g.Status == meta.StatusRunning {
+		log.WithFunc("Guest.resizeSpec").Warnf(ctx, "%s can't be resized live, restart it: %s", g.ID, err)
+		err = g.coldResize(ctx, cpu, mem)
+	}
+	if err != nil {
 		return errors.Wrap(err, "")
 	}
 
 	return g.Guest.Resize(cpu, mem)
 }
 
+// coldResize stops the guest, resizes it and starts it again,
+// the guest is always started even if the resizing failed.
+func (g *Guest) coldResize(ctx context.Context, cpu int, mem int64) error {
+	if err := g.Stop(ctx, false); err != nil {
+		return errors.Wrap(err, "")
+	}
+	resizeErr := g.botOperate(func(bot Bot) error {
+		return bot.Resize(cpu, mem)
+	})
+	if err := g.Start(ctx, false); err != nil {
+		return errors.CombineErrors(resizeErr, err)
+	}
+	return resizeErr
+}
+
 // ListSnapshot If volID == "", list snapshots of all vols. Else will find vol with matching volID.
 func (g *Guest) ListSnapshot(volID string) (map[volume.Volume]base.Snapshots, error) {
 	volSnap := make(map[volume.Volume]base.Snapshots)
@@ -544,11 +710,23 @@ func (g *Guest) migrate() error {
 }
 
 func (g *Guest) PrepareVolumesForCreate(ctx context.Context) error {
+	iso, err := g.ISOInstall()
+	if err != nil {
+		return err
+	}
+	var sysOpts []base.Option
+	if iso != nil {
+		if g.ISOPath, err = resolveISO(ctx, iso.Source); err != nil {
+			return err
+		}
+		sysOpts = append(sysOpts, base.WithBlank())
+	}
+
 	rl := interutils.GetRollbackListFromContext(ctx)
 	for _, vol := range g.Vols {
 		if err := volume.WithLocker(vol, func() error {
 			if vol.IsSys() {
-				return vol.PrepareSysDisk(ctx, g.Img)
+				return vol.PrepareSysDisk(ctx, g.Img, sysOpts...)
 			}
 			return vol.PrepareDataDisk(ctx)
 		}); err != nil {
@@ -580,15 +758,31 @@ func (g *Guest) DefineGuestForCreate(ctx context.Context) error {
 
 func (g *Guest) create(ctx context.Context) error {
 	return g.botOperate(func(bot Bot) error {
-		return bot.Define(ctx)
+		if err := bot.Define(ctx); err != nil {
+			return err
+		}
+		// persist the dedicated pCPUs allocated while defining
+		if g.CPUPinning != nil {
+			return g.Save()
+		}
+		return nil
 	})
 }
 
 // Stop .
 func (g *Guest) Stop(ctx context.Context, force bool) error {
+	hibernated := g.Status == meta.StatusHibernated
 	if err := g.ForwardStopping(); !force && err != nil {
 		return errors.Wrap(err, "")
 	}
+	if hibernated {
+		// it's a cold boot next time
+		if err := g.botOperate(func(bot Bot) error {
+			return bot.DiscardHibernation()
+		}); err != nil {
+			return err
+		}
+	}
 	return g.stop(ctx, force)
 }
 
@@ -601,6 +795,47 @@ func (g *Guest) stop(ctx context.Context, force bool) error {
 	})
 }
 
+// Hibernate saves the memory of the running guest to disk and stops it,
+// the guest is restored rather than booted by the next Start.
+func (g *Guest) Hibernate(_ context.Context) error {
+	if err := g.ForwardHibernating(); err != nil {
+		return err
+	}
+	err := g.botOperate(func(bot Bot) error {
+		if err := bot.Hibernate(); err != nil {
+			return err
+		}
+		return g.ForwardHibernated()
+	})
+	// e.g. the guest is locked, or the memory isn't saved,
+	// it mustn't be left in hibernating.
+	if err != nil && g.Status == meta.StatusHibernating {
+		_ = g.ForwardStatus(g.domainStatus(meta.StatusRunning), true)
+	}
+	return err
+}
+
+// domainStatus returns the status by the state of domain, or fallback if it's unknown.
+func (g *Guest) domainStatus(fallback string) string {
+	status := fallback
+	_ = g.botOperate(func(bot Bot) error {
+		st, err := bot.GetState()
+		if err != nil {
+			return err
+		}
+		switch st {
+		case libvirt.DomainRunning:
+			status = meta.StatusRunning
+		case libvirt.DomainPaused:
+			status = meta.StatusPaused
+		case libvirt.DomainShutoff:
+			status = meta.StatusStopped
+		}
+		return nil
+	}, true)
+	return status
+}
+
 // Suspend .
 func (g *Guest) Suspend() error {
 	return utils.Invoke([]func() error{
@@ -641,6 +876,9 @@ func (g *Guest) InitSysDisk(
 	args *types.InitSysDiskArgs, newSysVol volume.Volume,
 ) error {
 	logger := log.WithFunc("InitSysDisk")
+	if g.RescueImage != "" {
+		return errors.Wrapf(terrors.ErrInvalidValue, "guest %s is in rescue mode, unrescue it first", g.ID)
+	}
 	ciCfg, err := g.GenCloudInit(img)
 	if err != nil {
 		return errors.Wrap(err, "failed to generate cloud init config")
@@ -750,6 +988,7 @@ func (g *Guest) ProcessDestroy(ctx context.Context, force bool) error {
 			logger.Error(ctx, err, "failed to undefine guest")
 			return errors.Wrap(err, "")
 		}
+		resources.GetManager().ReleaseHugepages(g.ID)
 		// delete cloud-init iso
 		ciISOFname := filepath.Join(configs.Conf.VirtCloudInitDir, fmt.Sprintf("%s.iso", g.ID))
 		_ = os.Remove(ciISOFname)
@@ -765,6 +1004,10 @@ func (g *Guest) ProcessDestroy(ctx context.Context, force bool) error {
 				logger.Errorf(ctx, err, "failed to undefine volume (volID: %s)", vol.GetID())
 			}
 		}
+		// the checkpoints are gone with the volumes
+		if err := models.DeleteCheckpoints(g.ID); err != nil {
+			logger.Error(ctx, err, "failed to delete checkpoints")
+		}
 		return g.Delete(force)
 	}, force)
 }
@@ -875,8 +1118,14 @@ func (g *Guest) rangeVolumes(fn func(int, volume.Volume) bool) {
 	}
 }
 
-// Distro .
+// Distro returns the detected distro of the sys volume, or the image's one.
 func (g *Guest) Distro() string {
+	if g.OSDistro != "" && g.OSDistro != types.Unknown {
+		return g.OSDistro
+	}
+	if g.Img == nil {
+		return ""
+	}
 	return g.Img.OS.Distrib
 }
 
@@ -1019,6 +1268,16 @@ func (g *Guest) ExecuteCommand(ctx context.Context, commands []string) (output [
 	return
 }
 
+// Screenshot captures the display of guest in PNG, it doesn't take the lock
+// since it's read-only, and the console may be attached.
+func (g *Guest) Screenshot(_ context.Context) (png []byte, err error) {
+	err = g.botOperate(func(bot Bot) error {
+		png, err = bot.Screenshot()
+		return err
+	}, true)
+	return
+}
+
 // nextVolumeName .
 // 这里不能通过guest的vols长度来生成名字，原因如下：
 // vda, vdb, vdc, 如果detach vdb, 那么这时候长度为2, 在生成名字就是vdc, 那么就冲突了
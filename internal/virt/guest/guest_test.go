@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/agiledragon/gomonkey/v2"
+	"github.com/cockroachdb/errors"
 	cpumemtypes "github.com/projecteru2/core/resource/plugins/cpumem/types"
 	"github.com/projecteru2/yavirt/configs"
 	"github.com/projecteru2/yavirt/internal/meta"
@@ -22,6 +23,7 @@ import (
 	"github.com/projecteru2/yavirt/pkg/idgen"
 	"github.com/projecteru2/yavirt/pkg/libvirt"
 	storemocks "github.com/projecteru2/yavirt/pkg/store/mocks"
+	"github.com/projecteru2/yavirt/pkg/terrors"
 	"github.com/projecteru2/yavirt/pkg/test/assert"
 	"github.com/projecteru2/yavirt/pkg/test/mock"
 	"github.com/projecteru2/yavirt/pkg/utils"
@@ -205,6 +207,32 @@ func TestLifecycle_InvalidStatus(t *testing.T) {
 	assert.Err(t, guest.Resize(cpumem, gpu, []volume.Volume{}))
 }
 
+func TestHibernate_Rollback(t *testing.T) {
+	var guest, bot = newMockedGuest(t)
+	defer bot.AssertExpectations(t)
+
+	var sto, stoCancel = storemocks.Mock()
+	defer stoCancel()
+	defer sto.AssertExpectations(t)
+	sto.On("Update", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	bot.On("Close").Return(nil)
+	bot.On("Unlock").Return()
+
+	// the guest is locked by another operation
+	guest.Status = meta.StatusRunning
+	bot.On("Trylock").Return(terrors.ErrFlockLocked).Once()
+	bot.On("GetState").Return(libvirt.DomainRunning, nil).Once()
+	assert.Err(t, guest.Hibernate(context.Background()))
+	assert.Equal(t, meta.StatusRunning, guest.Status)
+
+	// the memory isn't saved, and the domain is paused
+	bot.On("Trylock").Return(nil).Once()
+	bot.On("Hibernate").Return(errors.New("failed")).Once()
+	bot.On("GetState").Return(libvirt.DomainPaused, nil).Once()
+	assert.Err(t, guest.Hibernate(context.Background()))
+	assert.Equal(t, meta.StatusPaused, guest.Status)
+}
+
 func TestSyncState(t *testing.T) {
 	ctx, cancelFn := context.WithTimeout(context.Background(), 5*time.Minute)
 	defer cancelFn()
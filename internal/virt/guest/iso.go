@@ -0,0 +1,77 @@
+package guest
+
+import (
+	"context"
+
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/yavirt/configs"
+	"github.com/projecteru2/yavirt/internal/types"
+	interutils "github.com/projecteru2/yavirt/internal/utils"
+	"github.com/projecteru2/yavirt/pkg/terrors"
+	vmiFact "github.com/projecteru2/yavirt/pkg/vmimage/factory"
+	vmitypes "github.com/projecteru2/yavirt/pkg/vmimage/types"
+)
+
+// AttachISO inserts the ISO into the guest, src is an image name of the image hub
+// or an absolute host path under the ISO dir, the inserted one is replaced.
+func (g *Guest) AttachISO(ctx context.Context, src string) error {
+	path, err := resolveISO(ctx, src)
+	if err != nil {
+		return err
+	}
+	return g.changeMedia(path)
+}
+
+// EjectISO ejects the inserted ISO from the guest.
+func (g *Guest) EjectISO() error {
+	if g.ISOPath == "" {
+		return nil
+	}
+	return g.changeMedia("")
+}
+
+// SetBootOrder changes the boot devices of the guest, the empty order
+// boots from the sys disk, it takes effect by the next boot.
+func (g *Guest) SetBootOrder(order []string) error {
+	if err := types.CheckBootOrder(order); err != nil {
+		return err
+	}
+	// the rescue disk is booted first until unrescuing
+	if g.RescueImage != "" {
+		return errors.Wrapf(terrors.ErrInvalidValue, "guest %s is in rescue mode, unrescue it first", g.ID)
+	}
+	return g.botOperate(func(bot Bot) error {
+		if err := bot.SetBootOrder(order); err != nil {
+			return err
+		}
+		g.BootOrder = order
+		return g.Save()
+	})
+}
+
+func (g *Guest) changeMedia(path string) error {
+	return g.botOperate(func(bot Bot) error {
+		if err := bot.ChangeMedia(path); err != nil {
+			return err
+		}
+		g.ISOPath = path
+		return g.Save()
+	})
+}
+
+// resolveISO returns the host path of the ISO, the image is pulled from the image hub.
+func resolveISO(ctx context.Context, src string) (string, error) {
+	if types.IsHostPathISO(src) {
+		return types.ResolveHostPathISO(configs.Conf.ISODir, src)
+	}
+	img, err := vmiFact.LoadImage(ctx, src)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to load image %s", src)
+	}
+	rc, err := vmiFact.Pull(ctx, img, vmitypes.PullPolicyAlways)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to pull image %s", img.Fullname())
+	}
+	interutils.EnsureReaderClosed(rc)
+	return img.Filepath(), nil
+}
@@ -157,6 +157,42 @@ func (_m *Bot) Capture(imgName string) (*types.Image, error) {
 	return r0, r1
 }
 
+// ChangeMedia provides a mock function with given fields: isoPath
+func (_m *Bot) ChangeMedia(isoPath string) error {
+	ret := _m.Called(isoPath)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ChangeMedia")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(isoPath)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CheckCheckpoint provides a mock function with given fields:
+func (_m *Bot) CheckCheckpoint() error {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for CheckCheckpoint")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // CheckVolume provides a mock function with given fields: _a0
 func (_m *Bot) CheckVolume(_a0 volume.Volume) error {
 	ret := _m.Called(_a0)
@@ -229,6 +265,42 @@ func (_m *Bot) CommitSnapshotByDay(_a0 volume.Volume, _a1 int) error {
 	return r0
 }
 
+// CoreDump provides a mock function with given fields: ctx, path, format, progress
+func (_m *Bot) CoreDump(ctx context.Context, path string, format string, progress func(uint64, uint64)) error {
+	ret := _m.Called(ctx, path, format, progress)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CoreDump")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, func(uint64, uint64)) error); ok {
+		r0 = rf(ctx, path, format, progress)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateCheckpoint provides a mock function with given fields: name, desc
+func (_m *Bot) CreateCheckpoint(name string, desc string) error {
+	ret := _m.Called(name, desc)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateCheckpoint")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(name, desc)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // CreateSnapshot provides a mock function with given fields: _a0
 func (_m *Bot) CreateSnapshot(_a0 volume.Volume) error {
 	ret := _m.Called(_a0)
@@ -265,6 +337,24 @@ func (_m *Bot) Define(ctx context.Context) error {
 	return r0
 }
 
+// DeleteCheckpoint provides a mock function with given fields: name
+func (_m *Bot) DeleteCheckpoint(name string) error {
+	ret := _m.Called(name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteCheckpoint")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(name)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DetachGPUs provides a mock function with given fields: pcm
 func (_m *Bot) DetachGPUs(pcm map[string]int) error {
 	ret := _m.Called(pcm)
@@ -301,6 +391,24 @@ func (_m *Bot) DetachVolume(vol volume.Volume) error {
 	return r0
 }
 
+// DiscardHibernation provides a mock function with given fields:
+func (_m *Bot) DiscardHibernation() error {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for DiscardHibernation")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // ExecuteCommand provides a mock function with given fields: _a0, _a1
 func (_m *Bot) ExecuteCommand(_a0 context.Context, _a1 []string) ([]byte, int, int, error) {
 	ret := _m.Called(_a0, _a1)
@@ -485,6 +593,24 @@ func (_m *Bot) GetUUID() (string, error) {
 	return r0, r1
 }
 
+// Hibernate provides a mock function with given fields:
+func (_m *Bot) Hibernate() error {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Hibernate")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // IsFolder provides a mock function with given fields: _a0, _a1
 func (_m *Bot) IsFolder(_a0 context.Context, _a1 string) (bool, error) {
 	ret := _m.Called(_a0, _a1)
@@ -663,6 +789,24 @@ func (_m *Bot) ReplaceSysVolume(vol volume.Volume) error {
 	return r0
 }
 
+// Rescue provides a mock function with given fields: rescuePath
+func (_m *Bot) Rescue(rescuePath string) error {
+	ret := _m.Called(rescuePath)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Rescue")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(rescuePath)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Resize provides a mock function with given fields: cpu, mem
 func (_m *Bot) Resize(cpu int, mem int64) error {
 	ret := _m.Called(cpu, mem)
@@ -717,6 +861,144 @@ func (_m *Bot) Resume() error {
 	return r0
 }
 
+// RevertCheckpoint provides a mock function with given fields: name
+func (_m *Bot) RevertCheckpoint(name string) error {
+	ret := _m.Called(name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevertCheckpoint")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(name)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Screenshot provides a mock function with given fields:
+func (_m *Bot) Screenshot() ([]byte, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Screenshot")
+	}
+
+	var r0 []byte
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]byte, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []byte); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetBootOrder provides a mock function with given fields: order
+func (_m *Bot) SetBootOrder(order []string) error {
+	ret := _m.Called(order)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetBootOrder")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]string) error); ok {
+		r0 = rf(order)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetCPUQoS provides a mock function with given fields: qos
+func (_m *Bot) SetCPUQoS(qos *internaltypes.CPUQoS) error {
+	ret := _m.Called(qos)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetCPUQoS")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*internaltypes.CPUQoS) error); ok {
+		r0 = rf(qos)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetNICBandwidth provides a mock function with given fields: bw
+func (_m *Bot) SetNICBandwidth(bw *internaltypes.NICBandwidth) error {
+	ret := _m.Called(bw)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetNICBandwidth")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*internaltypes.NICBandwidth) error); ok {
+		r0 = rf(bw)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetThrottleGroup provides a mock function with given fields: vol, group
+func (_m *Bot) SetThrottleGroup(vol volume.Volume, group *internaltypes.ThrottleGroup) error {
+	ret := _m.Called(vol, group)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetThrottleGroup")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(volume.Volume, *internaltypes.ThrottleGroup) error); ok {
+		r0 = rf(vol, group)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetVolumeQoS provides a mock function with given fields: vol, qos
+func (_m *Bot) SetVolumeQoS(vol volume.Volume, qos *internaltypes.VolumeQoS) error {
+	ret := _m.Called(vol, qos)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetVolumeQoS")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(volume.Volume, *internaltypes.VolumeQoS) error); ok {
+		r0 = rf(vol, qos)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Shutdown provides a mock function with given fields: ctx, force
 func (_m *Bot) Shutdown(ctx context.Context, force bool) error {
 	ret := _m.Called(ctx, force)
@@ -794,6 +1076,24 @@ func (_m *Bot) Unlock() {
 	_m.Called()
 }
 
+// Unrescue provides a mock function with given fields: sysVol
+func (_m *Bot) Unrescue(sysVol volume.Volume) error {
+	ret := _m.Called(sysVol)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Unrescue")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(volume.Volume) error); ok {
+		r0 = rf(sysVol)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewBot creates a new instance of Bot. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewBot(t interface {
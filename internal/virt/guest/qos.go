@@ -0,0 +1,209 @@
+package guest
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/core/log"
+	"github.com/projecteru2/yavirt/configs"
+	"github.com/projecteru2/yavirt/internal/meta"
+	"github.com/projecteru2/yavirt/internal/types"
+	"github.com/projecteru2/yavirt/internal/volume/local"
+	"github.com/projecteru2/yavirt/pkg/terrors"
+	bdtypes "github.com/yuyang0/resource-bandwidth/bandwidth/types"
+)
+
+// UpdateQoS changes the QoS of guest without restart, and saves it
+// so the guest keeps it across reboots. The applied changes are rolled
+// back if any of them fails.
+func (g *Guest) UpdateQoS(ctx context.Context, qos *types.GuestQoS) error {
+	if err := qos.Check(&configs.Conf.Resource); err != nil {
+		return err
+	}
+	switch g.Status {
+	case meta.StatusRunning, meta.StatusStopped, meta.StatusPaused:
+	default:
+		return errors.Wrapf(terrors.ErrForwardStatus,
+			"only stopped/running/paused guest can update QoS, but it's %s", g.Status)
+	}
+	vols, err := g.qosVolumes(qos.Volumes)
+	if err != nil {
+		return err
+	}
+	oldBW, err := g.NICBandwidth()
+	if err != nil {
+		// the invalid label takes the defaults as Define does
+		oldBW = &types.NICBandwidth{}
+	}
+
+	return g.botOperate(func(bot Bot) (err error) {
+		var undos []func() error
+		defer func() {
+			if err == nil {
+				return
+			}
+			for i := len(undos) - 1; i >= 0; i-- {
+				if ue := undos[i](); ue != nil {
+					log.WithFunc("Guest.UpdateQoS").Warnf(ctx, "failed to roll back the QoS of guest %s: %s", g.ID, ue)
+				}
+			}
+		}()
+
+		if qos.CPU != nil {
+			old := &types.CPUQoS{}
+			if g.CPUQoS != nil {
+				old = g.CPUQoS
+			}
+			if err := bot.SetCPUQoS(qos.CPU); err != nil {
+				return err
+			}
+			undos = append(undos, func() error { return bot.SetCPUQoS(old) })
+		}
+		if qos.Bandwidth != nil {
+			if err := bot.SetNICBandwidth(qos.Bandwidth); err != nil {
+				return err
+			}
+			undos = append(undos, func() error { return bot.SetNICBandwidth(oldBW) })
+		}
+		for key, vol := range vols {
+			vq, old := qos.Volumes[key], volumeQoS(vol)
+			if err := bot.SetVolumeQoS(vol, &vq); err != nil {
+				return err
+			}
+			undos = append(undos, func() error { return bot.SetVolumeQoS(vol, &old) })
+		}
+
+		for key, vol := range vols {
+			vq, old := qos.Volumes[key], volumeQoS(vol)
+			setVolumeQoS(vol, vq)
+			if err := vol.Save(); err != nil {
+				setVolumeQoS(vol, old)
+				return err
+			}
+			undos = append(undos, func() error {
+				setVolumeQoS(vol, old)
+				return vol.Save()
+			})
+		}
+		return g.saveQoS(qos)
+	})
+}
+
+// saveQoS saves the CPU QoS and the NIC bandwidth of guest,
+// they're restored if the saving fails.
+func (g *Guest) saveQoS(qos *types.GuestQoS) error {
+	cpuQoS, labels, bdParams := g.CPUQoS, maps.Clone(g.JSONLabels), g.BDEngineParams
+	if qos.CPU != nil {
+		g.CPUQoS = qos.CPU
+		if qos.CPU.IsZero() {
+			g.CPUQoS = nil
+		}
+	}
+	if qos.Bandwidth != nil {
+		if err := g.setBandwidthLabel(qos.Bandwidth); err != nil {
+			return err
+		}
+		g.BDEngineParams = &bdtypes.EngineParams{Average: qos.Bandwidth.Average, Peak: qos.Bandwidth.Peak}
+	}
+	if err := g.Save(); err != nil {
+		g.CPUQoS, g.JSONLabels, g.BDEngineParams = cpuQoS, labels, bdParams
+		return err
+	}
+	return nil
+}
+
+// UpdateThrottleGroup applies the new limits of group to the volumes in it.
+func (g *Guest) UpdateThrottleGroup(_ context.Context, group *types.ThrottleGroup) error {
+	vols := g.StaleThrottleGroupVolumes(group)
+	if len(vols) == 0 {
+		return nil
+	}
+	return g.botOperate(func(bot Bot) error {
+		for _, vol := range vols {
+			if err := bot.SetThrottleGroup(vol, group); err != nil {
+				return err
+			}
+			vol.JoinThrottleGroup(group)
+			if err := vol.Save(); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ThrottleGroupVolumes returns the volumes in the throttle group.
+func (g *Guest) ThrottleGroupVolumes(name string) []*local.Volume {
+	var vols []*local.Volume
+	for _, vol := range g.Vols {
+		if lv, ok := vol.(*local.Volume); ok && lv.ThrottleGroup == name {
+			vols = append(vols, lv)
+		}
+	}
+	return vols
+}
+
+// StaleThrottleGroupVolumes returns the volumes in the throttle group,
+// whose limits differ from the group.
+func (g *Guest) StaleThrottleGroupVolumes(group *types.ThrottleGroup) []*local.Volume {
+	var vols []*local.Volume
+	for _, vol := range g.ThrottleGroupVolumes(group.Name) {
+		if volumeQoS(vol) != group.VolumeQoS {
+			vols = append(vols, vol)
+		}
+	}
+	return vols
+}
+
+// qosVolumes finds the volumes by their IDs or devices,
+// only the local volumes have QoS.
+func (g *Guest) qosVolumes(keys map[string]types.VolumeQoS) (map[string]*local.Volume, error) {
+	vols := map[string]*local.Volume{}
+	for key := range keys {
+		for _, vol := range g.Vols {
+			if vol.GetID() != key && vol.GetDevice() != key {
+				continue
+			}
+			lv, ok := vol.(*local.Volume)
+			if !ok {
+				return nil, errors.Wrapf(terrors.ErrInvalidValue, "volume %s doesn't support QoS", key)
+			}
+			if lv.ThrottleGroup != "" {
+				return nil, errors.Wrapf(terrors.ErrInvalidValue, "volume %s takes the QoS of throttle group %s", key, lv.ThrottleGroup)
+			}
+			vols[key] = lv
+			break
+		}
+		if _, ok := vols[key]; !ok {
+			return nil, errors.Wrapf(terrors.ErrInvalidValue, "volume %s isn't attached to guest %s", key, g.ID)
+		}
+	}
+	return vols, nil
+}
+
+func volumeQoS(vol *local.Volume) types.VolumeQoS {
+	return types.VolumeQoS{ReadIOPS: vol.ReadIOPS, WriteIOPS: vol.WriteIOPS, ReadBPS: vol.ReadBPS, WriteBPS: vol.WriteBPS}
+}
+
+func setVolumeQoS(vol *local.Volume, vq types.VolumeQoS) {
+	vol.ReadIOPS, vol.WriteIOPS = vq.ReadIOPS, vq.WriteIOPS
+	vol.ReadBPS, vol.WriteBPS = vq.ReadBPS, vq.WriteBPS
+}
+
+func (g *Guest) setBandwidthLabel(bw *types.NICBandwidth) error {
+	if *bw == (types.NICBandwidth{}) {
+		delete(g.JSONLabels, types.NICBandwidthLabelKey)
+		return nil
+	}
+	bs, err := json.Marshal(bw)
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+	if g.JSONLabels == nil {
+		g.JSONLabels = map[string]string{}
+	}
+	g.JSONLabels[types.NICBandwidthLabelKey] = string(bs)
+	return nil
+}
@@ -0,0 +1,109 @@
+package guest
+
+import (
+	"context"
+	"os"
+
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/core/log"
+	"github.com/projecteru2/yavirt/internal/meta"
+	interutils "github.com/projecteru2/yavirt/internal/utils"
+	"github.com/projecteru2/yavirt/internal/virt/domain"
+	"github.com/projecteru2/yavirt/pkg/terrors"
+	vmiFact "github.com/projecteru2/yavirt/pkg/vmimage/factory"
+	vmitypes "github.com/projecteru2/yavirt/pkg/vmimage/types"
+)
+
+// Rescue boots the guest from the rescue image, its system volume is attached
+// as a secondary disk, the networks and the status are preserved.
+func (g *Guest) Rescue(ctx context.Context, img *vmitypes.Image) error {
+	if g.RescueImage != "" {
+		return errors.Wrapf(terrors.ErrInvalidValue, "guest %s is in rescue mode already", g.ID)
+	}
+	running, err := g.checkRescueStatus()
+	if err != nil {
+		return err
+	}
+
+	rc, err := vmiFact.Pull(ctx, img, vmitypes.PullPolicyAlways)
+	if err != nil {
+		return errors.Wrapf(err, "failed to pull image %s", img.Fullname())
+	}
+	interutils.EnsureReaderClosed(rc)
+	rescuePath := domain.RescueFilepath(g.ID)
+	if err := interutils.CreateSnapshot(ctx, img.Filepath(), rescuePath); err != nil {
+		return errors.Wrapf(err, "failed to create rescue disk")
+	}
+
+	if err := g.rebootWith(ctx, running, func(bot Bot) error {
+		if err := bot.Rescue(rescuePath); err != nil {
+			return err
+		}
+		g.RescueImage = img.Fullname()
+		return g.Save()
+	}); err != nil {
+		if g.RescueImage == "" {
+			_ = os.Remove(rescuePath)
+		}
+		return err
+	}
+	return nil
+}
+
+// Unrescue boots the guest from its system volume again.
+func (g *Guest) Unrescue(ctx context.Context) error {
+	if g.RescueImage == "" {
+		return errors.Wrapf(terrors.ErrInvalidValue, "guest %s isn't in rescue mode", g.ID)
+	}
+	running, err := g.checkRescueStatus()
+	if err != nil {
+		return err
+	}
+	sysVol, err := g.sysVolume()
+	if err != nil {
+		return err
+	}
+
+	return g.rebootWith(ctx, running, func(bot Bot) error {
+		if err := bot.Unrescue(sysVol); err != nil {
+			return err
+		}
+		if err := os.Remove(domain.RescueFilepath(g.ID)); err != nil && !os.IsNotExist(err) {
+			log.WithFunc("Guest.Unrescue").Warnf(ctx, "failed to remove rescue disk: %s", err)
+		}
+		g.RescueImage = ""
+		return g.Save()
+	})
+}
+
+func (g *Guest) checkRescueStatus() (running bool, err error) {
+	switch g.Status {
+	case meta.StatusRunning:
+		return true, nil
+	case meta.StatusStopped:
+		return false, nil
+	default:
+		return false, errors.Wrapf(terrors.ErrForwardStatus,
+			"only stopped/running guest can be rescued, but it's %s", g.Status)
+	}
+}
+
+// rebootWith redefines the stopped guest by fn, the running guest is stopped
+// before and started after that.
+func (g *Guest) rebootWith(ctx context.Context, running bool, fn func(Bot) error) error {
+	if running {
+		if err := g.Stop(ctx, false); err != nil {
+			return errors.Wrap(err, "")
+		}
+	}
+	if err := g.botOperate(fn); err != nil {
+		if running {
+			_ = g.Start(ctx, false)
+		}
+		return err
+	}
+	if running {
+		return g.Start(ctx, false)
+	}
+	return nil
+}
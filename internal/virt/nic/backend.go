@@ -0,0 +1,402 @@
+package nic
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/projecteru2/yavirt/internal/meta"
+	"github.com/projecteru2/yavirt/internal/types"
+	"github.com/projecteru2/yavirt/pkg/terrors"
+	vmitypes "github.com/projecteru2/yavirt/pkg/vmimage/types"
+)
+
+const (
+	// BackendNetworkd .
+	BackendNetworkd = "systemd-networkd"
+	// BackendNetplan .
+	BackendNetplan = "netplan"
+	// BackendNetworkManager .
+	BackendNetworkManager = "NetworkManager"
+	// BackendIfcfg .
+	BackendIfcfg = "ifcfg"
+	// BackendIfupdown .
+	BackendIfupdown = "ifupdown"
+	// BackendAlpine .
+	BackendAlpine = "alpine"
+	// BackendWindows .
+	BackendWindows = "windows"
+)
+
+// Backend persists the network configuration of a NIC inside guest,
+// so the IP address survives from rebooting.
+type Backend interface {
+	Name() string
+	// ConfigFile returns the persistent config file of the device,
+	// an empty string means the backend doesn't use config file.
+	ConfigFile(dev string) string
+	// Render generates the content of the config file.
+	Render(dev string, ip meta.IP) string
+	// ApplyCmds returns the commands which make the config take effect.
+	ApplyCmds(dev string, ip meta.IP) [][]string
+	// DNSCmds returns the commands which set the fallback DNS servers,
+	// they run only if the guest fails to resolve names.
+	DNSCmds(dev string) [][]string
+}
+
+// the same as vm-init.sh
+var fallbackDNS = []string{"8.8.8.8", "1.1.1.1"}
+
+// GetBackend selects the network backend by distro and version,
+// the distro can be either the detected one(Gfsx.Distro) or the image's OSInfo.
+// The guests without distro keep using vm-init.sh with systemd-networkd.
+func GetBackend(distro, version string) (Backend, error) {
+	switch strings.ToLower(distro) {
+	case "":
+		return networkd{}, nil
+	case types.Ubuntu:
+		// netplan renders to systemd-networkd on the cloud images,
+		// so vm-init.sh works with all the versions.
+		return networkd{}, nil
+	case types.Debian:
+		return ifupdown{}, nil
+	case types.CentOS, types.RHEL:
+		// network-scripts are deprecated since 8
+		if majorVersion(version) >= 8 {
+			return networkManager{}, nil
+		}
+		return ifcfg{}, nil
+	case types.Rocky, types.AlmaLinux, types.Fedora:
+		return networkManager{}, nil
+	case types.Alpine, types.AlpineLinux:
+		return alpine{}, nil
+	case types.Windows:
+		return windows{}, nil
+	default:
+		return nil, errors.Wrapf(terrors.ErrInvalidValue, "unknown distro: %s", distro)
+	}
+}
+
+// GetBackendByName returns the backend by its name.
+func GetBackendByName(name string) (Backend, error) {
+	for _, b := range []Backend{networkd{}, netplan{}, networkManager{}, ifcfg{}, ifupdown{}, alpine{}, windows{}} {
+		if b.Name() == name {
+			return b, nil
+		}
+	}
+	return nil, errors.Wrapf(terrors.ErrInvalidValue, "unknown network backend: %s", name)
+}
+
+// GetBackendByOS selects the network backend by the image's OSInfo.
+func GetBackendByOS(osInfo *vmitypes.OSInfo) (Backend, error) {
+	if osInfo == nil {
+		return networkd{}, nil
+	}
+	if osInfo.Type == types.Windows {
+		return windows{}, nil
+	}
+	return GetBackend(osInfo.Distrib, osInfo.Version)
+}
+
+// SelectBackend prefers the backend of name, then the detected distro,
+// then the image's OSInfo. The version of OSInfo is used only if the
+// distros are the same.
+func SelectBackend(name, distro string, osInfo *vmitypes.OSInfo) (Backend, error) {
+	switch {
+	case name != "":
+		return GetBackendByName(name)
+	case distro == "" || distro == types.Unknown:
+		return GetBackendByOS(osInfo)
+	case osInfo != nil && strings.EqualFold(osInfo.Distrib, distro):
+		return GetBackend(distro, osInfo.Version)
+	default:
+		return GetBackend(distro, "")
+	}
+}
+
+func majorVersion(version string) int {
+	parts := strings.SplitN(version, ".", 2)
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0
+	}
+	return major
+}
+
+type networkd struct{}
+
+func (networkd) Name() string {
+	return BackendNetworkd
+}
+
+func (networkd) ConfigFile(dev string) string {
+	return fmt.Sprintf(types.EthNetworkdFileFmt, dev)
+}
+
+func (networkd) Render(dev string, ip meta.IP) string {
+	return fmt.Sprintf(`[Match]
+Name=%s
+
+[Address]
+Address=%s
+
+[Route]
+Gateway=%s
+GatewayOnlink=yes
+`, dev, ip.CIDR(), ip.GatewayAddr())
+}
+
+func (networkd) ApplyCmds(string, meta.IP) [][]string {
+	return [][]string{
+		{"systemctl", "restart", "systemd-networkd"},
+	}
+}
+
+func (networkd) DNSCmds(string) [][]string {
+	return resolvedDNSCmds()
+}
+
+func resolvedDNSCmds() [][]string {
+	const dir = "/etc/systemd/resolved.conf.d"
+	conf := fmt.Sprintf("[Resolve]\nDNS=%s\n", strings.Join(fallbackDNS, " "))
+	return [][]string{
+		{"mkdir", "-p", dir},
+		{"sh", "-c", fmt.Sprintf("printf '%s' > %s/dns_servers.conf", conf, dir)},
+		{"systemctl", "restart", "systemd-resolved"},
+	}
+}
+
+type netplan struct{}
+
+func (netplan) Name() string {
+	return BackendNetplan
+}
+
+func (netplan) ConfigFile(dev string) string {
+	return fmt.Sprintf(types.EthNetplanFileFmt, dev)
+}
+
+func (netplan) Render(dev string, ip meta.IP) string {
+	return fmt.Sprintf(`network:
+  version: 2
+  ethernets:
+    %s:
+      dhcp4: false
+      addresses:
+        - %s
+      routes:
+        - to: 0.0.0.0/0
+          via: %s
+          on-link: true
+`, dev, ip.CIDR(), ip.GatewayAddr())
+}
+
+func (b netplan) ApplyCmds(dev string, _ meta.IP) [][]string {
+	return [][]string{
+		{"chmod", "600", b.ConfigFile(dev)},
+		{"netplan", "apply"},
+	}
+}
+
+// netplan works with systemd-resolved as well.
+func (netplan) DNSCmds(string) [][]string {
+	return resolvedDNSCmds()
+}
+
+type networkManager struct{}
+
+func (networkManager) Name() string {
+	return BackendNetworkManager
+}
+
+func (networkManager) ConfigFile(dev string) string {
+	return fmt.Sprintf(types.EthNMKeyfileFmt, dev)
+}
+
+func (networkManager) Render(dev string, ip meta.IP) string {
+	return fmt.Sprintf(`[connection]
+id=yavirt-%s
+type=ethernet
+interface-name=%s
+autoconnect=true
+
+[ipv4]
+method=manual
+address1=%s
+gateway=%s
+may-fail=false
+
+[ipv6]
+method=ignore
+`, dev, dev, ip.CIDR(), ip.GatewayAddr())
+}
+
+func (b networkManager) ApplyCmds(dev string, _ meta.IP) [][]string {
+	// NetworkManager refuses to load keyfiles which are readable by others.
+	return [][]string{
+		{"chmod", "600", b.ConfigFile(dev)},
+		{"nmcli", "connection", "reload"},
+		{"nmcli", "connection", "up", fmt.Sprintf("yavirt-%s", dev)},
+	}
+}
+
+func (networkManager) DNSCmds(dev string) [][]string {
+	conn := fmt.Sprintf("yavirt-%s", dev)
+	return [][]string{
+		{"nmcli", "connection", "modify", conn, "ipv4.dns", strings.Join(fallbackDNS, " ")},
+		{"nmcli", "connection", "up", conn},
+	}
+}
+
+type ifcfg struct{}
+
+func (ifcfg) Name() string {
+	return BackendIfcfg
+}
+
+func (ifcfg) ConfigFile(dev string) string {
+	return fmt.Sprintf(types.EthCentOSFileFmt, dev)
+}
+
+func (ifcfg) Render(dev string, ip meta.IP) string {
+	return fmt.Sprintf(`DEVICE=%s
+TYPE=Ethernet
+ONBOOT=yes
+BOOTPROTO=none
+IPADDR=%s
+PREFIX=%d
+GATEWAY=%s
+DEFROUTE=yes
+`, dev, ip.IPAddr(), ip.Prefix(), ip.GatewayAddr())
+}
+
+func (ifcfg) ApplyCmds(dev string, _ meta.IP) [][]string {
+	return [][]string{
+		{"ifdown", dev},
+		{"ifup", dev},
+	}
+}
+
+func (b ifcfg) DNSCmds(dev string) [][]string {
+	var conf string
+	for i, addr := range fallbackDNS {
+		conf += fmt.Sprintf("DNS%d=%s\n", i+1, addr)
+	}
+	return [][]string{
+		{"sh", "-c", fmt.Sprintf("printf '%s' >> %s", conf, b.ConfigFile(dev))},
+		{"ifdown", dev},
+		{"ifup", dev},
+	}
+}
+
+type ifupdown struct{}
+
+func (ifupdown) Name() string {
+	return BackendIfupdown
+}
+
+func (ifupdown) ConfigFile(dev string) string {
+	return fmt.Sprintf(types.EthUbuntuFileFmt, dev)
+}
+
+func (ifupdown) Render(dev string, ip meta.IP) string {
+	return renderInterfaces(dev, ip)
+}
+
+func (ifupdown) ApplyCmds(dev string, _ meta.IP) [][]string {
+	return [][]string{
+		{"ifdown", "--force", dev},
+		{"ifup", dev},
+	}
+}
+
+// dns-nameservers works only with resolvconf, so resolv.conf is written.
+func (ifupdown) DNSCmds(string) [][]string {
+	return resolvConfDNSCmds()
+}
+
+func resolvConfDNSCmds() [][]string {
+	var conf string
+	for _, addr := range fallbackDNS {
+		conf += fmt.Sprintf("nameserver %s\n", addr)
+	}
+	return [][]string{
+		{"sh", "-c", fmt.Sprintf("printf '%s' > /etc/resolv.conf", conf)},
+	}
+}
+
+type alpine struct{}
+
+func (alpine) Name() string {
+	return BackendAlpine
+}
+
+// Alpine doesn't source interfaces.d by default,
+// so the whole /etc/network/interfaces is rewritten.
+func (alpine) ConfigFile(string) string {
+	return types.EthAlpineFile
+}
+
+func (alpine) Render(dev string, ip meta.IP) string {
+	return fmt.Sprintf("auto lo\niface lo inet loopback\n\n%s", renderInterfaces(dev, ip))
+}
+
+func (alpine) ApplyCmds(string, meta.IP) [][]string {
+	return [][]string{
+		{"rc-update", "add", "networking", "boot"},
+		{"rc-service", "networking", "restart"},
+	}
+}
+
+func (alpine) DNSCmds(string) [][]string {
+	return resolvConfDNSCmds()
+}
+
+func renderInterfaces(dev string, ip meta.IP) string {
+	return fmt.Sprintf(`auto %s
+iface %s inet static
+    address %s
+    netmask %s
+    gateway %s
+`, dev, dev, ip.IPAddr(), ip.Netmask(), ip.GatewayAddr())
+}
+
+type windows struct{}
+
+func (windows) Name() string {
+	return BackendWindows
+}
+
+func (windows) ConfigFile(string) string {
+	return ""
+}
+
+func (windows) Render(string, meta.IP) string {
+	return ""
+}
+
+// Addresses set by New-NetIPAddress are persistent by default.
+func (windows) ApplyCmds(dev string, ip meta.IP) [][]string {
+	script := fmt.Sprintf(
+		"Remove-NetIPAddress -InterfaceAlias '%s' -AddressFamily IPv4 -Confirm:$false -ErrorAction SilentlyContinue; "+
+			"Remove-NetRoute -InterfaceAlias '%s' -DestinationPrefix '0.0.0.0/0' -Confirm:$false -ErrorAction SilentlyContinue; "+
+			"New-NetIPAddress -InterfaceAlias '%s' -IPAddress %s -PrefixLength %d -DefaultGateway %s",
+		dev, dev, dev, ip.IPAddr(), ip.Prefix(), ip.GatewayAddr(),
+	)
+	return [][]string{
+		powershell(script),
+	}
+}
+
+func (windows) DNSCmds(dev string) [][]string {
+	script := fmt.Sprintf("Set-DnsClientServerAddress -InterfaceAlias '%s' -ServerAddresses %s",
+		dev, strings.Join(fallbackDNS, ","))
+	return [][]string{
+		powershell(script),
+	}
+}
+
+func powershell(script string) []string {
+	return []string{"powershell.exe", "-NoProfile", "-NonInteractive", "-Command", script}
+}
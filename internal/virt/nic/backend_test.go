@@ -0,0 +1,133 @@
+package nic
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/projecteru2/yavirt/internal/meta"
+	"github.com/projecteru2/yavirt/internal/types"
+	"github.com/projecteru2/yavirt/pkg/test/assert"
+	vmitypes "github.com/projecteru2/yavirt/pkg/vmimage/types"
+)
+
+type testIP struct {
+	meta.IP
+}
+
+func (testIP) CIDR() string        { return "10.0.0.5/24" }
+func (testIP) IPAddr() string      { return "10.0.0.5" }
+func (testIP) Prefix() int         { return 24 }
+func (testIP) Netmask() string     { return "255.255.255.0" }
+func (testIP) GatewayAddr() string { return "10.0.0.1" }
+
+func TestGetBackend(t *testing.T) {
+	cases := []struct {
+		distro  string
+		version string
+		backend string
+	}{
+		{"", "", BackendNetworkd},
+		{types.Ubuntu, "", BackendNetworkd},
+		{types.Ubuntu, "22.04", BackendNetworkd},
+		{types.Debian, "12", BackendIfupdown},
+		{types.CentOS, "7", BackendIfcfg},
+		{types.CentOS, "", BackendIfcfg},
+		{types.RHEL, "9.2", BackendNetworkManager},
+		{types.Rocky, "9", BackendNetworkManager},
+		{types.AlmaLinux, "8", BackendNetworkManager},
+		{types.Fedora, "39", BackendNetworkManager},
+		{types.Alpine, "3.18", BackendAlpine},
+		{types.AlpineLinux, "", BackendAlpine},
+		{types.Windows, "", BackendWindows},
+		{"Rocky", "9", BackendNetworkManager},
+	}
+	for _, c := range cases {
+		b, err := GetBackend(c.distro, c.version)
+		assert.NilErr(t, err)
+		assert.Equal(t, c.backend, b.Name())
+	}
+
+	_, err := GetBackend("archlinux", "")
+	assert.Err(t, err)
+	_, err = GetBackend(types.Unknown, "")
+	assert.Err(t, err)
+}
+
+func TestSelectBackend(t *testing.T) {
+	osInfo := &vmitypes.OSInfo{Type: "linux", Distrib: types.CentOS, Version: "8"}
+	cases := []struct {
+		name    string
+		distro  string
+		osInfo  *vmitypes.OSInfo
+		backend string
+	}{
+		{"", "", nil, BackendNetworkd},
+		{BackendNetplan, types.Ubuntu, nil, BackendNetplan},
+		{"", "", osInfo, BackendNetworkManager},
+		{"", types.Unknown, osInfo, BackendNetworkManager},
+		// the version of OSInfo is used with the same distro only
+		{"", types.CentOS, osInfo, BackendNetworkManager},
+		{"", types.Debian, osInfo, BackendIfupdown},
+		{"", "", &vmitypes.OSInfo{Type: types.Windows}, BackendWindows},
+	}
+	for _, c := range cases {
+		b, err := SelectBackend(c.name, c.distro, c.osInfo)
+		assert.NilErr(t, err)
+		assert.Equal(t, c.backend, b.Name())
+	}
+
+	_, err := SelectBackend("wicked", "", nil)
+	assert.Err(t, err)
+}
+
+func TestBackendRender(t *testing.T) {
+	ip := testIP{}
+	cases := []struct {
+		backend Backend
+		file    string
+		content []string
+	}{
+		{networkd{}, "/etc/systemd/network/10-eth0.network", []string{"Name=eth0", "Address=10.0.0.5/24", "Gateway=10.0.0.1"}},
+		{netplan{}, "/etc/netplan/60-yavirt-eth0.yaml", []string{"eth0:", "- 10.0.0.5/24", "to: 0.0.0.0/0", "via: 10.0.0.1"}},
+		{networkManager{}, "/etc/NetworkManager/system-connections/yavirt-eth0.nmconnection", []string{"id=yavirt-eth0", "address1=10.0.0.5/24", "gateway=10.0.0.1"}},
+		{ifcfg{}, "/etc/sysconfig/network-scripts/ifcfg-eth0", []string{"DEVICE=eth0", "IPADDR=10.0.0.5", "PREFIX=24", "GATEWAY=10.0.0.1"}},
+		{ifupdown{}, "/etc/network/interfaces.d/eth0.cfg", []string{"iface eth0 inet static", "address 10.0.0.5", "netmask 255.255.255.0", "gateway 10.0.0.1"}},
+		{alpine{}, "/etc/network/interfaces", []string{"iface lo inet loopback", "iface eth0 inet static", "gateway 10.0.0.1"}},
+		{windows{}, "", nil},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.file, c.backend.ConfigFile("eth0"))
+		content := c.backend.Render("eth0", ip)
+		for _, s := range c.content {
+			assert.True(t, strings.Contains(content, s), "%s: %q not in %q", c.backend.Name(), s, content)
+		}
+		assert.True(t, len(c.backend.ApplyCmds("eth0", ip)) > 0)
+		assert.True(t, len(c.backend.DNSCmds("eth0")) > 0)
+	}
+
+	cmds := windows{}.ApplyCmds("Ethernet", ip)
+	assert.True(t, strings.Contains(cmds[0][len(cmds[0])-1], "-IPAddress 10.0.0.5 -PrefixLength 24 -DefaultGateway 10.0.0.1"))
+	cmds = windows{}.DNSCmds("Ethernet")
+	assert.True(t, strings.Contains(cmds[0][len(cmds[0])-1], "-ServerAddresses 8.8.8.8,1.1.1.1"))
+}
+
+func TestGetEthFiles(t *testing.T) {
+	files, err := GetEthFiles(types.Ubuntu, "eth0")
+	assert.NilErr(t, err)
+	assert.Equal(t, "/etc/network/interfaces.d/eth0.cfg", files[0])
+	assert.True(t, strings.Contains(strings.Join(files, " "), "/etc/systemd/network/10-eth0.network"))
+
+	files, err = GetEthFiles(types.CentOS, "eth0")
+	assert.NilErr(t, err)
+	assert.Equal(t, "/etc/sysconfig/network-scripts/ifcfg-eth0", files[0])
+
+	// the interfaces of Alpine is shared with the loopback
+	for _, distro := range []string{types.AlpineLinux, types.Windows} {
+		files, err = GetEthFiles(distro, "eth0")
+		assert.NilErr(t, err)
+		assert.Equal(t, 0, len(files))
+	}
+
+	_, err = GetEthFiles("archlinux", "eth0")
+	assert.Err(t, err)
+}
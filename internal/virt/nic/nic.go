@@ -6,6 +6,7 @@ import (
 	_ "embed"
 	"fmt"
 	"path"
+	"strings"
 
 	"github.com/cockroachdb/errors"
 	"github.com/projecteru2/core/log"
@@ -19,24 +20,41 @@ import (
 var vmInitScript string
 
 type NICs struct {
-	ips []meta.IP
-	ga  *agent.Agent
+	ips     []meta.IP
+	ga      *agent.Agent
+	backend Backend
 }
 
-// NewNic .
-func NewNicList(ips []meta.IP, ga *agent.Agent) *NICs {
-	return &NICs{ips: ips, ga: ga}
+// NewNicList .
+func NewNicList(ips []meta.IP, ga *agent.Agent, backend Backend) *NICs {
+	return &NICs{ips: ips, ga: ga, backend: backend}
 }
 
 // Setup .
 func (nl *NICs) Setup(ctx context.Context) error {
-	args := make([]string, 0, 2*len(nl.ips))
-	for _, ip := range nl.ips {
-		args = append(args, ip.CIDR(), ip.GatewayAddr())
+	log.Infof(ctx, "Setup NIC list %v with %s", nl.ips, nl.backend.Name())
+	if nl.backend.Name() == BackendNetworkd {
+		args := make([]string, 0, 2*len(nl.ips))
+		for _, ip := range nl.ips {
+			args = append(args, ip.CIDR(), ip.GatewayAddr())
+		}
+		if err := nl.execVMInitScript(ctx, args...); err != nil {
+			return errors.Wrap(err, "")
+		}
+	} else {
+		devs, err := nl.persist(ctx)
+		if err != nil {
+			return errors.Wrap(err, "")
+		}
+		// vm-init.sh sets the fallback DNS by itself
+		if len(devs) > 0 {
+			if err := nl.setupDNS(ctx, devs[0]); err != nil {
+				return errors.Wrap(err, "")
+			}
+		}
 	}
-	log.Infof(ctx, "Setup NIC list %v", nl.ips)
-	if err := nl.execVMInitScript(ctx, args...); err != nil {
-		return errors.Wrap(err, "")
+	if nl.backend.Name() == BackendWindows {
+		return nil
 	}
 	for idx, ip := range nl.ips {
 		switch cidr, err := ip.AutoRouteCIDR(); {
@@ -66,6 +84,135 @@ func (nl *NICs) GetNic(idx int) (*Nic, error) {
 	}, nil
 }
 
+// persist assigns the IPs to the NICs which have no IPv4 address in order,
+// it's the same as vm-init.sh but works with all the backends.
+// It returns the devices which are configured.
+func (nl *NICs) persist(ctx context.Context) ([]string, error) {
+	devs, err := nl.listDevices(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "")
+	}
+	var done []string
+	for idx, dev := range devs {
+		if idx >= len(nl.ips) {
+			break
+		}
+		if dev.hasIP {
+			log.Infof(ctx, "The interface %s has an IP address.", dev.name)
+			continue
+		}
+		if err := Persist(ctx, nl.ga, nl.backend, dev.name, nl.ips[idx]); err != nil {
+			return nil, errors.Wrapf(err, "failed to persist network config of %s", dev.name)
+		}
+		done = append(done, dev.name)
+	}
+	return done, nil
+}
+
+// setupDNS sets the fallback DNS servers with dev if the guest fails to resolve names.
+func (nl *NICs) setupDNS(ctx context.Context, dev string) error {
+	check := []string{"getent", "hosts", "baidu.com"}
+	if nl.backend.Name() == BackendWindows {
+		check = powershell("Resolve-DnsName baidu.com -ErrorAction Stop")
+	}
+	if _, err := nl.execOutput(ctx, check[0], check[1:]...); err == nil {
+		return nil
+	}
+	log.Infof(ctx, "Setting DNS of %s...", dev)
+	for _, cmd := range nl.backend.DNSCmds(dev) {
+		if _, err := nl.execOutput(ctx, cmd[0], cmd[1:]...); err != nil {
+			return errors.Wrap(err, "failed to set DNS")
+		}
+	}
+	return nil
+}
+
+type device struct {
+	name  string
+	hasIP bool
+}
+
+func (nl *NICs) listDevices(ctx context.Context) ([]device, error) {
+	if nl.backend.Name() == BackendWindows {
+		// the output is lines of "<name>,<True|False>"
+		script := "Get-NetAdapter | Sort-Object ifIndex | ForEach-Object { " +
+			"$ip = Get-NetIPAddress -InterfaceIndex $_.ifIndex -AddressFamily IPv4 -ErrorAction SilentlyContinue | " +
+			"Where-Object { $_.PrefixOrigin -ne 'WellKnown' }; " +
+			"'{0},{1}' -f $_.Name, [bool]$ip }"
+		cmd := powershell(script)
+		so, err := nl.execOutput(ctx, cmd[0], cmd[1:]...)
+		if err != nil {
+			return nil, err
+		}
+		var devs []device
+		for _, line := range strings.Split(strings.TrimSpace(string(so)), "\n") {
+			parts := strings.Split(strings.TrimSpace(line), ",")
+			if len(parts) != 2 {
+				continue
+			}
+			devs = append(devs, device{name: parts[0], hasIP: strings.EqualFold(parts[1], "true")})
+		}
+		return devs, nil
+	}
+
+	so, err := nl.execOutput(ctx, "ip", "-o", "link", "show")
+	if err != nil {
+		return nil, err
+	}
+	addrs, err := nl.execOutput(ctx, "ip", "-o", "-4", "addr", "show")
+	if err != nil {
+		return nil, err
+	}
+	withIP := map[string]bool{}
+	for _, line := range strings.Split(string(addrs), "\n") {
+		if fields := strings.Fields(line); len(fields) > 1 {
+			withIP[fields[1]] = true
+		}
+	}
+	var devs []device
+	for _, line := range strings.Split(string(so), "\n") {
+		// 2: ens5: <BROADCAST,MULTICAST,UP,LOWER_UP> mtu 1500 ...
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		name := strings.TrimSuffix(fields[1], ":")
+		if idx := strings.Index(name, "@"); idx >= 0 {
+			name = name[:idx]
+		}
+		if name == "lo" {
+			continue
+		}
+		devs = append(devs, device{name: name, hasIP: withIP[name]})
+	}
+	return devs, nil
+}
+
+func (nl *NICs) execOutput(ctx context.Context, prog string, args ...string) ([]byte, error) {
+	var st = <-nl.ga.ExecOutput(ctx, prog, args...)
+	so, se, err := st.Stdio()
+	if err != nil {
+		return nil, errors.Wrapf(err, "run %s failed: %s", prog, string(se))
+	}
+	return so, nil
+}
+
+// Persist writes the network config of the device with the backend and applies it.
+func Persist(ctx context.Context, ga *agent.Agent, backend Backend, dev string, ip meta.IP) error {
+	if fname := backend.ConfigFile(dev); fname != "" {
+		if err := writeFileToGuest(ctx, ga, []byte(backend.Render(dev, ip)), fname); err != nil {
+			return errors.Wrapf(err, "failed to write %s", fname)
+		}
+	}
+	for _, cmd := range backend.ApplyCmds(dev, ip) {
+		var st = <-ga.ExecOutput(ctx, cmd[0], cmd[1:]...)
+		if _, se, err := st.Stdio(); err != nil {
+			return errors.Wrapf(err, "failed to run %v: %s", cmd, string(se))
+		}
+	}
+	return nil
+}
+
 func (nl *NICs) execVMInitScript(ctx context.Context, args ...string) error {
 	vmFname := "/tmp/vm-init.sh"
 	if err := writeFileToGuest(ctx, nl.ga, []byte(vmInitScript), vmFname); err != nil {
@@ -190,22 +337,22 @@ func (n *Nic) doIP(ctx context.Context, cmd string, args ...string) error {
 	return errors.Wrapf(err, "%s %v failed", cmd, args)
 }
 
-// GetEthFile .
-func GetEthFile(distro, dev string) (string, error) {
-	switch distro {
+// GetEthFiles returns the network config files of the device which may be
+// written by yavirt, they're removed from the captured images.
+// Alpine's /etc/network/interfaces holds the loopback as well, so it's kept.
+func GetEthFiles(distro, dev string) ([]string, error) {
+	switch strings.ToLower(distro) {
 	case types.Ubuntu:
-		return getEthUbuntuFile(dev), nil
-	case types.CentOS:
-		return getEthCentOSFile(dev), nil
+		return []string{fmt.Sprintf(types.EthUbuntuFileFmt, dev), networkd{}.ConfigFile(dev), netplan{}.ConfigFile(dev)}, nil
+	case types.Debian:
+		return []string{ifupdown{}.ConfigFile(dev), networkd{}.ConfigFile(dev)}, nil
+	case types.CentOS, types.RHEL:
+		return []string{ifcfg{}.ConfigFile(dev), networkManager{}.ConfigFile(dev)}, nil
+	case types.Rocky, types.AlmaLinux, types.Fedora:
+		return []string{networkManager{}.ConfigFile(dev)}, nil
+	case types.Alpine, types.AlpineLinux, types.Windows:
+		return nil, nil
 	default:
-		return "", errors.Wrapf(terrors.ErrInvalidValue, "invalid distro: %s", distro)
+		return nil, errors.Wrapf(terrors.ErrInvalidValue, "invalid distro: %s", distro)
 	}
 }
-
-func getEthUbuntuFile(dev string) string {
-	return fmt.Sprintf(types.EthUbuntuFileFmt, dev)
-}
-
-func getEthCentOSFile(dev string) string {
-	return fmt.Sprintf(types.EthCentOSFileFmt, dev)
-}
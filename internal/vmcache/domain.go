@@ -7,6 +7,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/cockroachdb/errors"
 	"github.com/digitalocean/go-libvirt"
 	"github.com/digitalocean/go-libvirt/socket/dialers"
 	"github.com/dustin/go-humanize"
@@ -50,21 +51,76 @@ type VMCache struct {
 }
 
 func (vc *VMCache) NotifyEvent(e libvirt.DomainEventLifecycleMsg) {
-	evt := intertypes.Event{
-		ID:   e.Dom.Name,
-		Type: intertypes.EventTypeGuest,
-	}
+	evt := vc.newEvent(e.Dom.Name)
 	switch e.Event {
 	case DomainEventStarted:
 		evt.Op = intertypes.StartOp
 	case DomainEventStopped:
 		evt.Op = intertypes.DieOp
+	case DomainEventSuspended:
+		evt.Op = intertypes.PauseOp
+		evt.Detail = suspendedDetail(e.Detail)
+	case DomainEventCrashed:
+		evt.Op = intertypes.CrashOp
+		evt.Detail = "panicked"
 	default:
 		return
 	}
 	vc.watchers.Watched(evt)
 }
 
+// NotifyWatchdog turns the expiry of watchdog into an event.
+func (vc *VMCache) NotifyWatchdog(e libvirt.DomainEventWatchdogMsg) {
+	evt := vc.newEvent(e.Dom.Name)
+	evt.Op = intertypes.WatchdogOp
+	evt.Detail = watchdogAction(e.Action)
+	vc.watchers.Watched(evt)
+}
+
+// NotifyAgentLifecycle turns the connection of guest agent into an event.
+func (vc *VMCache) NotifyAgentLifecycle(e *libvirt.DomainEventCallbackAgentLifecycleMsg) {
+	evt := vc.newEvent(e.Dom.Name)
+	switch libvirt.ConnectDomainEventAgentLifecycleState(e.State) {
+	case libvirt.ConnectDomainEventAgentLifecycleStateConnected:
+		evt.Op = intertypes.AgentConnectOp
+	case libvirt.ConnectDomainEventAgentLifecycleStateDisconnected:
+		evt.Op = intertypes.AgentDisconnectOp
+	default:
+		return
+	}
+	vc.watchers.Watched(evt)
+}
+
+// NotifyBlockJob turns the end of block job, e.g. the commit of snapshot,
+// into an event.
+func (vc *VMCache) NotifyBlockJob(e libvirt.DomainEventBlockJobMsg) {
+	evt := vc.newEvent(e.Dom.Name)
+	evt.Op = intertypes.BlockJobOp
+	evt.Detail = fmt.Sprintf("%s job of %s", blockJobType(e.Type), e.Path)
+	switch libvirt.ConnectDomainEventBlockJobStatus(e.Status) {
+	case libvirt.DomainBlockJobFailed:
+		evt.Error = "block job failed"
+	default:
+		evt.Status = blockJobStatus(e.Status)
+	}
+	vc.watchers.Watched(evt)
+}
+
+// newEvent returns an event of domain with its cached state.
+func (vc *VMCache) newEvent(name string) intertypes.Event {
+	evt := intertypes.Event{
+		ID:   name,
+		Type: intertypes.EventTypeGuest,
+		Time: time.Now().UTC(),
+	}
+	vc.mu.Lock()
+	defer vc.mu.Unlock()
+	if entry, ok := vc.localDomainCache[name]; ok {
+		evt.Status = State2Str(entry.State)
+	}
+	return evt
+}
+
 // in order to avoid data race, please don't change this type of value after it created
 type DomainCacheEntry struct {
 	Name     string
@@ -74,7 +130,9 @@ type DomainCacheEntry struct {
 	CPU      int
 	Memory   int64
 	GPUAddrs []string
-	Schema   *libvirtxml.Domain
+	// the dedicated pCPUs of vCPUs and emulator threads
+	PinnedCPUs []int
+	Schema     *libvirtxml.Domain
 
 	AppID    string
 	AppSID   string
@@ -90,6 +148,7 @@ func (dce *DomainCacheEntry) Copy() *DomainCacheEntry {
 	ans := *dce
 	ans.GPUAddrs = make([]string, len(dce.GPUAddrs))
 	copy(ans.GPUAddrs, dce.GPUAddrs)
+	ans.PinnedCPUs = append([]int(nil), dce.PinnedCPUs...)
 	return &ans
 }
 
@@ -117,6 +176,10 @@ func extractInfoFromXML(domcfg *libvirtxml.Domain) *DomainCacheEntry {
 		}
 	}
 	cpu := int(domcfg.VCPU.Value)
+	// the guest which has hotpluggable vCPUs
+	if domcfg.VCPU.Current > 0 {
+		cpu = int(domcfg.VCPU.Current)
+	}
 	memStr := fmt.Sprintf("%d %s", domcfg.Memory.Value, domcfg.Memory.Unit)
 	memory, err := humanize.ParseBytes(memStr)
 	if err != nil {
@@ -146,24 +209,48 @@ func extractInfoFromXML(domcfg *libvirtxml.Domain) *DomainCacheEntry {
 	logger.Debugf(context.TODO(), "GPU addrs<%s>: %v", domcfg.Name, gpuAddrs)
 
 	entry := &DomainCacheEntry{
-		Name:     domcfg.Name,
-		UUID:     domcfg.UUID,
-		CPU:      cpu,
-		Memory:   int64(memory),
-		VNCPort:  vncPort,
-		GPUAddrs: gpuAddrs,
-		Schema:   domcfg,
-		EruName:  types.EruID(domcfg.Name),
-		IP:       meta.App.IP.IP,
-		AppID:    meta.App.ID.ID,
-		AppSID:   meta.App.ID.SID,
-		AppName:  meta.App.Name.Name,
-		UserName: meta.App.Owner.UserName,
-		UserID:   meta.App.Owner.UserID,
+		Name:       domcfg.Name,
+		UUID:       domcfg.UUID,
+		CPU:        cpu,
+		Memory:     int64(memory),
+		VNCPort:    vncPort,
+		GPUAddrs:   gpuAddrs,
+		PinnedCPUs: extractPinnedCPUs(domcfg),
+		Schema:     domcfg,
+		EruName:    types.EruID(domcfg.Name),
+		IP:         meta.App.IP.IP,
+		AppID:      meta.App.ID.ID,
+		AppSID:     meta.App.ID.SID,
+		AppName:    meta.App.Name.Name,
+		UserName:   meta.App.Owner.UserName,
+		UserID:     meta.App.Owner.UserID,
 	}
 	return entry
 }
 
+func extractPinnedCPUs(domcfg *libvirtxml.Domain) []int {
+	if domcfg.CPUTune == nil {
+		return nil
+	}
+	var cpusets []string
+	for _, pin := range domcfg.CPUTune.VCPUPin {
+		cpusets = append(cpusets, pin.CPUSet)
+	}
+	if domcfg.CPUTune.EmulatorPin != nil {
+		cpusets = append(cpusets, domcfg.CPUTune.EmulatorPin.CPUSet)
+	}
+	var ans []int
+	for _, cpuset := range cpusets {
+		cpus, err := intertypes.ParseCPUSet(cpuset)
+		if err != nil {
+			log.WithFunc("extractPinnedCPUs").Warnf(context.TODO(), "invalid cpuset of %s: %s", domcfg.Name, cpuset)
+			continue
+		}
+		ans = append(ans, cpus...)
+	}
+	return ans
+}
+
 func (vc *VMCache) updateAllDomainsHelper(l *libvirt.Libvirt) {
 	logger := log.WithFunc("updateAllDomainsHelper")
 
@@ -252,7 +339,7 @@ func (vc *VMCache) updateOneDomain(ctx context.Context, l *libvirt.Libvirt, dom
 	return nil
 }
 
-func (vc *VMCache) processLibvirtEvents(ctx context.Context, l *libvirt.Libvirt, ch <-chan libvirt.DomainEventLifecycleMsg) {
+func (vc *VMCache) processLibvirtEvents(ctx context.Context, l *libvirt.Libvirt, ch <-chan libvirt.DomainEventLifecycleMsg, subCh <-chan any) {
 	logger := log.WithFunc("processLibvirtEvents")
 	for {
 		select {
@@ -274,6 +361,22 @@ func (vc *VMCache) processLibvirtEvents(ctx context.Context, l *libvirt.Libvirt,
 				}
 			}
 			vc.NotifyEvent(evt)
+		case evt, ok := <-subCh:
+			if !ok {
+				logger.Warnf(ctx, "subscribed event channel seems closed")
+				return
+			}
+			switch msg := evt.(type) {
+			case *libvirt.DomainEventCallbackWatchdogMsg:
+				logger.Warnf(ctx, "watchdog of domain %s expired, action %d", msg.Msg.Dom.Name, msg.Msg.Action)
+				vc.NotifyWatchdog(msg.Msg)
+			case *libvirt.DomainEventCallbackAgentLifecycleMsg:
+				logger.Infof(ctx, "agent of domain %s state %d", msg.Dom.Name, msg.State)
+				vc.NotifyAgentLifecycle(msg)
+			case *libvirt.DomainEventCallbackBlockJobMsg:
+				logger.Infof(ctx, "block job of domain %s %v", msg.Msg.Dom.Name, msg.Msg)
+				vc.NotifyBlockJob(msg.Msg)
+			}
 		case <-ctx.Done():
 			logger.Infof(context.TODO(), "[processLibvirtEvents] ctx done")
 			return
@@ -281,6 +384,35 @@ func (vc *VMCache) processLibvirtEvents(ctx context.Context, l *libvirt.Libvirt,
 	}
 }
 
+// subscribeEvents merges the events of ids into one channel, which is closed
+// once any of them is closed, so the caller reconnects libvirt.
+func subscribeEvents(ctx context.Context, l *libvirt.Libvirt, ids ...libvirt.DomainEventID) (<-chan any, error) {
+	out := make(chan any)
+	done := make(chan struct{})
+	var once sync.Once
+	for _, id := range ids {
+		ch, err := l.SubscribeEvents(ctx, id, libvirt.OptDomain{})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to subscribe events %d", id)
+		}
+		go func() {
+			defer once.Do(func() { close(done) })
+			for evt := range ch {
+				select {
+				case out <- evt:
+				case <-ctx.Done():
+					// go-libvirt blocks on sending, so drain it until it's closed
+				}
+			}
+		}()
+	}
+	go func() {
+		<-done
+		close(out)
+	}()
+	return out, nil
+}
+
 func (vc *VMCache) handleDomainEvents(ctx context.Context) {
 	logger := log.WithFunc("handleDomainEvents")
 	defer logger.Infof(ctx, "[handleDomainEvents] exit")
@@ -305,7 +437,17 @@ func (vc *VMCache) handleDomainEvents(ctx context.Context) {
 			evtCancel()
 			continue
 		}
-		vc.processLibvirtEvents(ctx, l, ch)
+		subCh, err := subscribeEvents(evtCtx, l,
+			libvirt.DomainEventIDWatchdog,
+			libvirt.DomainEventIDAgentLifecycle,
+			libvirt.DomainEventIDBlockJob,
+		)
+		if err != nil {
+			logger.Errorf(ctx, err, "failed to subscribe events")
+			evtCancel()
+			continue
+		}
+		vc.processLibvirtEvents(ctx, l, ch, subCh)
 		evtCancel()
 	}
 }
@@ -388,6 +530,18 @@ func FetchGPUAddrs() []string {
 	return resps
 }
 
+// FetchPinnedCPUs returns the pCPUs which are pinned by domains.
+func FetchPinnedCPUs() []int {
+	gVC.mu.Lock()
+	defer gVC.mu.Unlock()
+
+	resps := make([]int, 0)
+	for _, entry := range gVC.localDomainCache {
+		resps = append(resps, entry.PinnedCPUs...)
+	}
+	return resps
+}
+
 func FetchDomainGPUAddrs() map[string][]string {
 	gVC.mu.Lock()
 	defer gVC.mu.Unlock()
@@ -406,8 +560,9 @@ func FetchDomainsInfo() map[string]any {
 	resps := make(map[string]any)
 	for name, entry := range gVC.localDomainCache {
 		resps[name] = map[string]any{
-			"gpus":  entry.GPUAddrs,
-			"state": State2Str(entry.State),
+			"gpus":        entry.GPUAddrs,
+			"pinned_cpus": intertypes.FormatCPUSet(entry.PinnedCPUs),
+			"state":       State2Str(entry.State),
 		}
 	}
 	return resps
@@ -435,6 +590,7 @@ func Setup(ctx context.Context, cfg *configs.Config, ws *interutils.Watchers) er
 		statsUpdateInterval: 10 * time.Second,
 		watchers:            ws,
 	}
-	gVC.Run(ctx, cfg.EnableLibvirtMetrics)
+	// the balloon policy depends on the memory stats
+	gVC.Run(ctx, cfg.EnableLibvirtMetrics || cfg.Resource.Balloon.Enable)
 	return nil
 }
@@ -1,9 +1,12 @@
 package vmcache
 
 import (
+	"context"
 	"testing"
 
 	"github.com/digitalocean/go-libvirt"
+	intertypes "github.com/projecteru2/yavirt/internal/types"
+	interutils "github.com/projecteru2/yavirt/internal/utils"
 	"github.com/stretchr/testify/assert"
 	"libvirt.org/go/libvirtxml"
 )
@@ -42,3 +45,57 @@ func TestDomainCacheEntryCopy(t *testing.T) {
 	assert.Equal(t, copied.GPUAddrs, []string{"GPU1", "GPU2"})
 	assert.Equal(t, original.GPUAddrs, []string{"GPU1", "GPU2", "GPU3"})
 }
+
+func TestNotifyEvents(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ws := interutils.NewWatchers()
+	go ws.Run(ctx)
+	vc := &VMCache{
+		localDomainCache: map[string]*DomainCacheEntry{
+			"guest": {Name: "guest", State: libvirt.DomainPaused},
+		},
+		watchers: ws,
+	}
+	w, err := ws.Get()
+	assert.NoError(t, err)
+	defer w.Stop()
+
+	dom := libvirt.Domain{Name: "guest"}
+	vc.NotifyEvent(libvirt.DomainEventLifecycleMsg{
+		Dom:    dom,
+		Event:  DomainEventSuspended,
+		Detail: int32(libvirt.DomainEventSuspendedIoerror),
+	})
+	evt := <-w.Events()
+	assert.Equal(t, intertypes.PauseOp, evt.Op)
+	assert.Equal(t, "io-error", evt.Detail)
+	assert.Equal(t, "PAUSED", evt.Status)
+
+	vc.NotifyAgentLifecycle(&libvirt.DomainEventCallbackAgentLifecycleMsg{
+		Dom:   dom,
+		State: int32(libvirt.ConnectDomainEventAgentLifecycleStateDisconnected),
+	})
+	evt = <-w.Events()
+	assert.Equal(t, intertypes.AgentDisconnectOp, evt.Op)
+
+	vc.NotifyBlockJob(libvirt.DomainEventBlockJobMsg{
+		Dom:    dom,
+		Path:   "vda",
+		Type:   int32(libvirt.DomainBlockJobTypeCommit),
+		Status: int32(libvirt.DomainBlockJobFailed),
+	})
+	evt = <-w.Events()
+	assert.Equal(t, intertypes.BlockJobOp, evt.Op)
+	assert.Equal(t, "commit job of vda", evt.Detail)
+	assert.True(t, evt.Failed())
+
+	vc.NotifyWatchdog(libvirt.DomainEventWatchdogMsg{
+		Dom:    libvirt.Domain{Name: "gone"},
+		Action: int32(libvirt.DomainEventWatchdogReset),
+	})
+	evt = <-w.Events()
+	assert.Equal(t, intertypes.WatchdogOp, evt.Op)
+	assert.Equal(t, "reset", evt.Detail)
+	assert.Empty(t, evt.Status)
+}
@@ -24,6 +24,7 @@ const (
 	DomainEventStopped
 	DomainEventShutdown
 	DomainEventPMSuspended
+	DomainEventCrashed
 )
 
 func State2Str(state libvirt.DomainState) string {
@@ -47,6 +48,84 @@ func State2Str(state libvirt.DomainState) string {
 	}
 }
 
+func suspendedDetail(detail int32) string {
+	switch libvirt.DomainEventSuspendedDetailType(detail) {
+	case libvirt.DomainEventSuspendedPaused:
+		return "paused"
+	case libvirt.DomainEventSuspendedMigrated:
+		return "migrated"
+	case libvirt.DomainEventSuspendedIoerror:
+		return "io-error"
+	case libvirt.DomainEventSuspendedWatchdog:
+		return "watchdog"
+	case libvirt.DomainEventSuspendedRestored:
+		return "restored"
+	case libvirt.DomainEventSuspendedFromSnapshot:
+		return "from-snapshot"
+	case libvirt.DomainEventSuspendedAPIError:
+		return "api-error"
+	case libvirt.DomainEventSuspendedPostcopy:
+		return "postcopy"
+	case libvirt.DomainEventSuspendedPostcopyFailed:
+		return "postcopy-failed"
+	default:
+		return "unknown"
+	}
+}
+
+func watchdogAction(action int32) string {
+	switch libvirt.DomainEventWatchdogAction(action) {
+	case libvirt.DomainEventWatchdogNone:
+		return "none"
+	case libvirt.DomainEventWatchdogPause:
+		return "pause"
+	case libvirt.DomainEventWatchdogReset:
+		return "reset"
+	case libvirt.DomainEventWatchdogPoweroff:
+		return "poweroff"
+	case libvirt.DomainEventWatchdogShutdown:
+		return "shutdown"
+	case libvirt.DomainEventWatchdogDebug:
+		return "debug"
+	case libvirt.DomainEventWatchdogInjectnmi:
+		return "inject-nmi"
+	default:
+		return "unknown"
+	}
+}
+
+func blockJobType(typ int32) string {
+	switch libvirt.DomainBlockJobType(typ) {
+	case libvirt.DomainBlockJobTypePull:
+		return "pull"
+	case libvirt.DomainBlockJobTypeCopy:
+		return "copy"
+	case libvirt.DomainBlockJobTypeCommit:
+		return "commit"
+	case libvirt.DomainBlockJobTypeActiveCommit:
+		return "active-commit"
+	case libvirt.DomainBlockJobTypeBackup:
+		return "backup"
+	default:
+		return "unknown"
+	}
+}
+
+func blockJobStatus(status int32) string {
+	switch libvirt.ConnectDomainEventBlockJobStatus(status) {
+	case libvirt.DomainBlockJobCompleted:
+		return "completed"
+	case libvirt.DomainBlockJobFailed:
+		return "failed"
+	case libvirt.DomainBlockJobCanceled:
+		return "canceled"
+	case libvirt.DomainBlockJobReady:
+		return "ready"
+	default:
+		return "unknown"
+	}
+}
+
 type LibvirtPool struct {
 	index   atomic.Int64
 	socket  string
@@ -3,6 +3,7 @@ package base
 type Option func(o *OptionValue)
 type OptionValue struct {
 	Snapshot string
+	Blank    bool
 }
 
 func WithSnapshot(snapshot string) Option {
@@ -10,3 +11,11 @@ func WithSnapshot(snapshot string) Option {
 		o.Snapshot = snapshot
 	}
 }
+
+// WithBlank prepares a blank sys disk rather than writing the image to it,
+// it's for installing the OS from an ISO.
+func WithBlank() Option {
+	return func(o *OptionValue) {
+		o.Blank = true
+	}
+}
@@ -290,11 +290,16 @@ func resetEth0(gfs guestfs.Guestfs) error {
 		return errors.Wrap(err, "")
 	}
 
-	path, err := nic.GetEthFile(distro, "eth0")
+	paths, err := nic.GetEthFiles(distro, "eth0")
 	if err != nil {
 		return errors.Wrap(err, "")
 	}
-	return gfs.Remove(path)
+	for _, path := range paths {
+		if err := gfs.Remove(path); err != nil {
+			return errors.Wrapf(err, "failed to remove %s", path)
+		}
+	}
+	return nil
 }
 
 // GetDevicePathByName .
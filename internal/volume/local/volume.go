@@ -15,6 +15,7 @@ import (
 	stotypes "github.com/projecteru2/resource-storage/storage/types"
 	"github.com/projecteru2/yavirt/configs"
 	"github.com/projecteru2/yavirt/internal/meta"
+	"github.com/projecteru2/yavirt/internal/types"
 	interutils "github.com/projecteru2/yavirt/internal/utils"
 	"github.com/projecteru2/yavirt/internal/virt/agent"
 	"github.com/projecteru2/yavirt/internal/virt/guestfs"
@@ -45,6 +46,7 @@ type Volume struct {
 	Format         string       `json:"format" mapstructure:"format"`
 	SnapIDs        []string     `json:"snaps" mapstructure:"snaps"`
 	BaseSnapshotID string       `json:"base_snapshot_id" mapstructure:"base_snapshot_id"`
+	ThrottleGroup  string       `json:"throttle_group,omitempty" mapstructure:"throttle_group"`
 	Snaps          Snapshots    `json:"-" mapstructure:"-"`
 	flock          *utils.Flock `json:"-" mapstructure:"-"`
 }
@@ -265,10 +267,17 @@ func (v *Volume) IsSys() bool {
 	return strings.Contains(v.Flags, "s")
 }
 
-func (v *Volume) PrepareSysDisk(ctx context.Context, img *vmitypes.Image, _ ...base.Option) error {
+func (v *Volume) PrepareSysDisk(ctx context.Context, img *vmitypes.Image, opts ...base.Option) error {
 	if !v.IsSys() {
 		panic("not a sys disk")
 	}
+	optVal := &base.OptionValue{}
+	for _, opt := range opts {
+		opt(optVal)
+	}
+	if optVal.Blank {
+		return interutils.CreateImage(ctx, VolQcow2Format, v.Filepath(), v.SizeInBytes)
+	}
 	rc, err := vmiFact.Pull(ctx, img, vmitypes.PullPolicyAlways)
 	if err != nil {
 		return errors.Wrapf(err, "failed to pull image %s: %s", img.Fullname(), err)
@@ -333,6 +342,7 @@ func (v *Volume) GenerateXML() ([]byte, error) {
 		"write_iops": fmt.Sprintf("%d", v.WriteIOPS),
 		"read_bps":   fmt.Sprintf("%d", v.ReadBPS),
 		"write_bps":  fmt.Sprintf("%d", v.WriteBPS),
+		"group_name": v.ThrottleGroup,
 	}
 
 	if tmpl == nil {
@@ -350,6 +360,14 @@ func (v *Volume) GenerateXML() ([]byte, error) {
 	return wr.Bytes(), nil
 }
 
+// JoinThrottleGroup takes the limits of group, which are shared by
+// the volumes of the guest in the group.
+func (v *Volume) JoinThrottleGroup(group *types.ThrottleGroup) {
+	v.ThrottleGroup = group.Name
+	v.ReadIOPS, v.WriteIOPS = group.ReadIOPS, group.WriteIOPS
+	v.ReadBPS, v.WriteBPS = group.ReadBPS, group.WriteBPS
+}
+
 func (v *Volume) GetGfx() (guestfs.Guestfs, error) {
 	return gfsx.New(v.Filepath())
 }
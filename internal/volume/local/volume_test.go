@@ -3,9 +3,11 @@ package local
 import (
 	"fmt"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/projecteru2/yavirt/configs"
+	"github.com/projecteru2/yavirt/internal/types"
 	"github.com/projecteru2/yavirt/pkg/test/assert"
 )
 
@@ -50,4 +52,10 @@ func TestGenerateXML(t *testing.T) {
 	bs, err := vol.GenerateXML()
 	assert.NilErr(t, err)
 	fmt.Printf("%s\n", string(bs))
+
+	vol.JoinThrottleGroup(&types.ThrottleGroup{Name: "g1", VolumeQoS: types.VolumeQoS{ReadIOPS: 100}})
+	bs, err = vol.GenerateXML()
+	assert.NilErr(t, err)
+	assert.True(t, strings.Contains(string(bs), "<group_name>g1</group_name>"))
+	assert.True(t, strings.Contains(string(bs), "<read_iops_sec>100</read_iops_sec>"))
 }
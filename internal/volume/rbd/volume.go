@@ -113,6 +113,9 @@ func (v *Volume) PrepareSysDisk(ctx context.Context, img *vmitypes.Image, opts .
 	}
 
 	rbdDisk := fmt.Sprintf("rbd:%s/%s:id=%s", v.Pool, v.Image, configs.Conf.Storage.Ceph.Username)
+	if optVal.Blank {
+		return interutils.CreateImage(ctx, "raw", rbdDisk, v.SizeInBytes)
+	}
 	// try to create rbd from image snapshot
 	if err := v.createSysRBDFromSnap(ctx, client, ioctx, img); err != nil {
 		logger.Warnf(ctx, "failed to create rbd(%s) from image(%s) snaoshot: %s", rbdDisk, img.Fullname(), err)
@@ -27,6 +27,10 @@ const (
 	DomainRunning = libvirtgo.DomainRunning
 	// DomainUndefineManagedSave .
 	DomainUndefineManagedSave = libvirtgo.DomainUndefineManagedSave
+	// DomainUndefineNvram .
+	DomainUndefineNvram = libvirtgo.DomainUndefineNvram
+	// DomainUndefineSnapshotsMetadata .
+	DomainUndefineSnapshotsMetadata = libvirtgo.DomainUndefineSnapshotsMetadata
 	// DomainShutoff is shutted down.
 	DomainShutoff = libvirtgo.DomainShutoff
 	// DomainShutting is shuting state.
@@ -61,6 +65,8 @@ const (
 	DomainMemMaximum = libvirtgo.DomainMemMaximum
 	// DomainMemConfig .
 	DomainMemConfig = libvirtgo.DomainMemConfig
+	// DomainMemLive .
+	DomainMemLive = libvirtgo.DomainMemLive
 
 	// DomainConsoleForce .
 	DomainConsoleForce = libvirtgo.DomainConsoleForce
@@ -76,4 +82,32 @@ const (
 	DomainDeviceModifyCurrent = libvirtgo.DomainDeviceModifyCurrent
 	// DomainDeviceModifyLive .
 	DomainDeviceModifyLive = libvirtgo.DomainDeviceModifyLive
+
+	// DomainAffectLive .
+	DomainAffectLive = libvirtgo.DomainAffectLive
+	// DomainAffectConfig .
+	DomainAffectConfig = libvirtgo.DomainAffectConfig
+
+	// DumpMemoryOnly dumps the memory of guest only, which crash or kdump tools read.
+	DumpMemoryOnly = libvirtgo.DumpMemoryOnly
+	// DumpLive keeps the guest running while dumping.
+	DumpLive = libvirtgo.DumpLive
+
+	// DomainCoreDumpFormatRaw is ELF.
+	DomainCoreDumpFormatRaw = libvirtgo.DomainCoreDumpFormatRaw
+	// DomainCoreDumpFormatKdumpZlib is kdump-compressed by zlib.
+	DomainCoreDumpFormatKdumpZlib = libvirtgo.DomainCoreDumpFormatKdumpZlib
+	// DomainCoreDumpFormatKdumpLzo is kdump-compressed by lzo.
+	DomainCoreDumpFormatKdumpLzo = libvirtgo.DomainCoreDumpFormatKdumpLzo
+	// DomainCoreDumpFormatKdumpSnappy is kdump-compressed by snappy.
+	DomainCoreDumpFormatKdumpSnappy = libvirtgo.DomainCoreDumpFormatKdumpSnappy
 )
+
+// DomainModificationImpact .
+type DomainModificationImpact = libvirtgo.DomainModificationImpact
+
+// DomainCoreDumpFlags .
+type DomainCoreDumpFlags = libvirtgo.DomainCoreDumpFlags
+
+// DomainCoreDumpFormat .
+type DomainCoreDumpFormat = libvirtgo.DomainCoreDumpFormat
@@ -1,6 +1,7 @@
 package libvirt
 
 import (
+	"bytes"
 	"context"
 	"encoding/hex"
 	"time"
@@ -28,6 +29,7 @@ type Domain interface { //nolint
 	AmplifyVolume(filepath string, capacity uint64) error
 	AttachDevice(xml string) (DomainState, error)
 	DetachDevice(xml string) (st DomainState, err error)
+	UpdateDevice(xml string) (st DomainState, err error)
 
 	GetState() (DomainState, error)
 	GetInfo() (*DomainInfo, error)
@@ -36,6 +38,19 @@ type Domain interface { //nolint
 	GetName() (string, error)
 	QemuAgentCommand(ctx context.Context, cmd string) (string, error)
 	OpenConsole(devname string, flags *ConsoleFlags) (*Console, error)
+	Screenshot(screen uint32) (mime string, data []byte, err error)
+	ManagedSave() error
+	HasManagedSaveImage() (bool, error)
+	ManagedSaveRemove() error
+	SnapshotCreateXML(xml string) error
+	RevertToSnapshot(name string) error
+	DeleteSnapshot(name string) error
+	SetSchedulerParameters(params TypedParams, flags DomainModificationImpact) error
+	SetBlockIOTune(disk string, params TypedParams, flags DomainModificationImpact) error
+	SetInterfaceParameters(device string, params TypedParams, flags DomainModificationImpact) error
+	CoreDumpWithFormat(to string, format DomainCoreDumpFormat, flags DomainCoreDumpFlags) error
+	GetJobInfo() (*DomainJobInfo, error)
+	AbortJob() error
 }
 
 // Domainee is a implement of Domain.
@@ -196,6 +211,118 @@ func (d *Domainee) QemuAgentCommand(ctx context.Context, cmd string) (string, er
 	return retStrArr[0], nil
 }
 
+// ManagedSave saves the memory and stops the domain, it's restored by Create.
+func (d *Domainee) ManagedSave() error {
+	return d.Libvirt.DomainManagedSave(*d.Domain, 0)
+}
+
+// HasManagedSaveImage .
+func (d *Domainee) HasManagedSaveImage() (bool, error) {
+	ret, err := d.Libvirt.DomainHasManagedSaveImage(*d.Domain, 0)
+	return ret == 1, err
+}
+
+// ManagedSaveRemove discards the saved memory.
+func (d *Domainee) ManagedSaveRemove() error {
+	return d.Libvirt.DomainManagedSaveRemove(*d.Domain, 0)
+}
+
+// SnapshotCreateXML .
+func (d *Domainee) SnapshotCreateXML(xml string) error {
+	_, err := d.Libvirt.DomainSnapshotCreateXML(*d.Domain, xml, uint32(libvirtgo.DomainSnapshotCreateAtomic))
+	return err
+}
+
+// RevertToSnapshot reverts the domain to the state of snapshot, including running or not.
+func (d *Domainee) RevertToSnapshot(name string) error {
+	snap, err := d.Libvirt.DomainSnapshotLookupByName(*d.Domain, name, 0)
+	if err != nil {
+		return err
+	}
+	return d.Libvirt.DomainRevertToSnapshot(snap, uint32(libvirtgo.DomainSnapshotRevertForce))
+}
+
+// DeleteSnapshot .
+func (d *Domainee) DeleteSnapshot(name string) error {
+	snap, err := d.Libvirt.DomainSnapshotLookupByName(*d.Domain, name, 0)
+	if err != nil {
+		return err
+	}
+	return d.Libvirt.DomainSnapshotDelete(snap, 0)
+}
+
+// SetSchedulerParameters changes the <cputune>, e.g. cpu_shares.
+func (d *Domainee) SetSchedulerParameters(params TypedParams, flags DomainModificationImpact) error {
+	tps, err := params.typed()
+	if err != nil {
+		return err
+	}
+	return d.Libvirt.DomainSetSchedulerParametersFlags(*d.Domain, tps, uint32(flags))
+}
+
+// SetBlockIOTune changes the <iotune> of disk, which is the target dev, e.g. vda.
+func (d *Domainee) SetBlockIOTune(disk string, params TypedParams, flags DomainModificationImpact) error {
+	tps, err := params.typed()
+	if err != nil {
+		return err
+	}
+	return d.Libvirt.DomainSetBlockIOTune(*d.Domain, disk, tps, uint32(flags))
+}
+
+// SetInterfaceParameters changes the <bandwidth> of interface, device is its target dev or MAC.
+func (d *Domainee) SetInterfaceParameters(device string, params TypedParams, flags DomainModificationImpact) error {
+	tps, err := params.typed()
+	if err != nil {
+		return err
+	}
+	return d.Libvirt.DomainSetInterfaceParameters(*d.Domain, device, tps, uint32(flags))
+}
+
+// CoreDumpWithFormat dumps the guest into the host file to.
+func (d *Domainee) CoreDumpWithFormat(to string, format DomainCoreDumpFormat, flags DomainCoreDumpFlags) error {
+	return d.Libvirt.DomainCoreDumpWithFormat(*d.Domain, to, uint32(format), flags)
+}
+
+// GetJobInfo returns the progress of the running job.
+func (d *Domainee) GetJobInfo() (*DomainJobInfo, error) {
+	typ, elapsed, remaining, dataTotal, dataProcessed, dataRemaining, memTotal, memProcessed, memRemaining, fileTotal, fileProcessed, fileRemaining, err := d.Libvirt.DomainGetJobInfo(*d.Domain)
+	if err != nil {
+		return nil, err
+	}
+	return &DomainJobInfo{
+		Type:          typ,
+		TimeElapsed:   elapsed,
+		TimeRemaining: remaining,
+		DataTotal:     dataTotal,
+		DataProcessed: dataProcessed,
+		DataRemaining: dataRemaining,
+		MemTotal:      memTotal,
+		MemProcessed:  memProcessed,
+		MemRemaining:  memRemaining,
+		FileTotal:     fileTotal,
+		FileProcessed: fileProcessed,
+		FileRemaining: fileRemaining,
+	}, nil
+}
+
+// AbortJob aborts the running job, e.g. dumping.
+func (d *Domainee) AbortJob() error {
+	return d.Libvirt.DomainAbortJob(*d.Domain)
+}
+
+// Screenshot dumps the screen, QEMU returns a PPM image.
+func (d *Domainee) Screenshot(screen uint32) (string, []byte, error) {
+	var buf bytes.Buffer
+	mime, err := d.Libvirt.DomainScreenshot(*d.Domain, &buf, screen, 0)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(mime) == 0 {
+		return "", buf.Bytes(), nil
+	}
+	return mime[0], buf.Bytes(), nil
+}
+
 func (d *Domainee) OpenConsole(devname string, cf *ConsoleFlags) (*Console, error) {
 	con := newConsole()
 	go func() {
@@ -245,6 +372,24 @@ func (d *Domainee) DetachDevice(xml string) (st DomainState, err error) {
 	return
 }
 
+// UpdateDevice .
+func (d *Domainee) UpdateDevice(xml string) (st DomainState, err error) {
+	flags := DomainDeviceModifyConfig | DomainDeviceModifyCurrent
+
+	switch st, err = d.GetState(); {
+	case err != nil:
+		return
+	case st == DomainRunning:
+		flags |= DomainDeviceModifyLive
+	case st != DomainShutoff:
+		return DomainNoState, errors.Wrapf(terrors.ErrInvalidValue, "invalid domain state: %v", st)
+	}
+
+	err = d.Libvirt.DomainUpdateDeviceFlags(*d.Domain, xml, libvirtgo.DomainDeviceModifyFlags(flags))
+
+	return
+}
+
 // GetState .
 func (d *Domainee) GetState() (st DomainState, err error) {
 	flags := DomainNoState
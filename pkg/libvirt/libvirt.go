@@ -17,6 +17,8 @@ type Libvirt interface {
 	DefineDomain(string) (Domain, error)
 	ListDomainsNames() ([]string, error)
 	GetAllDomainStats(doms []libvirtgo.Domain) ([]libvirtgo.DomainStatsRecord, error)
+	GetCapabilities() (string, error)
+	BaselineCPU(cpus []string, migratable bool) (string, error)
 }
 
 // Libvirtee is a Libvirt implement.
@@ -94,3 +96,20 @@ func (l *Libvirtee) GetAllDomainStats(doms []libvirtgo.Domain) ([]libvirtgo.Doma
 	var statsType libvirtgo.DomainStatsTypes
 	return l.ConnectGetAllDomainStats(doms, uint32(statsType), flags)
 }
+
+// GetCapabilities returns the capabilities XML of the host.
+func (l *Libvirtee) GetCapabilities() (string, error) {
+	caps, err := l.ConnectGetCapabilities()
+	return caps, errors.Wrap(err, "")
+}
+
+// BaselineCPU computes the most feature-rich CPU which is compatible with all given CPUs,
+// the features which block migration are removed if migratable is true.
+func (l *Libvirtee) BaselineCPU(cpus []string, migratable bool) (string, error) {
+	var flags libvirtgo.ConnectBaselineCPUFlags
+	if migratable {
+		flags |= libvirtgo.ConnectBaselineCPUMigratable
+	}
+	cpu, err := l.ConnectBaselineCPU(cpus, flags)
+	return cpu, errors.Wrap(err, "")
+}
@@ -16,6 +16,20 @@ type Domain struct {
 	mock.Mock
 }
 
+// AbortJob provides a mock function with given fields:
+func (_m *Domain) AbortJob() error {
+	ret := _m.Called()
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // AmplifyVolume provides a mock function with given fields: filepath, cap
 func (_m *Domain) AmplifyVolume(filepath string, cap uint64) error {
 	ret := _m.Called(filepath, cap)
@@ -54,6 +68,20 @@ func (_m *Domain) AttachDevice(xml string) (third_partylibvirt.DomainState, erro
 	return r0, r1
 }
 
+// CoreDumpWithFormat provides a mock function with given fields: to, format, flags
+func (_m *Domain) CoreDumpWithFormat(to string, format libvirt.DomainCoreDumpFormat, flags libvirt.DomainCoreDumpFlags) error {
+	ret := _m.Called(to, format, flags)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, libvirt.DomainCoreDumpFormat, libvirt.DomainCoreDumpFlags) error); ok {
+		r0 = rf(to, format, flags)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Create provides a mock function with given fields:
 func (_m *Domain) Create() error {
 	ret := _m.Called()
@@ -68,6 +96,20 @@ func (_m *Domain) Create() error {
 	return r0
 }
 
+// DeleteSnapshot provides a mock function with given fields: name
+func (_m *Domain) DeleteSnapshot(name string) error {
+	ret := _m.Called(name)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(name)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Destroy provides a mock function with given fields:
 func (_m *Domain) Destroy() error {
 	ret := _m.Called()
@@ -146,6 +188,32 @@ func (_m *Domain) GetInfo() (*third_partylibvirt.DomainGetInfoRet, error) {
 	return r0, r1
 }
 
+// GetJobInfo provides a mock function with given fields:
+func (_m *Domain) GetJobInfo() (*third_partylibvirt.DomainGetJobInfoRet, error) {
+	ret := _m.Called()
+
+	var r0 *third_partylibvirt.DomainGetJobInfoRet
+	var r1 error
+	if rf, ok := ret.Get(0).(func() (*third_partylibvirt.DomainGetJobInfoRet, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() *third_partylibvirt.DomainGetJobInfoRet); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*third_partylibvirt.DomainGetJobInfoRet)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetName provides a mock function with given fields:
 func (_m *Domain) GetName() (string, error) {
 	ret := _m.Called()
@@ -242,6 +310,58 @@ func (_m *Domain) GetXMLDesc(flags third_partylibvirt.DomainXMLFlags) (string, e
 	return r0, r1
 }
 
+// HasManagedSaveImage provides a mock function with given fields:
+func (_m *Domain) HasManagedSaveImage() (bool, error) {
+	ret := _m.Called()
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func() (bool, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() bool); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ManagedSave provides a mock function with given fields:
+func (_m *Domain) ManagedSave() error {
+	ret := _m.Called()
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ManagedSaveRemove provides a mock function with given fields:
+func (_m *Domain) ManagedSaveRemove() error {
+	ret := _m.Called()
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // OpenConsole provides a mock function with given fields: devname, flags
 func (_m *Domain) OpenConsole(devname string, flags *libvirt.ConsoleFlags) (*libvirt.Console, error) {
 	ret := _m.Called(devname, flags)
@@ -306,6 +426,53 @@ func (_m *Domain) Resume() error {
 	return r0
 }
 
+// RevertToSnapshot provides a mock function with given fields: name
+func (_m *Domain) RevertToSnapshot(name string) error {
+	ret := _m.Called(name)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(name)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Screenshot provides a mock function with given fields: screen
+func (_m *Domain) Screenshot(screen uint32) (string, []byte, error) {
+	ret := _m.Called(screen)
+
+	var r0 string
+	var r1 []byte
+	var r2 error
+	if rf, ok := ret.Get(0).(func(uint32) (string, []byte, error)); ok {
+		return rf(screen)
+	}
+	if rf, ok := ret.Get(0).(func(uint32) string); ok {
+		r0 = rf(screen)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(uint32) []byte); ok {
+		r1 = rf(screen)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]byte)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(uint32) error); ok {
+		r2 = rf(screen)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // SetAutostart provides a mock function with given fields: autostart
 func (_m *Domain) SetAutostart(autostart bool) error {
 	ret := _m.Called(autostart)
@@ -320,6 +487,34 @@ func (_m *Domain) SetAutostart(autostart bool) error {
 	return r0
 }
 
+// SetBlockIOTune provides a mock function with given fields: disk, params, flags
+func (_m *Domain) SetBlockIOTune(disk string, params libvirt.TypedParams, flags libvirt.DomainModificationImpact) error {
+	ret := _m.Called(disk, params, flags)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, libvirt.TypedParams, libvirt.DomainModificationImpact) error); ok {
+		r0 = rf(disk, params, flags)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetInterfaceParameters provides a mock function with given fields: device, params, flags
+func (_m *Domain) SetInterfaceParameters(device string, params libvirt.TypedParams, flags libvirt.DomainModificationImpact) error {
+	ret := _m.Called(device, params, flags)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, libvirt.TypedParams, libvirt.DomainModificationImpact) error); ok {
+		r0 = rf(device, params, flags)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // SetMemoryFlags provides a mock function with given fields: memory, flags
 func (_m *Domain) SetMemoryFlags(memory uint64, flags third_partylibvirt.DomainMemoryModFlags) error {
 	ret := _m.Called(memory, flags)
@@ -348,6 +543,20 @@ func (_m *Domain) SetMemoryStatsPeriod(period int, config bool, live bool) error
 	return r0
 }
 
+// SetSchedulerParameters provides a mock function with given fields: params, flags
+func (_m *Domain) SetSchedulerParameters(params libvirt.TypedParams, flags libvirt.DomainModificationImpact) error {
+	ret := _m.Called(params, flags)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(libvirt.TypedParams, libvirt.DomainModificationImpact) error); ok {
+		r0 = rf(params, flags)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // SetVcpusFlags provides a mock function with given fields: vcpu, flags
 func (_m *Domain) SetVcpusFlags(vcpu uint, flags third_partylibvirt.DomainVCPUFlags) error {
 	ret := _m.Called(vcpu, flags)
@@ -376,6 +585,20 @@ func (_m *Domain) ShutdownFlags(flags libvirt.DomainShutdownFlags) error {
 	return r0
 }
 
+// SnapshotCreateXML provides a mock function with given fields: xml
+func (_m *Domain) SnapshotCreateXML(xml string) error {
+	ret := _m.Called(xml)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(xml)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Suspend provides a mock function with given fields:
 func (_m *Domain) Suspend() error {
 	ret := _m.Called()
@@ -404,6 +627,30 @@ func (_m *Domain) UndefineFlags(flags third_partylibvirt.DomainUndefineFlagsValu
 	return r0
 }
 
+// UpdateDevice provides a mock function with given fields: xml
+func (_m *Domain) UpdateDevice(xml string) (third_partylibvirt.DomainState, error) {
+	ret := _m.Called(xml)
+
+	var r0 third_partylibvirt.DomainState
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (third_partylibvirt.DomainState, error)); ok {
+		return rf(xml)
+	}
+	if rf, ok := ret.Get(0).(func(string) third_partylibvirt.DomainState); ok {
+		r0 = rf(xml)
+	} else {
+		r0 = ret.Get(0).(third_partylibvirt.DomainState)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(xml)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewDomain creates a new instance of Domain. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewDomain(t interface {
@@ -14,6 +14,30 @@ type Libvirt struct {
 	mock.Mock
 }
 
+// BaselineCPU provides a mock function with given fields: cpus, migratable
+func (_m *Libvirt) BaselineCPU(cpus []string, migratable bool) (string, error) {
+	ret := _m.Called(cpus, migratable)
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]string, bool) (string, error)); ok {
+		return rf(cpus, migratable)
+	}
+	if rf, ok := ret.Get(0).(func([]string, bool) string); ok {
+		r0 = rf(cpus, migratable)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func([]string, bool) error); ok {
+		r1 = rf(cpus, migratable)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Close provides a mock function with given fields:
 func (_m *Libvirt) Close() (int, error) {
 	ret := _m.Called()
@@ -90,6 +114,30 @@ func (_m *Libvirt) GetAllDomainStats(doms []third_partylibvirt.Domain) ([]third_
 	return r0, r1
 }
 
+// GetCapabilities provides a mock function with given fields:
+func (_m *Libvirt) GetCapabilities() (string, error) {
+	ret := _m.Called()
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func() (string, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ListDomainsNames provides a mock function with given fields:
 func (_m *Libvirt) ListDomainsNames() ([]string, error) {
 	ret := _m.Called()
@@ -1,6 +1,9 @@
 package libvirt
 
 import (
+	"sort"
+
+	"github.com/cockroachdb/errors"
 	libvirtgo "github.com/projecteru2/yavirt/third_party/libvirt"
 )
 
@@ -50,6 +53,9 @@ func GetDomainStateString(s DomainState) string {
 // DomainInfo .
 type DomainInfo = libvirtgo.DomainGetInfoRet
 
+// DomainJobInfo is the progress of the job running on domain, e.g. dumping.
+type DomainJobInfo = libvirtgo.DomainGetJobInfoRet
+
 // DomainXMLFlags .
 type DomainXMLFlags = libvirtgo.DomainXMLFlags
 
@@ -70,3 +76,34 @@ type DomainVcpuFlags = libvirtgo.DomainVCPUFlags
 
 // DomainMemoryModFlags .
 type DomainMemoryModFlags = libvirtgo.DomainMemoryModFlags
+
+// TypedParams are the named parameters of libvirt,
+// the values must be uint32, int64, uint64 or string.
+type TypedParams map[string]any
+
+func (p TypedParams) typed() ([]libvirtgo.TypedParam, error) {
+	fields := make([]string, 0, len(p))
+	for field := range p {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	tps := make([]libvirtgo.TypedParam, 0, len(p))
+	for _, field := range fields {
+		var val *libvirtgo.TypedParamValue
+		switch v := p[field].(type) {
+		case uint32:
+			val = libvirtgo.NewTypedParamValueUint(v)
+		case int64:
+			val = libvirtgo.NewTypedParamValueLlong(v)
+		case uint64:
+			val = libvirtgo.NewTypedParamValueUllong(v)
+		case string:
+			val = libvirtgo.NewTypedParamValueString(v)
+		default:
+			return nil, errors.Newf("unsupported type %T of parameter %s", v, field)
+		}
+		tps = append(tps, libvirtgo.TypedParam{Field: field, Value: *val})
+	}
+	return tps, nil
+}
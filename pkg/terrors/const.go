@@ -63,6 +63,8 @@ var (
 	ErrTooManyVolumes = errors.New("too many extra volumes")
 	// ErrCannotShrinkVolume .
 	ErrCannotShrinkVolume = errors.New("cannot shrink a volume")
+	// ErrLiveResizeUnavailable .
+	ErrLiveResizeUnavailable = errors.New("cannot resize a running guest")
 	// ErrDomainNotExists .
 	ErrDomainNotExists = errors.New("domain not exists")
 	// ErrInvalidVolumeBind .
@@ -110,4 +112,7 @@ var (
 	ErrFlockLocked = errors.New("flock locked")
 
 	ErrUnknownNetworkDriver = errors.New("unknown network driver")
+
+	// ErrDumpQuotaExceeded .
+	ErrDumpQuotaExceeded = errors.New("dump quota exceeded")
 )
@@ -5,6 +5,9 @@ import testify "github.com/stretchr/testify/mock"
 // Anything .
 const Anything = testify.Anything
 
+// MatchedBy .
+var MatchedBy = testify.MatchedBy
+
 // Mock .
 type Mock = testify.Mock
 
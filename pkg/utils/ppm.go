@@ -0,0 +1,117 @@
+package utils
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"strconv"
+
+	"github.com/cockroachdb/errors"
+)
+
+// PPMToPNG converts the binary PPM (P6) image to PNG,
+// QEMU dumps the screen in PPM.
+func PPMToPNG(ppm []byte) ([]byte, error) {
+	img, err := decodePPM(bytes.NewReader(ppm))
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, errors.Wrap(err, "")
+	}
+	return buf.Bytes(), nil
+}
+
+func decodePPM(r *bytes.Reader) (image.Image, error) {
+	magic, err := readPPMToken(r)
+	if err != nil {
+		return nil, err
+	}
+	if magic != "P6" {
+		return nil, errors.Newf("unsupported PPM format %s", magic)
+	}
+	var fields [3]int
+	for i := range fields {
+		tok, err := readPPMToken(r)
+		if err != nil {
+			return nil, err
+		}
+		if fields[i], err = strconv.Atoi(tok); err != nil || fields[i] <= 0 {
+			return nil, errors.Newf("invalid PPM header %s", tok)
+		}
+	}
+	width, height, maxVal := fields[0], fields[1], fields[2]
+	if maxVal > 65535 {
+		return nil, errors.Newf("invalid PPM max value %d", maxVal)
+	}
+
+	sampleSize := 1
+	if maxVal > 255 {
+		sampleSize = 2
+	}
+	// check the size before allocating, a bogus header mustn't make a huge image,
+	// the division avoids the overflow of multiplication
+	rowSize := r.Len() / height
+	if rowSize/3/sampleSize < width {
+		return nil, errors.Newf("truncated PPM, %dx%d needs more than %d bytes", width, height, r.Len())
+	}
+	row := make([]byte, width*3*sampleSize)
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		if _, err := io.ReadFull(r, row); err != nil {
+			return nil, errors.Wrap(err, "truncated PPM")
+		}
+		for x := 0; x < width; x++ {
+			var rgb [3]uint8
+			for c := range rgb {
+				off := (x*3 + c) * sampleSize
+				v := int(row[off])
+				if sampleSize == 2 {
+					v = v<<8 | int(row[off+1])
+				}
+				rgb[c] = uint8(v * 255 / maxVal)
+			}
+			img.SetRGBA(x, y, color.RGBA{R: rgb[0], G: rgb[1], B: rgb[2], A: 255})
+		}
+	}
+	return img, nil
+}
+
+// readPPMToken reads a whitespace separated token of the header,
+// the comments are skipped, and the single whitespace after it is consumed.
+func readPPMToken(r *bytes.Reader) (string, error) {
+	var tok []byte
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return "", errors.Wrap(err, "truncated PPM header")
+		}
+		switch {
+		case b == '#' && len(tok) == 0:
+			if err := skipPPMComment(r); err != nil {
+				return "", err
+			}
+		case b == ' ' || b == '\t' || b == '\n' || b == '\r':
+			if len(tok) > 0 {
+				return string(tok), nil
+			}
+		default:
+			tok = append(tok, b)
+		}
+	}
+}
+
+func skipPPMComment(r *bytes.Reader) error {
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return errors.Wrap(err, "truncated PPM header")
+		}
+		if b == '\n' {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/projecteru2/yavirt/pkg/test/assert"
+)
+
+func TestPPMToPNG(t *testing.T) {
+	ppm := append([]byte("P6\n# by qemu\n2 1\n255\n"), 255, 0, 0, 0, 128, 255)
+	bs, err := PPMToPNG(ppm)
+	assert.NilErr(t, err)
+	img, err := png.Decode(bytes.NewReader(bs))
+	assert.NilErr(t, err)
+	assert.Equal(t, 2, img.Bounds().Dx())
+	assert.Equal(t, 1, img.Bounds().Dy())
+	assert.Equal(t, color.RGBAModel.Convert(color.RGBA{R: 255, A: 255}), color.RGBAModel.Convert(img.At(0, 0)))
+	assert.Equal(t, color.RGBAModel.Convert(color.RGBA{G: 128, B: 255, A: 255}), color.RGBAModel.Convert(img.At(1, 0)))
+
+	// 16-bit samples
+	ppm = append([]byte("P6 1 1 65535\n"), 0xff, 0xff, 0, 0, 0x80, 0)
+	bs, err = PPMToPNG(ppm)
+	assert.NilErr(t, err)
+	img, err = png.Decode(bytes.NewReader(bs))
+	assert.NilErr(t, err)
+	assert.Equal(t, color.RGBAModel.Convert(color.RGBA{R: 255, B: 127, A: 255}), color.RGBAModel.Convert(img.At(0, 0)))
+
+	for _, invalid := range [][]byte{
+		nil,
+		[]byte("P3\n1 1\n255\n"),
+		[]byte("P6\n0 1\n255\n"),
+		append([]byte("P6\n2 1\n255\n"), 1, 2, 3),
+		// a bogus size mustn't be allocated
+		append([]byte("P6\n1000000000 1000000000\n255\n"), 1, 2, 3),
+		append([]byte("P6\n9223372036854775807 1\n255\n"), 1, 2, 3),
+	} {
+		_, err = PPMToPNG(invalid)
+		assert.Err(t, err)
+	}
+}
@@ -20,6 +20,10 @@ type OSInfo struct {
 	Distrib string `json:"distrib" default:"ubuntu"`
 	Version string `json:"version"`
 	Arch    string `json:"arch" default:"amd64"`
+	// boot requirements, empty Firmware means the host's default one
+	Firmware   string `json:"firmware,omitempty"`
+	SecureBoot bool   `json:"secure_boot,omitempty"`
+	TPM        bool   `json:"tpm,omitempty"`
 }
 
 type Image struct {
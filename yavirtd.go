@@ -15,6 +15,7 @@ import (
 	"github.com/projecteru2/core/log"
 	coretypes "github.com/projecteru2/core/types"
 	"github.com/projecteru2/yavirt/configs"
+	"github.com/projecteru2/yavirt/internal/console"
 	"github.com/projecteru2/yavirt/internal/debug"
 	"github.com/projecteru2/yavirt/internal/metrics"
 	grpcserver "github.com/projecteru2/yavirt/internal/rpc"
@@ -95,9 +96,10 @@ func initConfig(c *cli.Context) error {
 	return cfg.Prepare(c)
 }
 
-func startHTTPServer(addr string) {
+func startHTTPServer(addr string, svc *boar.Boar) {
 	http.Handle("/metrics", metrics.Handler())
 	http.HandleFunc("/debug/custom", debug.Handler)
+	http.Handle(console.Pattern(), console.NewHandler(svc))
 	server := &http.Server{
 		Addr:              addr,
 		ReadHeaderTimeout: 5 * time.Second,
@@ -147,6 +149,12 @@ func Run(c *cli.Context) error {
 	if err := virt.Cleanup(); err != nil {
 		return errors.Wrap(err, "")
 	}
+	if err := br.EnableEventJournal(ctx); err != nil {
+		return err
+	}
+	go br.PublishHostCPU(ctx)
+	br.RestoreGuests(ctx)
+	br.HandleCrashes(ctx)
 
 	grpcSrv, err := grpcserver.New(&configs.Conf, br)
 	if err != nil {
@@ -156,7 +164,7 @@ func Run(c *cli.Context) error {
 
 	errExitCh := make(chan struct{})
 	if configs.Conf.BindHTTPAddr != "" {
-		go startHTTPServer(configs.Conf.BindHTTPAddr)
+		go startHTTPServer(configs.Conf.BindHTTPAddr, br)
 	}
 	go func() {
 		defer close(errExitCh)
@@ -176,5 +184,8 @@ func Run(c *cli.Context) error {
 
 	grpcSrv.Stop(false)
 
+	if err := br.Shutdown(c.Context); err != nil {
+		log.Error(c.Context, err, "[main] failed to shutdown guests")
+	}
 	return nil
 }